@@ -14,9 +14,395 @@ const (
 	// Failure represents the failed operation label value for metrics.
 	Failure = "failure"
 
+	// ClientCanceled represents the label value for HTTP requests abandoned by the client
+	// (cancelled request context or status 499), which are not counted as server failures.
+	ClientCanceled = "client_canceled"
+
+	// Partial represents the label value for HTTP responses that were started with a 2XX status and
+	// some body bytes but failed before completing, e.g. a stream cut short by a broken connection.
+	Partial = "partial"
+
+	// ShortCircuited represents the label value for downstream calls rejected by a circuit breaker
+	// without calling the downstream service, which are not counted as downstream failures.
+	ShortCircuited = "short_circuited"
+
+	// HTTPStatusClientClosedRequest is the non-standard status code (popularised by nginx)
+	// reported when the client closed the connection before the response was sent.
+	HTTPStatusClientClosedRequest = 499
+
 	// HTTPStatus2XXMaxValue is the maximum HTTP status code considered successful (inclusive).
 	HTTPStatus2XXMaxValue = 299
 
 	// HTTPStatus2XXMinValue is the minimum HTTP status code considered successful (inclusive).
 	HTTPStatus2XXMinValue = 200
 )
+
+// MethodOther is the method label value that non-standard HTTP methods are folded into
+// to bound the cardinality of the method label.
+const MethodOther = "OTHER"
+
+// ContentTypeOther is the content type label value that unknown response content types are folded into
+// to bound the cardinality of the content_type label.
+const ContentTypeOther = "other"
+
+// StatusOther is the status label value that statuses passed to ObserveWithStatus outside AllowedStatuses are
+// folded into to bound the cardinality of the status label.
+const StatusOther = "other"
+
+// OpTypeOther is the op type label value that op types outside AllowedOpTypes are folded into
+// to keep the op type labels of database and pub/sub metrics to a controlled vocabulary.
+const OpTypeOther = "other"
+
+// Constants for the op types of the common SQL statements, as inferred by interfaces.InferOpType.
+const (
+	OpTypeSelect = "select"
+	OpTypeInsert = "insert"
+	OpTypeUpdate = "update"
+	OpTypeDelete = "delete"
+
+	// OpTypeUpsert is the op type of an INSERT resolving conflicts (ON CONFLICT, ON DUPLICATE KEY UPDATE)
+	// and of the UPSERT statement.
+	OpTypeUpsert = "upsert"
+)
+
+// ServiceOther is the service label value that downstream services outside AllowedServices are folded into
+// to bound the cardinality of the service label.
+const ServiceOther = "other"
+
+// SeriesOverflow is the label value every label of a new series is folded into when the series exceeds
+// the new series rate limit of its metric (MetricMeta.NewSeriesPerMinute).
+const SeriesOverflow = "overflow"
+
+// Constants for the caller label values of requests whose caller identity is not recorded as-is.
+const (
+	// CallerUnknown is the caller label value of requests without a caller identity header.
+	CallerUnknown = "unknown"
+
+	// CallerOther is the caller label value that callers outside AllowedCallers are folded into
+	// to bound the cardinality of the caller label.
+	CallerOther = "other"
+)
+
+// Constants for RouterMetricsMeta.NotFoundPolicy, which decides how requests matching no route are recorded.
+const (
+	// NotFoundPolicyGroup records requests matching no route under the PathUnmatched path label value.
+	// It is the default.
+	NotFoundPolicyGroup = "group"
+
+	// NotFoundPolicyDrop doesn't record requests matching no route at all.
+	NotFoundPolicyDrop = "drop"
+
+	// NotFoundPolicyRaw records requests matching no route under their raw URL path. Clients probing random
+	// paths create a series per path, so it should only be used where the request paths are trusted.
+	NotFoundPolicyRaw = "raw"
+
+	// PathUnmatched is the path label value of requests matching no route under NotFoundPolicyGroup.
+	PathUnmatched = "<unmatched>"
+)
+
+// Constants for the shared counter of cardinality guards (app_monitoring_cardinality_protection_total).
+const (
+	// CardinalityProtectionNamespace is the namespace of the cardinality protection counter. It is fixed,
+	// rather than taken from a meta, as the counter is shared by the guards of every metrics type.
+	CardinalityProtectionNamespace = "app_monitoring"
+
+	// LabelMetric is the label name for the metric whose label value was protected.
+	LabelMetric = "metric"
+
+	// LabelReason is the label name for the cardinality guard that protected a label value.
+	LabelReason = "reason"
+
+	// ReasonMethodFolded is the reason recorded when a non-standard HTTP method is folded into MethodOther.
+	ReasonMethodFolded = "method_folded"
+
+	// ReasonContentTypeFolded is the reason recorded when an unknown content type is folded into ContentTypeOther.
+	ReasonContentTypeFolded = "content_type_folded"
+
+	// ReasonCallerFolded is the reason recorded when a caller outside AllowedCallers is folded into CallerOther.
+	ReasonCallerFolded = "caller_folded"
+
+	// ReasonServiceNormalized is the reason recorded when ServiceNameNormalizer changes a downstream service name.
+	ReasonServiceNormalized = "service_normalized"
+
+	// ReasonOpTypeFolded is the reason recorded when an op type outside AllowedOpTypes is folded into OpTypeOther.
+	ReasonOpTypeFolded = "op_type_folded"
+
+	// ReasonStatusFolded is the reason recorded when a status outside AllowedStatuses is folded into StatusOther.
+	ReasonStatusFolded = "status_folded"
+
+	// ReasonServiceFolded is the reason recorded when a downstream service outside AllowedServices is folded into ServiceOther.
+	ReasonServiceFolded = "service_folded"
+
+	// ReasonSeriesRateLimited is the reason recorded when a new series exceeding the new series rate limit of
+	// its metric is folded into the SeriesOverflow series.
+	ReasonSeriesRateLimited = "series_rate_limited"
+
+	// ReasonLabelTruncated is the reason recorded when a label value longer than MaxLabelValueLength is truncated.
+	ReasonLabelTruncated = "label_truncated"
+
+	// LabelValueTruncatedMarker ends a label value truncated to MaxLabelValueLength.
+	LabelValueTruncatedMarker = "..."
+)
+
+// Constants for the dependency kinds of an operation recorded by OperationMetricsInterface.
+const (
+	// DependencyDB is the dependency kind for database operations.
+	DependencyDB = "db"
+
+	// DependencyDownstream is the dependency kind for downstream service calls.
+	DependencyDownstream = "downstream"
+
+	// DependencyCache is the dependency kind for cache lookups.
+	DependencyCache = "cache"
+)
+
+// Constants for the outcome label values of database transactions recorded by TxnMetricsInterface.
+const (
+	// TxnCommit is the outcome label value for committed transactions.
+	TxnCommit = "commit"
+
+	// TxnRollback is the outcome label value for rolled back transactions.
+	TxnRollback = "rollback"
+)
+
+// Constants for optional label names.
+// Optional labels are only recorded when their name is declared in the metric's configured labels.
+const (
+	// LabelAPIVersion is the label name for the API version extracted from the request path.
+	LabelAPIVersion = "api_version"
+
+	// LabelStatement is the label name for the prepared-statement name of a database operation.
+	LabelStatement = "statement"
+
+	// LabelShard is the label name for the database shard an operation ran against.
+	LabelShard = "shard"
+
+	// LabelTarget is the label name for the database instance role (primary or replica) an operation ran against.
+	LabelTarget = "target"
+
+	// LabelPartition is the label name for the topic partition a message was published to or consumed from.
+	LabelPartition = "partition"
+
+	// LabelConsumerGroup is the label name for the consumer group that consumed a pub/sub message.
+	LabelConsumerGroup = "consumer_group"
+
+	// LabelReplay is the label name for whether a pub/sub message was recorded in replay mode ("true" or "false").
+	LabelReplay = "replay"
+
+	// LabelFormat is the label name for the serialization format of a pub/sub message (e.g. "json", "proto" or "avro").
+	LabelFormat = "format"
+
+	// LabelComponent is the label name for the application component whose readiness is recorded.
+	LabelComponent = "component"
+
+	// LabelRouteGroup is the label name for the route group (top-level path prefix) that served the request.
+	LabelRouteGroup = "route_group"
+
+	// LabelClientBucket is the label name for the coarse network or geographic bucket of the client of the request.
+	LabelClientBucket = "client_bucket"
+
+	// LabelHandler is the label name for the name of the gin handler that served the request.
+	LabelHandler = "handler"
+
+	// LabelContentType is the label name for the base media type of the response Content-Type header.
+	LabelContentType = "content_type"
+
+	// LabelCaller is the label name for the service that sent an inbound request.
+	LabelCaller = "caller"
+
+	// LabelDraining is the label name for whether a request started while the process was draining ("true" or "false").
+	LabelDraining = "draining"
+
+	// LabelIdempotent is the label name for whether the method of a downstream call is idempotent ("true" or "false").
+	LabelIdempotent = "idempotent"
+
+	// LabelAttempt is the label name for the attempt number of a downstream call in its latency histogram
+	// ("1", "2", or AttemptCapped for the third and later attempts).
+	LabelAttempt = "attempt"
+
+	// LabelStatus is the label name for the success/failure outcome of a downstream call in its latency histogram.
+	LabelStatus = "status"
+)
+
+// Constants for the conventional label names of the metrics.
+// When a metric declares every conventional label it records, the labels may be declared in any order.
+const (
+	// LabelMethod is the label name for the HTTP method of a request.
+	LabelMethod = "method"
+
+	// LabelCode is the label name for the HTTP status code of a response.
+	LabelCode = "code"
+
+	// LabelPath is the label name for the route path of a request.
+	LabelPath = "path"
+
+	// LabelOpType is the label name for the operation type of a database operation or pub/sub message.
+	LabelOpType = "op_type"
+
+	// LabelSource is the label name for the component an operation or message originates from.
+	LabelSource = "source"
+
+	// LabelIsTxn is the label name for whether a database operation ran inside a transaction.
+	LabelIsTxn = "is_txn"
+
+	// LabelService is the label name for the name of a downstream service.
+	LabelService = "service"
+
+	// LabelAPI is the label name for the API identifier of a downstream service call.
+	LabelAPI = "api"
+
+	// LabelJobName is the label name for the name of a cron job.
+	LabelJobName = "job_name"
+
+	// LabelErrorCode is the label name for the error code of a failed message consumption.
+	LabelErrorCode = "error_code"
+
+	// LabelOperation is the label name for the name of a logical operation.
+	LabelOperation = "operation"
+
+	// LabelKind is the label name for the dependency kind of an operation dependency.
+	LabelKind = "kind"
+
+	// LabelName is the label name for the name of an operation dependency.
+	LabelName = "name"
+
+	// LabelOutcome is the label name for the outcome of an operation or transaction.
+	LabelOutcome = "outcome"
+
+	// LabelTaskType is the label name for the type of a worker pool task.
+	LabelTaskType = "task_type"
+)
+
+// Constants for label names that features depend on.
+// Features reading these labels are disabled, with an error logged, when the label is not configured.
+const (
+	// LabelEntity is the label name for the entity a pub/sub message relates to.
+	LabelEntity = "entity"
+)
+
+// Constants for the error codes recorded by the Track helpers of the interfaces package.
+const (
+	// ErrorCodePanic is the error code recorded for a tracked operation that panicked.
+	ErrorCodePanic = "PANIC"
+
+	// ErrorCodeUnknown is the error code recorded for a failed consumption whose app error has no code,
+	// and for a plain error returned to InstrumentExec or InstrumentQuery or passed to LogMetricsPostErr.
+	ErrorCodeUnknown = "UNKNOWN"
+)
+
+// Constants for the consume outcomes recorded by LogMetricsPostOutcome as the status label value of
+// pubsub_messages_consumed, in place of success/failure.
+const (
+	// ConsumeOutcomeAcked is the outcome of a message processed and acknowledged.
+	ConsumeOutcomeAcked = "acked"
+
+	// ConsumeOutcomeNacked is the outcome of a message negatively acknowledged, to be redelivered.
+	ConsumeOutcomeNacked = "nacked"
+
+	// ConsumeOutcomeSkipped is the outcome of a message filtered out without processing; it is not a failure.
+	ConsumeOutcomeSkipped = "skipped"
+
+	// ConsumeOutcomeDeadLettered is the outcome of a message moved to a dead-letter queue.
+	ConsumeOutcomeDeadLettered = "dead_lettered"
+)
+
+// Constants for the retry outcomes recorded by LogMetricsRetryOutcome as the outcome label value of
+// downstream_service_retry_outcome_total.
+const (
+	// RetryOutcomeNoRetryNeeded is the outcome of a call that succeeded on its first attempt.
+	RetryOutcomeNoRetryNeeded = "no_retry_needed"
+
+	// RetryOutcomeSucceededAfterRetry is the outcome of a call that succeeded after one or more retries.
+	RetryOutcomeSucceededAfterRetry = "succeeded_after_retry"
+
+	// RetryOutcomeExhausted is the outcome of a call that still failed when its retries ran out.
+	RetryOutcomeExhausted = "exhausted"
+)
+
+// Constants for the attempt label of downstream_service_http_request_latency_millis.
+const (
+	// MaxLabeledAttempt is the highest attempt number recorded as-is in the attempt label.
+	MaxLabeledAttempt = 3
+
+	// AttemptCapped is the attempt label value of the attempts from MaxLabeledAttempt on, bounding the
+	// label to three values however many times a call is retried.
+	AttemptCapped = "3+"
+)
+
+// Constants for the pub/sub subscription states recorded by SetSubscriptionState.
+const (
+	// SubscriptionStateDisconnected is the state of a subscription client that is not connected to the broker.
+	SubscriptionStateDisconnected = 0
+
+	// SubscriptionStateConnecting is the state of a subscription client connecting or reconnecting to the broker.
+	SubscriptionStateConnecting = 1
+
+	// SubscriptionStateConnected is the state of a subscription client connected to the broker.
+	SubscriptionStateConnected = 2
+)
+
+// Constants for the health statuses served by HealthHandler.
+const (
+	// HealthUp is the status of a healthy component.
+	HealthUp = "up"
+
+	// HealthDegraded is the status of a component that works with reduced capacity or reliability.
+	HealthDegraded = "degraded"
+
+	// HealthDown is the status of a component that doesn't work.
+	HealthDown = "down"
+)
+
+// Constants for the database target label values.
+const (
+	// DBTargetPrimary is the target label value for operations run against the primary.
+	DBTargetPrimary = "primary"
+
+	// DBTargetReplica is the target label value for operations run against a read replica.
+	DBTargetReplica = "replica"
+)
+
+// Constants for histogram bucket profiles.
+const (
+	// LabelBucketProfile is the constant label name identifying the bucket profile of a histogram series.
+	LabelBucketProfile = "bucket_profile"
+
+	// BucketProfileDefault is the bucket profile of observations the profile selector doesn't assign to a configured profile.
+	BucketProfileDefault = "default"
+)
+
+// Constants for the deployment track set by SetDeploymentTrack.
+const (
+	// LabelTrack is the constant label name identifying the deployment track of an instance.
+	LabelTrack = "track"
+
+	// DeploymentTrackCanary is the track of instances running a canary release.
+	DeploymentTrackCanary = "canary"
+
+	// DeploymentTrackStable is the track of instances running the current stable release.
+	DeploymentTrackStable = "stable"
+
+	// DeploymentTrackBaseline is the track of instances running the stable release next to a canary,
+	// started at the same time so that they compare with it on equal footing.
+	DeploymentTrackBaseline = "baseline"
+)
+
+// Constants for the asynchronous recording dispatcher.
+const (
+	// AsyncRecordingNamespace is the namespace of the counter of observations dropped by the asynchronous
+	// dispatcher (app_monitoring_async_observations_dropped_total).
+	AsyncRecordingNamespace = "app_monitoring"
+)
+
+// Constants for the negative latency protection.
+const (
+	// NegativeLatencyNamespace is the namespace of the counter of negative latencies observed as 0
+	// (app_monitoring_negative_latency_observations_total).
+	NegativeLatencyNamespace = "app_monitoring"
+)
+
+// Constants for exemplar label names.
+const (
+	// LabelRequestID is the exemplar label name for the request or correlation ID of an observation.
+	LabelRequestID = "request_id"
+)
@@ -11,11 +11,14 @@
 package main
 
 import (
+	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	ae "github.com/piyushkumar96/app-error"
+	"github.com/piyushkumar96/app-monitoring/httputil"
 	"github.com/piyushkumar96/app-monitoring/interfaces"
 	"github.com/piyushkumar96/app-monitoring/models"
 	prom "github.com/piyushkumar96/app-monitoring/prometheus"
@@ -208,16 +211,24 @@ func createUserHandler(c *gin.Context) {
 	}
 	downstreamMetrics.LogMetricsPre(labelValues)
 
+	// Wrap the request body so its size is counted as it is sent
+	reqBody := httputil.NewCountingReader(strings.NewReader(`{"template":"welcome"}`))
+
 	// Simulate downstream HTTP call
 	startTime := time.Now()
-	resp, err := callNotificationService()
+	resp, err := callNotificationService(reqBody)
+
+	// Wrap the response body so its size is counted as it is consumed
+	respBody := httputil.NewCountingReader(resp.Body)
+	_, _ = io.Copy(io.Discard, respBody)
+	_ = respBody.Close()
 
 	// Record downstream call metrics
 	httpMetrics := &models.HTTPMetrics{
 		Method:                "POST",
 		Code:                  resp.StatusCode,
-		RequestBodySizeBytes:  1024,
-		ResponseBodySizeBytes: 256,
+		RequestBodySizeBytes:  reqBody.Count(),
+		ResponseBodySizeBytes: respBody.Count(),
 		ResponseTime:          time.Since(startTime),
 	}
 
@@ -283,9 +294,10 @@ func fetchUsersFromDB() ([]string, *ae.AppError) {
 }
 
 // callNotificationService simulates an HTTP call to an external service
-func callNotificationService() (*http.Response, error) {
-	// In real implementation, this would make an HTTP request
-	return &http.Response{StatusCode: 200}, nil
+func callNotificationService(body io.Reader) (*http.Response, error) {
+	// In real implementation, this would make an HTTP request with the body
+	_, _ = io.Copy(io.Discard, body)
+	return &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader(`{"status":"queued"}`))}, nil
 }
 
 // performCleanup simulates a cleanup job execution
@@ -0,0 +1,88 @@
+// Package httputil provides backend-agnostic HTTP helpers for application monitoring.
+// These helpers capture HTTP payload sizes without buffering bodies into memory.
+package httputil
+
+import (
+	"io"
+	"sync/atomic"
+)
+
+// CountingReader wraps an io.Reader and counts the bytes read through it.
+// It lets callers size a request or response body as it streams, instead of
+// buffering the whole body just to measure it.
+//
+// CountingReader also implements io.Closer, closing the underlying reader when it
+// is an io.Closer, so it can be used directly as an http.Request or http.Response body.
+//
+// Example:
+//
+//	reqBody := httputil.NewCountingReader(body)
+//	req, _ := http.NewRequest(http.MethodPost, url, reqBody)
+//	resp, err := client.Do(req)
+//	httpMetrics.RequestBodySizeBytes = reqBody.Count()
+type CountingReader struct {
+	reader io.Reader
+	count  atomic.Int64
+}
+
+// NewCountingReader creates a CountingReader that counts bytes read from r.
+func NewCountingReader(r io.Reader) *CountingReader {
+	return &CountingReader{reader: r}
+}
+
+// Read reads from the underlying reader and adds the number of bytes read to the count.
+func (cr *CountingReader) Read(p []byte) (int, error) {
+	n, err := cr.reader.Read(p)
+	cr.count.Add(int64(n))
+	return n, err
+}
+
+// Close closes the underlying reader if it implements io.Closer.
+func (cr *CountingReader) Close() error {
+	if closer, ok := cr.reader.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// Count returns the number of bytes read so far.
+// It is safe to call concurrently with Read.
+func (cr *CountingReader) Count() int64 {
+	return cr.count.Load()
+}
+
+// CountingWriteCloser wraps an io.WriteCloser and counts the bytes written through it.
+// Use it when a body is produced by writing (e.g. through an io.Pipe) rather than reading.
+type CountingWriteCloser struct {
+	writer io.WriteCloser
+	count  atomic.Int64
+}
+
+// NewCountingWriteCloser creates a CountingWriteCloser that counts bytes written to w.
+func NewCountingWriteCloser(w io.WriteCloser) *CountingWriteCloser {
+	return &CountingWriteCloser{writer: w}
+}
+
+// Write writes to the underlying writer and adds the number of bytes written to the count.
+func (cw *CountingWriteCloser) Write(p []byte) (int, error) {
+	n, err := cw.writer.Write(p)
+	cw.count.Add(int64(n))
+	return n, err
+}
+
+// Close closes the underlying writer.
+func (cw *CountingWriteCloser) Close() error {
+	return cw.writer.Close()
+}
+
+// Count returns the number of bytes written so far.
+// It is safe to call concurrently with Write.
+func (cw *CountingWriteCloser) Count() int64 {
+	return cw.count.Load()
+}
+
+// Compile-time interface implementation checks
+var (
+	_ io.ReadCloser  = (*CountingReader)(nil)
+	_ io.WriteCloser = (*CountingWriteCloser)(nil)
+)
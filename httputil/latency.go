@@ -0,0 +1,36 @@
+package httputil
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseLatencyHeader parses the value of a response header carrying the processing time reported by
+// the server, such as X-Response-Time. Values with a unit are parsed as Go durations ("12.5ms", "1.2s",
+// "250us"), and bare numbers as milliseconds ("12.5"). It reports false for empty, unparseable,
+// negative or out of range values, which callers should skip rather than record.
+//
+// Example:
+//
+//	if latency, ok := httputil.ParseLatencyHeader(resp.Header.Get("X-Response-Time")); ok {
+//		dsMetrics.RecordUpstreamLatency(labelValues, latency)
+//	}
+func ParseLatencyHeader(value string) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if latency, err := time.ParseDuration(value); err == nil {
+		if latency < 0 {
+			return 0, false
+		}
+		return latency, true
+	}
+	millis, err := strconv.ParseFloat(value, 64)
+	if err != nil || millis < 0 || math.IsNaN(millis) || millis > math.MaxInt64/float64(time.Millisecond) {
+		return 0, false
+	}
+	return time.Duration(millis * float64(time.Millisecond)), true
+}
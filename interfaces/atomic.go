@@ -0,0 +1,484 @@
+package interfaces
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	ae "github.com/piyushkumar96/app-error"
+	"github.com/piyushkumar96/app-monitoring/models"
+	pubsub "github.com/piyushkumar96/generic-pubsub"
+)
+
+// The Atomic types below implement each metrics interface by delegating to a backend that can be swapped
+// at runtime, e.g. to move from Prometheus to OpenTelemetry behind a feature flag without a restart. Swap
+// is safe while calls are in flight: every call reads the current backend once, so a call made during a
+// swap is recorded entirely by either the old or the new backend. A Pre/Post pair spanning a swap is
+// split between the two, which can leave gauges such as in-flight calls off by one on each backend.
+//
+// Example:
+//
+//	routerMetrics := interfaces.NewAtomicRouterMetrics(promRouterMetrics)
+//	router.Use(routerMetrics.LogMetrics("/metrics"))
+//	...
+//	old := routerMetrics.Swap(otelRouterMetrics)
+
+// atomicHolder holds the current backend of an Atomic type. Its exported methods are promoted to them.
+type atomicHolder[T any] struct {
+	current atomic.Pointer[T]
+}
+
+// Load returns the current backend.
+func (h *atomicHolder[T]) Load() T {
+	return *h.current.Load()
+}
+
+// Swap replaces the current backend with metrics, which must not be nil, and returns the previous one,
+// e.g. to flush it once the calls in flight have completed.
+func (h *atomicHolder[T]) Swap(metrics T) T {
+	return *h.current.Swap(&metrics)
+}
+
+// Flush flushes the current backend when it implements Flusher, and does nothing otherwise.
+func (h *atomicHolder[T]) Flush(ctx context.Context) error {
+	if flusher, ok := any(h.Load()).(Flusher); ok {
+		return flusher.Flush(ctx)
+	}
+	return nil
+}
+
+// SelfTest runs the self-test of the current backend when it implements SelfTester, and does nothing otherwise.
+func (h *atomicHolder[T]) SelfTest() error {
+	if tester, ok := any(h.Load()).(SelfTester); ok {
+		return tester.SelfTest()
+	}
+	return nil
+}
+
+// AtomicRouterMetrics is a RouterMetricsInterface that delegates to a swappable backend.
+type AtomicRouterMetrics struct {
+	atomicHolder[RouterMetricsInterface]
+}
+
+// NewAtomicRouterMetrics returns an AtomicRouterMetrics delegating to metrics.
+func NewAtomicRouterMetrics(metrics RouterMetricsInterface) *AtomicRouterMetrics {
+	a := &AtomicRouterMetrics{}
+	a.current.Store(&metrics)
+	return a
+}
+
+// routerHandler is the middleware of a router metrics backend.
+type routerHandler struct {
+	metrics RouterMetricsInterface
+	handler gin.HandlerFunc
+}
+
+// LogMetrics returns a Gin middleware that records each request with the backend current at the time of
+// the request. The middleware of a backend is created on the first request after it is swapped in.
+func (a *AtomicRouterMetrics) LogMetrics(metricsPath string) gin.HandlerFunc {
+	var last atomic.Pointer[routerHandler]
+	return func(gc *gin.Context) {
+		metrics := a.Load()
+		current := last.Load()
+		if current == nil || current.metrics != metrics {
+			current = &routerHandler{metrics: metrics, handler: metrics.LogMetrics(metricsPath)}
+			last.Store(current)
+		}
+		current.handler(gc)
+	}
+}
+
+// RecordConcurrencyRejection delegates to the current backend.
+func (a *AtomicRouterMetrics) RecordConcurrencyRejection(path string) {
+	a.Load().RecordConcurrencyRejection(path)
+}
+
+// LogBatch delegates to the current backend.
+func (a *AtomicRouterMetrics) LogBatch(entries []models.HTTPMetrics) {
+	a.Load().LogBatch(entries)
+}
+
+// AtomicDBMetrics is a DBMetricsInterface that delegates to a swappable backend.
+type AtomicDBMetrics struct {
+	atomicHolder[DBMetricsInterface]
+}
+
+// NewAtomicDBMetrics returns an AtomicDBMetrics delegating to metrics.
+func NewAtomicDBMetrics(metrics DBMetricsInterface) *AtomicDBMetrics {
+	a := &AtomicDBMetrics{}
+	a.current.Store(&metrics)
+	return a
+}
+
+// LogMetricsPre delegates to the current backend.
+func (a *AtomicDBMetrics) LogMetricsPre(dbMetricsLabelValues *models.DBMetricsLabelValues) time.Time {
+	return a.Load().LogMetricsPre(dbMetricsLabelValues)
+}
+
+// LogMetricsPreWithAcquire delegates to the current backend.
+func (a *AtomicDBMetrics) LogMetricsPreWithAcquire(dbMetricsLabelValues *models.DBMetricsLabelValues, acquireStart time.Time) time.Time {
+	return a.Load().LogMetricsPreWithAcquire(dbMetricsLabelValues, acquireStart)
+}
+
+// LogMetricsPost delegates to the current backend.
+func (a *AtomicDBMetrics) LogMetricsPost(appErr *ae.AppError, dbMetricsLabelValues *models.DBMetricsLabelValues, opsExecTime time.Time) {
+	a.Load().LogMetricsPost(appErr, dbMetricsLabelValues, opsExecTime)
+}
+
+// LogMetricsPostErr delegates to the current backend.
+func (a *AtomicDBMetrics) LogMetricsPostErr(err error, dbMetricsLabelValues *models.DBMetricsLabelValues, opsExecTime time.Time) {
+	a.Load().LogMetricsPostErr(err, dbMetricsLabelValues, opsExecTime)
+}
+
+// ObserveLatency delegates to the current backend.
+func (a *AtomicDBMetrics) ObserveLatency(appErr *ae.AppError, dbMetricsLabelValues *models.DBMetricsLabelValues, duration time.Duration) {
+	a.Load().ObserveLatency(appErr, dbMetricsLabelValues, duration)
+}
+
+// ObserveWithStatus delegates to the current backend.
+func (a *AtomicDBMetrics) ObserveWithStatus(dbMetricsLabelValues *models.DBMetricsLabelValues, status string, duration time.Duration) {
+	a.Load().ObserveWithStatus(dbMetricsLabelValues, status, duration)
+}
+
+// RecordRowsAffected delegates to the current backend.
+func (a *AtomicDBMetrics) RecordRowsAffected(dbMetricsLabelValues *models.DBMetricsLabelValues, rows int64) {
+	a.Load().RecordRowsAffected(dbMetricsLabelValues, rows)
+}
+
+// AtomicDownstreamServiceMetrics is a DownstreamServiceMetricsInterface that delegates to a swappable backend.
+type AtomicDownstreamServiceMetrics struct {
+	atomicHolder[DownstreamServiceMetricsInterface]
+}
+
+// NewAtomicDownstreamServiceMetrics returns an AtomicDownstreamServiceMetrics delegating to metrics.
+func NewAtomicDownstreamServiceMetrics(metrics DownstreamServiceMetricsInterface) *AtomicDownstreamServiceMetrics {
+	a := &AtomicDownstreamServiceMetrics{}
+	a.current.Store(&metrics)
+	return a
+}
+
+// LogMetricsPre delegates to the current backend.
+func (a *AtomicDownstreamServiceMetrics) LogMetricsPre(dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues) {
+	a.Load().LogMetricsPre(dssMetricsLabelValues)
+}
+
+// LogMetricsPost delegates to the current backend.
+func (a *AtomicDownstreamServiceMetrics) LogMetricsPost(success bool, dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues, httpMetrics *models.HTTPMetrics) {
+	a.Load().LogMetricsPost(success, dssMetricsLabelValues, httpMetrics)
+}
+
+// LogMetricsPostResp delegates to the current backend.
+func (a *AtomicDownstreamServiceMetrics) LogMetricsPostResp(dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues, resp *http.Response, start time.Time, err error) {
+	a.Load().LogMetricsPostResp(dssMetricsLabelValues, resp, start, err)
+}
+
+// LogMetricsPostBatch delegates to the current backend.
+func (a *AtomicDownstreamServiceMetrics) LogMetricsPostBatch(dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues, httpMetrics *models.HTTPMetrics, succeeded, failed int) {
+	a.Load().LogMetricsPostBatch(dssMetricsLabelValues, httpMetrics, succeeded, failed)
+}
+
+// LogMetricsPostPaged delegates to the current backend.
+func (a *AtomicDownstreamServiceMetrics) LogMetricsPostPaged(dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues, httpMetrics *models.HTTPMetrics, pages int) {
+	a.Load().LogMetricsPostPaged(dssMetricsLabelValues, httpMetrics, pages)
+}
+
+// LogMetricsRetryOutcome delegates to the current backend.
+func (a *AtomicDownstreamServiceMetrics) LogMetricsRetryOutcome(dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues, outcome string, attempts int) {
+	a.Load().LogMetricsRetryOutcome(dssMetricsLabelValues, outcome, attempts)
+}
+
+// ObserveLatency delegates to the current backend.
+func (a *AtomicDownstreamServiceMetrics) ObserveLatency(success bool, dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues, duration time.Duration) {
+	a.Load().ObserveLatency(success, dssMetricsLabelValues, duration)
+}
+
+// ObserveWithStatus delegates to the current backend.
+func (a *AtomicDownstreamServiceMetrics) ObserveWithStatus(dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues, status string, duration time.Duration) {
+	a.Load().ObserveWithStatus(dssMetricsLabelValues, status, duration)
+}
+
+// LogMetricsShortCircuited delegates to the current backend.
+func (a *AtomicDownstreamServiceMetrics) LogMetricsShortCircuited(dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues) {
+	a.Load().LogMetricsShortCircuited(dssMetricsLabelValues)
+}
+
+// RecordParseTime delegates to the current backend.
+func (a *AtomicDownstreamServiceMetrics) RecordParseTime(dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues, duration time.Duration) {
+	a.Load().RecordParseTime(dssMetricsLabelValues, duration)
+}
+
+// RecordUpstreamLatency delegates to the current backend.
+func (a *AtomicDownstreamServiceMetrics) RecordUpstreamLatency(dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues, latency time.Duration) {
+	a.Load().RecordUpstreamLatency(dssMetricsLabelValues, latency)
+}
+
+// RecordConnectionSetup delegates to the current backend.
+func (a *AtomicDownstreamServiceMetrics) RecordConnectionSetup(dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues, times models.ConnectionSetupTimes) {
+	a.Load().RecordConnectionSetup(dssMetricsLabelValues, times)
+}
+
+// AtomicCronJobMetrics is a CronJobMetricsInterface that delegates to a swappable backend.
+type AtomicCronJobMetrics struct {
+	atomicHolder[CronJobMetricsInterface]
+}
+
+// NewAtomicCronJobMetrics returns an AtomicCronJobMetrics delegating to metrics.
+func NewAtomicCronJobMetrics(metrics CronJobMetricsInterface) *AtomicCronJobMetrics {
+	a := &AtomicCronJobMetrics{}
+	a.current.Store(&metrics)
+	return a
+}
+
+// LogMetricsPre delegates to the current backend.
+func (a *AtomicCronJobMetrics) LogMetricsPre(cjMetricsLabelValues *models.CronJobMetricsLabelValues) time.Time {
+	return a.Load().LogMetricsPre(cjMetricsLabelValues)
+}
+
+// LogMetricsPost delegates to the current backend.
+func (a *AtomicCronJobMetrics) LogMetricsPost(appErr *ae.AppError, cjMetricsLabelValues *models.CronJobMetricsLabelValues, opsExecTime time.Time) {
+	a.Load().LogMetricsPost(appErr, cjMetricsLabelValues, opsExecTime)
+}
+
+// LogMetricsPostErr delegates to the current backend.
+func (a *AtomicCronJobMetrics) LogMetricsPostErr(err error, cjMetricsLabelValues *models.CronJobMetricsLabelValues, opsExecTime time.Time) {
+	a.Load().LogMetricsPostErr(err, cjMetricsLabelValues, opsExecTime)
+}
+
+// ObserveLatency delegates to the current backend.
+func (a *AtomicCronJobMetrics) ObserveLatency(appErr *ae.AppError, cjMetricsLabelValues *models.CronJobMetricsLabelValues, duration time.Duration) {
+	a.Load().ObserveLatency(appErr, cjMetricsLabelValues, duration)
+}
+
+// ObserveWithStatus delegates to the current backend.
+func (a *AtomicCronJobMetrics) ObserveWithStatus(cjMetricsLabelValues *models.CronJobMetricsLabelValues, status string, duration time.Duration) {
+	a.Load().ObserveWithStatus(cjMetricsLabelValues, status, duration)
+}
+
+// RecordScheduleDrift delegates to the current backend.
+func (a *AtomicCronJobMetrics) RecordScheduleDrift(jobName string, expected, actual time.Time) {
+	a.Load().RecordScheduleDrift(jobName, expected, actual)
+}
+
+// RecordBudget delegates to the current backend.
+func (a *AtomicCronJobMetrics) RecordBudget(jobName string, budget time.Duration) {
+	a.Load().RecordBudget(jobName, budget)
+}
+
+// AtomicPSMetrics is a PSMetricsInterface that delegates to a swappable backend.
+type AtomicPSMetrics struct {
+	atomicHolder[PSMetricsInterface]
+}
+
+// NewAtomicPSMetrics returns an AtomicPSMetrics delegating to metrics.
+func NewAtomicPSMetrics(metrics PSMetricsInterface) *AtomicPSMetrics {
+	a := &AtomicPSMetrics{}
+	a.current.Store(&metrics)
+	return a
+}
+
+// LogMetricsPre delegates to the current backend.
+func (a *AtomicPSMetrics) LogMetricsPre(psMetricsLabelValues *models.PSMetricsLabelValues) time.Time {
+	return a.Load().LogMetricsPre(psMetricsLabelValues)
+}
+
+// LogMetricsPost delegates to the current backend.
+func (a *AtomicPSMetrics) LogMetricsPost(psMetricsLabelValues *models.PSMetricsLabelValues, eventTxnData *pubsub.EventTxnData) {
+	a.Load().LogMetricsPost(psMetricsLabelValues, eventTxnData)
+}
+
+// LogMetricsPostWithWireSize delegates to the current backend.
+func (a *AtomicPSMetrics) LogMetricsPostWithWireSize(psMetricsLabelValues *models.PSMetricsLabelValues, eventTxnData *pubsub.EventTxnData, wireSizeBytes int) {
+	a.Load().LogMetricsPostWithWireSize(psMetricsLabelValues, eventTxnData, wireSizeBytes)
+}
+
+// LogMetricsPostOutcome delegates to the current backend.
+func (a *AtomicPSMetrics) LogMetricsPostOutcome(psMetricsLabelValues *models.PSMetricsLabelValues, outcome string, startTime time.Time) {
+	a.Load().LogMetricsPostOutcome(psMetricsLabelValues, outcome, startTime)
+}
+
+// LogMetricsPreWithTimestamps delegates to the current backend.
+func (a *AtomicPSMetrics) LogMetricsPreWithTimestamps(psMetricsLabelValues *models.PSMetricsLabelValues, publishTime, receiveTime time.Time) models.PSMessageTimestamps {
+	return a.Load().LogMetricsPreWithTimestamps(psMetricsLabelValues, publishTime, receiveTime)
+}
+
+// LogMetricsPostWithTimestamps delegates to the current backend.
+func (a *AtomicPSMetrics) LogMetricsPostWithTimestamps(psMetricsLabelValues *models.PSMetricsLabelValues, timestamps models.PSMessageTimestamps) {
+	a.Load().LogMetricsPostWithTimestamps(psMetricsLabelValues, timestamps)
+}
+
+// LogMetricsBatch delegates to the current backend.
+func (a *AtomicPSMetrics) LogMetricsBatch(entries []models.PSBatchEntry) {
+	a.Load().LogMetricsBatch(entries)
+}
+
+// ObserveLatency delegates to the current backend.
+func (a *AtomicPSMetrics) ObserveLatency(published bool, psMetricsLabelValues *models.PSMetricsLabelValues, duration time.Duration) {
+	a.Load().ObserveLatency(published, psMetricsLabelValues, duration)
+}
+
+// ObserveWithStatus delegates to the current backend.
+func (a *AtomicPSMetrics) ObserveWithStatus(psMetricsLabelValues *models.PSMetricsLabelValues, status string, duration time.Duration) {
+	a.Load().ObserveWithStatus(psMetricsLabelValues, status, duration)
+}
+
+// RecordPublishConfirmLatency delegates to the current backend.
+func (a *AtomicPSMetrics) RecordPublishConfirmLatency(psMetricsLabelValues *models.PSMetricsLabelValues, latency time.Duration) {
+	a.Load().RecordPublishConfirmLatency(psMetricsLabelValues, latency)
+}
+
+// SetPublisherQueueDepth delegates to the current backend.
+func (a *AtomicPSMetrics) SetPublisherQueueDepth(entity string, depth int) {
+	a.Load().SetPublisherQueueDepth(entity, depth)
+}
+
+// SetSubscriptionState delegates to the current backend.
+func (a *AtomicPSMetrics) SetSubscriptionState(source string, state int) {
+	a.Load().SetSubscriptionState(source, state)
+}
+
+// AtomicAppMetrics is an AppMetricsInterface that delegates to a swappable backend.
+type AtomicAppMetrics struct {
+	atomicHolder[AppMetricsInterface]
+}
+
+// NewAtomicAppMetrics returns an AtomicAppMetrics delegating to metrics.
+func NewAtomicAppMetrics(metrics AppMetricsInterface) *AtomicAppMetrics {
+	a := &AtomicAppMetrics{}
+	a.current.Store(&metrics)
+	return a
+}
+
+// LogMetrics delegates to the current backend.
+func (a *AtomicAppMetrics) LogMetrics(errCodes []string) {
+	a.Load().LogMetrics(errCodes)
+}
+
+// LogMetricsUnique delegates to the current backend.
+func (a *AtomicAppMetrics) LogMetricsUnique(errCodes []string) {
+	a.Load().LogMetricsUnique(errCodes)
+}
+
+// DecrementAppErrorCount delegates to the current backend.
+func (a *AtomicAppMetrics) DecrementAppErrorCount(errCode string) {
+	a.Load().DecrementAppErrorCount(errCode)
+}
+
+// AtomicReadinessMetrics is a ReadinessMetricsInterface that delegates to a swappable backend.
+type AtomicReadinessMetrics struct {
+	atomicHolder[ReadinessMetricsInterface]
+}
+
+// NewAtomicReadinessMetrics returns an AtomicReadinessMetrics delegating to metrics.
+func NewAtomicReadinessMetrics(metrics ReadinessMetricsInterface) *AtomicReadinessMetrics {
+	a := &AtomicReadinessMetrics{}
+	a.current.Store(&metrics)
+	return a
+}
+
+// SetReady delegates to the current backend.
+func (a *AtomicReadinessMetrics) SetReady(component string, ready bool) {
+	a.Load().SetReady(component, ready)
+}
+
+// AtomicTxnMetrics is a TxnMetricsInterface that delegates to a swappable backend.
+// A transaction is ended by the backend that began it.
+type AtomicTxnMetrics struct {
+	atomicHolder[TxnMetricsInterface]
+}
+
+// NewAtomicTxnMetrics returns an AtomicTxnMetrics delegating to metrics.
+func NewAtomicTxnMetrics(metrics TxnMetricsInterface) *AtomicTxnMetrics {
+	a := &AtomicTxnMetrics{}
+	a.current.Store(&metrics)
+	return a
+}
+
+// BeginTxn delegates to the current backend.
+func (a *AtomicTxnMetrics) BeginTxn(source string) TxnHandle {
+	return a.Load().BeginTxn(source)
+}
+
+// AtomicOperationMetrics is an OperationMetricsInterface that delegates to a swappable backend.
+// An operation and its dependencies are recorded by the backend that started it.
+type AtomicOperationMetrics struct {
+	atomicHolder[OperationMetricsInterface]
+}
+
+// NewAtomicOperationMetrics returns an AtomicOperationMetrics delegating to metrics.
+func NewAtomicOperationMetrics(metrics OperationMetricsInterface) *AtomicOperationMetrics {
+	a := &AtomicOperationMetrics{}
+	a.current.Store(&metrics)
+	return a
+}
+
+// Start delegates to the current backend.
+func (a *AtomicOperationMetrics) Start(operation string) OperationHandle {
+	return a.Load().Start(operation)
+}
+
+// AtomicWorkerPoolMetrics is a WorkerPoolMetricsInterface that delegates to a swappable backend.
+// A task is recorded by the backend that enqueued it.
+type AtomicWorkerPoolMetrics struct {
+	atomicHolder[WorkerPoolMetricsInterface]
+}
+
+// NewAtomicWorkerPoolMetrics returns an AtomicWorkerPoolMetrics delegating to metrics.
+func NewAtomicWorkerPoolMetrics(metrics WorkerPoolMetricsInterface) *AtomicWorkerPoolMetrics {
+	a := &AtomicWorkerPoolMetrics{}
+	a.current.Store(&metrics)
+	return a
+}
+
+// Enqueue delegates to the current backend.
+func (a *AtomicWorkerPoolMetrics) Enqueue(taskType string) WorkerTaskHandle {
+	return a.Load().Enqueue(taskType)
+}
+
+// AtomicREDMetrics is a REDMetricsInterface that delegates to a swappable backend.
+type AtomicREDMetrics struct {
+	atomicHolder[REDMetricsInterface]
+}
+
+// NewAtomicREDMetrics returns an AtomicREDMetrics delegating to metrics.
+func NewAtomicREDMetrics(metrics REDMetricsInterface) *AtomicREDMetrics {
+	a := &AtomicREDMetrics{}
+	a.current.Store(&metrics)
+	return a
+}
+
+// Observe delegates to the current backend.
+func (a *AtomicREDMetrics) Observe(operation string, d time.Duration, err error) {
+	a.Load().Observe(operation, d, err)
+}
+
+// Compile-time interface implementation checks for Atomic types
+var (
+	_ RouterMetricsInterface            = (*AtomicRouterMetrics)(nil)
+	_ DBMetricsInterface                = (*AtomicDBMetrics)(nil)
+	_ DownstreamServiceMetricsInterface = (*AtomicDownstreamServiceMetrics)(nil)
+	_ CronJobMetricsInterface           = (*AtomicCronJobMetrics)(nil)
+	_ PSMetricsInterface                = (*AtomicPSMetrics)(nil)
+	_ AppMetricsInterface               = (*AtomicAppMetrics)(nil)
+	_ ReadinessMetricsInterface         = (*AtomicReadinessMetrics)(nil)
+	_ TxnMetricsInterface               = (*AtomicTxnMetrics)(nil)
+	_ OperationMetricsInterface         = (*AtomicOperationMetrics)(nil)
+	_ WorkerPoolMetricsInterface        = (*AtomicWorkerPoolMetrics)(nil)
+	_ REDMetricsInterface               = (*AtomicREDMetrics)(nil)
+)
+
+// Compile-time interface implementation checks
+var (
+	_ RouterMetricsInterface            = (*AtomicRouterMetrics)(nil)
+	_ DBMetricsInterface                = (*AtomicDBMetrics)(nil)
+	_ DownstreamServiceMetricsInterface = (*AtomicDownstreamServiceMetrics)(nil)
+	_ CronJobMetricsInterface           = (*AtomicCronJobMetrics)(nil)
+	_ PSMetricsInterface                = (*AtomicPSMetrics)(nil)
+	_ AppMetricsInterface               = (*AtomicAppMetrics)(nil)
+	_ ReadinessMetricsInterface         = (*AtomicReadinessMetrics)(nil)
+	_ TxnMetricsInterface               = (*AtomicTxnMetrics)(nil)
+	_ OperationMetricsInterface         = (*AtomicOperationMetrics)(nil)
+	_ WorkerPoolMetricsInterface        = (*AtomicWorkerPoolMetrics)(nil)
+	_ REDMetricsInterface               = (*AtomicREDMetrics)(nil)
+)
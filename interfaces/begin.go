@@ -0,0 +1,66 @@
+package interfaces
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/piyushkumar96/app-monitoring/models"
+
+	ae "github.com/piyushkumar96/app-error"
+)
+
+// The Begin helpers below record the start of an operation and return a closure that records its end,
+// so call sites can't pass the wrong start time to LogMetricsPost. Go evaluates the arguments of a deferred
+// call when the defer statement runs, so defer a function literal that calls the closure with the final error:
+//
+//	done := interfaces.BeginDB(dbMetrics, labelValues)
+//	defer func() { done(appErr) }()
+//
+// The closure must be called exactly once. Unlike the Track helpers, panics are not recorded.
+
+// BeginDB records the start of one database operation and returns a closure recording its outcome and latency.
+func BeginDB(metrics DBMetricsInterface, dbMetricsLabelValues *models.DBMetricsLabelValues) func(appErr *ae.AppError) {
+	start := metrics.LogMetricsPre(dbMetricsLabelValues)
+	return func(appErr *ae.AppError) {
+		metrics.LogMetricsPost(appErr, dbMetricsLabelValues, start)
+	}
+}
+
+// BeginCronJob records the start of one cron job execution and returns a closure recording its outcome and latency.
+func BeginCronJob(metrics CronJobMetricsInterface, cjMetricsLabelValues *models.CronJobMetricsLabelValues) func(appErr *ae.AppError) {
+	start := metrics.LogMetricsPre(cjMetricsLabelValues)
+	return func(appErr *ae.AppError) {
+		metrics.LogMetricsPost(appErr, cjMetricsLabelValues, start)
+	}
+}
+
+// BeginConsume records the start of the processing of one consumed message and returns a closure recording
+// its outcome. A failure is recorded with the error code of the app error, or constants.ErrorCodeUnknown
+// when it has none, as TrackConsume does.
+func BeginConsume(metrics PSMetricsInterface, psMetricsLabelValues *models.PSMetricsLabelValues) func(appErr *ae.AppError) {
+	metrics.LogMetricsPre(psMetricsLabelValues)
+	return func(appErr *ae.AppError) {
+		if appErr != nil {
+			metrics.LogMetricsPost(withErrorCode(psMetricsLabelValues, appErrorCode(appErr)), nil)
+			return
+		}
+		metrics.LogMetricsPost(psMetricsLabelValues, nil)
+	}
+}
+
+// BeginDownstream records the start of one downstream HTTP call and returns a closure recording its outcome,
+// latency and sizes from the response and error of the call, as LogMetricsPostResp does. resp may be nil
+// when err is set.
+//
+// Example:
+//
+//	done := interfaces.BeginDownstream(dsMetrics, labelValues)
+//	resp, err := client.Do(req)
+//	done(resp, err)
+func BeginDownstream(metrics DownstreamServiceMetricsInterface, dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues) func(resp *http.Response, err error) {
+	metrics.LogMetricsPre(dssMetricsLabelValues)
+	start := time.Now()
+	return func(resp *http.Response, err error) {
+		metrics.LogMetricsPostResp(dssMetricsLabelValues, resp, start, err)
+	}
+}
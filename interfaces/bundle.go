@@ -0,0 +1,136 @@
+package interfaces
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Flusher is implemented by metric backends that buffer observations and send them asynchronously
+// (e.g. StatsD, Datadog). Backends that record synchronously, such as Prometheus, don't need it.
+type Flusher interface {
+	// Flush forces any buffered metrics to be sent, waiting at most until the context is done.
+	Flush(ctx context.Context) error
+}
+
+// SelfTester is implemented by metric implementations that can verify their configuration at startup
+// by exercising each observation path once.
+type SelfTester interface {
+	// SelfTest exercises each observation path with placeholder values and returns an error
+	// if any of them fails due to misconfiguration (e.g. a label count mismatch).
+	SelfTest() error
+}
+
+// Bundle groups the metric instances of an application behind their generic interfaces,
+// so they can be passed around and managed together regardless of the backend.
+// Any field may be left nil when the application doesn't use that metric type.
+type Bundle struct {
+	// Router holds the router-level HTTP metrics.
+	Router RouterMetricsInterface
+
+	// DB holds the database operation metrics.
+	DB DBMetricsInterface
+
+	// Downstream holds the downstream service HTTP metrics.
+	Downstream DownstreamServiceMetricsInterface
+
+	// CronJob holds the cron job execution metrics.
+	CronJob CronJobMetricsInterface
+
+	// PubSub holds the pub/sub messaging metrics.
+	PubSub PSMetricsInterface
+
+	// App holds the application-level error metrics.
+	App AppMetricsInterface
+
+	// Readiness holds the component readiness metrics.
+	Readiness ReadinessMetricsInterface
+}
+
+// Flush forces every member that buffers observations to send them, waiting up to the context deadline.
+// Call it before a short-lived process (such as a cron job binary) exits so the last metrics aren't lost.
+//
+// Members that don't implement Flusher (e.g. the Prometheus implementations) are skipped, which makes
+// Flush a no-op for purely synchronous backends. Errors from individual members are joined together.
+func (b *Bundle) Flush(ctx context.Context) error {
+	var errs []error
+	for _, member := range b.Members() {
+		flusher, ok := member.(Flusher)
+		if !ok {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+		if err := flusher.Flush(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// SelfTest runs the self-test of every member that implements SelfTester and joins their errors.
+// Run it in a startup health check so label-schema mismatches fail fast instead of panicking
+// on production traffic.
+func (b *Bundle) SelfTest() error {
+	var errs []error
+	for _, member := range b.Members() {
+		if tester, ok := member.(SelfTester); ok {
+			if err := tester.SelfTest(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Members returns the non-nil metric instances held by the bundle.
+func (b *Bundle) Members() []any {
+	var members []any
+	for _, member := range []any{b.Router, b.DB, b.Downstream, b.CronJob, b.PubSub, b.App, b.Readiness} {
+		if member != nil {
+			members = append(members, member)
+		}
+	}
+	return members
+}
+
+// MergeBundles combines the bundles of an application composed from libraries that each expose their own,
+// so a single bundle can be flushed, self-tested and exposed. Each member is taken from the bundle that
+// sets it and nil bundles are skipped. A bundle holds one instance per metric type, so an error naming
+// the member is returned when several bundles set the same one.
+func MergeBundles(bundles ...*Bundle) (*Bundle, error) {
+	merged := &Bundle{}
+	var errs []error
+	for _, bundle := range bundles {
+		if bundle == nil {
+			continue
+		}
+		errs = append(errs,
+			mergeMember("Router", &merged.Router, bundle.Router),
+			mergeMember("DB", &merged.DB, bundle.DB),
+			mergeMember("Downstream", &merged.Downstream, bundle.Downstream),
+			mergeMember("CronJob", &merged.CronJob, bundle.CronJob),
+			mergeMember("PubSub", &merged.PubSub, bundle.PubSub),
+			mergeMember("App", &merged.App, bundle.App),
+			mergeMember("Readiness", &merged.Readiness, bundle.Readiness),
+		)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return merged, nil
+}
+
+// mergeMember sets the merged member to member when it is non-nil, failing when the member is already set.
+func mergeMember[T any](name string, merged *T, member T) error {
+	if any(member) == nil {
+		return nil
+	}
+	if any(*merged) != nil {
+		return fmt.Errorf("bundle member %s is set by more than one bundle", name)
+	}
+	*merged = member
+	return nil
+}
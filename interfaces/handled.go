@@ -0,0 +1,39 @@
+package interfaces
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	ae "github.com/piyushkumar96/app-error"
+)
+
+// RecordHandledError maps an app error handled by a gin error-handling middleware or handler to its metrics,
+// so every call site maps errors the same way. It sets the response status to the HTTP code of appErr
+// (500 when it has none), which the router metrics middleware then records like any other status, and
+// counts each distinct error code of appErr once in the app metrics (UNKNOWN when it has none).
+// The response body is left to the caller. A nil appErr records nothing.
+//
+// Example:
+//
+//	if appErr := svc.CreateUser(ctx, req); appErr != nil {
+//		interfaces.RecordHandledError(appMetrics, gc, appErr)
+//		gc.JSON(gc.Writer.Status(), gin.H{"error": appErr.GetMsg()})
+//		return
+//	}
+func RecordHandledError(metrics AppMetricsInterface, gc *gin.Context, appErr *ae.AppError) {
+	if appErr == nil {
+		return
+	}
+
+	code := appErr.GetHTTPCode()
+	if code == 0 {
+		code = http.StatusInternalServerError
+	}
+	gc.Status(code)
+
+	errCodes := appErr.GetErrCodes()
+	if len(errCodes) == 0 {
+		errCodes = []string{appErrorCode(appErr)}
+	}
+	metrics.LogMetricsUnique(errCodes)
+}
@@ -3,6 +3,7 @@
 package interfaces
 
 import (
+	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -17,6 +18,13 @@ import (
 type RouterMetricsInterface interface {
 	// LogMetrics returns a Gin middleware that logs HTTP request metrics.
 	LogMetrics(metricsPath string) gin.HandlerFunc
+
+	// RecordConcurrencyRejection records a request rejected by a concurrency limiter.
+	RecordConcurrencyRejection(path string)
+
+	// LogBatch records completed requests captured elsewhere (e.g. a load-test log) without the middleware.
+	// Intended for offline replay and testing.
+	LogBatch(entries []models.HTTPMetrics)
 }
 
 // DBMetricsInterface defines the contract for database operation metrics.
@@ -27,8 +35,25 @@ type DBMetricsInterface interface {
 	// Returns the start time for latency calculation.
 	LogMetricsPre(dbMetricsLabelValues *models.DBMetricsLabelValues) time.Time
 
+	// LogMetricsPreWithAcquire should be called once a pooled connection was acquired, before the database operation.
+	// It records the time spent acquiring since acquireStart and returns the start time for latency calculation.
+	LogMetricsPreWithAcquire(dbMetricsLabelValues *models.DBMetricsLabelValues, acquireStart time.Time) time.Time
+
 	// LogMetricsPost should be called after a database operation completes.
 	LogMetricsPost(appErr *ae.AppError, dbMetricsLabelValues *models.DBMetricsLabelValues, opsExecTime time.Time)
+
+	// LogMetricsPostErr should be called after a database operation completes, in place of LogMetricsPost,
+	// by code returning a plain error. The operation succeeded if err is nil.
+	LogMetricsPostErr(err error, dbMetricsLabelValues *models.DBMetricsLabelValues, opsExecTime time.Time)
+
+	// ObserveLatency records a database operation whose duration was already measured elsewhere.
+	ObserveLatency(appErr *ae.AppError, dbMetricsLabelValues *models.DBMetricsLabelValues, duration time.Duration)
+
+	// ObserveWithStatus records a database operation whose duration was already measured elsewhere, with a caller-supplied status.
+	ObserveWithStatus(dbMetricsLabelValues *models.DBMetricsLabelValues, status string, duration time.Duration)
+
+	// RecordRowsAffected records the number of rows a completed write operation affected.
+	RecordRowsAffected(dbMetricsLabelValues *models.DBMetricsLabelValues, rows int64)
 }
 
 // DownstreamServiceMetricsInterface defines the contract for downstream HTTP service metrics.
@@ -40,6 +65,40 @@ type DownstreamServiceMetricsInterface interface {
 
 	// LogMetricsPost should be called after a downstream HTTP call completes.
 	LogMetricsPost(success bool, dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues, httpMetrics *models.HTTPMetrics)
+
+	// LogMetricsPostResp should be called after a downstream HTTP call completes, in place of LogMetricsPost.
+	// It derives the HTTP metrics and success from the response, call start time and error.
+	LogMetricsPostResp(dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues, resp *http.Response, start time.Time, err error)
+
+	// LogMetricsPostBatch should be called after a downstream batch call completes, in place of LogMetricsPost.
+	// It also records the number of items of the batch that succeeded and failed.
+	LogMetricsPostBatch(dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues, httpMetrics *models.HTTPMetrics, succeeded, failed int)
+
+	// LogMetricsPostPaged should be called after a paginated downstream call completes, in place of LogMetricsPost,
+	// with the number of pages it fetched.
+	LogMetricsPostPaged(dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues, httpMetrics *models.HTTPMetrics, pages int)
+
+	// LogMetricsRetryOutcome records the final outcome of a call made with retries and the number of attempts it made.
+	LogMetricsRetryOutcome(dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues, outcome string, attempts int)
+
+	// ObserveLatency records a downstream HTTP call whose duration was already measured elsewhere.
+	ObserveLatency(success bool, dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues, duration time.Duration)
+
+	// ObserveWithStatus records a downstream HTTP call whose duration was already measured elsewhere, with a caller-supplied status.
+	ObserveWithStatus(dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues, status string, duration time.Duration)
+
+	// LogMetricsShortCircuited records a downstream call that a circuit breaker rejected without calling the
+	// downstream service, in place of the LogMetricsPre/LogMetricsPost pair.
+	LogMetricsShortCircuited(dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues)
+
+	// RecordParseTime records the time spent decoding the response body of a downstream HTTP call.
+	RecordParseTime(dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues, duration time.Duration)
+
+	// RecordUpstreamLatency records the processing time a downstream service reported for an HTTP call.
+	RecordUpstreamLatency(dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues, latency time.Duration)
+
+	// RecordConnectionSetup records the DNS lookup, TCP connect and TLS handshake times of a downstream HTTP call.
+	RecordConnectionSetup(dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues, times models.ConnectionSetupTimes)
 }
 
 // CronJobMetricsInterface defines the contract for cron job execution metrics.
@@ -52,6 +111,24 @@ type CronJobMetricsInterface interface {
 
 	// LogMetricsPost should be called after a cron job execution completes.
 	LogMetricsPost(appErr *ae.AppError, cjMetricsLabelValues *models.CronJobMetricsLabelValues, opsExecTime time.Time)
+
+	// LogMetricsPostErr should be called after a cron job execution completes, in place of LogMetricsPost,
+	// by code returning a plain error. The execution succeeded if err is nil.
+	LogMetricsPostErr(err error, cjMetricsLabelValues *models.CronJobMetricsLabelValues, opsExecTime time.Time)
+
+	// ObserveLatency records a cron job execution whose duration was already measured elsewhere.
+	ObserveLatency(appErr *ae.AppError, cjMetricsLabelValues *models.CronJobMetricsLabelValues, duration time.Duration)
+
+	// ObserveWithStatus records a cron job execution whose duration was already measured elsewhere, with a caller-supplied status.
+	ObserveWithStatus(cjMetricsLabelValues *models.CronJobMetricsLabelValues, status string, duration time.Duration)
+
+	// RecordScheduleDrift records how late a cron job started relative to its schedule.
+	// Should be called by the scheduler integration at job start.
+	RecordScheduleDrift(jobName string, expected, actual time.Time)
+
+	// RecordBudget sets the time budget of a cron job; runs exceeding it are counted as over budget.
+	// Should be called when the job is scheduled.
+	RecordBudget(jobName string, budget time.Duration)
 }
 
 // PSMetricsInterface defines the contract for pub/sub messaging metrics.
@@ -64,6 +141,45 @@ type PSMetricsInterface interface {
 
 	// LogMetricsPost should be called after a pub/sub operation completes.
 	LogMetricsPost(psMetricsLabelValues *models.PSMetricsLabelValues, eventTxnData *pubsub.EventTxnData)
+
+	// LogMetricsPostWithWireSize should be called after publishing over a compressing transport, in place of
+	// LogMetricsPost. It also records the on-the-wire size of the message next to its serialized size.
+	LogMetricsPostWithWireSize(psMetricsLabelValues *models.PSMetricsLabelValues, eventTxnData *pubsub.EventTxnData, wireSizeBytes int)
+
+	// LogMetricsPostOutcome should be called after processing a consumed message, in place of LogMetricsPost.
+	// It records the outcome (see the constants.ConsumeOutcome* values) as the status of the consumption.
+	LogMetricsPostOutcome(psMetricsLabelValues *models.PSMetricsLabelValues, outcome string, startTime time.Time)
+
+	// LogMetricsPreWithTimestamps should be called when starting to process a consumed message whose broker
+	// timestamps are known, in place of LogMetricsPre. Returns the timestamps for LogMetricsPostWithTimestamps.
+	LogMetricsPreWithTimestamps(psMetricsLabelValues *models.PSMetricsLabelValues, publishTime, receiveTime time.Time) models.PSMessageTimestamps
+
+	// LogMetricsPostWithTimestamps should be called after processing a consumed message, in place of LogMetricsPost.
+	// It also records the time spent in the broker and the processing time since the message was received.
+	LogMetricsPostWithTimestamps(psMetricsLabelValues *models.PSMetricsLabelValues, timestamps models.PSMessageTimestamps)
+
+	// LogMetricsBatch records a batch of completed pub/sub operations in one call,
+	// equivalent to calling LogMetricsPre and LogMetricsPost for each entry.
+	LogMetricsBatch(entries []models.PSBatchEntry)
+
+	// ObserveLatency records a publish whose duration was already measured elsewhere.
+	ObserveLatency(published bool, psMetricsLabelValues *models.PSMetricsLabelValues, duration time.Duration)
+
+	// ObserveWithStatus records a publish whose duration was already measured elsewhere, with a caller-supplied status.
+	ObserveWithStatus(psMetricsLabelValues *models.PSMetricsLabelValues, status string, duration time.Duration)
+
+	// RecordPublishConfirmLatency records the time between sending a message and the broker confirming it.
+	// Should be called by publishers using publisher confirms, once the confirmation arrives.
+	RecordPublishConfirmLatency(psMetricsLabelValues *models.PSMetricsLabelValues, latency time.Duration)
+
+	// SetPublisherQueueDepth records the number of messages buffered by an async publisher for an entity.
+	// Should be called when the publisher enqueues or dequeues messages.
+	SetPublisherQueueDepth(entity string, depth int)
+
+	// SetSubscriptionState records the connection state of the subscription client for a source
+	// (0 disconnected, 1 connecting, 2 connected; see the constants.SubscriptionState* values).
+	// Should be called on the client's connection lifecycle events.
+	SetSubscriptionState(source string, state int)
 }
 
 // AppMetricsInterface defines the contract for application-level error metrics.
@@ -73,6 +189,88 @@ type AppMetricsInterface interface {
 	// LogMetrics increments the application error counter for each provided error code.
 	LogMetrics(errCodes []string)
 
+	// LogMetricsUnique increments the application error counter once for each distinct error code,
+	// ignoring repeated codes within the same call.
+	LogMetricsUnique(errCodes []string)
+
 	// DecrementAppErrorCount decrements the application error counter for a specific error code.
 	DecrementAppErrorCount(errCode string)
 }
+
+// ReadinessMetricsInterface defines the contract for component readiness metrics.
+// Implement this interface to provide custom readiness metrics implementations
+// for different backends (Prometheus, OpenTelemetry, StatsD, etc.).
+type ReadinessMetricsInterface interface {
+	// SetReady records whether the given component (e.g. "db", "pubsub", "cache") is ready.
+	SetReady(component string, ready bool)
+}
+
+// TxnMetricsInterface defines the contract for database transaction lifecycle metrics.
+// It complements DBMetricsInterface, which records individual operations, with transaction-scoped
+// behavior such as long-running transactions holding locks.
+type TxnMetricsInterface interface {
+	// BeginTxn should be called when a transaction begins. Call Commit or Rollback on the returned handle
+	// once the transaction ends.
+	BeginTxn(source string) TxnHandle
+}
+
+// TxnHandle times a single transaction started by TxnMetricsInterface.BeginTxn.
+// Call exactly one of Commit or Rollback, once.
+type TxnHandle interface {
+	// Commit records the transaction as committed along with its duration.
+	Commit()
+
+	// Rollback records the transaction as rolled back along with its duration.
+	Rollback()
+}
+
+// OperationMetricsInterface defines the contract for logical operation metrics.
+// A single operation (e.g. a repository method) may touch the database, a cache and downstream services;
+// implementations record the outer operation duration and a per-dependency breakdown.
+type OperationMetricsInterface interface {
+	// Start begins timing the named operation. Call End on the returned handle once the operation completes.
+	Start(operation string) OperationHandle
+}
+
+// OperationHandle times a single in-flight operation started by OperationMetricsInterface.Start.
+type OperationHandle interface {
+	// Dependency begins timing a call to a dependency of the operation, identified by its kind
+	// (e.g. constants.DependencyDB) and name (e.g. the table or service name). labelValues, which may be nil,
+	// are the label values the call is recorded with by the metrics of its kind.
+	// Call End on the returned handle once the dependency call completes.
+	Dependency(kind, name string, labelValues *models.DependencyLabelValues) DependencyHandle
+
+	// End records the operation duration with its outcome (nil appErr for success). Call it exactly once.
+	End(appErr *ae.AppError)
+}
+
+// DependencyHandle times a single dependency call started by OperationHandle.Dependency.
+type DependencyHandle interface {
+	// End records the dependency call duration with its outcome (nil appErr for success). Call it exactly once.
+	End(appErr *ae.AppError)
+}
+
+// WorkerPoolMetricsInterface defines the contract for worker pool metrics. It generalizes CronJobMetricsInterface
+// to continuously-running worker pools, separating the time a task waits in the queue from the time it executes.
+type WorkerPoolMetricsInterface interface {
+	// Enqueue should be called when a task is added to the queue. Call Start on the returned handle when a worker
+	// dequeues the task, and Done once it completes.
+	Enqueue(taskType string) WorkerTaskHandle
+}
+
+// WorkerTaskHandle times a single task enqueued by WorkerPoolMetricsInterface.Enqueue.
+type WorkerTaskHandle interface {
+	// Start records the time the task waited in the queue. Call it once, when a worker dequeues the task.
+	Start()
+
+	// Done records the task execution duration with its outcome (nil err for success). Call it exactly once.
+	// A task that was not started is recorded as started when done, with no execution time.
+	Done(err error)
+}
+
+// REDMetricsInterface defines the contract for RED method (Rate, Errors, Duration) metrics, a lightweight
+// alternative to the specialized metrics for small services that record any operation the same way.
+type REDMetricsInterface interface {
+	// Observe records one operation (e.g. "http_get_user", "db_insert_order") that took d, failed when err is non-nil.
+	Observe(operation string, d time.Duration, err error)
+}
@@ -0,0 +1,13 @@
+package interfaces_test
+
+import (
+	"os"
+	"testing"
+
+	l "github.com/piyushkumar96/generic-logger"
+)
+
+func TestMain(m *testing.M) {
+	l.Init()
+	os.Exit(m.Run())
+}
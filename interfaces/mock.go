@@ -1,6 +1,7 @@
 package interfaces
 
 import (
+	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -9,12 +10,24 @@ import (
 	pubsub "github.com/piyushkumar96/generic-pubsub"
 )
 
+// The mocks in this file record calls in plain fields without locking and are not safe for concurrent use.
+
 // MockRouterMetrics is a mock implementation of RouterMetricsInterface for testing.
 type MockRouterMetrics struct {
 	// LogMetricsCalled tracks if LogMetrics was called.
 	LogMetricsCalled bool
 	// LogMetricsPath stores the metricsPath argument.
 	LogMetricsPath string
+
+	// RecordConcurrencyRejectionCalled tracks if RecordConcurrencyRejection was called.
+	RecordConcurrencyRejectionCalled bool
+	// RecordConcurrencyRejectionPath stores the path from RecordConcurrencyRejection.
+	RecordConcurrencyRejectionPath string
+
+	// LogBatchCalled tracks if LogBatch was called.
+	LogBatchCalled bool
+	// LogBatchEntries stores the entries from LogBatch.
+	LogBatchEntries []models.HTTPMetrics
 }
 
 // NewMockRouterMetrics creates a new mock router metrics instance.
@@ -31,6 +44,18 @@ func (m *MockRouterMetrics) LogMetrics(metricsPath string) gin.HandlerFunc {
 	}
 }
 
+// RecordConcurrencyRejection records the call.
+func (m *MockRouterMetrics) RecordConcurrencyRejection(path string) {
+	m.RecordConcurrencyRejectionCalled = true
+	m.RecordConcurrencyRejectionPath = path
+}
+
+// LogBatch records the call.
+func (m *MockRouterMetrics) LogBatch(entries []models.HTTPMetrics) {
+	m.LogBatchCalled = true
+	m.LogBatchEntries = entries
+}
+
 // MockDBMetrics is a mock implementation of DBMetricsInterface for testing.
 type MockDBMetrics struct {
 	// LogMetricsPreCalled tracks if LogMetricsPre was called.
@@ -38,12 +63,51 @@ type MockDBMetrics struct {
 	// LogMetricsPreLabelValues stores the label values from LogMetricsPre.
 	LogMetricsPreLabelValues *models.DBMetricsLabelValues
 
+	// LogMetricsPreWithAcquireCalled tracks if LogMetricsPreWithAcquire was called.
+	LogMetricsPreWithAcquireCalled bool
+	// LogMetricsPreWithAcquireLabelValues stores the label values from LogMetricsPreWithAcquire.
+	LogMetricsPreWithAcquireLabelValues *models.DBMetricsLabelValues
+	// LogMetricsPreWithAcquireStart stores the acquire start time from LogMetricsPreWithAcquire.
+	LogMetricsPreWithAcquireStart time.Time
+
 	// LogMetricsPostCalled tracks if LogMetricsPost was called.
 	LogMetricsPostCalled bool
 	// LogMetricsPostAppErr stores the appErr from LogMetricsPost.
 	LogMetricsPostAppErr *ae.AppError
 	// LogMetricsPostLabelValues stores the label values from LogMetricsPost.
 	LogMetricsPostLabelValues *models.DBMetricsLabelValues
+
+	// LogMetricsPostErrCalled tracks if LogMetricsPostErr was called.
+	LogMetricsPostErrCalled bool
+	// LogMetricsPostErrErr stores the err from LogMetricsPostErr.
+	LogMetricsPostErrErr error
+	// LogMetricsPostErrLabelValues stores the label values from LogMetricsPostErr.
+	LogMetricsPostErrLabelValues *models.DBMetricsLabelValues
+
+	// ObserveLatencyCalled tracks if ObserveLatency was called.
+	ObserveLatencyCalled bool
+	// ObserveLatencyAppErr stores the appErr from ObserveLatency.
+	ObserveLatencyAppErr *ae.AppError
+	// ObserveLatencyLabelValues stores the label values from ObserveLatency.
+	ObserveLatencyLabelValues *models.DBMetricsLabelValues
+	// ObserveLatencyDuration stores the duration from ObserveLatency.
+	ObserveLatencyDuration time.Duration
+
+	// ObserveWithStatusCalled tracks if ObserveWithStatus was called.
+	ObserveWithStatusCalled bool
+	// ObserveWithStatusLabelValues stores the label values from ObserveWithStatus.
+	ObserveWithStatusLabelValues *models.DBMetricsLabelValues
+	// ObserveWithStatusStatus stores the status from ObserveWithStatus.
+	ObserveWithStatusStatus string
+	// ObserveWithStatusDuration stores the duration from ObserveWithStatus.
+	ObserveWithStatusDuration time.Duration
+
+	// RecordRowsAffectedCalled tracks if RecordRowsAffected was called.
+	RecordRowsAffectedCalled bool
+	// RecordRowsAffectedLabelValues stores the label values from RecordRowsAffected.
+	RecordRowsAffectedLabelValues *models.DBMetricsLabelValues
+	// RecordRowsAffectedRows stores the rows from RecordRowsAffected.
+	RecordRowsAffectedRows int64
 }
 
 // NewMockDBMetrics creates a new mock database metrics instance.
@@ -58,6 +122,14 @@ func (m *MockDBMetrics) LogMetricsPre(dbMetricsLabelValues *models.DBMetricsLabe
 	return time.Now()
 }
 
+// LogMetricsPreWithAcquire records the call and returns the current time.
+func (m *MockDBMetrics) LogMetricsPreWithAcquire(dbMetricsLabelValues *models.DBMetricsLabelValues, acquireStart time.Time) time.Time {
+	m.LogMetricsPreWithAcquireCalled = true
+	m.LogMetricsPreWithAcquireLabelValues = dbMetricsLabelValues
+	m.LogMetricsPreWithAcquireStart = acquireStart
+	return time.Now()
+}
+
 // LogMetricsPost records the call.
 func (m *MockDBMetrics) LogMetricsPost(appErr *ae.AppError, dbMetricsLabelValues *models.DBMetricsLabelValues, _ time.Time) {
 	m.LogMetricsPostCalled = true
@@ -65,6 +137,36 @@ func (m *MockDBMetrics) LogMetricsPost(appErr *ae.AppError, dbMetricsLabelValues
 	m.LogMetricsPostLabelValues = dbMetricsLabelValues
 }
 
+// LogMetricsPostErr records the call.
+func (m *MockDBMetrics) LogMetricsPostErr(err error, dbMetricsLabelValues *models.DBMetricsLabelValues, _ time.Time) {
+	m.LogMetricsPostErrCalled = true
+	m.LogMetricsPostErrErr = err
+	m.LogMetricsPostErrLabelValues = dbMetricsLabelValues
+}
+
+// ObserveLatency records the call.
+func (m *MockDBMetrics) ObserveLatency(appErr *ae.AppError, dbMetricsLabelValues *models.DBMetricsLabelValues, duration time.Duration) {
+	m.ObserveLatencyCalled = true
+	m.ObserveLatencyAppErr = appErr
+	m.ObserveLatencyLabelValues = dbMetricsLabelValues
+	m.ObserveLatencyDuration = duration
+}
+
+// ObserveWithStatus records the call.
+func (m *MockDBMetrics) ObserveWithStatus(dbMetricsLabelValues *models.DBMetricsLabelValues, status string, duration time.Duration) {
+	m.ObserveWithStatusCalled = true
+	m.ObserveWithStatusLabelValues = dbMetricsLabelValues
+	m.ObserveWithStatusStatus = status
+	m.ObserveWithStatusDuration = duration
+}
+
+// RecordRowsAffected records the call.
+func (m *MockDBMetrics) RecordRowsAffected(dbMetricsLabelValues *models.DBMetricsLabelValues, rows int64) {
+	m.RecordRowsAffectedCalled = true
+	m.RecordRowsAffectedLabelValues = dbMetricsLabelValues
+	m.RecordRowsAffectedRows = rows
+}
+
 // MockDownstreamServiceMetrics is a mock implementation of DownstreamServiceMetricsInterface for testing.
 type MockDownstreamServiceMetrics struct {
 	// LogMetricsPreCalled tracks if LogMetricsPre was called.
@@ -80,6 +182,89 @@ type MockDownstreamServiceMetrics struct {
 	LogMetricsPostLabelValues *models.DownstreamServiceMetricsLabelValues
 	// LogMetricsPostHTTPMetrics stores the HTTP metrics from LogMetricsPost.
 	LogMetricsPostHTTPMetrics *models.HTTPMetrics
+
+	// LogMetricsPostRespCalled tracks if LogMetricsPostResp was called.
+	LogMetricsPostRespCalled bool
+	// LogMetricsPostRespLabelValues stores the label values from LogMetricsPostResp.
+	LogMetricsPostRespLabelValues *models.DownstreamServiceMetricsLabelValues
+	// LogMetricsPostRespResponse stores the response from LogMetricsPostResp.
+	LogMetricsPostRespResponse *http.Response
+	// LogMetricsPostRespStart stores the call start time from LogMetricsPostResp.
+	LogMetricsPostRespStart time.Time
+	// LogMetricsPostRespErr stores the call error from LogMetricsPostResp.
+	LogMetricsPostRespErr error
+
+	// LogMetricsPostBatchCalled tracks if LogMetricsPostBatch was called.
+	LogMetricsPostBatchCalled bool
+	// LogMetricsPostBatchLabelValues stores the label values from LogMetricsPostBatch.
+	LogMetricsPostBatchLabelValues *models.DownstreamServiceMetricsLabelValues
+	// LogMetricsPostBatchHTTPMetrics stores the HTTP metrics from LogMetricsPostBatch.
+	LogMetricsPostBatchHTTPMetrics *models.HTTPMetrics
+	// LogMetricsPostBatchSucceeded stores the succeeded items from LogMetricsPostBatch.
+	LogMetricsPostBatchSucceeded int
+	// LogMetricsPostBatchFailed stores the failed items from LogMetricsPostBatch.
+	LogMetricsPostBatchFailed int
+
+	// LogMetricsPostPagedCalled tracks if LogMetricsPostPaged was called.
+	LogMetricsPostPagedCalled bool
+	// LogMetricsPostPagedLabelValues stores the label values from LogMetricsPostPaged.
+	LogMetricsPostPagedLabelValues *models.DownstreamServiceMetricsLabelValues
+	// LogMetricsPostPagedHTTPMetrics stores the HTTP metrics from LogMetricsPostPaged.
+	LogMetricsPostPagedHTTPMetrics *models.HTTPMetrics
+	// LogMetricsPostPagedPages stores the pages from LogMetricsPostPaged.
+	LogMetricsPostPagedPages int
+
+	// LogMetricsRetryOutcomeCalled tracks if LogMetricsRetryOutcome was called.
+	LogMetricsRetryOutcomeCalled bool
+	// LogMetricsRetryOutcomeLabelValues stores the label values from LogMetricsRetryOutcome.
+	LogMetricsRetryOutcomeLabelValues *models.DownstreamServiceMetricsLabelValues
+	// LogMetricsRetryOutcomeOutcome stores the outcome from LogMetricsRetryOutcome.
+	LogMetricsRetryOutcomeOutcome string
+	// LogMetricsRetryOutcomeAttempts stores the attempts from LogMetricsRetryOutcome.
+	LogMetricsRetryOutcomeAttempts int
+
+	// ObserveLatencyCalled tracks if ObserveLatency was called.
+	ObserveLatencyCalled bool
+	// ObserveLatencySuccess stores the success flag from ObserveLatency.
+	ObserveLatencySuccess bool
+	// ObserveLatencyLabelValues stores the label values from ObserveLatency.
+	ObserveLatencyLabelValues *models.DownstreamServiceMetricsLabelValues
+	// ObserveLatencyDuration stores the duration from ObserveLatency.
+	ObserveLatencyDuration time.Duration
+
+	// ObserveWithStatusCalled tracks if ObserveWithStatus was called.
+	ObserveWithStatusCalled bool
+	// ObserveWithStatusLabelValues stores the label values from ObserveWithStatus.
+	ObserveWithStatusLabelValues *models.DownstreamServiceMetricsLabelValues
+	// ObserveWithStatusStatus stores the status from ObserveWithStatus.
+	ObserveWithStatusStatus string
+	// ObserveWithStatusDuration stores the duration from ObserveWithStatus.
+	ObserveWithStatusDuration time.Duration
+
+	// LogMetricsShortCircuitedCalled tracks if LogMetricsShortCircuited was called.
+	LogMetricsShortCircuitedCalled bool
+	// LogMetricsShortCircuitedLabelValues stores the label values from LogMetricsShortCircuited.
+	LogMetricsShortCircuitedLabelValues *models.DownstreamServiceMetricsLabelValues
+
+	// RecordParseTimeCalled tracks if RecordParseTime was called.
+	RecordParseTimeCalled bool
+	// RecordParseTimeLabelValues stores the label values from RecordParseTime.
+	RecordParseTimeLabelValues *models.DownstreamServiceMetricsLabelValues
+	// RecordParseTimeDuration stores the duration from RecordParseTime.
+	RecordParseTimeDuration time.Duration
+
+	// RecordUpstreamLatencyCalled tracks if RecordUpstreamLatency was called.
+	RecordUpstreamLatencyCalled bool
+	// RecordUpstreamLatencyLabelValues stores the label values from RecordUpstreamLatency.
+	RecordUpstreamLatencyLabelValues *models.DownstreamServiceMetricsLabelValues
+	// RecordUpstreamLatencyLatency stores the latency from RecordUpstreamLatency.
+	RecordUpstreamLatencyLatency time.Duration
+	// RecordConnectionSetupCalled tracks if RecordConnectionSetup was called.
+	RecordConnectionSetupCalled bool
+	// RecordConnectionSetupLabelValues stores the label values from RecordConnectionSetup.
+	RecordConnectionSetupLabelValues *models.DownstreamServiceMetricsLabelValues
+	// RecordConnectionSetupTimes stores the connection setup times from RecordConnectionSetup.
+	RecordConnectionSetupTimes models.ConnectionSetupTimes
 }
 
 // NewMockDownstreamServiceMetrics creates a new mock downstream service metrics instance.
@@ -101,6 +286,83 @@ func (m *MockDownstreamServiceMetrics) LogMetricsPost(success bool, dssMetricsLa
 	m.LogMetricsPostHTTPMetrics = httpMetrics
 }
 
+// LogMetricsPostResp records the call.
+func (m *MockDownstreamServiceMetrics) LogMetricsPostResp(dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues, resp *http.Response, start time.Time, err error) {
+	m.LogMetricsPostRespCalled = true
+	m.LogMetricsPostRespLabelValues = dssMetricsLabelValues
+	m.LogMetricsPostRespResponse = resp
+	m.LogMetricsPostRespStart = start
+	m.LogMetricsPostRespErr = err
+}
+
+// LogMetricsPostBatch records the call.
+func (m *MockDownstreamServiceMetrics) LogMetricsPostBatch(dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues, httpMetrics *models.HTTPMetrics, succeeded, failed int) {
+	m.LogMetricsPostBatchCalled = true
+	m.LogMetricsPostBatchLabelValues = dssMetricsLabelValues
+	m.LogMetricsPostBatchHTTPMetrics = httpMetrics
+	m.LogMetricsPostBatchSucceeded = succeeded
+	m.LogMetricsPostBatchFailed = failed
+}
+
+// LogMetricsPostPaged records the call.
+func (m *MockDownstreamServiceMetrics) LogMetricsPostPaged(dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues, httpMetrics *models.HTTPMetrics, pages int) {
+	m.LogMetricsPostPagedCalled = true
+	m.LogMetricsPostPagedLabelValues = dssMetricsLabelValues
+	m.LogMetricsPostPagedHTTPMetrics = httpMetrics
+	m.LogMetricsPostPagedPages = pages
+}
+
+// LogMetricsRetryOutcome records the call.
+func (m *MockDownstreamServiceMetrics) LogMetricsRetryOutcome(dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues, outcome string, attempts int) {
+	m.LogMetricsRetryOutcomeCalled = true
+	m.LogMetricsRetryOutcomeLabelValues = dssMetricsLabelValues
+	m.LogMetricsRetryOutcomeOutcome = outcome
+	m.LogMetricsRetryOutcomeAttempts = attempts
+}
+
+// ObserveLatency records the call.
+func (m *MockDownstreamServiceMetrics) ObserveLatency(success bool, dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues, duration time.Duration) {
+	m.ObserveLatencyCalled = true
+	m.ObserveLatencySuccess = success
+	m.ObserveLatencyLabelValues = dssMetricsLabelValues
+	m.ObserveLatencyDuration = duration
+}
+
+// ObserveWithStatus records the call.
+func (m *MockDownstreamServiceMetrics) ObserveWithStatus(dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues, status string, duration time.Duration) {
+	m.ObserveWithStatusCalled = true
+	m.ObserveWithStatusLabelValues = dssMetricsLabelValues
+	m.ObserveWithStatusStatus = status
+	m.ObserveWithStatusDuration = duration
+}
+
+// LogMetricsShortCircuited records the call.
+func (m *MockDownstreamServiceMetrics) LogMetricsShortCircuited(dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues) {
+	m.LogMetricsShortCircuitedCalled = true
+	m.LogMetricsShortCircuitedLabelValues = dssMetricsLabelValues
+}
+
+// RecordParseTime records the call.
+func (m *MockDownstreamServiceMetrics) RecordParseTime(dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues, duration time.Duration) {
+	m.RecordParseTimeCalled = true
+	m.RecordParseTimeLabelValues = dssMetricsLabelValues
+	m.RecordParseTimeDuration = duration
+}
+
+// RecordUpstreamLatency records the call.
+func (m *MockDownstreamServiceMetrics) RecordUpstreamLatency(dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues, latency time.Duration) {
+	m.RecordUpstreamLatencyCalled = true
+	m.RecordUpstreamLatencyLabelValues = dssMetricsLabelValues
+	m.RecordUpstreamLatencyLatency = latency
+}
+
+// RecordConnectionSetup records the call.
+func (m *MockDownstreamServiceMetrics) RecordConnectionSetup(dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues, times models.ConnectionSetupTimes) {
+	m.RecordConnectionSetupCalled = true
+	m.RecordConnectionSetupLabelValues = dssMetricsLabelValues
+	m.RecordConnectionSetupTimes = times
+}
+
 // MockCronJobMetrics is a mock implementation of CronJobMetricsInterface for testing.
 type MockCronJobMetrics struct {
 	// LogMetricsPreCalled tracks if LogMetricsPre was called.
@@ -114,6 +376,47 @@ type MockCronJobMetrics struct {
 	LogMetricsPostAppErr *ae.AppError
 	// LogMetricsPostLabelValues stores the label values from LogMetricsPost.
 	LogMetricsPostLabelValues *models.CronJobMetricsLabelValues
+
+	// LogMetricsPostErrCalled tracks if LogMetricsPostErr was called.
+	LogMetricsPostErrCalled bool
+	// LogMetricsPostErrErr stores the err from LogMetricsPostErr.
+	LogMetricsPostErrErr error
+	// LogMetricsPostErrLabelValues stores the label values from LogMetricsPostErr.
+	LogMetricsPostErrLabelValues *models.CronJobMetricsLabelValues
+
+	// ObserveLatencyCalled tracks if ObserveLatency was called.
+	ObserveLatencyCalled bool
+	// ObserveLatencyAppErr stores the appErr from ObserveLatency.
+	ObserveLatencyAppErr *ae.AppError
+	// ObserveLatencyLabelValues stores the label values from ObserveLatency.
+	ObserveLatencyLabelValues *models.CronJobMetricsLabelValues
+	// ObserveLatencyDuration stores the duration from ObserveLatency.
+	ObserveLatencyDuration time.Duration
+
+	// ObserveWithStatusCalled tracks if ObserveWithStatus was called.
+	ObserveWithStatusCalled bool
+	// ObserveWithStatusLabelValues stores the label values from ObserveWithStatus.
+	ObserveWithStatusLabelValues *models.CronJobMetricsLabelValues
+	// ObserveWithStatusStatus stores the status from ObserveWithStatus.
+	ObserveWithStatusStatus string
+	// ObserveWithStatusDuration stores the duration from ObserveWithStatus.
+	ObserveWithStatusDuration time.Duration
+
+	// RecordScheduleDriftCalled tracks if RecordScheduleDrift was called.
+	RecordScheduleDriftCalled bool
+	// RecordScheduleDriftJobName stores the job name from RecordScheduleDrift.
+	RecordScheduleDriftJobName string
+	// RecordScheduleDriftExpected stores the expected start time from RecordScheduleDrift.
+	RecordScheduleDriftExpected time.Time
+	// RecordScheduleDriftActual stores the actual start time from RecordScheduleDrift.
+	RecordScheduleDriftActual time.Time
+
+	// RecordBudgetCalled tracks if RecordBudget was called.
+	RecordBudgetCalled bool
+	// RecordBudgetJobName stores the job name from the last RecordBudget call.
+	RecordBudgetJobName string
+	// RecordBudgetBudget stores the budget from the last RecordBudget call.
+	RecordBudgetBudget time.Duration
 }
 
 // NewMockCronJobMetrics creates a new mock cron job metrics instance.
@@ -135,6 +438,44 @@ func (m *MockCronJobMetrics) LogMetricsPost(appErr *ae.AppError, cjMetricsLabelV
 	m.LogMetricsPostLabelValues = cjMetricsLabelValues
 }
 
+// LogMetricsPostErr records the call.
+func (m *MockCronJobMetrics) LogMetricsPostErr(err error, cjMetricsLabelValues *models.CronJobMetricsLabelValues, _ time.Time) {
+	m.LogMetricsPostErrCalled = true
+	m.LogMetricsPostErrErr = err
+	m.LogMetricsPostErrLabelValues = cjMetricsLabelValues
+}
+
+// ObserveLatency records the call.
+func (m *MockCronJobMetrics) ObserveLatency(appErr *ae.AppError, cjMetricsLabelValues *models.CronJobMetricsLabelValues, duration time.Duration) {
+	m.ObserveLatencyCalled = true
+	m.ObserveLatencyAppErr = appErr
+	m.ObserveLatencyLabelValues = cjMetricsLabelValues
+	m.ObserveLatencyDuration = duration
+}
+
+// ObserveWithStatus records the call.
+func (m *MockCronJobMetrics) ObserveWithStatus(cjMetricsLabelValues *models.CronJobMetricsLabelValues, status string, duration time.Duration) {
+	m.ObserveWithStatusCalled = true
+	m.ObserveWithStatusLabelValues = cjMetricsLabelValues
+	m.ObserveWithStatusStatus = status
+	m.ObserveWithStatusDuration = duration
+}
+
+// RecordScheduleDrift records the call.
+func (m *MockCronJobMetrics) RecordScheduleDrift(jobName string, expected, actual time.Time) {
+	m.RecordScheduleDriftCalled = true
+	m.RecordScheduleDriftJobName = jobName
+	m.RecordScheduleDriftExpected = expected
+	m.RecordScheduleDriftActual = actual
+}
+
+// RecordBudget records the call.
+func (m *MockCronJobMetrics) RecordBudget(jobName string, budget time.Duration) {
+	m.RecordBudgetCalled = true
+	m.RecordBudgetJobName = jobName
+	m.RecordBudgetBudget = budget
+}
+
 // MockPSMetrics is a mock implementation of PSMetricsInterface for testing.
 type MockPSMetrics struct {
 	// LogMetricsPreCalled tracks if LogMetricsPre was called.
@@ -148,6 +489,80 @@ type MockPSMetrics struct {
 	LogMetricsPostLabelValues *models.PSMetricsLabelValues
 	// LogMetricsPostEventTxnData stores the event txn data from LogMetricsPost.
 	LogMetricsPostEventTxnData *pubsub.EventTxnData
+
+	// LogMetricsPostWithWireSizeCalled tracks if LogMetricsPostWithWireSize was called.
+	LogMetricsPostWithWireSizeCalled bool
+	// LogMetricsPostWithWireSizeLabelValues stores the label values from LogMetricsPostWithWireSize.
+	LogMetricsPostWithWireSizeLabelValues *models.PSMetricsLabelValues
+	// LogMetricsPostWithWireSizeEventTxnData stores the event txn data from LogMetricsPostWithWireSize.
+	LogMetricsPostWithWireSizeEventTxnData *pubsub.EventTxnData
+	// LogMetricsPostWithWireSizeBytes stores the wire size from LogMetricsPostWithWireSize.
+	LogMetricsPostWithWireSizeBytes int
+
+	// LogMetricsPostOutcomeCalled tracks if LogMetricsPostOutcome was called.
+	LogMetricsPostOutcomeCalled bool
+	// LogMetricsPostOutcomeLabelValues stores the label values from LogMetricsPostOutcome.
+	LogMetricsPostOutcomeLabelValues *models.PSMetricsLabelValues
+	// LogMetricsPostOutcomeOutcome stores the outcome from LogMetricsPostOutcome.
+	LogMetricsPostOutcomeOutcome string
+	// LogMetricsPostOutcomeStartTime stores the start time from LogMetricsPostOutcome.
+	LogMetricsPostOutcomeStartTime time.Time
+
+	// LogMetricsPreWithTimestampsCalled tracks if LogMetricsPreWithTimestamps was called.
+	LogMetricsPreWithTimestampsCalled bool
+	// LogMetricsPreWithTimestampsLabelValues stores the label values from LogMetricsPreWithTimestamps.
+	LogMetricsPreWithTimestampsLabelValues *models.PSMetricsLabelValues
+
+	// LogMetricsPostWithTimestampsCalled tracks if LogMetricsPostWithTimestamps was called.
+	LogMetricsPostWithTimestampsCalled bool
+	// LogMetricsPostWithTimestampsLabelValues stores the label values from LogMetricsPostWithTimestamps.
+	LogMetricsPostWithTimestampsLabelValues *models.PSMetricsLabelValues
+	// LogMetricsPostWithTimestampsTimestamps stores the timestamps from LogMetricsPostWithTimestamps.
+	LogMetricsPostWithTimestampsTimestamps models.PSMessageTimestamps
+
+	// LogMetricsBatchCalled tracks if LogMetricsBatch was called.
+	LogMetricsBatchCalled bool
+	// LogMetricsBatchEntries stores the entries from LogMetricsBatch.
+	LogMetricsBatchEntries []models.PSBatchEntry
+
+	// ObserveLatencyCalled tracks if ObserveLatency was called.
+	ObserveLatencyCalled bool
+	// ObserveLatencyPublished stores the published flag from ObserveLatency.
+	ObserveLatencyPublished bool
+	// ObserveLatencyLabelValues stores the label values from ObserveLatency.
+	ObserveLatencyLabelValues *models.PSMetricsLabelValues
+	// ObserveLatencyDuration stores the duration from ObserveLatency.
+	ObserveLatencyDuration time.Duration
+
+	// ObserveWithStatusCalled tracks if ObserveWithStatus was called.
+	ObserveWithStatusCalled bool
+	// ObserveWithStatusLabelValues stores the label values from ObserveWithStatus.
+	ObserveWithStatusLabelValues *models.PSMetricsLabelValues
+	// ObserveWithStatusStatus stores the status from ObserveWithStatus.
+	ObserveWithStatusStatus string
+	// ObserveWithStatusDuration stores the duration from ObserveWithStatus.
+	ObserveWithStatusDuration time.Duration
+
+	// RecordPublishConfirmLatencyCalled tracks if RecordPublishConfirmLatency was called.
+	RecordPublishConfirmLatencyCalled bool
+	// RecordPublishConfirmLatencyLabelValues stores the label values from RecordPublishConfirmLatency.
+	RecordPublishConfirmLatencyLabelValues *models.PSMetricsLabelValues
+	// RecordPublishConfirmLatencyLatency stores the latency from RecordPublishConfirmLatency.
+	RecordPublishConfirmLatencyLatency time.Duration
+
+	// SetPublisherQueueDepthCalled tracks if SetPublisherQueueDepth was called.
+	SetPublisherQueueDepthCalled bool
+	// SetPublisherQueueDepthEntity stores the entity from the last SetPublisherQueueDepth call.
+	SetPublisherQueueDepthEntity string
+	// SetPublisherQueueDepthValue stores the depth from the last SetPublisherQueueDepth call.
+	SetPublisherQueueDepthValue int
+
+	// SetSubscriptionStateCalled tracks if SetSubscriptionState was called.
+	SetSubscriptionStateCalled bool
+	// SetSubscriptionStateSource stores the source from the last SetSubscriptionState call.
+	SetSubscriptionStateSource string
+	// SetSubscriptionStateValue stores the state from the last SetSubscriptionState call.
+	SetSubscriptionStateValue int
 }
 
 // NewMockPSMetrics creates a new mock pub/sub metrics instance.
@@ -169,6 +584,79 @@ func (m *MockPSMetrics) LogMetricsPost(psMetricsLabelValues *models.PSMetricsLab
 	m.LogMetricsPostEventTxnData = eventTxnData
 }
 
+// LogMetricsPostWithWireSize records the call.
+func (m *MockPSMetrics) LogMetricsPostWithWireSize(psMetricsLabelValues *models.PSMetricsLabelValues, eventTxnData *pubsub.EventTxnData, wireSizeBytes int) {
+	m.LogMetricsPostWithWireSizeCalled = true
+	m.LogMetricsPostWithWireSizeLabelValues = psMetricsLabelValues
+	m.LogMetricsPostWithWireSizeEventTxnData = eventTxnData
+	m.LogMetricsPostWithWireSizeBytes = wireSizeBytes
+}
+
+// LogMetricsPostOutcome records the call.
+func (m *MockPSMetrics) LogMetricsPostOutcome(psMetricsLabelValues *models.PSMetricsLabelValues, outcome string, startTime time.Time) {
+	m.LogMetricsPostOutcomeCalled = true
+	m.LogMetricsPostOutcomeLabelValues = psMetricsLabelValues
+	m.LogMetricsPostOutcomeOutcome = outcome
+	m.LogMetricsPostOutcomeStartTime = startTime
+}
+
+// LogMetricsPreWithTimestamps records the call and returns the given timestamps.
+func (m *MockPSMetrics) LogMetricsPreWithTimestamps(psMetricsLabelValues *models.PSMetricsLabelValues, publishTime, receiveTime time.Time) models.PSMessageTimestamps {
+	m.LogMetricsPreWithTimestampsCalled = true
+	m.LogMetricsPreWithTimestampsLabelValues = psMetricsLabelValues
+	return models.PSMessageTimestamps{PublishTime: publishTime, ReceiveTime: receiveTime}
+}
+
+// LogMetricsPostWithTimestamps records the call.
+func (m *MockPSMetrics) LogMetricsPostWithTimestamps(psMetricsLabelValues *models.PSMetricsLabelValues, timestamps models.PSMessageTimestamps) {
+	m.LogMetricsPostWithTimestampsCalled = true
+	m.LogMetricsPostWithTimestampsLabelValues = psMetricsLabelValues
+	m.LogMetricsPostWithTimestampsTimestamps = timestamps
+}
+
+// LogMetricsBatch records the call.
+func (m *MockPSMetrics) LogMetricsBatch(entries []models.PSBatchEntry) {
+	m.LogMetricsBatchCalled = true
+	m.LogMetricsBatchEntries = entries
+}
+
+// ObserveLatency records the call.
+func (m *MockPSMetrics) ObserveLatency(published bool, psMetricsLabelValues *models.PSMetricsLabelValues, duration time.Duration) {
+	m.ObserveLatencyCalled = true
+	m.ObserveLatencyPublished = published
+	m.ObserveLatencyLabelValues = psMetricsLabelValues
+	m.ObserveLatencyDuration = duration
+}
+
+// ObserveWithStatus records the call.
+func (m *MockPSMetrics) ObserveWithStatus(psMetricsLabelValues *models.PSMetricsLabelValues, status string, duration time.Duration) {
+	m.ObserveWithStatusCalled = true
+	m.ObserveWithStatusLabelValues = psMetricsLabelValues
+	m.ObserveWithStatusStatus = status
+	m.ObserveWithStatusDuration = duration
+}
+
+// RecordPublishConfirmLatency records the call.
+func (m *MockPSMetrics) RecordPublishConfirmLatency(psMetricsLabelValues *models.PSMetricsLabelValues, latency time.Duration) {
+	m.RecordPublishConfirmLatencyCalled = true
+	m.RecordPublishConfirmLatencyLabelValues = psMetricsLabelValues
+	m.RecordPublishConfirmLatencyLatency = latency
+}
+
+// SetPublisherQueueDepth records the call.
+func (m *MockPSMetrics) SetPublisherQueueDepth(entity string, depth int) {
+	m.SetPublisherQueueDepthCalled = true
+	m.SetPublisherQueueDepthEntity = entity
+	m.SetPublisherQueueDepthValue = depth
+}
+
+// SetSubscriptionState records the call.
+func (m *MockPSMetrics) SetSubscriptionState(source string, state int) {
+	m.SetSubscriptionStateCalled = true
+	m.SetSubscriptionStateSource = source
+	m.SetSubscriptionStateValue = state
+}
+
 // MockAppMetrics is a mock implementation of AppMetricsInterface for testing.
 type MockAppMetrics struct {
 	// LogMetricsCalled tracks if LogMetrics was called.
@@ -176,6 +664,11 @@ type MockAppMetrics struct {
 	// LogMetricsErrCodes stores the error codes from LogMetrics.
 	LogMetricsErrCodes []string
 
+	// LogMetricsUniqueCalled tracks if LogMetricsUnique was called.
+	LogMetricsUniqueCalled bool
+	// LogMetricsUniqueErrCodes stores the error codes from LogMetricsUnique.
+	LogMetricsUniqueErrCodes []string
+
 	// DecrementAppErrorCountCalled tracks if DecrementAppErrorCount was called.
 	DecrementAppErrorCountCalled bool
 	// DecrementAppErrorCountErrCode stores the error code from DecrementAppErrorCount.
@@ -193,12 +686,223 @@ func (m *MockAppMetrics) LogMetrics(errCodes []string) {
 	m.LogMetricsErrCodes = errCodes
 }
 
+// LogMetricsUnique records the call.
+func (m *MockAppMetrics) LogMetricsUnique(errCodes []string) {
+	m.LogMetricsUniqueCalled = true
+	m.LogMetricsUniqueErrCodes = errCodes
+}
+
 // DecrementAppErrorCount records the call.
 func (m *MockAppMetrics) DecrementAppErrorCount(errCode string) {
 	m.DecrementAppErrorCountCalled = true
 	m.DecrementAppErrorCountErrCode = errCode
 }
 
+// MockReadinessMetrics is a mock implementation of ReadinessMetricsInterface for testing.
+type MockReadinessMetrics struct {
+	// SetReadyCalled tracks if SetReady was called.
+	SetReadyCalled bool
+	// SetReadyComponent stores the component from the last SetReady call.
+	SetReadyComponent string
+	// SetReadyValue stores the ready flag from the last SetReady call.
+	SetReadyValue bool
+}
+
+// NewMockReadinessMetrics creates a new mock readiness metrics instance.
+func NewMockReadinessMetrics() *MockReadinessMetrics {
+	return &MockReadinessMetrics{}
+}
+
+// SetReady records the call.
+func (m *MockReadinessMetrics) SetReady(component string, ready bool) {
+	m.SetReadyCalled = true
+	m.SetReadyComponent = component
+	m.SetReadyValue = ready
+}
+
+// MockTxnMetrics is a mock implementation of TxnMetricsInterface for testing.
+type MockTxnMetrics struct {
+	// BeginTxnCalled tracks if BeginTxn was called.
+	BeginTxnCalled bool
+	// BeginTxnSource stores the source from the last BeginTxn call.
+	BeginTxnSource string
+	// Handle stores the handle returned by the last BeginTxn call.
+	Handle *MockTxnHandle
+}
+
+// NewMockTxnMetrics creates a new mock transaction metrics instance.
+func NewMockTxnMetrics() *MockTxnMetrics {
+	return &MockTxnMetrics{}
+}
+
+// BeginTxn records the call and returns a new MockTxnHandle.
+func (m *MockTxnMetrics) BeginTxn(source string) TxnHandle {
+	m.BeginTxnCalled = true
+	m.BeginTxnSource = source
+	m.Handle = &MockTxnHandle{}
+	return m.Handle
+}
+
+// MockTxnHandle is a mock implementation of TxnHandle for testing.
+type MockTxnHandle struct {
+	// CommitCalled tracks if Commit was called.
+	CommitCalled bool
+	// RollbackCalled tracks if Rollback was called.
+	RollbackCalled bool
+}
+
+// Commit records the call.
+func (m *MockTxnHandle) Commit() {
+	m.CommitCalled = true
+}
+
+// Rollback records the call.
+func (m *MockTxnHandle) Rollback() {
+	m.RollbackCalled = true
+}
+
+// MockOperationMetrics is a mock implementation of OperationMetricsInterface for testing.
+type MockOperationMetrics struct {
+	// StartCalled tracks if Start was called.
+	StartCalled bool
+	// StartOperation stores the operation from the last Start call.
+	StartOperation string
+	// Handle stores the handle returned by the last Start call.
+	Handle *MockOperationHandle
+}
+
+// NewMockOperationMetrics creates a new mock operation metrics instance.
+func NewMockOperationMetrics() *MockOperationMetrics {
+	return &MockOperationMetrics{}
+}
+
+// Start records the call and returns a new MockOperationHandle.
+func (m *MockOperationMetrics) Start(operation string) OperationHandle {
+	m.StartCalled = true
+	m.StartOperation = operation
+	m.Handle = &MockOperationHandle{}
+	return m.Handle
+}
+
+// MockOperationHandle is a mock implementation of OperationHandle for testing.
+type MockOperationHandle struct {
+	// DependencyCalled tracks if Dependency was called.
+	DependencyCalled bool
+	// DependencyKind stores the kind from the last Dependency call.
+	DependencyKind string
+	// DependencyName stores the name from the last Dependency call.
+	DependencyName string
+	// DependencyLabelValues stores the label values from the last Dependency call.
+	DependencyLabelValues *models.DependencyLabelValues
+	// DependencyHandle stores the handle returned by the last Dependency call.
+	DependencyHandle *MockDependencyHandle
+
+	// EndCalled tracks if End was called.
+	EndCalled bool
+	// EndAppErr stores the appErr from End.
+	EndAppErr *ae.AppError
+}
+
+// Dependency records the call and returns a new MockDependencyHandle.
+func (m *MockOperationHandle) Dependency(kind, name string, labelValues *models.DependencyLabelValues) DependencyHandle {
+	m.DependencyCalled = true
+	m.DependencyKind = kind
+	m.DependencyName = name
+	m.DependencyLabelValues = labelValues
+	m.DependencyHandle = &MockDependencyHandle{}
+	return m.DependencyHandle
+}
+
+// End records the call.
+func (m *MockOperationHandle) End(appErr *ae.AppError) {
+	m.EndCalled = true
+	m.EndAppErr = appErr
+}
+
+// MockDependencyHandle is a mock implementation of DependencyHandle for testing.
+type MockDependencyHandle struct {
+	// EndCalled tracks if End was called.
+	EndCalled bool
+	// EndAppErr stores the appErr from End.
+	EndAppErr *ae.AppError
+}
+
+// End records the call.
+func (m *MockDependencyHandle) End(appErr *ae.AppError) {
+	m.EndCalled = true
+	m.EndAppErr = appErr
+}
+
+// MockWorkerPoolMetrics is a mock implementation of WorkerPoolMetricsInterface for testing.
+type MockWorkerPoolMetrics struct {
+	// EnqueueCalled tracks if Enqueue was called.
+	EnqueueCalled bool
+	// EnqueueTaskType stores the task type from the last Enqueue call.
+	EnqueueTaskType string
+	// Handle stores the handle returned by the last Enqueue call.
+	Handle *MockWorkerTaskHandle
+}
+
+// NewMockWorkerPoolMetrics creates a new mock worker pool metrics instance.
+func NewMockWorkerPoolMetrics() *MockWorkerPoolMetrics {
+	return &MockWorkerPoolMetrics{}
+}
+
+// Enqueue records the call and returns a new MockWorkerTaskHandle.
+func (m *MockWorkerPoolMetrics) Enqueue(taskType string) WorkerTaskHandle {
+	m.EnqueueCalled = true
+	m.EnqueueTaskType = taskType
+	m.Handle = &MockWorkerTaskHandle{}
+	return m.Handle
+}
+
+// MockWorkerTaskHandle is a mock implementation of WorkerTaskHandle for testing.
+type MockWorkerTaskHandle struct {
+	// StartCalled tracks if Start was called.
+	StartCalled bool
+
+	// DoneCalled tracks if Done was called.
+	DoneCalled bool
+	// DoneErr stores the error from Done.
+	DoneErr error
+}
+
+// Start records the call.
+func (m *MockWorkerTaskHandle) Start() {
+	m.StartCalled = true
+}
+
+// Done records the call.
+func (m *MockWorkerTaskHandle) Done(err error) {
+	m.DoneCalled = true
+	m.DoneErr = err
+}
+
+// MockREDMetrics is a mock implementation of REDMetricsInterface for testing.
+type MockREDMetrics struct {
+	// ObserveCalled tracks if Observe was called.
+	ObserveCalled bool
+	// ObserveOperation stores the operation from the last Observe call.
+	ObserveOperation string
+	// ObserveDuration stores the duration from the last Observe call.
+	ObserveDuration time.Duration
+	// ObserveErr stores the error from the last Observe call.
+	ObserveErr error
+}
+
+// NewMockREDMetrics creates a new mock RED metrics instance.
+func NewMockREDMetrics() *MockREDMetrics {
+	return &MockREDMetrics{}
+}
+
+// Observe records the call.
+func (m *MockREDMetrics) Observe(operation string, d time.Duration, err error) {
+	m.ObserveCalled = true
+	m.ObserveOperation = operation
+	m.ObserveDuration = d
+	m.ObserveErr = err
+}
+
 // Compile-time interface implementation checks for Mock types
 var (
 	_ RouterMetricsInterface            = (*MockRouterMetrics)(nil)
@@ -207,4 +911,32 @@ var (
 	_ CronJobMetricsInterface           = (*MockCronJobMetrics)(nil)
 	_ PSMetricsInterface                = (*MockPSMetrics)(nil)
 	_ AppMetricsInterface               = (*MockAppMetrics)(nil)
+	_ ReadinessMetricsInterface         = (*MockReadinessMetrics)(nil)
+	_ TxnMetricsInterface               = (*MockTxnMetrics)(nil)
+	_ TxnHandle                         = (*MockTxnHandle)(nil)
+	_ OperationMetricsInterface         = (*MockOperationMetrics)(nil)
+	_ OperationHandle                   = (*MockOperationHandle)(nil)
+	_ DependencyHandle                  = (*MockDependencyHandle)(nil)
+	_ WorkerPoolMetricsInterface        = (*MockWorkerPoolMetrics)(nil)
+	_ WorkerTaskHandle                  = (*MockWorkerTaskHandle)(nil)
+	_ REDMetricsInterface               = (*MockREDMetrics)(nil)
+)
+
+// Compile-time interface implementation checks
+var (
+	_ RouterMetricsInterface            = (*MockRouterMetrics)(nil)
+	_ DBMetricsInterface                = (*MockDBMetrics)(nil)
+	_ DownstreamServiceMetricsInterface = (*MockDownstreamServiceMetrics)(nil)
+	_ CronJobMetricsInterface           = (*MockCronJobMetrics)(nil)
+	_ PSMetricsInterface                = (*MockPSMetrics)(nil)
+	_ AppMetricsInterface               = (*MockAppMetrics)(nil)
+	_ ReadinessMetricsInterface         = (*MockReadinessMetrics)(nil)
+	_ TxnMetricsInterface               = (*MockTxnMetrics)(nil)
+	_ TxnHandle                         = (*MockTxnHandle)(nil)
+	_ OperationMetricsInterface         = (*MockOperationMetrics)(nil)
+	_ OperationHandle                   = (*MockOperationHandle)(nil)
+	_ DependencyHandle                  = (*MockDependencyHandle)(nil)
+	_ WorkerPoolMetricsInterface        = (*MockWorkerPoolMetrics)(nil)
+	_ WorkerTaskHandle                  = (*MockWorkerTaskHandle)(nil)
+	_ REDMetricsInterface               = (*MockREDMetrics)(nil)
 )
@@ -0,0 +1,82 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	ae "github.com/piyushkumar96/app-error"
+	pubsub "github.com/piyushkumar96/generic-pubsub"
+
+	"github.com/piyushkumar96/app-monitoring/models"
+)
+
+// InstrumentPublisher returns publisher wrapped so that every message it publishes, with Publish or
+// PublishBatch, is recorded with metrics: LogMetricsPre before the publish and LogMetricsPost with the
+// returned event data after it, under the label values labelValues returns for the message. The other
+// methods (Listen, AcknowledgeMessage, CheckHealth, Teardown, ...) are forwarded to publisher unrecorded.
+//
+// A Publish returning an error is recorded as failed. Event data without a publish time or message size
+// is recorded with the time the call took and the length of the message, and a message PublishBatch
+// returns no event data for is recorded as a failed publish.
+//
+// Example:
+//
+//	publisher = interfaces.InstrumentPublisher(publisher, psMetrics, func(msg []byte) *models.PSMetricsLabelValues {
+//		return &models.PSMetricsLabelValues{Source: "orders-topic", Entity: "order", EntityOpType: "create"}
+//	})
+func InstrumentPublisher(publisher pubsub.IPubSub, metrics PSMetricsInterface, labelValues func(msg []byte) *models.PSMetricsLabelValues) pubsub.IPubSub {
+	return &instrumentedPublisher{IPubSub: publisher, metrics: metrics, labelValues: labelValues}
+}
+
+// instrumentedPublisher is the pubsub.IPubSub returned by InstrumentPublisher.
+type instrumentedPublisher struct {
+	pubsub.IPubSub
+	metrics     PSMetricsInterface
+	labelValues func(msg []byte) *models.PSMetricsLabelValues
+}
+
+// Publish publishes msg with the wrapped publisher, recording it with LogMetricsPre and LogMetricsPost.
+func (ip *instrumentedPublisher) Publish(ctx context.Context, msg []byte) (pubsub.EventTxnData, *ae.AppError) {
+	psMetricsLabelValues := ip.labelValues(msg)
+	start := ip.metrics.LogMetricsPre(psMetricsLabelValues)
+	eventTxnData, appErr := ip.IPubSub.Publish(ctx, msg)
+	ip.metrics.LogMetricsPost(psMetricsLabelValues, publishedTxnData(eventTxnData, appErr != nil, msg, time.Since(start)))
+	return eventTxnData, appErr
+}
+
+// PublishBatch publishes msgs with the wrapped publisher, recording each message with LogMetricsPre and
+// LogMetricsPost, matched to its event data by position. The messages are recorded by their own event data
+// when the batch returns an error, as some of them may have been published.
+func (ip *instrumentedPublisher) PublishBatch(ctx context.Context, msgs [][]byte) ([]pubsub.EventTxnData, *ae.AppError) {
+	labelValues := make([]*models.PSMetricsLabelValues, len(msgs))
+	for i, msg := range msgs {
+		labelValues[i] = ip.labelValues(msg)
+		ip.metrics.LogMetricsPre(labelValues[i])
+	}
+	start := time.Now()
+	eventTxnDatas, appErr := ip.IPubSub.PublishBatch(ctx, msgs)
+	elapsed := time.Since(start)
+	for i, msg := range msgs {
+		var eventTxnData pubsub.EventTxnData
+		if i < len(eventTxnDatas) {
+			eventTxnData = eventTxnDatas[i]
+		}
+		ip.metrics.LogMetricsPost(labelValues[i], publishedTxnData(eventTxnData, false, msg, elapsed))
+	}
+	return eventTxnDatas, appErr
+}
+
+// publishedTxnData returns a copy of the event data of a publish of msg to record, unpublished when failed,
+// with the publish time and message size filled from elapsed and msg when the publisher left them unset.
+func publishedTxnData(eventTxnData pubsub.EventTxnData, failed bool, msg []byte, elapsed time.Duration) *pubsub.EventTxnData {
+	if failed {
+		eventTxnData.IsPublished = false
+	}
+	if eventTxnData.TimeTakenToPublish == 0 {
+		eventTxnData.TimeTakenToPublish = elapsed
+	}
+	if eventTxnData.MessageSizeInBytes == 0 {
+		eventTxnData.MessageSizeInBytes = len(msg)
+	}
+	return &eventTxnData
+}
@@ -0,0 +1,212 @@
+package interfaces
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/piyushkumar96/app-monitoring/httputil"
+	"github.com/piyushkumar96/app-monitoring/models"
+)
+
+// RoundTripper is an http.RoundTripper that records every request it sends with Metrics, so that an
+// http.Client can be instrumented once instead of around each call. The latency of a call covers the
+// time until its response headers arrive; reading the body is not included. The request and response
+// sizes are the bytes sent and read through the bodies, so a call answered with a response is recorded
+// when its body is closed, which net/http requires of callers anyway; a call whose response body is
+// never closed is not recorded.
+//
+// Example:
+//
+//	client := &http.Client{Transport: &interfaces.RoundTripper{
+//		Metrics: dsMetrics,
+//		LabelValues: func(req *http.Request) *models.DownstreamServiceMetricsLabelValues {
+//			return &models.DownstreamServiceMetricsLabelValues{Name: "payments", HTTPMethod: req.Method, APIIdentifier: "charge"}
+//		},
+//	}}
+type RoundTripper struct {
+	// Next sends the requests. http.DefaultTransport is used when nil.
+	Next http.RoundTripper
+
+	// Metrics records the calls.
+	Metrics DownstreamServiceMetricsInterface
+
+	// LabelValues returns the label values a request is recorded with. When it is nil or returns nil,
+	// the request is recorded with the host as the service name and the request method.
+	LabelValues func(req *http.Request) *models.DownstreamServiceMetricsLabelValues
+
+	// TraceConnections records the DNS lookup, TCP connect and TLS handshake times of each call with
+	// RecordConnectionSetup. It is off by default, as tracing adds an httptrace.ClientTrace and its
+	// hooks to every request. Calls on a reused connection record zero for each phase.
+	TraceConnections bool
+}
+
+// RoundTrip sends req with Next, recording it with LogMetricsPre and LogMetricsPostResp. When the label
+// values have no Attempt, the attempt number carried by the request context (see ContextWithAttempt) is used.
+func (rt *RoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	labelValues := rt.labelValues(req)
+	if attempt := AttemptFromContext(req.Context()); attempt > 0 && labelValues.Attempt == 0 {
+		withAttempt := *labelValues
+		withAttempt.Attempt = attempt
+		labelValues = &withAttempt
+	}
+	var trace *connectionTrace
+	if rt.TraceConnections {
+		trace = &connectionTrace{}
+		req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace.clientTrace()))
+	}
+
+	var reqBody *httputil.CountingReader
+	if req.Body != nil && req.Body != http.NoBody {
+		reqBody = httputil.NewCountingReader(req.Body)
+		counted := *req
+		counted.Body = reqBody
+		req = &counted
+	}
+
+	next := rt.Next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	rt.Metrics.LogMetricsPre(labelValues)
+	start := time.Now()
+	resp, err := next.RoundTrip(req)
+	// Upgraded connections keep their writable body, recorded on arrival as it isn't a payload
+	if err != nil || resp == nil || resp.Body == nil || resp.StatusCode == http.StatusSwitchingProtocols {
+		rt.logPost(labelValues, resp, start, err, trace)
+		return resp, err
+	}
+
+	// The response is recorded as it was received, with the sizes counted until its body is closed
+	received := *resp
+	headersAt := time.Now()
+	resp.Body = &meteredBody{CountingReader: httputil.NewCountingReader(resp.Body), record: func(read int64) {
+		received.ContentLength = read
+		if reqBody != nil {
+			sent := *req
+			sent.ContentLength = reqBody.Count()
+			received.Request = &sent
+		}
+		// start is shifted by the time the body was open, so the latency still ends when the headers arrived
+		rt.logPost(labelValues, &received, start.Add(time.Since(headersAt)), nil, trace)
+	}}
+	return resp, nil
+}
+
+// labelValues returns the label values req is recorded with, falling back to the host and method of req
+// when LabelValues is unset or returns nil.
+func (rt *RoundTripper) labelValues(req *http.Request) *models.DownstreamServiceMetricsLabelValues {
+	if rt.LabelValues != nil {
+		if labelValues := rt.LabelValues(req); labelValues != nil {
+			return labelValues
+		}
+	}
+	return &models.DownstreamServiceMetricsLabelValues{Name: req.URL.Hostname(), HTTPMethod: req.Method}
+}
+
+// logPost records a completed call with LogMetricsPostResp, and its connection setup when traced.
+func (rt *RoundTripper) logPost(labelValues *models.DownstreamServiceMetricsLabelValues, resp *http.Response, start time.Time, err error, trace *connectionTrace) {
+	rt.Metrics.LogMetricsPostResp(labelValues, resp, start, err)
+	if trace != nil {
+		rt.Metrics.RecordConnectionSetup(labelValues, trace.times())
+	}
+}
+
+// meteredBody is the body of a response sent by RoundTripper. It counts the bytes read from it and
+// records the call with that count when it is first closed.
+type meteredBody struct {
+	*httputil.CountingReader
+	once   sync.Once
+	record func(read int64)
+}
+
+// Close closes the body and records the call, once.
+func (mb *meteredBody) Close() error {
+	err := mb.CountingReader.Close()
+	mb.once.Do(func() { mb.record(mb.Count()) })
+	return err
+}
+
+// attemptKey is the context key the attempt number of a request is stored under.
+type attemptKey struct{}
+
+// ContextWithAttempt returns a copy of ctx carrying the 1-based attempt number of a retried call, so that
+// the RoundTripper records the latency of each attempt under its own attempt label when
+// DownstreamServiceMetricsMeta.TrackAttempt is enabled.
+//
+// Example:
+//
+//	for attempt := 1; attempt <= maxAttempts; attempt++ {
+//		req = req.WithContext(interfaces.ContextWithAttempt(ctx, attempt))
+//		if resp, err = client.Do(req); err == nil && resp.StatusCode < 500 {
+//			break
+//		}
+//	}
+func ContextWithAttempt(ctx context.Context, attempt int) context.Context {
+	return context.WithValue(ctx, attemptKey{}, attempt)
+}
+
+// AttemptFromContext returns the attempt number carried by ctx, or zero when there is none.
+func AttemptFromContext(ctx context.Context) int {
+	attempt, _ := ctx.Value(attemptKey{}).(int)
+	return attempt
+}
+
+// connectionTrace collects the connection setup times of one request from its httptrace hooks, which
+// may run on other goroutines, e.g. when dialing several addresses of a host in parallel.
+type connectionTrace struct {
+	mu                               sync.Mutex
+	dnsStart, connectStart, tlsStart time.Time
+	dns, connect, tls                time.Duration
+}
+
+// clientTrace returns the hooks that fill ct. Only the first connect attempt starts the connect time,
+// and the first successful one ends it.
+func (ct *connectionTrace) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			ct.mu.Lock()
+			defer ct.mu.Unlock()
+			ct.dnsStart = time.Now()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			ct.mu.Lock()
+			defer ct.mu.Unlock()
+			ct.dns = time.Since(ct.dnsStart)
+		},
+		ConnectStart: func(string, string) {
+			ct.mu.Lock()
+			defer ct.mu.Unlock()
+			if ct.connectStart.IsZero() {
+				ct.connectStart = time.Now()
+			}
+		},
+		ConnectDone: func(_, _ string, err error) {
+			ct.mu.Lock()
+			defer ct.mu.Unlock()
+			if err == nil && ct.connect == 0 {
+				ct.connect = time.Since(ct.connectStart)
+			}
+		},
+		TLSHandshakeStart: func() {
+			ct.mu.Lock()
+			defer ct.mu.Unlock()
+			ct.tlsStart = time.Now()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			ct.mu.Lock()
+			defer ct.mu.Unlock()
+			ct.tls = time.Since(ct.tlsStart)
+		},
+	}
+}
+
+// times returns the connection setup times collected so far.
+func (ct *connectionTrace) times() models.ConnectionSetupTimes {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	return models.ConnectionSetupTimes{DNS: ct.dns, Connect: ct.connect, TLS: ct.tls}
+}
@@ -0,0 +1,128 @@
+package interfaces_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/piyushkumar96/app-monitoring/constants"
+	"github.com/piyushkumar96/app-monitoring/interfaces"
+	"github.com/piyushkumar96/app-monitoring/models"
+	prom "github.com/piyushkumar96/app-monitoring/prometheus"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// newTestDownstreamMetrics returns downstream metrics recording the requests, latencies and sizes of calls.
+func newTestDownstreamMetrics(t *testing.T) *prom.PromDownstreamServiceMetrics {
+	t.Helper()
+	useTestRegistry(t)
+	responseLabels := []string{constants.LabelService, constants.LabelMethod, constants.LabelCode, constants.LabelAPI}
+	return prom.NewPromDownstreamServiceMetrics(&models.DownstreamServiceMetricsMeta{
+		HTTPRequests:              &models.MetricMeta{Labels: append(responseLabels, constants.LabelStatus)},
+		HTTPRequestsLatencyMillis: &models.MetricMeta{Labels: responseLabels},
+		HTTPRequestSizeBytes:      &models.MetricMeta{Labels: responseLabels},
+		HTTPResponseSizeBytes:     &models.MetricMeta{Labels: responseLabels},
+	}).(*prom.PromDownstreamServiceMetrics)
+}
+
+// newChunkedServer returns a server reading the request body and answering body without a Content-Length.
+func newChunkedServer(t *testing.T, body string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		_, _ = io.WriteString(w, body)
+		w.(http.Flusher).Flush()
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// histogramSum returns the sum of the observations of the histogram series of the label values.
+func histogramSum(t *testing.T, vec *prometheus.HistogramVec, labelValues ...string) float64 {
+	t.Helper()
+	var metric dto.Metric
+	if err := vec.WithLabelValues(labelValues...).(prometheus.Metric).Write(&metric); err != nil {
+		t.Fatalf("write histogram: %v", err)
+	}
+	return metric.GetHistogram().GetSampleSum()
+}
+
+func TestRoundTripperCountsBodySizesUntilClose(t *testing.T) {
+	dsm := newTestDownstreamMetrics(t)
+	server := newChunkedServer(t, "pong-pong")
+	client := &http.Client{Transport: &interfaces.RoundTripper{
+		Metrics: dsm,
+		LabelValues: func(req *http.Request) *models.DownstreamServiceMetricsLabelValues {
+			return &models.DownstreamServiceMetricsLabelValues{Name: "echo", HTTPMethod: req.Method, APIIdentifier: "ping", RequestID: "req-1"}
+		},
+	}}
+
+	// Neither body has a Content-Length, so only counting them sizes the call
+	req, err := http.NewRequest(http.MethodPost, server.URL, io.NopCloser(strings.NewReader("ping")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.ContentLength != -1 {
+		t.Fatalf("response Content-Length = %d, want unknown", resp.ContentLength)
+	}
+	if _, err := io.Copy(io.Discard, resp.Body); err != nil {
+		t.Fatal(err)
+	}
+	labelValues := []string{"echo", http.MethodPost, "200", "ping"}
+	if count := sampleCount(t, dsm.GetHTTPResponseSizeBytesMetric(), labelValues...); count != 0 {
+		t.Errorf("response sizes recorded before the body was closed = %d, want 0", count)
+	}
+	resp.Body.Close()
+	resp.Body.Close()
+
+	if got := histogramSum(t, dsm.GetHTTPRequestSizeBytesMetric(), labelValues...); got != 4 {
+		t.Errorf("request size = %v, want the 4 bytes sent", got)
+	}
+	if got := histogramSum(t, dsm.GetHTTPResponseSizeBytesMetric(), labelValues...); got != 9 {
+		t.Errorf("response size = %v, want the 9 bytes read", got)
+	}
+	if count := sampleCount(t, dsm.GetHTTPRequestsLatencyMillisMetric(), labelValues...); count != 1 {
+		t.Errorf("latency observations after closing the body twice = %d, want 1", count)
+	}
+
+	// The request ID is attached to the latency observation as well as to failures
+	var metric dto.Metric
+	if err := dsm.GetHTTPRequestsLatencyMillisMetric().WithLabelValues(labelValues...).(prometheus.Metric).Write(&metric); err != nil {
+		t.Fatal(err)
+	}
+	var requestIDs []string
+	for _, bucket := range metric.GetHistogram().GetBucket() {
+		for _, label := range bucket.GetExemplar().GetLabel() {
+			requestIDs = append(requestIDs, label.GetName()+"="+label.GetValue())
+		}
+	}
+	if len(requestIDs) != 1 || requestIDs[0] != "request_id=req-1" {
+		t.Errorf("latency exemplars = %q, want [request_id=req-1]", requestIDs)
+	}
+}
+
+func TestRoundTripperWithoutLabelValuesRecordsTheHost(t *testing.T) {
+	dsm := newTestDownstreamMetrics(t)
+	server := newChunkedServer(t, "ok")
+	client := &http.Client{Transport: &interfaces.RoundTripper{Metrics: dsm}}
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	host := strings.Split(strings.TrimPrefix(server.URL, "http://"), ":")[0]
+	if got := testutil.ToFloat64(dsm.GetHTTPRequestsMetric().WithLabelValues(host, http.MethodGet, "200", "", constants.Success)); got != 1 {
+		t.Errorf("successful calls recorded under the host %q = %v, want 1", host, got)
+	}
+}
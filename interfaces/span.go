@@ -0,0 +1,52 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+)
+
+// SpanAnnotator enriches the trace span of a context with a completed operation, reusing the timing
+// taken for its metrics. It is independent of the metrics backend: metrics are still recorded by the
+// metric implementations, and the annotator only adds span events.
+type SpanAnnotator interface {
+	// AnnotateSpan adds an event with the operation name, duration and status to the span in ctx.
+	// It must be a no-op when ctx carries no span.
+	AnnotateSpan(ctx context.Context, operation string, duration time.Duration, status string)
+}
+
+// spanAnnotatorKey is the context key the SpanAnnotator is stored under.
+type spanAnnotatorKey struct{}
+
+// ContextWithSpanAnnotator returns a copy of ctx carrying annotator, so observations made with
+// the returned context (or contexts derived from it) also annotate their span.
+//
+// Example:
+//
+//	router.Use(func(c *gin.Context) {
+//		c.Request = c.Request.WithContext(interfaces.ContextWithSpanAnnotator(c.Request.Context(), annotator))
+//		c.Next()
+//	})
+func ContextWithSpanAnnotator(ctx context.Context, annotator SpanAnnotator) context.Context {
+	return context.WithValue(ctx, spanAnnotatorKey{}, annotator)
+}
+
+// SpanAnnotatorFromContext returns the SpanAnnotator carried by ctx, or nil when there is none.
+func SpanAnnotatorFromContext(ctx context.Context) SpanAnnotator {
+	annotator, _ := ctx.Value(spanAnnotatorKey{}).(SpanAnnotator)
+	return annotator
+}
+
+// AnnotateSpan annotates the span in ctx with a completed operation using the SpanAnnotator carried by ctx.
+// It does nothing when ctx carries no annotator. Call it next to LogMetricsPost with the same timing.
+//
+// Example:
+//
+//	start := dbMetrics.LogMetricsPre(labelValues)
+//	appErr := repo.InsertUser(ctx, user)
+//	dbMetrics.LogMetricsPost(appErr, labelValues, start)
+//	interfaces.AnnotateSpan(ctx, "db.insert_user", time.Since(start), constants.Success)
+func AnnotateSpan(ctx context.Context, operation string, duration time.Duration, status string) {
+	if annotator := SpanAnnotatorFromContext(ctx); annotator != nil {
+		annotator.AnnotateSpan(ctx, operation, duration, status)
+	}
+}
@@ -0,0 +1,226 @@
+package interfaces
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/piyushkumar96/app-monitoring/constants"
+	"github.com/piyushkumar96/app-monitoring/models"
+
+	ae "github.com/piyushkumar96/app-error"
+)
+
+// The Instrument helpers below record a database/sql call (on a *sql.DB, *sql.Stmt or *sql.Tx) with
+// TrackDB, so that code built on the standard library does not have to convert its errors by hand.
+// The error of the call is returned to the caller unchanged; only the copy recorded with the metrics
+// is converted to an *ae.AppError:
+//   - an error wrapping an *ae.AppError is recorded as that app error;
+//   - any other error is recorded as an app error with code constants.ErrorCodeUnknown, whose
+//     ActualErr is the original error.
+//
+// sql.ErrNoRows is therefore a failure by default. To count it as a success, configure
+// DBMetricsMeta.FailurePredicate to ignore it, e.g.
+//
+//	FailurePredicate: func(appErr *ae.AppError) bool {
+//		return !errors.Is(appErr.ActualErr, sql.ErrNoRows)
+//	},
+
+// InstrumentExec runs fn as one database write and records it with metrics, returning the result and
+// error of fn. When fn succeeds and its result reports the rows affected, they are recorded with
+// RecordRowsAffected.
+//
+// Example:
+//
+//	res, err := interfaces.InstrumentExec(dbMetrics, labelValues, func() (sql.Result, error) {
+//		return stmt.ExecContext(ctx, user.Name, user.ID)
+//	})
+func InstrumentExec(metrics DBMetricsInterface, dbMetricsLabelValues *models.DBMetricsLabelValues, fn func() (sql.Result, error)) (result sql.Result, err error) {
+	TrackDB(metrics, dbMetricsLabelValues, func() *ae.AppError {
+		result, err = fn()
+		return dbAppError(err)
+	})
+	if err == nil && result != nil {
+		if rows, rowsErr := result.RowsAffected(); rowsErr == nil {
+			metrics.RecordRowsAffected(dbMetricsLabelValues, rows)
+		}
+	}
+	return result, err
+}
+
+// InstrumentQuery runs fn as one database read and records it with metrics, returning the value and
+// error of fn. Only fn itself is timed: rows returned by it and iterated afterwards are not.
+//
+// Example:
+//
+//	rows, err := interfaces.InstrumentQuery(dbMetrics, labelValues, func() (*sql.Rows, error) {
+//		return tx.QueryContext(ctx, "SELECT id, name FROM users WHERE team = $1", team)
+//	})
+func InstrumentQuery[T any](metrics DBMetricsInterface, dbMetricsLabelValues *models.DBMetricsLabelValues, fn func() (T, error)) (value T, err error) {
+	TrackDB(metrics, dbMetricsLabelValues, func() *ae.AppError {
+		value, err = fn()
+		return dbAppError(err)
+	})
+	return value, err
+}
+
+// dbAppError returns the app error recorded for err, or nil when err is nil.
+func dbAppError(err error) *ae.AppError {
+	if err == nil {
+		return nil
+	}
+	var appErr *ae.AppError
+	if errors.As(err, &appErr) && appErr != nil {
+		return appErr
+	}
+	return &ae.AppError{
+		ActualErr:  err,
+		CustomErr:  &ae.CustomErr{Code: constants.ErrorCodeUnknown, Message: err.Error()},
+		ErrorCodes: []string{constants.ErrorCodeUnknown},
+	}
+}
+
+// InferOpType returns the op type of a SQL statement, its leading verb in lower case (e.g. "select" for
+// "SELECT * FROM users"), so that callers can populate DBMetricsLabelValues.OpType consistently. Leading
+// comments and parentheses are skipped, the common table expressions of a WITH clause are skipped to
+// the verb of the main statement, and an INSERT resolving conflicts (ON CONFLICT, ON DUPLICATE KEY UPDATE)
+// is reported as constants.OpTypeUpsert. constants.OpTypeOther is returned when no verb is found.
+//
+// The inference is best-effort: it doesn't parse SQL, and e.g. a statement that builds its verb
+// dynamically or several statements in one query are not recognized. Prefer explicit op types for
+// complex queries.
+//
+// Example:
+//
+//	labelValues := &models.DBMetricsLabelValues{OpType: interfaces.InferOpType(query), Source: "postgres", AdEntity: "users"}
+func InferOpType(query string) string {
+	verb, rest := sqlWord(query)
+	if verb == "with" {
+		rest = skipCTEs(rest)
+		verb, rest = sqlWord(rest)
+	}
+	switch verb {
+	case "":
+		return constants.OpTypeOther
+	case constants.OpTypeInsert:
+		upper := strings.ToUpper(rest)
+		if strings.Contains(upper, "ON CONFLICT") || strings.Contains(upper, "ON DUPLICATE KEY UPDATE") {
+			return constants.OpTypeUpsert
+		}
+	}
+	return verb
+}
+
+// skipSQLNoise returns query without its leading white space, comments and opening parentheses.
+func skipSQLNoise(query string) string {
+	for {
+		query = strings.TrimLeft(query, " \t\r\n(")
+		switch {
+		case strings.HasPrefix(query, "--"):
+			_, query, _ = strings.Cut(query, "\n")
+		case strings.HasPrefix(query, "/*"):
+			_, query, _ = strings.Cut(query, "*/")
+		default:
+			return query
+		}
+	}
+}
+
+// sqlWord returns the leading keyword or identifier of query in lower case, skipping the noise before it,
+// and the rest of query. The word is empty when query doesn't start with one.
+func sqlWord(query string) (string, string) {
+	query = skipSQLNoise(query)
+	end := strings.IndexFunc(query, func(r rune) bool {
+		return !(r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	})
+	if end < 0 {
+		end = len(query)
+	}
+	return strings.ToLower(query[:end]), query[end:]
+}
+
+// skipCTEs returns query without the common table expressions following its WITH keyword, e.g.
+// "SELECT * FROM recent" for "recent AS (SELECT ...) SELECT * FROM recent".
+func skipCTEs(query string) string {
+	for {
+		// Skip the header ("[RECURSIVE] name [(columns)] AS [[NOT] MATERIALIZED]") up to the body.
+		for {
+			query = strings.TrimLeft(query, " \t\r\n")
+			if query == "" {
+				return query
+			}
+			if query[0] == '(' {
+				query = skipSQLGroup(query)
+				continue
+			}
+			if query[0] == '"' || query[0] == '`' {
+				query = skipSQLQuoted(query)
+				continue
+			}
+			word, rest := sqlWord(query)
+			if word == "" {
+				return query
+			}
+			query = rest
+			if word == "as" {
+				break
+			}
+		}
+		for {
+			word, rest := sqlWord(query)
+			if word != "not" && word != "materialized" {
+				break
+			}
+			query = rest
+		}
+		query = skipSQLGroup(strings.TrimLeft(query, " \t\r\n"))
+
+		// Another expression follows a comma, the main statement anything else.
+		query = strings.TrimLeft(query, " \t\r\n")
+		if !strings.HasPrefix(query, ",") {
+			return query
+		}
+		query = query[1:]
+	}
+}
+
+// skipSQLGroup returns query without its leading parenthesized group, skipping the quoted strings and
+// identifiers inside it. query is returned as is when it doesn't start with a parenthesis.
+func skipSQLGroup(query string) string {
+	if !strings.HasPrefix(query, "(") {
+		return query
+	}
+	depth := 0
+	for query != "" {
+		switch query[0] {
+		case '\'', '"', '`':
+			query = skipSQLQuoted(query)
+			continue
+		case '(':
+			depth++
+		case ')':
+			depth--
+		}
+		query = query[1:]
+		if depth == 0 {
+			return query
+		}
+	}
+	return query
+}
+
+// skipSQLQuoted returns query without its leading quoted string or identifier, whose quote is doubled to escape it.
+func skipSQLQuoted(query string) string {
+	quote := query[0]
+	for i := 1; i < len(query); i++ {
+		if query[i] != quote {
+			continue
+		}
+		if i+1 < len(query) && query[i+1] == quote {
+			i++
+			continue
+		}
+		return query[i+1:]
+	}
+	return ""
+}
@@ -0,0 +1,46 @@
+package interfaces_test
+
+import (
+	"testing"
+
+	"github.com/piyushkumar96/app-monitoring/constants"
+	"github.com/piyushkumar96/app-monitoring/interfaces"
+)
+
+func TestInferOpType(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{name: "select", query: "SELECT id, name FROM users WHERE team = $1", want: constants.OpTypeSelect},
+		{name: "lower case", query: "select * from users", want: constants.OpTypeSelect},
+		{name: "mixed case", query: "InSeRt INTO users (name) VALUES ($1)", want: constants.OpTypeInsert},
+		{name: "update", query: "UPDATE users SET name = $1 WHERE id = $2", want: constants.OpTypeUpdate},
+		{name: "delete", query: "DELETE FROM users WHERE id = $1", want: constants.OpTypeDelete},
+		{name: "upsert on conflict", query: "INSERT INTO users (id, name) VALUES ($1, $2) ON CONFLICT (id) DO UPDATE SET name = $2", want: constants.OpTypeUpsert},
+		{name: "upsert on duplicate key", query: "insert into users (id, name) values (?, ?) on duplicate key update name = values(name)", want: constants.OpTypeUpsert},
+		{name: "leading white space", query: "\n\t  SELECT 1", want: constants.OpTypeSelect},
+		{name: "leading line comment", query: "-- fetch the team\nSELECT * FROM users", want: constants.OpTypeSelect},
+		{name: "leading block comment", query: "/* service=billing */ UPDATE invoices SET paid = true", want: constants.OpTypeUpdate},
+		{name: "several leading comments", query: "/* a */ -- b\n/* c */DELETE FROM sessions", want: constants.OpTypeDelete},
+		{name: "parenthesized", query: "(SELECT id FROM a) UNION (SELECT id FROM b)", want: constants.OpTypeSelect},
+		{name: "cte", query: "WITH recent AS (SELECT * FROM orders WHERE created_at > now() - interval '1 day') SELECT count(*) FROM recent", want: constants.OpTypeSelect},
+		{name: "cte with data modifying main statement", query: "WITH stale AS (SELECT id FROM sessions WHERE expired) DELETE FROM sessions USING stale WHERE sessions.id = stale.id", want: constants.OpTypeDelete},
+		{name: "several ctes", query: "WITH a AS (SELECT 1), b (x) AS (SELECT 2) INSERT INTO t SELECT * FROM a, b", want: constants.OpTypeInsert},
+		{name: "recursive cte", query: "WITH RECURSIVE tree AS (SELECT id FROM nodes UNION ALL SELECT n.id FROM nodes n JOIN tree t ON n.parent = t.id) SELECT * FROM tree", want: constants.OpTypeSelect},
+		{name: "materialized cte", query: "WITH totals AS NOT MATERIALIZED (SELECT sum(amount) FROM payments) SELECT * FROM totals", want: constants.OpTypeSelect},
+		{name: "cte with quoted parenthesis", query: `WITH "odd (name" AS (SELECT ')' AS paren) UPDATE t SET v = 1`, want: constants.OpTypeUpdate},
+		{name: "comment before cte", query: "/* report */ WITH r AS (SELECT 1) SELECT * FROM r", want: constants.OpTypeSelect},
+		{name: "empty", query: "", want: constants.OpTypeOther},
+		{name: "only a comment", query: "-- nothing to run", want: constants.OpTypeOther},
+		{name: "no verb", query: "$1", want: constants.OpTypeOther},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := interfaces.InferOpType(tt.query); got != tt.want {
+				t.Errorf("InferOpType(%q) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package interfaces
+
+import (
+	"context"
+
+	ae "github.com/piyushkumar96/app-error"
+	pubsub "github.com/piyushkumar96/generic-pubsub"
+
+	"github.com/piyushkumar96/app-monitoring/constants"
+	"github.com/piyushkumar96/app-monitoring/models"
+)
+
+// MessageHandler processes one message consumed from a generic-pubsub client, e.g. read from a listener
+// channel registered with AddListener, returning an app error when the processing failed.
+type MessageHandler func(ctx context.Context, msg *pubsub.ConsumedMessage) *ae.AppError
+
+// InstrumentSubscriber returns handler wrapped so that every message it processes is recorded with metrics,
+// under the label values labelValues returns for the message: LogMetricsPre before the processing, then
+// LogMetricsPostOutcome with the time it took, as a success when handler returns nil and as a failure
+// otherwise, with the error code of the app error, or constants.ErrorCodeUnknown when it has none, as
+// TrackConsume does. A panic in handler is recorded as a failure with constants.ErrorCodePanic, then re-raised.
+//
+// Example:
+//
+//	handle := interfaces.InstrumentSubscriber(processOrder, psMetrics, func(msg *pubsub.ConsumedMessage) *models.PSMetricsLabelValues {
+//		return &models.PSMetricsLabelValues{Source: "orders-subscription", Entity: "order", EntityOpType: "create"}
+//	})
+//	for msg := range messages {
+//		_ = handle(ctx, msg)
+//	}
+func InstrumentSubscriber(handler MessageHandler, metrics PSMetricsInterface, labelValues func(msg *pubsub.ConsumedMessage) *models.PSMetricsLabelValues) MessageHandler {
+	return func(ctx context.Context, msg *pubsub.ConsumedMessage) (appErr *ae.AppError) {
+		psMetricsLabelValues := labelValues(msg)
+		start := metrics.LogMetricsPre(psMetricsLabelValues)
+		defer func() {
+			if r := recover(); r != nil {
+				metrics.LogMetricsPostOutcome(withErrorCode(psMetricsLabelValues, constants.ErrorCodePanic), constants.Failure, start)
+				panic(r)
+			}
+			if appErr != nil {
+				metrics.LogMetricsPostOutcome(withErrorCode(psMetricsLabelValues, appErrorCode(appErr)), constants.Failure, start)
+				return
+			}
+			metrics.LogMetricsPostOutcome(psMetricsLabelValues, constants.Success, start)
+		}()
+		return handler(ctx, msg)
+	}
+}
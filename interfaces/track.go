@@ -0,0 +1,109 @@
+package interfaces
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/piyushkumar96/app-monitoring/constants"
+	"github.com/piyushkumar96/app-monitoring/models"
+
+	ae "github.com/piyushkumar96/app-error"
+	pubsub "github.com/piyushkumar96/generic-pubsub"
+)
+
+// The Track helpers below wrap an operation between the Pre and Post calls of its metrics, so that the
+// total count always matches success + failure. If the operation panics, it is recorded as a failure
+// with its latency so far, and the panic is then propagated to the caller unchanged.
+
+// TrackDB runs fn as one database operation and records it with metrics, returning the app error of fn.
+//
+// Example:
+//
+//	appErr := interfaces.TrackDB(dbMetrics, labelValues, func() *ae.AppError {
+//		return repo.InsertUser(ctx, user)
+//	})
+func TrackDB(metrics DBMetricsInterface, dbMetricsLabelValues *models.DBMetricsLabelValues, fn func() *ae.AppError) (appErr *ae.AppError) {
+	start := metrics.LogMetricsPre(dbMetricsLabelValues)
+	defer func() {
+		if r := recover(); r != nil {
+			metrics.LogMetricsPost(panicAppError(r), dbMetricsLabelValues, start)
+			panic(r)
+		}
+		metrics.LogMetricsPost(appErr, dbMetricsLabelValues, start)
+	}()
+	return fn()
+}
+
+// TrackCronJob runs fn as one cron job execution and records it with metrics, returning the app error of fn.
+func TrackCronJob(metrics CronJobMetricsInterface, cjMetricsLabelValues *models.CronJobMetricsLabelValues, fn func() *ae.AppError) (appErr *ae.AppError) {
+	start := metrics.LogMetricsPre(cjMetricsLabelValues)
+	defer func() {
+		if r := recover(); r != nil {
+			metrics.LogMetricsPost(panicAppError(r), cjMetricsLabelValues, start)
+			panic(r)
+		}
+		metrics.LogMetricsPost(appErr, cjMetricsLabelValues, start)
+	}()
+	return fn()
+}
+
+// TrackPublish runs fn as one publish and records it with metrics, returning the event data of fn.
+// A panic, or nil event data, is recorded as a failed publish that took the time fn ran for.
+func TrackPublish(metrics PSMetricsInterface, psMetricsLabelValues *models.PSMetricsLabelValues, fn func() *pubsub.EventTxnData) (eventTxnData *pubsub.EventTxnData) {
+	start := metrics.LogMetricsPre(psMetricsLabelValues)
+	defer func() {
+		if r := recover(); r != nil {
+			metrics.LogMetricsPost(psMetricsLabelValues, &pubsub.EventTxnData{TimeTakenToPublish: time.Since(start)})
+			panic(r)
+		}
+		if eventTxnData == nil {
+			metrics.LogMetricsPost(psMetricsLabelValues, &pubsub.EventTxnData{TimeTakenToPublish: time.Since(start)})
+			return
+		}
+		metrics.LogMetricsPost(psMetricsLabelValues, eventTxnData)
+	}()
+	return fn()
+}
+
+// TrackConsume runs fn as the processing of one consumed message and records it with metrics,
+// returning the app error of fn. A failure is recorded with the error code of the app error,
+// constants.ErrorCodeUnknown when it has none, or constants.ErrorCodePanic when fn panics.
+func TrackConsume(metrics PSMetricsInterface, psMetricsLabelValues *models.PSMetricsLabelValues, fn func() *ae.AppError) (appErr *ae.AppError) {
+	metrics.LogMetricsPre(psMetricsLabelValues)
+	defer func() {
+		if r := recover(); r != nil {
+			metrics.LogMetricsPost(withErrorCode(psMetricsLabelValues, constants.ErrorCodePanic), nil)
+			panic(r)
+		}
+		if appErr != nil {
+			metrics.LogMetricsPost(withErrorCode(psMetricsLabelValues, appErrorCode(appErr)), nil)
+			return
+		}
+		metrics.LogMetricsPost(psMetricsLabelValues, nil)
+	}()
+	return fn()
+}
+
+// panicAppError returns the app error recorded for an operation that panicked with r.
+func panicAppError(r any) *ae.AppError {
+	return &ae.AppError{
+		ActualErr:  fmt.Errorf("panic: %v", r),
+		CustomErr:  &ae.CustomErr{Code: constants.ErrorCodePanic, Message: "operation panicked"},
+		ErrorCodes: []string{constants.ErrorCodePanic},
+	}
+}
+
+// appErrorCode returns the error code of appErr, or constants.ErrorCodeUnknown when it has none.
+func appErrorCode(appErr *ae.AppError) string {
+	if appErr.CustomErr != nil && appErr.CustomErr.Code != "" {
+		return appErr.CustomErr.Code
+	}
+	return constants.ErrorCodeUnknown
+}
+
+// withErrorCode returns a copy of the label values with the given error code, leaving the caller's unchanged.
+func withErrorCode(psMetricsLabelValues *models.PSMetricsLabelValues, errorCode string) *models.PSMetricsLabelValues {
+	labelValues := *psMetricsLabelValues
+	labelValues.ErrorCode = errorCode
+	return &labelValues
+}
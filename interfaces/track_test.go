@@ -0,0 +1,132 @@
+package interfaces_test
+
+import (
+	"testing"
+
+	"github.com/piyushkumar96/app-monitoring/constants"
+	"github.com/piyushkumar96/app-monitoring/interfaces"
+	"github.com/piyushkumar96/app-monitoring/models"
+	prom "github.com/piyushkumar96/app-monitoring/prometheus"
+
+	ae "github.com/piyushkumar96/app-error"
+	pubsub "github.com/piyushkumar96/generic-pubsub"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// useTestRegistry registers the metrics created by the test against a fresh registry, restoring the
+// default registerer when the test ends.
+func useTestRegistry(t *testing.T) {
+	t.Helper()
+	prom.SetRegisterer(prometheus.NewRegistry())
+	t.Cleanup(func() { prom.SetRegisterer(prometheus.DefaultRegisterer) })
+}
+
+// sampleCount returns the number of observations of the histogram series of the label values.
+func sampleCount(t *testing.T, vec *prometheus.HistogramVec, labelValues ...string) uint64 {
+	t.Helper()
+	var metric dto.Metric
+	if err := vec.WithLabelValues(labelValues...).(prometheus.Metric).Write(&metric); err != nil {
+		t.Fatalf("write histogram: %v", err)
+	}
+	return metric.GetHistogram().GetSampleCount()
+}
+
+// mustPanic calls fn and reports an error unless it panics with want.
+func mustPanic(t *testing.T, want any, fn func()) {
+	t.Helper()
+	defer func() {
+		if r := recover(); r != want {
+			t.Errorf("recovered %v, want the panic %v to be propagated", r, want)
+		}
+	}()
+	fn()
+}
+
+func TestTrackDBRecordsPanics(t *testing.T) {
+	useTestRegistry(t)
+	metrics := prom.NewPromDatabaseMetrics(&models.DBMetricsMeta{
+		OperationsTotal:         &models.MetricMeta{Labels: []string{"op_type", "source", "entity", "is_txn", "status"}},
+		OperationsLatencyMillis: &models.MetricMeta{Labels: []string{"op_type", "source", "entity", "is_txn"}},
+	}).(*prom.PromDBMetrics)
+	labelValues := &models.DBMetricsLabelValues{OpType: "insert", Source: "postgres", AdEntity: "users", IsTxn: "false"}
+
+	mustPanic(t, "boom", func() {
+		interfaces.TrackDB(metrics, labelValues, func() *ae.AppError { panic("boom") })
+	})
+
+	total := metrics.GetOperationsTotalMetric()
+	if got := testutil.ToFloat64(total.WithLabelValues("insert", "postgres", "users", "false", constants.Total)); got != 1 {
+		t.Errorf("total operations = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(total.WithLabelValues("insert", "postgres", "users", "false", constants.Failure)); got != 1 {
+		t.Errorf("failed operations = %v, want 1", got)
+	}
+	if got := sampleCount(t, metrics.GetOperationsLatencyMillisMetric(), "insert", "postgres", "users", "false"); got != 1 {
+		t.Errorf("latency observations = %d, want 1", got)
+	}
+}
+
+func TestTrackCronJobRecordsPanics(t *testing.T) {
+	useTestRegistry(t)
+	metrics := prom.NewPromCronJobMetrics(&models.CronJobMetricsMeta{
+		JobExecutionTotal:         &models.MetricMeta{Labels: []string{"job_name", "status"}},
+		JobExecutionLatencyMillis: &models.MetricMeta{Labels: []string{"job_name"}},
+		JobsActive:                &models.MetricMeta{},
+	}).(*prom.PromCronJobMetrics)
+	labelValues := &models.CronJobMetricsLabelValues{JobName: "cleanup"}
+
+	mustPanic(t, "boom", func() {
+		interfaces.TrackCronJob(metrics, labelValues, func() *ae.AppError { panic("boom") })
+	})
+
+	if got := testutil.ToFloat64(metrics.GetJobExecutionTotalMetric().WithLabelValues("cleanup", constants.Failure)); got != 1 {
+		t.Errorf("failed executions = %v, want 1", got)
+	}
+	if got := sampleCount(t, metrics.GetJobExecutionLatencyMillisMetric(), "cleanup"); got != 1 {
+		t.Errorf("latency observations = %d, want 1", got)
+	}
+	if got := testutil.ToFloat64(metrics.GetJobsActiveMetric().WithLabelValues()); got != 0 {
+		t.Errorf("active executions = %v, want 0", got)
+	}
+}
+
+func TestTrackPublishRecordsPanics(t *testing.T) {
+	useTestRegistry(t)
+	metrics := prom.NewPromPubSubMetrics(&models.PSMetricsMeta{
+		TotalMessagesPublished:         &models.MetricMeta{Labels: []string{"entity", "op_type", "status"}},
+		MessagesPublishedLatencyMillis: &models.MetricMeta{Labels: []string{"entity", "op_type"}},
+	}).(*prom.PromPSMetrics)
+	labelValues := &models.PSMetricsLabelValues{Entity: "order", EntityOpType: "created"}
+
+	mustPanic(t, "boom", func() {
+		interfaces.TrackPublish(metrics, labelValues, func() *pubsub.EventTxnData { panic("boom") })
+	})
+
+	if got := testutil.ToFloat64(metrics.GetTotalMessagesPublishedMetric().WithLabelValues("order", "created", constants.Failure)); got != 1 {
+		t.Errorf("failed publishes = %v, want 1", got)
+	}
+	if got := sampleCount(t, metrics.GetMessagesPublishedLatencyMillisMetric(), "order", "created"); got != 1 {
+		t.Errorf("latency observations = %d, want 1", got)
+	}
+}
+
+func TestTrackConsumeRecordsPanics(t *testing.T) {
+	useTestRegistry(t)
+	metrics := prom.NewPromPubSubMetrics(&models.PSMetricsMeta{
+		TotalMessagesConsumed: &models.MetricMeta{Labels: []string{"source", "entity", "op_type", "status", "error_code"}},
+	}).(*prom.PromPSMetrics)
+	labelValues := &models.PSMetricsLabelValues{Source: "orders-sub", Entity: "order", EntityOpType: "created"}
+
+	mustPanic(t, "boom", func() {
+		interfaces.TrackConsume(metrics, labelValues, func() *ae.AppError { panic("boom") })
+	})
+
+	if got := testutil.ToFloat64(metrics.GetTotalMessagesConsumedMetric().WithLabelValues("orders-sub", "order", "created", constants.Failure, constants.ErrorCodePanic)); got != 1 {
+		t.Errorf("failed consumptions = %v, want 1", got)
+	}
+	if labelValues.ErrorCode != "" {
+		t.Errorf("caller's label values were changed to error code %q", labelValues.ErrorCode)
+	}
+}
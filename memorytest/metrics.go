@@ -0,0 +1,795 @@
+package memorytest
+
+import (
+	"maps"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/piyushkumar96/app-monitoring/constants"
+	"github.com/piyushkumar96/app-monitoring/httputil"
+	"github.com/piyushkumar96/app-monitoring/interfaces"
+	"github.com/piyushkumar96/app-monitoring/models"
+
+	"github.com/gin-gonic/gin"
+	ae "github.com/piyushkumar96/app-error"
+	pubsub "github.com/piyushkumar96/generic-pubsub"
+)
+
+// NewBundle creates a Bundle of memory implementations sharing one Recorder, which is returned
+// so that tests can inspect the observations of every member in recording order.
+func NewBundle() (*interfaces.Bundle, *Recorder) {
+	recorder := NewRecorder()
+	return &interfaces.Bundle{
+		Router:     &RouterMetrics{Recorder: recorder},
+		DB:         &DBMetrics{Recorder: recorder},
+		Downstream: &DownstreamServiceMetrics{Recorder: recorder},
+		CronJob:    &CronJobMetrics{Recorder: recorder},
+		PubSub:     &PSMetrics{Recorder: recorder},
+		App:        &AppMetrics{Recorder: recorder},
+		Readiness:  &ReadinessMetrics{Recorder: recorder},
+	}, recorder
+}
+
+// RouterMetrics is an in-memory implementation of interfaces.RouterMetricsInterface.
+type RouterMetrics struct {
+	*Recorder
+}
+
+// NewRouterMetrics creates a router metrics instance recording into its own Recorder.
+func NewRouterMetrics() *RouterMetrics {
+	return &RouterMetrics{Recorder: NewRecorder()}
+}
+
+// LogMetrics returns a Gin middleware recording each request, except those to metricsPath, into
+// http_requests (labels: method, code, path, status), http_request_latency_millis,
+// http_request_size_bytes and http_response_size_bytes (labels: method, code, path).
+func (rm *RouterMetrics) LogMetrics(metricsPath string) gin.HandlerFunc {
+	return func(gc *gin.Context) {
+		if gc.Request.URL.Path == metricsPath {
+			gc.Next()
+			return
+		}
+		start := time.Now()
+		gc.Next()
+		rm.logEntry(models.HTTPMetrics{
+			Method:                gc.Request.Method,
+			URL:                   gc.FullPath(),
+			Code:                  gc.Writer.Status(),
+			RequestBodySizeBytes:  max(gc.Request.ContentLength, 0),
+			ResponseBodySizeBytes: int64(max(gc.Writer.Size(), 0)),
+			ResponseTime:          time.Since(start),
+		})
+	}
+}
+
+// RecordConcurrencyRejection records 1 into http_requests_rejected_concurrency_total (labels: path).
+func (rm *RouterMetrics) RecordConcurrencyRejection(path string) {
+	rm.record("http_requests_rejected_concurrency_total", 1, map[string]string{constants.LabelPath: path})
+}
+
+// LogBatch records each entry as LogMetrics records a request, with its URL as the path label.
+func (rm *RouterMetrics) LogBatch(entries []models.HTTPMetrics) {
+	for _, entry := range entries {
+		rm.logEntry(entry)
+	}
+}
+
+// logEntry records one completed request.
+func (rm *RouterMetrics) logEntry(entry models.HTTPMetrics) {
+	code := strconv.Itoa(entry.Code)
+	status := constants.Failure
+	if entry.Code >= constants.HTTPStatus2XXMinValue && entry.Code <= constants.HTTPStatus2XXMaxValue {
+		status = constants.Success
+	} else if entry.Code == constants.HTTPStatusClientClosedRequest {
+		status = constants.ClientCanceled
+	}
+	rm.record("http_requests", 1, map[string]string{constants.LabelMethod: entry.Method, constants.LabelCode: code, constants.LabelPath: entry.URL, constants.LabelStatus: status})
+	labels := map[string]string{constants.LabelMethod: entry.Method, constants.LabelCode: code, constants.LabelPath: entry.URL}
+	rm.record("http_request_latency_millis", durationMillis(entry.ResponseTime), labels)
+	rm.record("http_request_size_bytes", float64(entry.RequestBodySizeBytes), labels)
+	rm.record("http_response_size_bytes", float64(entry.ResponseBodySizeBytes), labels)
+}
+
+// DBMetrics is an in-memory implementation of interfaces.DBMetricsInterface.
+type DBMetrics struct {
+	*Recorder
+}
+
+// NewDBMetrics creates a database metrics instance recording into its own Recorder.
+func NewDBMetrics() *DBMetrics {
+	return &DBMetrics{Recorder: NewRecorder()}
+}
+
+// LogMetricsPre records nothing and returns the current time.
+func (dm *DBMetrics) LogMetricsPre(_ *models.DBMetricsLabelValues) time.Time {
+	return time.Now()
+}
+
+// LogMetricsPreWithAcquire records the time since acquireStart into db_conn_wait_millis
+// (labels: op_type, source, entity, is_txn) and returns the current time.
+func (dm *DBMetrics) LogMetricsPreWithAcquire(dbMetricsLabelValues *models.DBMetricsLabelValues, acquireStart time.Time) time.Time {
+	now := time.Now()
+	dm.record("db_conn_wait_millis", durationMillis(now.Sub(acquireStart)), dbLabels(dbMetricsLabelValues))
+	return now
+}
+
+// LogMetricsPost records the operation as ObserveLatency does, with the time since opsExecTime as its duration.
+func (dm *DBMetrics) LogMetricsPost(appErr *ae.AppError, dbMetricsLabelValues *models.DBMetricsLabelValues, opsExecTime time.Time) {
+	dm.ObserveLatency(appErr, dbMetricsLabelValues, time.Since(opsExecTime))
+}
+
+// LogMetricsPostErr records the operation as LogMetricsPost does, as a success if err is nil.
+func (dm *DBMetrics) LogMetricsPostErr(err error, dbMetricsLabelValues *models.DBMetricsLabelValues, opsExecTime time.Time) {
+	dm.ObserveLatency(errAppError(err), dbMetricsLabelValues, time.Since(opsExecTime))
+}
+
+// ObserveLatency records 1 into db_operations (labels: op_type, source, entity, is_txn, status)
+// and the duration into db_operations_latency_millis (labels: op_type, source, entity, is_txn).
+func (dm *DBMetrics) ObserveLatency(appErr *ae.AppError, dbMetricsLabelValues *models.DBMetricsLabelValues, duration time.Duration) {
+	dm.ObserveWithStatus(dbMetricsLabelValues, errStatus(appErr), duration)
+}
+
+// ObserveWithStatus records the operation as ObserveLatency does, with status as the status label.
+func (dm *DBMetrics) ObserveWithStatus(dbMetricsLabelValues *models.DBMetricsLabelValues, status string, duration time.Duration) {
+	dm.record("db_operations", 1, withLabel(dbLabels(dbMetricsLabelValues), constants.LabelStatus, status))
+	dm.record("db_operations_latency_millis", durationMillis(duration), dbLabels(dbMetricsLabelValues))
+}
+
+// RecordRowsAffected records rows into db_rows_affected (labels: op_type, source, entity, is_txn).
+func (dm *DBMetrics) RecordRowsAffected(dbMetricsLabelValues *models.DBMetricsLabelValues, rows int64) {
+	dm.record("db_rows_affected", float64(rows), dbLabels(dbMetricsLabelValues))
+}
+
+// dbLabels returns the labels of a database operation.
+func dbLabels(dbMetricsLabelValues *models.DBMetricsLabelValues) map[string]string {
+	return map[string]string{
+		constants.LabelOpType: dbMetricsLabelValues.OpType,
+		constants.LabelSource: dbMetricsLabelValues.Source,
+		constants.LabelEntity: dbMetricsLabelValues.AdEntity,
+		constants.LabelIsTxn:  dbMetricsLabelValues.IsTxn,
+	}
+}
+
+// DownstreamServiceMetrics is an in-memory implementation of interfaces.DownstreamServiceMetricsInterface.
+type DownstreamServiceMetrics struct {
+	*Recorder
+}
+
+// NewDownstreamServiceMetrics creates a downstream service metrics instance recording into its own Recorder.
+func NewDownstreamServiceMetrics() *DownstreamServiceMetrics {
+	return &DownstreamServiceMetrics{Recorder: NewRecorder()}
+}
+
+// LogMetricsPre records nothing.
+func (dsm *DownstreamServiceMetrics) LogMetricsPre(_ *models.DownstreamServiceMetricsLabelValues) {
+}
+
+// LogMetricsPost records 1 into downstream_service_http_requests (labels: service, method, code, api, status),
+// and the latency and sizes into downstream_service_http_request_latency_millis,
+// downstream_service_http_request_size_bytes and downstream_service_http_response_size_bytes
+// (labels: service, method, code, api).
+func (dsm *DownstreamServiceMetrics) LogMetricsPost(success bool, dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues, httpMetrics *models.HTTPMetrics) {
+	labels := downstreamLabels(dssMetricsLabelValues, httpMetrics.Method, strconv.Itoa(httpMetrics.Code))
+	dsm.record("downstream_service_http_requests", 1, withLabel(labels, constants.LabelStatus, successStatus(success)))
+	dsm.record("downstream_service_http_request_latency_millis", durationMillis(httpMetrics.ResponseTime), labels)
+	dsm.record("downstream_service_http_request_size_bytes", float64(httpMetrics.RequestBodySizeBytes), labels)
+	dsm.record("downstream_service_http_response_size_bytes", float64(httpMetrics.ResponseBodySizeBytes), labels)
+}
+
+// LogMetricsPostResp records the call as LogMetricsPost does, deriving the HTTP metrics from resp and start
+// as the Prometheus implementation does, and the upstream latency from the default X-Response-Time header.
+// The call is successful when err is nil and the status code is below 400.
+func (dsm *DownstreamServiceMetrics) LogMetricsPostResp(dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues, resp *http.Response, start time.Time, err error) {
+	httpMetrics := &models.HTTPMetrics{Method: dssMetricsLabelValues.HTTPMethod, ResponseTime: time.Since(start)}
+	if resp != nil {
+		httpMetrics.Code = resp.StatusCode
+		httpMetrics.ResponseBodySizeBytes = max(resp.ContentLength, 0)
+		if req := resp.Request; req != nil {
+			if req.Method != "" {
+				httpMetrics.Method = req.Method
+			}
+			httpMetrics.RequestBodySizeBytes = max(req.ContentLength, 0)
+		}
+	}
+	success := err == nil && resp != nil && resp.StatusCode < http.StatusBadRequest
+	dsm.LogMetricsPost(success, dssMetricsLabelValues, httpMetrics)
+	if resp != nil {
+		if latency, ok := httputil.ParseLatencyHeader(resp.Header.Get("X-Response-Time")); ok {
+			dsm.RecordUpstreamLatency(dssMetricsLabelValues, latency)
+		}
+	}
+}
+
+// LogMetricsPostBatch records the call as LogMetricsPost does, successful when its status code is between
+// 1 and 399, and succeeded and failed into downstream_service_batch_items_total (labels: service, api, status).
+func (dsm *DownstreamServiceMetrics) LogMetricsPostBatch(dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues, httpMetrics *models.HTTPMetrics, succeeded, failed int) {
+	dsm.LogMetricsPost(httpMetrics.Code > 0 && httpMetrics.Code < http.StatusBadRequest, dssMetricsLabelValues, httpMetrics)
+	labels := map[string]string{
+		constants.LabelService: dssMetricsLabelValues.Name,
+		constants.LabelAPI:     dssMetricsLabelValues.APIIdentifier,
+	}
+	dsm.record("downstream_service_batch_items_total", float64(succeeded), withLabel(labels, constants.LabelStatus, constants.Success))
+	dsm.record("downstream_service_batch_items_total", float64(failed), withLabel(labels, constants.LabelStatus, constants.Failure))
+}
+
+// LogMetricsPostPaged records the call as LogMetricsPost does, successful when its status code is between
+// 1 and 399, and pages into downstream_service_pages_fetched (labels: service, api).
+func (dsm *DownstreamServiceMetrics) LogMetricsPostPaged(dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues, httpMetrics *models.HTTPMetrics, pages int) {
+	dsm.LogMetricsPost(httpMetrics.Code > 0 && httpMetrics.Code < http.StatusBadRequest, dssMetricsLabelValues, httpMetrics)
+	dsm.record("downstream_service_pages_fetched", float64(pages), map[string]string{
+		constants.LabelService: dssMetricsLabelValues.Name,
+		constants.LabelAPI:     dssMetricsLabelValues.APIIdentifier,
+	})
+}
+
+// LogMetricsRetryOutcome records 1 into downstream_service_retry_outcome_total and attempts into
+// downstream_service_retry_attempts (labels: service, api, outcome). The outcome is recorded as given.
+func (dsm *DownstreamServiceMetrics) LogMetricsRetryOutcome(dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues, outcome string, attempts int) {
+	labels := map[string]string{
+		constants.LabelService: dssMetricsLabelValues.Name,
+		constants.LabelAPI:     dssMetricsLabelValues.APIIdentifier,
+		constants.LabelOutcome: outcome,
+	}
+	dsm.record("downstream_service_retry_outcome_total", 1, labels)
+	dsm.record("downstream_service_retry_attempts", float64(attempts), labels)
+}
+
+// ObserveLatency records 1 into downstream_service_http_requests and the duration into
+// downstream_service_http_request_latency_millis, with an empty code label.
+func (dsm *DownstreamServiceMetrics) ObserveLatency(success bool, dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues, duration time.Duration) {
+	dsm.ObserveWithStatus(dssMetricsLabelValues, successStatus(success), duration)
+}
+
+// ObserveWithStatus records the call as ObserveLatency does, with status as the status label.
+func (dsm *DownstreamServiceMetrics) ObserveWithStatus(dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues, status string, duration time.Duration) {
+	labels := downstreamLabels(dssMetricsLabelValues, dssMetricsLabelValues.HTTPMethod, "")
+	dsm.record("downstream_service_http_requests", 1, withLabel(labels, constants.LabelStatus, status))
+	dsm.record("downstream_service_http_request_latency_millis", durationMillis(duration), labels)
+}
+
+// LogMetricsShortCircuited records 1 into downstream_service_http_requests with the short_circuited status
+// and an empty code label.
+func (dsm *DownstreamServiceMetrics) LogMetricsShortCircuited(dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues) {
+	labels := downstreamLabels(dssMetricsLabelValues, dssMetricsLabelValues.HTTPMethod, "")
+	dsm.record("downstream_service_http_requests", 1, withLabel(labels, constants.LabelStatus, constants.ShortCircuited))
+}
+
+// RecordParseTime records the duration into downstream_service_http_response_parse_millis (labels: service, api).
+func (dsm *DownstreamServiceMetrics) RecordParseTime(dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues, duration time.Duration) {
+	dsm.record("downstream_service_http_response_parse_millis", durationMillis(duration), map[string]string{
+		constants.LabelService: dssMetricsLabelValues.Name,
+		constants.LabelAPI:     dssMetricsLabelValues.APIIdentifier,
+	})
+}
+
+// RecordUpstreamLatency records the latency into downstream_service_upstream_latency_millis (labels: service, api).
+func (dsm *DownstreamServiceMetrics) RecordUpstreamLatency(dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues, latency time.Duration) {
+	dsm.record("downstream_service_upstream_latency_millis", durationMillis(latency), map[string]string{
+		constants.LabelService: dssMetricsLabelValues.Name,
+		constants.LabelAPI:     dssMetricsLabelValues.APIIdentifier,
+	})
+}
+
+// RecordConnectionSetup records the connection setup times into downstream_service_dns_millis,
+// downstream_service_connect_millis and downstream_service_tls_millis (labels: service).
+func (dsm *DownstreamServiceMetrics) RecordConnectionSetup(dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues, times models.ConnectionSetupTimes) {
+	labels := map[string]string{constants.LabelService: dssMetricsLabelValues.Name}
+	dsm.record("downstream_service_dns_millis", durationMillis(times.DNS), labels)
+	dsm.record("downstream_service_connect_millis", durationMillis(times.Connect), labels)
+	dsm.record("downstream_service_tls_millis", durationMillis(times.TLS), labels)
+}
+
+// downstreamLabels returns the labels of a downstream call.
+func downstreamLabels(dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues, method, code string) map[string]string {
+	return map[string]string{
+		constants.LabelService: dssMetricsLabelValues.Name,
+		constants.LabelMethod:  method,
+		constants.LabelCode:    code,
+		constants.LabelAPI:     dssMetricsLabelValues.APIIdentifier,
+	}
+}
+
+// CronJobMetrics is an in-memory implementation of interfaces.CronJobMetricsInterface.
+type CronJobMetrics struct {
+	*Recorder
+
+	// budgets holds the time budget of each job set with RecordBudget.
+	budgets sync.Map
+
+	// active is the number of executions between LogMetricsPre and LogMetricsPost.
+	active atomic.Int64
+}
+
+// NewCronJobMetrics creates a cron job metrics instance recording into its own Recorder.
+func NewCronJobMetrics() *CronJobMetrics {
+	return &CronJobMetrics{Recorder: NewRecorder()}
+}
+
+// LogMetricsPre records the executions in progress, incremented, into cron_jobs_active_total (no labels)
+// and returns the current time.
+func (cjm *CronJobMetrics) LogMetricsPre(_ *models.CronJobMetricsLabelValues) time.Time {
+	cjm.record("cron_jobs_active_total", float64(cjm.active.Add(1)), map[string]string{})
+	return time.Now()
+}
+
+// LogMetricsPost records the executions in progress, decremented, into cron_jobs_active_total (no labels),
+// and the execution as ObserveLatency does, with the time since opsExecTime as its duration.
+func (cjm *CronJobMetrics) LogMetricsPost(appErr *ae.AppError, cjMetricsLabelValues *models.CronJobMetricsLabelValues, opsExecTime time.Time) {
+	cjm.record("cron_jobs_active_total", float64(cjm.active.Add(-1)), map[string]string{})
+	cjm.ObserveLatency(appErr, cjMetricsLabelValues, time.Since(opsExecTime))
+}
+
+// LogMetricsPostErr records the execution as LogMetricsPost does, as a success if err is nil.
+func (cjm *CronJobMetrics) LogMetricsPostErr(err error, cjMetricsLabelValues *models.CronJobMetricsLabelValues, opsExecTime time.Time) {
+	cjm.LogMetricsPost(errAppError(err), cjMetricsLabelValues, opsExecTime)
+}
+
+// ObserveLatency records 1 into cron_job_execution_count (labels: job_name, status)
+// and the duration into cron_job_execution_latency_millis (labels: job_name), and 1 into
+// cron_job_over_budget_total (labels: job_name) when the duration exceeds the budget of the job.
+func (cjm *CronJobMetrics) ObserveLatency(appErr *ae.AppError, cjMetricsLabelValues *models.CronJobMetricsLabelValues, duration time.Duration) {
+	cjm.ObserveWithStatus(cjMetricsLabelValues, errStatus(appErr), duration)
+}
+
+// ObserveWithStatus records the execution as ObserveLatency does, with status as the status label.
+func (cjm *CronJobMetrics) ObserveWithStatus(cjMetricsLabelValues *models.CronJobMetricsLabelValues, status string, duration time.Duration) {
+	cjm.record("cron_job_execution_count", 1, map[string]string{constants.LabelJobName: cjMetricsLabelValues.JobName, constants.LabelStatus: status})
+	cjm.record("cron_job_execution_latency_millis", durationMillis(duration), map[string]string{constants.LabelJobName: cjMetricsLabelValues.JobName})
+	if budget, ok := cjm.budgets.Load(cjMetricsLabelValues.JobName); ok && duration > budget.(time.Duration) {
+		cjm.record("cron_job_over_budget_total", 1, map[string]string{constants.LabelJobName: cjMetricsLabelValues.JobName})
+	}
+}
+
+// RecordBudget sets the time budget of a job checked by ObserveLatency and LogMetricsPost;
+// a budget that is not positive removes it.
+func (cjm *CronJobMetrics) RecordBudget(jobName string, budget time.Duration) {
+	if budget <= 0 {
+		cjm.budgets.Delete(jobName)
+		return
+	}
+	cjm.budgets.Store(jobName, budget)
+}
+
+// RecordScheduleDrift records how late the job started into cron_job_schedule_drift_millis (labels: job_name).
+func (cjm *CronJobMetrics) RecordScheduleDrift(jobName string, expected, actual time.Time) {
+	cjm.record("cron_job_schedule_drift_millis", durationMillis(actual.Sub(expected)), map[string]string{constants.LabelJobName: jobName})
+}
+
+// PSMetrics is an in-memory implementation of interfaces.PSMetricsInterface.
+type PSMetrics struct {
+	*Recorder
+}
+
+// NewPSMetrics creates a pub/sub metrics instance recording into its own Recorder.
+func NewPSMetrics() *PSMetrics {
+	return &PSMetrics{Recorder: NewRecorder()}
+}
+
+// LogMetricsPre records nothing and returns the current time.
+func (psm *PSMetrics) LogMetricsPre(_ *models.PSMetricsLabelValues) time.Time {
+	return time.Now()
+}
+
+// LogMetricsPost records a publish when eventTxnData is set: 1 into pubsub_messages_published
+// (labels: entity, op_type, status), and the latency and size into pubsub_messages_published_latency_millis
+// and pubsub_messages_published_size_bytes (labels: entity, op_type); the latency is not recorded in replay mode.
+// Without eventTxnData, it records a consumption: 1 into pubsub_messages_consumed
+// (labels: source, entity, op_type, status, error_code).
+func (psm *PSMetrics) LogMetricsPost(psMetricsLabelValues *models.PSMetricsLabelValues, eventTxnData *pubsub.EventTxnData) {
+	psm.LogMetricsBatch([]models.PSBatchEntry{{LabelValues: psMetricsLabelValues, EventTxnData: eventTxnData}})
+}
+
+// LogMetricsPostOutcome records a consumption: 1 into pubsub_messages_consumed with the outcome as its status
+// (labels: source, entity, op_type, status, error_code), and the time since startTime into
+// pubsub_messages_consumed_latency_millis (labels: entity, op_type) outside replay mode.
+func (psm *PSMetrics) LogMetricsPostOutcome(psMetricsLabelValues *models.PSMetricsLabelValues, outcome string, startTime time.Time) {
+	psm.record("pubsub_messages_consumed", 1, psConsumedLabels(psMetricsLabelValues, outcome))
+	if !psMetricsLabelValues.ReplayMode {
+		psm.record("pubsub_messages_consumed_latency_millis", durationMillis(time.Since(startTime)), psEntityLabels(psMetricsLabelValues))
+	}
+}
+
+// LogMetricsPreWithTimestamps records nothing and returns the given timestamps.
+func (psm *PSMetrics) LogMetricsPreWithTimestamps(_ *models.PSMetricsLabelValues, publishTime, receiveTime time.Time) models.PSMessageTimestamps {
+	return models.PSMessageTimestamps{PublishTime: publishTime, ReceiveTime: receiveTime}
+}
+
+// LogMetricsPostWithTimestamps records a consumption as LogMetricsPost does and, outside replay mode, the time
+// from the publish to the receive timestamp into pubsub_message_queue_millis and the time since the receive
+// timestamp into pubsub_message_process_millis (labels: entity, op_type), each when its timestamps are known.
+func (psm *PSMetrics) LogMetricsPostWithTimestamps(psMetricsLabelValues *models.PSMetricsLabelValues, timestamps models.PSMessageTimestamps) {
+	psm.logPost(psMetricsLabelValues, nil, 0)
+	if psMetricsLabelValues.ReplayMode || timestamps.ReceiveTime.IsZero() {
+		return
+	}
+	if !timestamps.PublishTime.IsZero() {
+		psm.record("pubsub_message_queue_millis", durationMillis(timestamps.ReceiveTime.Sub(timestamps.PublishTime)), psEntityLabels(psMetricsLabelValues))
+	}
+	psm.record("pubsub_message_process_millis", durationMillis(time.Since(timestamps.ReceiveTime)), psEntityLabels(psMetricsLabelValues))
+}
+
+// LogMetricsPostWithWireSize records what LogMetricsPost records, and a non-zero wireSizeBytes of a publish
+// into pubsub_messages_published_wire_bytes (labels: entity, op_type).
+func (psm *PSMetrics) LogMetricsPostWithWireSize(psMetricsLabelValues *models.PSMetricsLabelValues, eventTxnData *pubsub.EventTxnData, wireSizeBytes int) {
+	psm.LogMetricsBatch([]models.PSBatchEntry{{LabelValues: psMetricsLabelValues, EventTxnData: eventTxnData, WireSizeBytes: wireSizeBytes}})
+}
+
+// LogMetricsBatch records each entry as LogMetricsPostWithWireSize does, and a non-zero ConfirmLatency of a
+// publish as RecordPublishConfirmLatency does.
+func (psm *PSMetrics) LogMetricsBatch(entries []models.PSBatchEntry) {
+	for _, entry := range entries {
+		psm.logPost(entry.LabelValues, entry.EventTxnData, entry.WireSizeBytes)
+		if entry.EventTxnData != nil && entry.ConfirmLatency > 0 {
+			psm.RecordPublishConfirmLatency(entry.LabelValues, entry.ConfirmLatency)
+		}
+	}
+}
+
+// ObserveLatency records 1 into pubsub_messages_published and, outside replay mode, the duration into
+// pubsub_messages_published_latency_millis.
+func (psm *PSMetrics) ObserveLatency(published bool, psMetricsLabelValues *models.PSMetricsLabelValues, duration time.Duration) {
+	psm.ObserveWithStatus(psMetricsLabelValues, successStatus(published), duration)
+}
+
+// ObserveWithStatus records the publish as ObserveLatency does, with status as the status label.
+func (psm *PSMetrics) ObserveWithStatus(psMetricsLabelValues *models.PSMetricsLabelValues, status string, duration time.Duration) {
+	labels := psEntityLabels(psMetricsLabelValues)
+	psm.record("pubsub_messages_published", 1, withLabel(labels, constants.LabelStatus, status))
+	if !psMetricsLabelValues.ReplayMode {
+		psm.record("pubsub_messages_published_latency_millis", durationMillis(duration), labels)
+	}
+}
+
+// RecordPublishConfirmLatency records latency into pubsub_publish_confirm_latency_millis (labels: entity, op_type),
+// outside replay mode.
+func (psm *PSMetrics) RecordPublishConfirmLatency(psMetricsLabelValues *models.PSMetricsLabelValues, latency time.Duration) {
+	if !psMetricsLabelValues.ReplayMode {
+		psm.record("pubsub_publish_confirm_latency_millis", durationMillis(latency), psEntityLabels(psMetricsLabelValues))
+	}
+}
+
+// SetPublisherQueueDepth records depth into pubsub_publisher_queue_depth (labels: entity).
+func (psm *PSMetrics) SetPublisherQueueDepth(entity string, depth int) {
+	psm.record("pubsub_publisher_queue_depth", float64(depth), map[string]string{constants.LabelEntity: entity})
+}
+
+// SetSubscriptionState records state into pubsub_subscription_state (labels: source).
+func (psm *PSMetrics) SetSubscriptionState(source string, state int) {
+	psm.record("pubsub_subscription_state", float64(state), map[string]string{constants.LabelSource: source})
+}
+
+// logPost records one completed publish or consumption; a zero wireSizeBytes records no wire size.
+func (psm *PSMetrics) logPost(psMetricsLabelValues *models.PSMetricsLabelValues, eventTxnData *pubsub.EventTxnData, wireSizeBytes int) {
+	if eventTxnData == nil {
+		status := constants.Success
+		if psMetricsLabelValues.ErrorCode != "" {
+			status = constants.Failure
+		}
+		psm.record("pubsub_messages_consumed", 1, psConsumedLabels(psMetricsLabelValues, status))
+		return
+	}
+	labels := psEntityLabels(psMetricsLabelValues)
+	psm.record("pubsub_messages_published", 1, withLabel(labels, constants.LabelStatus, successStatus(eventTxnData.IsPublished)))
+	if !psMetricsLabelValues.ReplayMode {
+		psm.record("pubsub_messages_published_latency_millis", durationMillis(eventTxnData.TimeTakenToPublish), labels)
+	}
+	psm.record("pubsub_messages_published_size_bytes", float64(eventTxnData.MessageSizeInBytes), labels)
+	if wireSizeBytes > 0 {
+		psm.record("pubsub_messages_published_wire_bytes", float64(wireSizeBytes), labels)
+	}
+}
+
+// psConsumedLabels returns the labels of a consumed message with status.
+func psConsumedLabels(psMetricsLabelValues *models.PSMetricsLabelValues, status string) map[string]string {
+	return map[string]string{
+		constants.LabelSource:    psMetricsLabelValues.Source,
+		constants.LabelEntity:    psMetricsLabelValues.Entity,
+		constants.LabelOpType:    psMetricsLabelValues.EntityOpType,
+		constants.LabelStatus:    status,
+		constants.LabelErrorCode: psMetricsLabelValues.ErrorCode,
+	}
+}
+
+// psEntityLabels returns the entity labels of a published message.
+func psEntityLabels(psMetricsLabelValues *models.PSMetricsLabelValues) map[string]string {
+	return map[string]string{
+		constants.LabelEntity: psMetricsLabelValues.Entity,
+		constants.LabelOpType: psMetricsLabelValues.EntityOpType,
+	}
+}
+
+// AppMetrics is an in-memory implementation of interfaces.AppMetricsInterface.
+type AppMetrics struct {
+	*Recorder
+}
+
+// NewAppMetrics creates an application metrics instance recording into its own Recorder.
+func NewAppMetrics() *AppMetrics {
+	return &AppMetrics{Recorder: NewRecorder()}
+}
+
+// LogMetrics records 1 into application_errors_total (labels: error_code) for each error code.
+func (am *AppMetrics) LogMetrics(errCodes []string) {
+	for _, errCode := range errCodes {
+		am.record("application_errors_total", 1, map[string]string{constants.LabelErrorCode: errCode})
+	}
+}
+
+// LogMetricsUnique records 1 into application_errors_total once for each distinct error code.
+func (am *AppMetrics) LogMetricsUnique(errCodes []string) {
+	seen := make(map[string]struct{}, len(errCodes))
+	for _, errCode := range errCodes {
+		if _, ok := seen[errCode]; ok {
+			continue
+		}
+		seen[errCode] = struct{}{}
+		am.record("application_errors_total", 1, map[string]string{constants.LabelErrorCode: errCode})
+	}
+}
+
+// DecrementAppErrorCount records -1 into application_errors_total for the error code.
+func (am *AppMetrics) DecrementAppErrorCount(errCode string) {
+	am.record("application_errors_total", -1, map[string]string{constants.LabelErrorCode: errCode})
+}
+
+// ReadinessMetrics is an in-memory implementation of interfaces.ReadinessMetricsInterface.
+type ReadinessMetrics struct {
+	*Recorder
+}
+
+// NewReadinessMetrics creates a readiness metrics instance recording into its own Recorder.
+func NewReadinessMetrics() *ReadinessMetrics {
+	return &ReadinessMetrics{Recorder: NewRecorder()}
+}
+
+// SetReady records 1 (ready) or 0 (not ready) into app_ready (labels: component).
+func (rdm *ReadinessMetrics) SetReady(component string, ready bool) {
+	value := 0.0
+	if ready {
+		value = 1
+	}
+	rdm.record("app_ready", value, map[string]string{constants.LabelComponent: component})
+}
+
+// TxnMetrics is an in-memory implementation of interfaces.TxnMetricsInterface.
+type TxnMetrics struct {
+	*Recorder
+}
+
+// NewTxnMetrics creates a transaction metrics instance recording into its own Recorder.
+func NewTxnMetrics() *TxnMetrics {
+	return &TxnMetrics{Recorder: NewRecorder()}
+}
+
+// BeginTxn starts timing a transaction. Commit or Rollback on the returned handle record 1 into
+// db_transactions_total and the duration into db_transaction_duration_millis (labels: source, outcome).
+func (tm *TxnMetrics) BeginTxn(source string) interfaces.TxnHandle {
+	return &txnHandle{metrics: tm, source: source, start: time.Now()}
+}
+
+// txnHandle times one transaction started by TxnMetrics.BeginTxn.
+type txnHandle struct {
+	metrics *TxnMetrics
+	source  string
+	start   time.Time
+}
+
+// Commit records the transaction as committed.
+func (th *txnHandle) Commit() {
+	th.end(constants.TxnCommit)
+}
+
+// Rollback records the transaction as rolled back.
+func (th *txnHandle) Rollback() {
+	th.end(constants.TxnRollback)
+}
+
+// end records the outcome and duration of the transaction.
+func (th *txnHandle) end(outcome string) {
+	labels := map[string]string{constants.LabelSource: th.source, constants.LabelOutcome: outcome}
+	th.metrics.record("db_transactions_total", 1, labels)
+	th.metrics.record("db_transaction_duration_millis", durationMillis(time.Since(th.start)), labels)
+}
+
+// OperationMetrics is an in-memory implementation of interfaces.OperationMetricsInterface.
+type OperationMetrics struct {
+	*Recorder
+}
+
+// NewOperationMetrics creates an operation metrics instance recording into its own Recorder.
+func NewOperationMetrics() *OperationMetrics {
+	return &OperationMetrics{Recorder: NewRecorder()}
+}
+
+// Start begins timing the named operation. End on the returned handle records the duration into
+// operation_duration_millis (labels: operation, outcome), and End on its dependency handles into
+// operation_dependency_duration_millis (labels: operation, kind, name, outcome).
+func (om *OperationMetrics) Start(operation string) interfaces.OperationHandle {
+	return &operationHandle{metrics: om, operation: operation, start: time.Now()}
+}
+
+// operationHandle times one operation started by OperationMetrics.Start.
+type operationHandle struct {
+	metrics   *OperationMetrics
+	operation string
+	start     time.Time
+}
+
+// Dependency begins timing a dependency call of the operation.
+// Label values are not recorded, since operation metrics are not routed to other metrics here.
+func (oh *operationHandle) Dependency(kind, name string, _ *models.DependencyLabelValues) interfaces.DependencyHandle {
+	return &dependencyHandle{operation: oh, kind: kind, name: name, start: time.Now()}
+}
+
+// End records the operation duration with its outcome.
+func (oh *operationHandle) End(appErr *ae.AppError) {
+	oh.metrics.record("operation_duration_millis", durationMillis(time.Since(oh.start)), map[string]string{
+		constants.LabelOperation: oh.operation,
+		constants.LabelOutcome:   errStatus(appErr),
+	})
+}
+
+// dependencyHandle times one dependency call started by operationHandle.Dependency.
+type dependencyHandle struct {
+	operation *operationHandle
+	kind      string
+	name      string
+	start     time.Time
+}
+
+// End records the dependency call duration with its outcome.
+func (dh *dependencyHandle) End(appErr *ae.AppError) {
+	dh.operation.metrics.record("operation_dependency_duration_millis", durationMillis(time.Since(dh.start)), map[string]string{
+		constants.LabelOperation: dh.operation.operation,
+		constants.LabelKind:      dh.kind,
+		constants.LabelName:      dh.name,
+		constants.LabelOutcome:   errStatus(appErr),
+	})
+}
+
+// withLabel returns labels with one more label, leaving labels unchanged.
+func withLabel(labels map[string]string, name, value string) map[string]string {
+	merged := maps.Clone(labels)
+	merged[name] = value
+	return merged
+}
+
+// errStatus returns the status label value for an operation outcome (nil appErr for success).
+func errStatus(appErr *ae.AppError) string {
+	return successStatus(appErr == nil)
+}
+
+// errAppError returns an app error wrapping err, or nil when err is nil.
+func errAppError(err error) *ae.AppError {
+	if err == nil {
+		return nil
+	}
+	return &ae.AppError{ActualErr: err}
+}
+
+// successStatus returns the status label value for a success flag.
+func successStatus(success bool) string {
+	if success {
+		return constants.Success
+	}
+	return constants.Failure
+}
+
+// WorkerPoolMetrics is an in-memory implementation of interfaces.WorkerPoolMetricsInterface.
+type WorkerPoolMetrics struct {
+	*Recorder
+
+	mu         sync.Mutex
+	queueDepth map[string]int
+}
+
+// NewWorkerPoolMetrics creates a worker pool metrics instance recording into its own Recorder.
+func NewWorkerPoolMetrics() *WorkerPoolMetrics {
+	return &WorkerPoolMetrics{Recorder: NewRecorder()}
+}
+
+// Enqueue records the queue depth of the task type, incremented, into worker_queue_depth (labels: task_type)
+// and starts timing the task. Start on the returned handle records the queue depth, decremented, and the time
+// the task waited into worker_queue_wait_millis (labels: task_type); Done records the execution time into
+// worker_exec_millis (labels: task_type) and 1 into worker_tasks_total (labels: task_type, status).
+func (wm *WorkerPoolMetrics) Enqueue(taskType string) interfaces.WorkerTaskHandle {
+	wm.addQueueDepth(taskType, 1)
+	return &workerTaskHandle{metrics: wm, taskType: taskType, enqueued: time.Now()}
+}
+
+// addQueueDepth adds delta to the queue depth of the task type and records it.
+func (wm *WorkerPoolMetrics) addQueueDepth(taskType string, delta int) {
+	wm.mu.Lock()
+	if wm.queueDepth == nil {
+		wm.queueDepth = make(map[string]int)
+	}
+	wm.queueDepth[taskType] += delta
+	depth := wm.queueDepth[taskType]
+	wm.mu.Unlock()
+	wm.record("worker_queue_depth", float64(depth), map[string]string{constants.LabelTaskType: taskType})
+}
+
+// workerTaskHandle times one task enqueued by WorkerPoolMetrics.Enqueue.
+type workerTaskHandle struct {
+	metrics  *WorkerPoolMetrics
+	taskType string
+	enqueued time.Time
+	started  time.Time
+}
+
+// Start records the time the task waited in the queue. Calls after the first are ignored.
+func (th *workerTaskHandle) Start() {
+	if !th.started.IsZero() {
+		return
+	}
+	th.started = time.Now()
+	th.metrics.addQueueDepth(th.taskType, -1)
+	th.metrics.record("worker_queue_wait_millis", durationMillis(th.started.Sub(th.enqueued)), map[string]string{constants.LabelTaskType: th.taskType})
+}
+
+// Done records the execution time and outcome of the task, starting it first when Start was not called.
+func (th *workerTaskHandle) Done(err error) {
+	th.Start()
+	labels := map[string]string{constants.LabelTaskType: th.taskType}
+	th.metrics.record("worker_exec_millis", durationMillis(time.Since(th.started)), labels)
+	th.metrics.record("worker_tasks_total", 1, withLabel(labels, constants.LabelStatus, successStatus(err == nil)))
+}
+
+// REDMetrics is an in-memory implementation of interfaces.REDMetricsInterface.
+type REDMetrics struct {
+	*Recorder
+}
+
+// NewREDMetrics creates a RED metrics instance recording into its own Recorder.
+func NewREDMetrics() *REDMetrics {
+	return &REDMetrics{Recorder: NewRecorder()}
+}
+
+// Observe records 1 into requests_total (labels: operation, status) and the duration into
+// request_duration_millis (labels: operation).
+func (rm *REDMetrics) Observe(operation string, d time.Duration, err error) {
+	rm.record("requests_total", 1, map[string]string{constants.LabelOperation: operation, constants.LabelStatus: successStatus(err == nil)})
+	rm.record("request_duration_millis", durationMillis(d), map[string]string{constants.LabelOperation: operation})
+}
+
+// durationMillis converts d to fractional milliseconds, recording a negative d as 0 like the Prometheus backend.
+func durationMillis(d time.Duration) float64 {
+	return float64(max(d, 0)) / float64(time.Millisecond)
+}
+
+// Compile-time interface implementation checks
+var (
+	_ interfaces.RouterMetricsInterface            = (*RouterMetrics)(nil)
+	_ interfaces.DBMetricsInterface                = (*DBMetrics)(nil)
+	_ interfaces.DownstreamServiceMetricsInterface = (*DownstreamServiceMetrics)(nil)
+	_ interfaces.CronJobMetricsInterface           = (*CronJobMetrics)(nil)
+	_ interfaces.PSMetricsInterface                = (*PSMetrics)(nil)
+	_ interfaces.AppMetricsInterface               = (*AppMetrics)(nil)
+	_ interfaces.ReadinessMetricsInterface         = (*ReadinessMetrics)(nil)
+	_ interfaces.TxnMetricsInterface               = (*TxnMetrics)(nil)
+	_ interfaces.OperationMetricsInterface         = (*OperationMetrics)(nil)
+	_ interfaces.WorkerPoolMetricsInterface        = (*WorkerPoolMetrics)(nil)
+	_ interfaces.REDMetricsInterface               = (*REDMetrics)(nil)
+)
+
+// Compile-time interface implementation checks
+var (
+	_ interfaces.RouterMetricsInterface            = (*RouterMetrics)(nil)
+	_ interfaces.DBMetricsInterface                = (*DBMetrics)(nil)
+	_ interfaces.DownstreamServiceMetricsInterface = (*DownstreamServiceMetrics)(nil)
+	_ interfaces.CronJobMetricsInterface           = (*CronJobMetrics)(nil)
+	_ interfaces.PSMetricsInterface                = (*PSMetrics)(nil)
+	_ interfaces.AppMetricsInterface               = (*AppMetrics)(nil)
+	_ interfaces.ReadinessMetricsInterface         = (*ReadinessMetrics)(nil)
+	_ interfaces.TxnMetricsInterface               = (*TxnMetrics)(nil)
+	_ interfaces.TxnHandle                         = (*txnHandle)(nil)
+	_ interfaces.OperationMetricsInterface         = (*OperationMetrics)(nil)
+	_ interfaces.OperationHandle                   = (*operationHandle)(nil)
+	_ interfaces.DependencyHandle                  = (*dependencyHandle)(nil)
+	_ interfaces.WorkerPoolMetricsInterface        = (*WorkerPoolMetrics)(nil)
+	_ interfaces.WorkerTaskHandle                  = (*workerTaskHandle)(nil)
+	_ interfaces.REDMetricsInterface               = (*REDMetrics)(nil)
+)
@@ -0,0 +1,85 @@
+// Package memorytest provides an in-memory metrics backend for unit-testing instrumentation logic.
+// It implements every metrics interface of the interfaces package and records each observation, with its
+// metric name, labels and value, into a Recorder that tests can inspect without a Prometheus registry or
+// testutil. Unlike the mocks of the interfaces package, which only track that calls happened, it captures
+// the observed values (latencies, sizes, gauge values).
+//
+// Metrics are named as the Prometheus implementation names them, without the namespace
+// (e.g. "db_operations_latency_millis"), and labeled with their conventional label names.
+// Each completed operation is recorded once, by the Post, ObserveLatency or End call with its outcome;
+// the Pre calls only return the start time. The backend is meant for tests, not for production use.
+package memorytest
+
+import (
+	"maps"
+	"sync"
+)
+
+// Observation is one value recorded by the memory backend.
+type Observation struct {
+	// Metric is the metric name without namespace (e.g. "http_requests").
+	Metric string
+
+	// Labels are the label values of the observation, keyed by label name.
+	Labels map[string]string
+
+	// Value is the increment of a counter (1, or -1 for DecrementAppErrorCount), the value observed
+	// into a histogram (latencies in milliseconds, sizes in bytes) or the value set on a gauge.
+	Value float64
+}
+
+// Recorder stores the observations of the memory backend in recording order.
+// It is safe for concurrent use, so instrumented code may record from several goroutines.
+type Recorder struct {
+	mu           sync.Mutex
+	observations []Observation
+}
+
+// NewRecorder creates an empty Recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Observations returns a copy of every observation recorded so far, in recording order.
+func (r *Recorder) Observations() []Observation {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	observations := make([]Observation, len(r.observations))
+	for i, observation := range r.observations {
+		observation.Labels = maps.Clone(observation.Labels)
+		observations[i] = observation
+	}
+	return observations
+}
+
+// ObservationsOf returns the observations recorded for metric, in recording order.
+//
+// Example:
+//
+//	latencies := dbMetrics.ObservationsOf("db_operations_latency_millis")
+//	if len(latencies) != 1 || latencies[0].Value != 12 {
+//	    t.Fatalf("unexpected latencies: %v", latencies)
+//	}
+func (r *Recorder) ObservationsOf(metric string) []Observation {
+	var observations []Observation
+	for _, observation := range r.Observations() {
+		if observation.Metric == metric {
+			observations = append(observations, observation)
+		}
+	}
+	return observations
+}
+
+// Reset discards every observation recorded so far.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.observations = nil
+}
+
+// record appends an observation.
+func (r *Recorder) record(metric string, value float64, labels map[string]string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.observations = append(r.observations, Observation{Metric: metric, Labels: labels, Value: value})
+}
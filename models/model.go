@@ -2,7 +2,13 @@
 // These models are used across all metric implementations.
 package models
 
-import "time"
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	ae "github.com/piyushkumar96/app-error"
+	pubsub "github.com/piyushkumar96/generic-pubsub"
+)
 
 // HTTPMetrics holds HTTP request/response metrics data captured during an HTTP call.
 // It is used to record metrics for downstream service calls and router-level monitoring.
@@ -24,6 +30,11 @@ type HTTPMetrics struct {
 
 	// ResponseTime is the duration taken to complete the HTTP request.
 	ResponseTime time.Duration
+
+	// ExtraLabels holds the values of the extra labels declared in the ExtraLabels of the metric metas,
+	// keyed by label name (optional). Keys that are not declared are ignored, and declared extra labels
+	// missing from the map are recorded empty. Keep the values bounded, as every distinct value creates new series.
+	ExtraLabels map[string]string
 }
 
 // MetricMeta contains common metadata for configuring metrics.
@@ -34,6 +45,63 @@ type MetricMeta struct {
 
 	// Buckets are the histogram bucket boundaries (only used for histogram metrics).
 	Buckets []float64
+
+	// BucketProfile names a bucket set registered with prometheus.RegisterBucketProfile (e.g. "db_latency"),
+	// used as the histogram buckets when Buckets is empty. Buckets overrides it when set.
+	BucketProfile string
+
+	// Quantiles, when set on a latency metric, backs it with a client-side t-digest instead of a histogram.
+	// Each quantile (between 0 and 1, e.g. 0.99) is exposed as its own gauge named "<metric>_p<NN>".
+	// Buckets are ignored when Quantiles is set.
+	Quantiles []float64
+
+	// QuantilesMaxAge, when positive, computes the Quantiles over the observations of the last QuantilesMaxAge
+	// only, like the MaxAge of a Prometheus Summary, instead of over every observation since startup.
+	QuantilesMaxAge time.Duration
+
+	// QuantilesAgeBuckets is the number of digests the QuantilesMaxAge window rotates through, like the
+	// AgeBuckets of a Prometheus Summary. Defaults to 5 when zero.
+	QuantilesAgeBuckets int
+
+	// Help overrides the default help text of the metric, e.g. to document org-specific semantics.
+	// The default is used when empty.
+	Help string
+
+	// NewSeriesPerMinute, when positive, limits how fast new label value combinations (series) of the metric
+	// can be created, smoothing bursts of new values while catching runaway growth of a label. A new series
+	// beyond the limit is recorded into the series with every label value set to constants.SeriesOverflow,
+	// and counted in app_monitoring_cardinality_protection_total. Series already created are not limited.
+	NewSeriesPerMinute float64
+
+	// NewSeriesBurst is the number of new series that can be created at once before NewSeriesPerMinute
+	// applies. Defaults to NewSeriesPerMinute (at least 1) when zero.
+	NewSeriesBurst int
+
+	// ExtraLabels declares label names appended to the Labels of the metric, whose values are taken from the
+	// ExtraLabels map of the label values passed to the LogMetrics* methods (e.g. "tenant_tier"). It is supported
+	// by the router, database, downstream service, pub/sub and cron job metrics. A declared extra label missing
+	// from the map is recorded empty, and keys of the map that are not declared are ignored.
+	// The values are recorded as given: keeping them bounded is the responsibility of the caller, as every
+	// distinct value creates new series.
+	ExtraLabels []string
+
+	// DashboardHint optionally describes how the metric should be charted by a dashboard generator.
+	// It is only metadata exported by DashboardSpec and doesn't affect the metric itself.
+	DashboardHint `yaml:"dashboard"`
+}
+
+// DashboardHint describes how a metric should be charted, for tools generating dashboards
+// (e.g. Grafana) from the metrics of a service. All fields are optional.
+type DashboardHint struct {
+	// Unit is the display unit of the metric, e.g. "ms", "bytes" or "percentunit".
+	Unit string
+
+	// Visualization is the preferred panel type, e.g. "timeseries", "heatmap" or "stat".
+	Visualization string
+
+	// SLOTarget is the objective the metric is measured against (e.g. 0.99), drawn as a threshold.
+	// Zero means no target.
+	SLOTarget float64
 }
 
 // RouterMetricsMeta contains configuration for router-level HTTP metrics.
@@ -42,6 +110,12 @@ type RouterMetricsMeta struct {
 	// Namespace is the metric namespace prefix for all router metrics.
 	Namespace string
 
+	// EnabledMetrics, when non-empty, is an allowlist of the metric names to create, without namespace
+	// (e.g. ["http_requests", "http_request_latency_millis"]). Metrics missing from it are not created,
+	// whatever their fields are set to. It only narrows what the fields enable, so a listed metric whose
+	// field is nil stays disabled. Unknown names are logged and ignored.
+	EnabledMetrics []string
+
 	// HTTPRequests configures the HTTP request counter metric.
 	// Set to nil to disable this metric.
 	HTTPRequests *MetricMeta
@@ -50,6 +124,13 @@ type RouterMetricsMeta struct {
 	// Set to nil to disable this metric.
 	HTTPRequestsLatencyMillis *MetricMeta
 
+	// HTTPTTFBMillis configures the histogram of the time to first byte: from the start of the request
+	// until the handler first writes the response (labels: method, code, path, and the optional labels of
+	// HTTPRequestsLatencyMillis). It is observed on that first write, so for streamed responses (SSE, downloads)
+	// it is recorded alongside HTTPRequestsLatencyMillis, which covers the full body. Responses without a body
+	// record the full latency. Set to nil to disable this metric (the default).
+	HTTPTTFBMillis *MetricMeta
+
 	// HTTPRequestSizeBytes configures the HTTP request size histogram.
 	// Set to nil to disable this metric.
 	HTTPRequestSizeBytes *MetricMeta
@@ -57,6 +138,201 @@ type RouterMetricsMeta struct {
 	// HTTPResponseSizeBytes configures the HTTP response size histogram.
 	// Set to nil to disable this metric.
 	HTTPResponseSizeBytes *MetricMeta
+
+	// HTTPResponseUncompressedSizeBytes configures the histogram of the size of HTTP responses before
+	// compression (labels: method, code, path). It is only recorded for the requests measured by the
+	// prometheus.MeasureUncompressedSize middleware. Set to nil to disable this metric (the default).
+	HTTPResponseUncompressedSizeBytes *MetricMeta
+
+	// HTTPResponseCompressionRatio configures the histogram of the ratio of the compressed to the uncompressed
+	// size of HTTP responses (labels: path), e.g. to spot endpoints returning already-compressed content. It is
+	// only recorded for the requests measured by the prometheus.MeasureUncompressedSize middleware, with a
+	// non-empty uncompressed body. Set to nil to disable this metric (the default).
+	HTTPResponseCompressionRatio *MetricMeta
+
+	// HTTPRequestBytesTotal configures the counter of cumulative HTTP request bytes.
+	// Unlike the size histogram, it makes rate() of ingress trivial for billing dashboards.
+	// Set to nil to disable this metric.
+	HTTPRequestBytesTotal *MetricMeta
+
+	// HTTPResponseBytesTotal configures the counter of cumulative HTTP response bytes.
+	// Unlike the size histogram, it makes rate() of egress trivial for billing dashboards.
+	// Set to nil to disable this metric.
+	HTTPResponseBytesTotal *MetricMeta
+
+	// HTTPRequestsRejectedConcurrency configures the counter of requests rejected by a concurrency
+	// limiter, recorded through RecordConcurrencyRejection (labels: path).
+	// Set to nil to disable this metric.
+	HTTPRequestsRejectedConcurrency *MetricMeta
+
+	// HTTPRequestsAborted configures the counter of requests aborted by a middleware (c.Abort())
+	// before reaching their handler, e.g. on auth or validation failures (labels: path, code).
+	// Only aborts by handlers running after LogMetrics are seen. Set to nil to disable this metric.
+	HTTPRequestsAborted *MetricMeta
+
+	// HTTPMetricsMiddlewareOverheadMicros configures the histogram of the time LogMetrics spends on its
+	// own bookkeeping per request, excluding the handlers it calls, in microseconds. It takes no labels;
+	// set Buckets in microseconds. Set to nil to disable this metric (the default).
+	HTTPMetricsMiddlewareOverheadMicros *MetricMeta
+
+	// ErrorBudgetBurnRate configures the slo_error_budget_burn_rate gauge (labels: service): the ratio of
+	// failed requests over SLOWindow divided by the error budget (1 - SLOTarget), recomputed at every scrape.
+	// Requests are failed when their status label is "failure" or "partial"; client-canceled requests are
+	// left out. It requires SLOTarget. Set to nil to disable this metric (the default).
+	ErrorBudgetBurnRate *MetricMeta
+
+	// SLOTarget is the success ratio objective of ErrorBudgetBurnRate, between 0 and 1 exclusive (e.g. 0.999).
+	SLOTarget float64
+
+	// SLOWindow is the length of the sliding window ErrorBudgetBurnRate is computed over.
+	// Defaults to 1 hour when zero.
+	SLOWindow time.Duration
+
+	// SLOService is the service label value of ErrorBudgetBurnRate. Defaults to Namespace when empty.
+	SLOService string
+
+	// DisableMethodNormalization records the raw request method as the method label.
+	// By default, methods are uppercased, so "get" and "GET" share a series, and methods outside the
+	// standard set (GET, HEAD, POST, PUT, PATCH, DELETE, CONNECT, OPTIONS, TRACE) are folded into
+	// "OTHER" so that clients sending arbitrary methods can't explode the label cardinality.
+	DisableMethodNormalization bool
+
+	// TrackAPIVersion enables the api_version label on the HTTP request counter,
+	// using the default extractor (the first path segment matching "vN", e.g. "v1").
+	// When enabled, "api_version" must be declared in HTTPRequests.Labels.
+	TrackAPIVersion bool
+
+	// VersionExtractor overrides the default API version extractor. It receives the
+	// matched route template (e.g. "/v2/users/:id") and returns the version label value.
+	// Setting it also enables the api_version label, as TrackAPIVersion does.
+	VersionExtractor func(path string) string
+
+	// TrackRouteGroup enables the route_group label on the HTTP request counter, using the default
+	// extractor (the first path segment, e.g. "api" for "/api/users/:id"), for a coarse breakdown of
+	// traffic by gin route group without per-path cardinality.
+	// When enabled, "route_group" must be declared in HTTPRequests.Labels.
+	TrackRouteGroup bool
+
+	// RouteGroupExtractor overrides the default route group extractor. It receives the matched
+	// route template (e.g. "/admin/users/:id") and returns the route group label value.
+	// Setting it also enables the route_group label, as TrackRouteGroup does.
+	RouteGroupExtractor func(path string) string
+
+	// TrackHandlerName enables the handler label on the HTTP request counter, using gin's
+	// HandlerName() (the fully-qualified name of the final handler, e.g. "main.getUserHandler").
+	// Handler names stay stable across route refactors. Disabled by default to bound cardinality;
+	// when enabled, "handler" must be declared in HTTPRequests.Labels.
+	TrackHandlerName bool
+
+	// ClientBucketFn, when set, returns the client_bucket label value of a request: a coarse bucket of its
+	// origin, such as the ASN or region of gc.ClientIP(). It must map clients onto a small, fixed set of
+	// buckets and never return the raw IP, which is unbounded and personal data. When set, "client_bucket"
+	// must be declared in HTTPRequests.Labels. LogBatch and WrapHandler record the label empty.
+	ClientBucketFn func(gc *gin.Context) string
+
+	// MaxLatencyMillis caps latency observations above it to this value and counts each capped
+	// observation in http_request_latency_clamped_total. Zero disables clamping.
+	MaxLatencyMillis float64
+
+	// MaxRequestBytes caps request size observations above it to this value and counts each oversized
+	// request in http_oversized_requests_total (labels: path). The request is still served; it is only
+	// measured. Zero disables the check.
+	MaxRequestBytes int64
+
+	// MaxResponseBytes caps response size observations above it to this value and counts each oversized
+	// response in http_oversized_responses_total (labels: path). The response is still written; it is only
+	// measured. Zero disables the check.
+	MaxResponseBytes int64
+
+	// TrackContentType enables the content_type label on the HTTP request counter, read from the
+	// response Content-Type header after the handler runs and normalized to the base media type
+	// (e.g. "application/json; charset=utf-8" -> "application/json"). Types outside a fixed set of
+	// common media types are folded into "other". Disabled by default to bound cardinality;
+	// when enabled, "content_type" must be declared in HTTPRequests.Labels.
+	TrackContentType bool
+
+	// CallerHeader enables the caller label, read from this request header carrying the caller identity
+	// in a service mesh (e.g. "X-Source-Service"), to segment inbound traffic by the service driving it.
+	// Requests without the header are recorded as "unknown", and callers missing from AllowedCallers are
+	// folded into "other" to bound cardinality. When set, "caller" must be declared in HTTPRequests.Labels;
+	// the latency histogram records it too when "caller" is declared in its labels.
+	CallerHeader string
+
+	// AllowedCallers are the caller identities recorded as-is by the caller label. Ignored unless
+	// CallerHeader is set.
+	AllowedCallers []string
+
+	// TrackDraining enables the draining label, "true" for requests that start while the process is marked
+	// as draining with prometheus.SetDraining (e.g. during a rolling deploy) and "false" otherwise, so SLO
+	// queries can exclude drain-time requests. When enabled, "draining" must be declared in HTTPRequests.Labels;
+	// the latency histogram records it too when "draining" is declared in its labels.
+	TrackDraining bool
+
+	// TrackPartialResponses records 2XX responses that fail after body bytes were written (a write
+	// to the client fails, the client goes away, or the handler reports an error with gc.Error) with
+	// status "partial" instead of "success". The response size still records the bytes delivered.
+	// Disabled by default, as it moves such requests out of the success series.
+	TrackPartialResponses bool
+
+	// LatencyBucketProfiles maps bucket profile names to the bucket sets of the latency histogram,
+	// so cheap and expensive endpoints each get fine resolution. Each profile is registered as a
+	// separate histogram vec with a constant bucket_profile label; observations go to the profile
+	// LatencyBucketProfileSelector returns, and to HTTPRequestsLatencyMillis.Buckets under the
+	// "default" profile otherwise. Ignored unless LatencyBucketProfileSelector is set, and when
+	// HTTPRequestsLatencyMillis.Quantiles is set.
+	LatencyBucketProfiles map[string][]float64
+
+	// LatencyBucketProfileSelector returns the bucket profile of a latency observation from its labels
+	// (label name to value, e.g. {"method": "GET", "code": "200", "path": "/report"}).
+	// It runs on every observation.
+	LatencyBucketProfileSelector func(labels map[string]string) string
+
+	// RequestIDKey is the context key the request ID is stored under, either in the gin context
+	// (gc.Set) or in the request context. When set and a string (or fmt.Stringer) ID is found, it
+	// is attached as the request_id exemplar label to latency histogram observations and to the failure
+	// increments of the requests counter, truncated to the Prometheus exemplar size limit. Exemplars are
+	// only exposed in the OpenMetrics format.
+	RequestIDKey any
+
+	// ExtraLabelsKey is the context key the values of the extra labels of a request are stored under, as a
+	// map[string]string, either in the gin context (gc.Set) or in the request context. The values are read once
+	// the handler has returned, so a handler can set them too, and recorded for the extra labels declared in the
+	// ExtraLabels of the metric metas. Requests without the key record the declared extra labels empty. With
+	// WrapHandler, only the request context passed to the wrapped handler is read. Keep the values bounded, as
+	// every distinct value creates new series.
+	ExtraLabelsKey any
+
+	// NotFoundPolicy decides how requests matching no route (404s and 405s raised by the router itself)
+	// are recorded: constants.NotFoundPolicyGroup records them under the constants.PathUnmatched path,
+	// constants.NotFoundPolicyDrop doesn't record them, and constants.NotFoundPolicyRaw records their raw
+	// URL path, one series per path requested. Defaults to constants.NotFoundPolicyGroup when empty.
+	NotFoundPolicy string
+
+	// SkipPaths are request paths (e.g. "/healthz", "/debug/pprof/heap") that are not recorded,
+	// in addition to the metrics path, to keep hot health checks and profiling out of the metrics.
+	// Paths are matched exactly against the request URL path.
+	SkipPaths []string
+
+	// AcceptableStatuses maps route templates (e.g. "/orders") to the non-2XX status codes recorded as
+	// success rather than failure on that route, such as a 409 Conflict returned for a duplicate order.
+	// 2XX codes are always successes; the requests of the routes not listed are classified as usual.
+	// LogBatch looks up the URL of the entry, and WrapHandler the path of the http.ServeMux pattern
+	// (e.g. "/orders/{id}"), instead of the gin route template.
+	AcceptableStatuses map[string][]int
+
+	// AppErrors, when set (typically to the interfaces.AppMetricsInterface of the application), receives
+	// the error codes of the errors handlers attach with gc.Error: after the handler returns, the
+	// middleware counts the distinct codes of each attached error that is or wraps an *ae.AppError
+	// (UNKNOWN when it has none), so handlers don't have to call the app metrics themselves.
+	// Other attached errors are ignored.
+	AppErrors interface {
+		LogMetricsUnique(errCodes []string)
+	}
+
+	// Now is the clock the middleware reads request start and end times from. Defaults to time.Now.
+	// Intended for tests, which can inject a fake clock advanced by the handler to assert exact latencies
+	// without real sleeps.
+	Now func() time.Time
 }
 
 // AppMetricsMeta contains configuration for application-level error metrics.
@@ -65,9 +341,34 @@ type AppMetricsMeta struct {
 	// Namespace is the metric namespace prefix for all app metrics.
 	Namespace string
 
+	// EnabledMetrics, when non-empty, is an allowlist of the metric names to create (e.g. ["application_errors_total"]).
+	// See RouterMetricsMeta.EnabledMetrics.
+	EnabledMetrics []string
+
 	// ApplicationErrorsCounter configures the application errors gauge metric.
 	// Set to nil to disable this metric.
 	ApplicationErrorsCounter *MetricMeta
+
+	// DistinctErrorCodes configures the gauge tracking how many distinct error codes are currently active.
+	// A code becomes active when LogMetrics first records it and stops being active once
+	// DecrementAppErrorCount brings its count back to zero. The gauge takes no labels.
+	// Set to nil to disable this metric (the default).
+	DistinctErrorCodes *MetricMeta
+
+	// ErrorRatePerMin configures the gauge of each error code's recent rate in errors per minute,
+	// an exponentially weighted moving average of LogMetrics increments (labels: error_code).
+	// Set to nil to disable this metric (the default).
+	ErrorRatePerMin *MetricMeta
+
+	// ErrorRateWindow is the time constant of the ErrorRatePerMin moving average; shorter windows
+	// react faster to spikes but are noisier. Defaults to 1 minute when zero.
+	ErrorRateWindow time.Duration
+
+	// ErrorCodeSanitizer, when set, maps each error code passed to LogMetrics, LogMetricsUnique and
+	// DecrementAppErrorCount before it is used as a label value, so codes embedding dynamic data (IDs,
+	// timestamps) such as "ERR_USER_12345" are recorded under a bounded set of codes. Use
+	// prometheus.DefaultErrorCodeSanitizer to strip trailing numbers and UUIDs. When nil, codes are recorded as-is.
+	ErrorCodeSanitizer func(code string) string
 }
 
 // DownstreamServiceMetricsMeta contains configuration for downstream service HTTP metrics.
@@ -76,14 +377,26 @@ type DownstreamServiceMetricsMeta struct {
 	// Namespace is the metric namespace prefix for all downstream service metrics.
 	Namespace string
 
+	// EnabledMetrics, when non-empty, is an allowlist of the metric names to create (e.g. ["downstream_service_http_requests"]).
+	// See RouterMetricsMeta.EnabledMetrics.
+	EnabledMetrics []string
+
 	// HTTPRequests configures the HTTP request counter metric for downstream calls.
+	// Add "idempotent" to its labels to record whether the call method is idempotent per RFC 7231.
 	// Set to nil to disable this metric.
 	HTTPRequests *MetricMeta
 
 	// HTTPRequestsLatencyMillis configures the HTTP request latency histogram for downstream calls.
+	// Add "status" to its labels to split the latencies of successful and failed calls, "idempotent"
+	// to record whether the call method is idempotent per RFC 7231, and "attempt" with TrackAttempt.
 	// Set to nil to disable this metric.
 	HTTPRequestsLatencyMillis *MetricMeta
 
+	// TrackAttempt enables the attempt label on the HTTP request latency histogram, recording the
+	// Attempt of the label values as "1", "2" or "3+", so the latencies of first attempts and retries
+	// get separate distributions. When enabled, "attempt" must be declared in HTTPRequestsLatencyMillis.Labels.
+	TrackAttempt bool
+
 	// HTTPRequestSizeBytes configures the HTTP request size histogram for downstream calls.
 	// Set to nil to disable this metric.
 	HTTPRequestSizeBytes *MetricMeta
@@ -91,6 +404,90 @@ type DownstreamServiceMetricsMeta struct {
 	// HTTPResponseSizeBytes configures the HTTP response size histogram for downstream calls.
 	// Set to nil to disable this metric.
 	HTTPResponseSizeBytes *MetricMeta
+
+	// LastCallTimestampSeconds configures the gauge of the Unix time of the last call to each downstream API
+	// (labels: service, api). Set to nil to disable this metric.
+	LastCallTimestampSeconds *MetricMeta
+
+	// LastSuccessTimestampSeconds configures the gauge of the Unix time of the last successful call to each
+	// downstream API (labels: service, api). Set to nil to disable this metric.
+	LastSuccessTimestampSeconds *MetricMeta
+
+	// ResponseParseMillis configures the histogram of the time spent decoding downstream response
+	// bodies, recorded through RecordParseTime (labels: service, api). It tells a slow dependency apart
+	// from slow deserialization of large payloads. Set to nil to disable this metric.
+	ResponseParseMillis *MetricMeta
+
+	// UpstreamLatencyMillis configures the histogram of the processing time downstream services report
+	// in the UpstreamLatencyHeader response header (labels: service, api), recorded by LogMetricsPostResp
+	// and RecordUpstreamLatency. Its difference from the call latency approximates network overhead.
+	// Responses without the header or with an unparseable value record nothing. Set to nil to disable this metric.
+	UpstreamLatencyMillis *MetricMeta
+
+	// UpstreamLatencyHeader is the response header LogMetricsPostResp reads UpstreamLatencyMillis from,
+	// holding a Go duration ("12.5ms") or a number of milliseconds ("12.5"). Defaults to "X-Response-Time".
+	UpstreamLatencyHeader string
+
+	// InFlight configures the gauge of calls to each downstream service that are currently outstanding,
+	// incremented by LogMetricsPre and decremented by LogMetricsPost (labels: service). A stuck dependency
+	// holding many open calls, exhausting the connection pool, shows up here before it surfaces as errors.
+	// Set to nil to disable this metric.
+	InFlight *MetricMeta
+
+	// BatchItemsTotal configures the counter of the items of batch calls that succeeded or failed, recorded
+	// by LogMetricsPostBatch (labels: service, api, status). It shows item-level failures of calls that
+	// succeeded as a whole. Set to nil to disable this metric.
+	BatchItemsTotal *MetricMeta
+
+	// RetryOutcomeTotal configures the counter of the final outcomes of retried calls, recorded by
+	// LogMetricsRetryOutcome (labels: service, api, outcome), with the outcomes no_retry_needed,
+	// succeeded_after_retry and exhausted. A growing share of calls succeeding only after a retry shows
+	// retries papering over a chronic problem. Set to nil to disable this metric.
+	RetryOutcomeTotal *MetricMeta
+
+	// RetryAttempts configures the histogram of the attempts made by retried calls, recorded by
+	// LogMetricsRetryOutcome (labels: service, api, outcome). Set Buckets to attempt counts (e.g. 1, 2, 3, 5),
+	// as the default buckets are meant for durations. Set to nil to disable this metric.
+	RetryAttempts *MetricMeta
+
+	// PagesFetched configures the histogram of the pages fetched by paginated calls, recorded by
+	// LogMetricsPostPaged (labels: service, api), to see logical calls fanning out into many physical requests.
+	// Set Buckets to page counts (e.g. 1, 2, 5, 10, 50), as the default buckets are meant for durations.
+	// Set to nil to disable this metric.
+	PagesFetched *MetricMeta
+
+	// DNSMillis, ConnectMillis and TLSMillis configure the histograms of the DNS lookup, TCP connect and
+	// TLS handshake times of downstream calls (labels: service), recorded by RecordConnectionSetup, e.g. from
+	// an interfaces.RoundTripper with TraceConnections set. Calls on a reused connection observe zero.
+	// Set to nil to disable these metrics.
+	DNSMillis     *MetricMeta
+	ConnectMillis *MetricMeta
+	TLSMillis     *MetricMeta
+
+	// MaxLatencyMillis caps latency observations above it to this value and counts each capped
+	// observation in downstream_service_http_request_latency_clamped_total. Zero disables clamping.
+	MaxLatencyMillis float64
+
+	// FailurePredicate, when set, decides whether an *ae.AppError found in the error passed to
+	// LogMetricsPostResp counts as a failure. Calls whose error it reports as expected (returns false for,
+	// e.g. a not-found lookup) are recorded as successful regardless of their status code. When nil, any
+	// error is a failure.
+	FailurePredicate func(appErr *ae.AppError) bool
+
+	// ServiceNameNormalizer, when set, maps the Name label value before it is recorded, e.g. to
+	// collapse "payment-service-pod-abc123" to "payment-service" and bound the service label's
+	// cardinality. When nil, Name is recorded as-is.
+	ServiceNameNormalizer func(name string) string
+
+	// AllowedServices, when non-empty, is the set of known dependencies: service names outside it (after
+	// ServiceNameNormalizer) are folded into "other" and counted in app_monitoring_cardinality_protection_total,
+	// so a bug passing dynamic names can't explode the service label. When empty, every name is recorded.
+	AllowedServices []string
+
+	// AllowedStatuses, when non-empty, is the set of statuses ObserveWithStatus records as-is besides success
+	// and failure: other statuses are folded into "other" and counted in app_monitoring_cardinality_protection_total.
+	// When empty, every status is recorded.
+	AllowedStatuses []string
 }
 
 // DownstreamServiceMetricsLabelValues holds the label values for downstream service metrics.
@@ -104,6 +501,36 @@ type DownstreamServiceMetricsLabelValues struct {
 
 	// APIIdentifier is a unique identifier for the API endpoint being called.
 	APIIdentifier string
+
+	// RequestID is the ID of the request the call is made for (optional). It is never a label: when set,
+	// it is attached as the request_id exemplar to the failure increments of the requests counter and to the
+	// latency observations, linking an error-rate or latency spike to the trace of a call. Exemplars are only
+	// exposed in the OpenMetrics format.
+	RequestID string
+
+	// Attempt is the 1-based number of the attempt of a retried call (optional). It is recorded as the
+	// attempt label of the latency histogram when DownstreamServiceMetricsMeta.TrackAttempt is enabled,
+	// with zero recorded as a first attempt and the attempts from the third on as "3+".
+	Attempt int
+
+	// ExtraLabels holds the values of the extra labels declared in the ExtraLabels of the metric metas,
+	// keyed by label name (optional). Keys that are not declared are ignored, and declared extra labels
+	// missing from the map are recorded empty. Keep the values bounded, as every distinct value creates new series.
+	ExtraLabels map[string]string
+}
+
+// ConnectionSetupTimes holds the connection setup phases of a downstream HTTP call, as captured with
+// httptrace by interfaces.RoundTripper. A phase the call didn't go through, such as all of them on a
+// reused (warm) connection, is zero.
+type ConnectionSetupTimes struct {
+	// DNS is the time spent resolving the host name.
+	DNS time.Duration
+
+	// Connect is the time spent establishing the TCP connection.
+	Connect time.Duration
+
+	// TLS is the time spent on the TLS handshake.
+	TLS time.Duration
 }
 
 // DBMetricsMeta contains configuration for database operation metrics.
@@ -112,6 +539,10 @@ type DBMetricsMeta struct {
 	// Namespace is the metric namespace prefix for all database metrics.
 	Namespace string
 
+	// EnabledMetrics, when non-empty, is an allowlist of the metric names to create (e.g. ["db_operations"]).
+	// See RouterMetricsMeta.EnabledMetrics.
+	EnabledMetrics []string
+
 	// OperationsTotal configures the database operations counter metric.
 	// Set to nil to disable this metric.
 	OperationsTotal *MetricMeta
@@ -119,6 +550,36 @@ type DBMetricsMeta struct {
 	// OperationsLatencyMillis configures the database operation latency histogram.
 	// Set to nil to disable this metric.
 	OperationsLatencyMillis *MetricMeta
+
+	// ConnWaitMillis configures the histogram of time spent waiting to acquire a pooled connection,
+	// recorded by LogMetricsPreWithAcquire separately from the operation latency
+	// (labels: op_type, source, entity, is_txn). Set to nil to disable this metric.
+	ConnWaitMillis *MetricMeta
+
+	// RowsAffected configures the histogram of the rows affected by write operations, recorded by
+	// RecordRowsAffected and interfaces.InstrumentExec (labels: op_type, source, entity, is_txn).
+	// Set to nil to disable this metric.
+	RowsAffected *MetricMeta
+
+	// MaxLatencyMillis caps latency observations above it to this value and counts each capped
+	// observation in db_operations_latency_clamped_total. Zero disables clamping.
+	MaxLatencyMillis float64
+
+	// FailurePredicate, when set, decides whether a non-nil appErr counts as a failure, so that expected
+	// errors (e.g. ERR_NOT_FOUND on lookups) it returns false for are recorded as successful operations.
+	// When nil, any non-nil appErr is a failure.
+	FailurePredicate func(appErr *ae.AppError) bool
+
+	// AllowedOpTypes, when non-empty, is the controlled vocabulary of the op_type label: op types outside
+	// it (e.g. a typo such as "selct") are folded into "other" and counted in
+	// app_monitoring_cardinality_protection_total instead of creating a parallel series. Empty by default,
+	// recording every op type as-is.
+	AllowedOpTypes []string
+
+	// AllowedStatuses, when non-empty, is the set of statuses ObserveWithStatus records as-is besides success
+	// and failure (e.g. "cached", "throttled"): other statuses are folded into "other" and counted in
+	// app_monitoring_cardinality_protection_total. When empty, every status is recorded.
+	AllowedStatuses []string
 }
 
 // DBMetricsLabelValues holds the label values for database metrics.
@@ -135,6 +596,52 @@ type DBMetricsLabelValues struct {
 
 	// IsTxn indicates whether the operation is part of a transaction ("true" or "false").
 	IsTxn string
+
+	// Statement is the name of the prepared statement being executed (optional).
+	// It is only recorded when "statement" is declared in the metric labels.
+	// Pass stable statement names (e.g. "get_user_by_id"), never raw SQL text,
+	// as raw queries are high-cardinality and can explode the number of series.
+	Statement string
+
+	// Shard is the database shard the operation ran against (optional).
+	// It is only recorded when "shard" is declared in the metric labels, which helps spot a single hot shard.
+	// Shard counts are usually small and bounded, so the label is safe cardinality-wise.
+	Shard string
+
+	// Target is the role of the database instance the operation ran against, constants.DBTargetPrimary
+	// or constants.DBTargetReplica (optional). It is only recorded when "target" is declared in the metric
+	// labels, which keeps a lagging or failing replica apart from the primary. Use the role, not the replica
+	// host name, so the label stays bounded to two values.
+	Target string
+
+	// RequestID is the ID of the request the operation is made for (optional). It is never a label: when set,
+	// it is attached as the request_id exemplar to the failure increments of the operations counter, linking
+	// an error-rate spike to the trace of a failing operation. Exemplars are only exposed in the OpenMetrics format.
+	RequestID string
+
+	// ExtraLabels holds the values of the extra labels declared in the ExtraLabels of the metric metas,
+	// keyed by label name (optional). Keys that are not declared are ignored, and declared extra labels
+	// missing from the map are recorded empty. Keep the values bounded, as every distinct value creates new series.
+	ExtraLabels map[string]string
+}
+
+// TxnMetricsMeta contains configuration for database transaction lifecycle metrics.
+// Use this to time whole transactions (begin to commit/rollback) and count commits vs rollbacks.
+type TxnMetricsMeta struct {
+	// Namespace is the metric namespace prefix for all transaction metrics.
+	Namespace string
+
+	// EnabledMetrics, when non-empty, is an allowlist of the metric names to create (e.g. ["db_transactions_total"]).
+	// See RouterMetricsMeta.EnabledMetrics.
+	EnabledMetrics []string
+
+	// TransactionsTotal configures the transaction counter metric.
+	// Expected labels: source, outcome (total/commit/rollback). Set to nil to disable this metric.
+	TransactionsTotal *MetricMeta
+
+	// TransactionDurationMillis configures the transaction duration histogram.
+	// Expected labels: source. Set to nil to disable this metric.
+	TransactionDurationMillis *MetricMeta
 }
 
 // PSMetricsMeta contains configuration for pub/sub messaging metrics.
@@ -143,6 +650,10 @@ type PSMetricsMeta struct {
 	// Namespace is the metric namespace prefix for all pub/sub metrics.
 	Namespace string
 
+	// EnabledMetrics, when non-empty, is an allowlist of the metric names to create (e.g. ["pubsub_messages_published"]).
+	// See RouterMetricsMeta.EnabledMetrics.
+	EnabledMetrics []string
+
 	// TotalMessagesConsumed configures the message consumption counter metric.
 	// Set to nil to disable this metric.
 	TotalMessagesConsumed *MetricMeta
@@ -158,6 +669,72 @@ type PSMetricsMeta struct {
 	// MessagesPublishedSizeBytes configures the published message size histogram.
 	// Set to nil to disable this metric.
 	MessagesPublishedSizeBytes *MetricMeta
+
+	// MessagesPublishedWireBytes configures the histogram of the on-the-wire (e.g. compressed) size of
+	// published messages, recorded by LogMetricsPostWithWireSize. Together with MessagesPublishedSizeBytes,
+	// which records the serialized size, it measures the compression ratio per entity.
+	// Set to nil to disable this metric.
+	MessagesPublishedWireBytes *MetricMeta
+
+	// PublishConfirmLatencyMillis configures the histogram of the time between sending a message and the broker
+	// confirming it, for brokers with publisher confirms (labels: entity, op_type). It is recorded by
+	// RecordPublishConfirmLatency, separately from the send latency of MessagesPublishedLatencyMillis.
+	// Set to nil to disable this metric.
+	PublishConfirmLatencyMillis *MetricMeta
+
+	// MessagesConsumedLatencyMillis configures the histogram of the time taken to process consumed messages
+	// (labels: entity, op_type). It is recorded by LogMetricsPostOutcome, which is given the processing start time.
+	// Set to nil to disable this metric.
+	MessagesConsumedLatencyMillis *MetricMeta
+
+	// MessageQueueMillis configures the histogram of the time consumed messages spent in the broker, from their
+	// publish to their receive timestamp (labels: entity, op_type). It is recorded by LogMetricsPostWithTimestamps
+	// when both timestamps are known. Set to nil to disable this metric.
+	MessageQueueMillis *MetricMeta
+
+	// MessageProcessMillis configures the histogram of the time taken to process consumed messages from their
+	// receive timestamp (labels: entity, op_type). It is recorded by LogMetricsPostWithTimestamps when the receive
+	// timestamp is known. Set to nil to disable this metric.
+	MessageProcessMillis *MetricMeta
+
+	// PublishSuccessRatio configures the gauge of publish success ratio per entity and op type,
+	// computed at scrape time over a sliding window of publish outcomes (labels: entity, op_type).
+	// Set to nil to disable this metric.
+	PublishSuccessRatio *MetricMeta
+
+	// PublisherQueueDepth configures the gauge of messages buffered by async publishers before sending
+	// (labels: entity). Set to nil to disable this metric.
+	PublisherQueueDepth *MetricMeta
+
+	// SubscriptionState configures the gauge of the connection state of subscription clients (labels: source),
+	// see the constants.SubscriptionState* values. Set to nil to disable this metric.
+	SubscriptionState *MetricMeta
+
+	// PublishConsumeRatio configures the gauge of consumed to successfully published messages per entity
+	// since startup (labels: entity); a ratio drifting below 1 signals a growing backlog or dropped messages.
+	// It requires both TotalMessagesConsumed and TotalMessagesPublished, each declaring the "entity" label,
+	// and is only meaningful when the same process publishes and consumes the entity.
+	// Set to nil to disable this metric.
+	PublishConsumeRatio *MetricMeta
+
+	// PublishSuccessRatioWindow is the length of the sliding window used by PublishSuccessRatio.
+	// Defaults to 5 minutes when zero.
+	PublishSuccessRatioWindow time.Duration
+
+	// MaxLatencyMillis caps latency observations above it to this value and counts each capped
+	// observation in pubsub_messages_published_latency_clamped_total. Zero disables clamping.
+	MaxLatencyMillis float64
+
+	// AllowedOpTypes, when non-empty, is the controlled vocabulary of the entity_op_type label: op types
+	// outside it (e.g. a typo such as "creat") are folded into "other" and counted in
+	// app_monitoring_cardinality_protection_total instead of creating a parallel series. Empty by default,
+	// recording every op type as-is.
+	AllowedOpTypes []string
+
+	// AllowedStatuses, when non-empty, is the set of publish statuses ObserveWithStatus records as-is besides
+	// success and failure (e.g. "throttled"): other statuses are folded into "other" and counted in
+	// app_monitoring_cardinality_protection_total. When empty, every status is recorded.
+	AllowedStatuses []string
 }
 
 // PSMetricsLabelValues holds the label values for pub/sub metrics.
@@ -174,6 +751,60 @@ type PSMetricsLabelValues struct {
 
 	// ErrorCode is the error code if the operation failed (empty string for success).
 	ErrorCode string
+
+	// Partition is the topic partition the message was published to or consumed from (optional).
+	// It is only recorded when "partition" is declared in the metric labels, which helps spot a hot partition.
+	// Partition counts are bounded per topic, so the label is safe cardinality-wise.
+	Partition string
+
+	// ConsumerGroup is the consumer group that consumed the message (optional). It is only recorded on the
+	// consumed messages counter, when "consumer_group" is declared in its labels, to compare throughput and
+	// error rates of the groups reading one subscription. Consumer groups are a fixed, deployment-defined
+	// set, so the label is safe cardinality-wise.
+	ConsumerGroup string
+
+	// ReplayMode marks an operation on a replayed or backfilled message. Its latency is not observed,
+	// and it is left out of the publish success and publish/consume ratios, since their time-based
+	// windows would be distorted by a burst of historical events. The counters still count it; declare
+	// "replay" in their labels to keep replayed messages apart from live traffic.
+	ReplayMode bool
+
+	// Format is the serialization format of the message, e.g. "json", "proto" or "avro" (optional). It is
+	// only recorded when "format" is declared in the metric labels, to compare the sizes and latencies of
+	// the encodings. Use a small fixed set of values, as every distinct format creates new series.
+	Format string
+
+	// ExtraLabels holds the values of the extra labels declared in the ExtraLabels of the metric metas,
+	// keyed by label name (optional). Keys that are not declared are ignored, and declared extra labels
+	// missing from the map are recorded empty. Keep the values bounded, as every distinct value creates new series.
+	ExtraLabels map[string]string
+}
+
+// PSMessageTimestamps holds the broker timestamps of a consumed message, as returned by LogMetricsPreWithTimestamps.
+type PSMessageTimestamps struct {
+	// PublishTime is when the message was published, zero when unknown.
+	PublishTime time.Time
+
+	// ReceiveTime is when the broker delivered the message to the consumer, zero when unknown.
+	ReceiveTime time.Time
+}
+
+// PSBatchEntry holds one completed pub/sub operation recorded through LogMetricsBatch.
+type PSBatchEntry struct {
+	// LabelValues are the label values of the operation. For consumed messages,
+	// LabelValues.ErrorCode carries the outcome (empty string for success).
+	LabelValues *PSMetricsLabelValues
+
+	// EventTxnData is the publish transaction data for published messages (nil for consumed messages).
+	EventTxnData *pubsub.EventTxnData
+
+	// WireSizeBytes is the on-the-wire (e.g. compressed) size of a published message, as passed to
+	// LogMetricsPostWithWireSize. Zero when unknown, in which case no wire size is recorded.
+	WireSizeBytes int
+
+	// ConfirmLatency is the time the broker took to confirm a published message, as passed to
+	// RecordPublishConfirmLatency. Zero when unavailable, in which case no confirmation latency is recorded.
+	ConfirmLatency time.Duration
 }
 
 // CronJobMetricsMeta contains configuration for cron job execution metrics.
@@ -182,6 +813,10 @@ type CronJobMetricsMeta struct {
 	// Namespace is the metric namespace prefix for all cron job metrics.
 	Namespace string
 
+	// EnabledMetrics, when non-empty, is an allowlist of the metric names to create (e.g. ["cron_job_execution_count"]).
+	// See RouterMetricsMeta.EnabledMetrics.
+	EnabledMetrics []string
+
 	// JobExecutionTotal configures the job execution counter metric.
 	// Set to nil to disable this metric.
 	JobExecutionTotal *MetricMeta
@@ -189,6 +824,133 @@ type CronJobMetricsMeta struct {
 	// JobExecutionLatencyMillis configures the job execution latency histogram.
 	// Set to nil to disable this metric.
 	JobExecutionLatencyMillis *MetricMeta
+
+	// JobScheduleDriftMillis configures the histogram of how late jobs start relative to their schedule.
+	// Expected labels: job name. Set to nil to disable this metric.
+	JobScheduleDriftMillis *MetricMeta
+
+	// JobConsecutiveFailures configures the gauge of how many times in a row each job has failed,
+	// reset to 0 by a successful run. Expected labels: job name. Set to nil to disable this metric.
+	JobConsecutiveFailures *MetricMeta
+
+	// JobLastRunSuccess configures the gauge set to 1 when the latest run of each job succeeded and 0
+	// when it failed, for single-stat panels of low-frequency jobs where rate() over the execution
+	// counters is meaningless. Expected labels: job name. Set to nil to disable this metric.
+	JobLastRunSuccess *MetricMeta
+
+	// JobOverBudget configures the counter of runs that took longer than the time budget set for their job
+	// with RecordBudget, e.g. the interval before the next fire. Jobs without a budget are never counted.
+	// Expected labels: job name. Set to nil to disable this metric.
+	JobOverBudget *MetricMeta
+
+	// JobsActive configures the gauge of job runs currently executing across all jobs, incremented by
+	// LogMetricsPre and decremented by LogMetricsPost. It has no labels: it is a single fleet-wide number.
+	// Set to nil to disable this metric.
+	JobsActive *MetricMeta
+
+	// MaxLatencyMillis caps latency observations above it to this value and counts each capped
+	// observation in cron_job_execution_latency_clamped_total. Zero disables clamping.
+	MaxLatencyMillis float64
+
+	// FailurePredicate, when set, decides whether a non-nil appErr counts as a failure, so that expected
+	// errors it returns false for are recorded as successful runs, in the execution counter and in the
+	// consecutive failures and last run success gauges. When nil, any non-nil appErr is a failure.
+	FailurePredicate func(appErr *ae.AppError) bool
+
+	// JobLatencyBuckets maps job names to the bucket sets of their latency histogram, so sub-millisecond
+	// jobs get fine low buckets and hour-long jobs coarse high ones. Each distinct job is registered as a
+	// separate histogram vec with a constant bucket_profile label set to the job name; other jobs use
+	// JobExecutionLatencyMillis.Buckets under the "default" profile. Ignored when
+	// JobExecutionLatencyMillis.Quantiles is set.
+	JobLatencyBuckets map[string][]float64
+
+	// PushGatewayURL, when set, makes LogMetricsPost and ObserveLatency push the cron job metrics of the job
+	// to the Pushgateway at this URL right after each run, grouped by job (set to the job name) and
+	// PushGroupingLabels, so the metrics of jobs that exit before the next scrape survive. Only the series
+	// of the job, identified by the job_name label, and the series without a job_name label are pushed.
+	// A failed push is logged and doesn't fail the job.
+	PushGatewayURL string
+
+	// PushGroupingLabels are the grouping labels added to the job of each push (e.g. {"instance": hostname}).
+	// Ignored when PushGatewayURL is empty.
+	PushGroupingLabels map[string]string
+
+	// AllowedStatuses, when non-empty, is the set of statuses ObserveWithStatus records as-is besides success
+	// and failure (e.g. "skipped"): other statuses are folded into "other" and counted in
+	// app_monitoring_cardinality_protection_total. When empty, every status is recorded.
+	AllowedStatuses []string
+}
+
+// ReadinessMetricsMeta contains configuration for component readiness metrics.
+// Use this to expose a single readiness signal shared by probes and dashboards.
+type ReadinessMetricsMeta struct {
+	// Namespace is the metric namespace prefix for all readiness metrics.
+	Namespace string
+
+	// EnabledMetrics, when non-empty, is an allowlist of the metric names to create (e.g. ["app_ready"]).
+	// See RouterMetricsMeta.EnabledMetrics.
+	EnabledMetrics []string
+
+	// AppReady configures the component readiness gauge metric.
+	// Set to nil to disable this metric.
+	AppReady *MetricMeta
+}
+
+// OperationMetricsMeta contains configuration for logical operation metrics.
+// Use this to time an operation (e.g. a repository method) together with the dependencies it touches.
+type OperationMetricsMeta struct {
+	// Namespace is the metric namespace prefix for all operation metrics.
+	Namespace string
+
+	// EnabledMetrics, when non-empty, is an allowlist of the metric names to create (e.g. ["operation_duration_millis"]).
+	// See RouterMetricsMeta.EnabledMetrics.
+	EnabledMetrics []string
+
+	// OperationDurationMillis configures the operation duration histogram.
+	// Expected labels: operation, outcome. Set to nil to disable this metric.
+	OperationDurationMillis *MetricMeta
+
+	// DependencyDurationMillis configures the per-dependency duration histogram.
+	// Expected labels: operation, kind, name, outcome. Set to nil to disable this metric.
+	DependencyDurationMillis *MetricMeta
+}
+
+// DependencyLabelValues holds the label values a dependency call is recorded with by the metrics
+// it is routed to, as passed to OperationHandle.Dependency. Set the field matching the dependency kind;
+// a call without label values for its kind is only recorded in the dependency duration histogram.
+type DependencyLabelValues struct {
+	// DB are the label values of a constants.DependencyDB call, recorded by the database metrics.
+	DB *DBMetricsLabelValues
+
+	// Downstream are the label values of a constants.DependencyDownstream call, recorded by the downstream service metrics.
+	Downstream *DownstreamServiceMetricsLabelValues
+}
+
+// WorkerPoolMetricsMeta contains configuration for worker pool metrics.
+// Use this to separate the time tasks of a continuously-running worker pool wait in the queue from the time they execute.
+type WorkerPoolMetricsMeta struct {
+	// Namespace is the metric namespace prefix for all worker pool metrics.
+	Namespace string
+
+	// EnabledMetrics, when non-empty, is an allowlist of the metric names to create (e.g. ["worker_exec_millis"]).
+	// See RouterMetricsMeta.EnabledMetrics.
+	EnabledMetrics []string
+
+	// QueueWaitMillis configures the histogram of the time tasks wait in the queue before a worker starts them.
+	// Expected labels: task_type. Set to nil to disable this metric.
+	QueueWaitMillis *MetricMeta
+
+	// ExecMillis configures the task execution duration histogram.
+	// Expected labels: task_type. Set to nil to disable this metric.
+	ExecMillis *MetricMeta
+
+	// TasksTotal configures the completed tasks counter metric.
+	// Expected labels: task_type, status. Set to nil to disable this metric.
+	TasksTotal *MetricMeta
+
+	// QueueDepth configures the gauge of the tasks enqueued but not started yet.
+	// Expected labels: task_type. Set to nil to disable this metric.
+	QueueDepth *MetricMeta
 }
 
 // CronJobMetricsLabelValues holds the label values for cron job metrics.
@@ -196,4 +958,9 @@ type CronJobMetricsMeta struct {
 type CronJobMetricsLabelValues struct {
 	// JobName is the unique name/identifier of the cron job.
 	JobName string
+
+	// ExtraLabels holds the values of the extra labels declared in the ExtraLabels of the metric metas,
+	// keyed by label name (optional). Keys that are not declared are ignored, and declared extra labels
+	// missing from the map are recorded empty. Keep the values bounded, as every distinct value creates new series.
+	ExtraLabels map[string]string
 }
@@ -0,0 +1,69 @@
+// Package otelresource converts OpenTelemetry resources for application monitoring, so the
+// Prometheus metrics can carry the same resource attributes as OpenTelemetry telemetry.
+package otelresource
+
+import (
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Resource is the part of an OpenTelemetry resource read by ConstLabelsFromResource.
+// It is implemented by *resource.Resource of go.opentelemetry.io/otel/sdk/resource.
+type Resource interface {
+	Attributes() []attribute.KeyValue
+}
+
+// ConstLabelsFromResource converts the attributes of res into Prometheus const labels, e.g.
+// service.name="checkout" into service_name="checkout", so metrics keep the label semantics of
+// OpenTelemetry during a migration between the two backends.
+//
+// Label names are the attribute keys with every character that is invalid in a Prometheus label
+// name (such as ".") replaced by "_", and prefixed with "_" when they start with a digit. Values
+// are the attributes' string forms. Attributes whose keys map to the same label name are joined
+// with ";" in key order, as the OpenTelemetry Prometheus exporter does. A nil res yields no labels.
+//
+// Example:
+//
+//	res, _ := resource.New(ctx, resource.WithAttributes(semconv.ServiceName("checkout")))
+//	constLabels := prometheus.Labels(otelresource.ConstLabelsFromResource(res))
+//	prom.SetRegisterer(prometheus.WrapRegistererWith(constLabels, prometheus.DefaultRegisterer))
+func ConstLabelsFromResource(res Resource) map[string]string {
+	labels := map[string]string{}
+	if res == nil {
+		return labels
+	}
+
+	attrs := append([]attribute.KeyValue(nil), res.Attributes()...)
+	sort.SliceStable(attrs, func(i, j int) bool { return attrs[i].Key < attrs[j].Key })
+	for _, attr := range attrs {
+		name := labelName(string(attr.Key))
+		if name == "" {
+			continue
+		}
+		value := attr.Value.Emit()
+		if existing, ok := labels[name]; ok {
+			value = existing + ";" + value
+		}
+		labels[name] = value
+	}
+	return labels
+}
+
+// labelName sanitizes an attribute key into a valid Prometheus label name.
+func labelName(key string) string {
+	if key == "" {
+		return ""
+	}
+	name := strings.Map(func(r rune) rune {
+		if r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, key)
+	if name[0] >= '0' && name[0] <= '9' {
+		name = "_" + name
+	}
+	return name
+}
@@ -0,0 +1,66 @@
+// Package oteltrace provides an OpenTelemetry trace adapter for application monitoring.
+// It annotates the spans of traced requests with the operations recorded by the metrics,
+// and is separate from any OpenTelemetry metrics backend.
+package oteltrace
+
+import (
+	"context"
+	"time"
+
+	"github.com/piyushkumar96/app-monitoring/interfaces"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DefaultEventName is the name of the span events added by SpanAnnotator when none is configured.
+const DefaultEventName = "app_monitoring.operation"
+
+// Constants for the attribute keys of the span events added by SpanAnnotator.
+const (
+	// AttributeOperation is the attribute key for the operation name.
+	AttributeOperation = "operation"
+
+	// AttributeDurationMillis is the attribute key for the operation duration in milliseconds.
+	AttributeDurationMillis = "duration_millis"
+
+	// AttributeStatus is the attribute key for the operation status (e.g. success or failure).
+	AttributeStatus = "status"
+)
+
+// SpanAnnotator is an interfaces.SpanAnnotator that adds an event to the OpenTelemetry span in the context.
+// Spans that are not recording (including the no-op span of a context without a span) are left untouched.
+type SpanAnnotator struct {
+	eventName string
+}
+
+// NewSpanAnnotator creates a SpanAnnotator adding events named eventName,
+// or DefaultEventName when eventName is empty.
+//
+// Example:
+//
+//	annotator := oteltrace.NewSpanAnnotator("")
+//	ctx = interfaces.ContextWithSpanAnnotator(ctx, annotator)
+func NewSpanAnnotator(eventName string) *SpanAnnotator {
+	if eventName == "" {
+		eventName = DefaultEventName
+	}
+	return &SpanAnnotator{eventName: eventName}
+}
+
+// AnnotateSpan adds an event with the operation name, duration and status to the span in ctx.
+// It does nothing when ctx carries no recording span.
+func (a *SpanAnnotator) AnnotateSpan(ctx context.Context, operation string, duration time.Duration, status string) {
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+	span.AddEvent(a.eventName, trace.WithAttributes(
+		attribute.String(AttributeOperation, operation),
+		attribute.Float64(AttributeDurationMillis, float64(duration)/float64(time.Millisecond)),
+		attribute.String(AttributeStatus, status),
+	))
+}
+
+// Compile-time interface implementation check
+var _ interfaces.SpanAnnotator = (*SpanAnnotator)(nil)
@@ -0,0 +1,288 @@
+package prometheus
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/piyushkumar96/app-monitoring/constants"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// defaultAsyncBufferSize is the buffer size of the asynchronous dispatcher when a non-positive one is given.
+const defaultAsyncBufferSize = 4096
+
+var (
+	// asyncDispatcher is the dispatcher observations are enqueued onto, or nil when they are recorded synchronously.
+	asyncDispatcher atomic.Pointer[AsyncDispatcher]
+
+	asyncDroppedOnce sync.Once
+	asyncDropped     *prometheus.CounterVec
+)
+
+// AsyncDispatcher records observations on a background goroutine, draining the buffered channel every
+// counter, gauge and histogram observation made by this package is enqueued onto while it is enabled.
+type AsyncDispatcher struct {
+	events  chan asyncEvent
+	done    chan struct{}
+	dropped prometheus.Counter
+	closed  atomic.Bool
+}
+
+// EnableAsyncRecording makes every observation made by this package (counter increments, gauge updates and
+// histogram observations) enqueue a lightweight event onto a buffer of bufferSize events, instead of
+// recording it on the calling goroutine. A background goroutine drains the buffer, taking the series
+// lookups and updates off the hot path, at the cost of the observations showing up slightly later.
+// A non-positive bufferSize defaults to 4096.
+//
+// When the buffer is full, observations are dropped rather than blocking the caller, and counted in
+// app_monitoring_async_observations_dropped_total; size the buffer for the bursts of the service.
+// Invalid label values are still reported to the observation error handler on the calling goroutine,
+// and DryRun takes precedence over asynchronous recording.
+//
+// Call it during startup, before metrics are observed, and Close the returned dispatcher on shutdown so
+// the buffered observations are recorded before the last scrape or push. Enabling it again replaces the
+// current dispatcher, which is closed.
+//
+// Example:
+//
+//	dispatcher := prometheus.EnableAsyncRecording(8192)
+//	defer dispatcher.Close()
+func EnableAsyncRecording(bufferSize int) *AsyncDispatcher {
+	if bufferSize <= 0 {
+		bufferSize = defaultAsyncBufferSize
+	}
+	asyncDroppedOnce.Do(func() {
+		asyncDropped = GetPromCounterVec(constants.AsyncRecordingNamespace, "async_observations_dropped_total",
+			"Counts observations dropped because the buffer of the asynchronous dispatcher was full", nil)
+	})
+	d := &AsyncDispatcher{
+		events:  make(chan asyncEvent, bufferSize),
+		done:    make(chan struct{}),
+		dropped: asyncDropped.WithLabelValues(),
+	}
+	runningGoroutines.Add(1)
+	go d.run()
+	if previous := asyncDispatcher.Swap(d); previous != nil {
+		previous.Close()
+	}
+	return d
+}
+
+// Flush blocks until the observations enqueued before the call are recorded, e.g. before reading
+// metric values in a test or pushing them to a Pushgateway. It returns at once when d is closed.
+func (d *AsyncDispatcher) Flush() {
+	if d.closed.Load() {
+		return
+	}
+	flushed := make(chan struct{})
+	select {
+	case d.events <- asyncEvent{flushed: flushed}:
+	case <-d.done:
+		return
+	}
+	select {
+	case <-flushed:
+	case <-d.done:
+	}
+}
+
+// Close stops enqueuing observations, which are recorded synchronously again, then records the
+// observations left in the buffer and stops the background goroutine, waiting for it to return. Observations made concurrently
+// with Close may be lost. Calls after the first are no-ops.
+func (d *AsyncDispatcher) Close() {
+	if !d.closed.CompareAndSwap(false, true) {
+		return
+	}
+	asyncDispatcher.CompareAndSwap(d, nil)
+	d.events <- asyncEvent{stop: true}
+	<-d.done
+}
+
+// run records the enqueued observations until a stop event is received. It is counted by RunningGoroutines
+// until done is closed, so that none is reported running once Close returns.
+func (d *AsyncDispatcher) run() {
+	defer close(d.done)
+	defer runningGoroutines.Add(-1)
+	for event := range d.events {
+		switch {
+		case event.stop:
+			return
+		case event.flushed != nil:
+			close(event.flushed)
+		default:
+			event.apply()
+		}
+	}
+}
+
+// enqueue adds event to the buffer, dropping and counting it when the buffer is full.
+func (d *AsyncDispatcher) enqueue(event asyncEvent) {
+	select {
+	case d.events <- event:
+	default:
+		d.dropped.Inc()
+	}
+}
+
+// asyncOp is the operation of an asynchronous observation.
+type asyncOp uint8
+
+const (
+	asyncCounterAdd asyncOp = iota
+	asyncGaugeAdd
+	asyncGaugeSet
+	asyncObserve
+)
+
+// asyncEvent is an observation enqueued onto the asynchronous dispatcher, or a flush or stop request.
+type asyncEvent struct {
+	op          asyncOp
+	vec         any
+	labelValues []string
+	value       float64
+	exemplar    prometheus.Labels
+
+	flushed chan struct{}
+	stop    bool
+}
+
+// apply records the observation into its series. Invalid label values are checked when the event is
+// enqueued, so a failure here is logged rather than reported to the observation error handler, whose
+// default panic would crash the background goroutine.
+func (e asyncEvent) apply() {
+	defer func() {
+		if r := recover(); r != nil {
+			logger().Error("failed to record asynchronous metric observation", "code", "OnAsyncObservationFailure", "err", fmt.Sprint(r))
+		}
+	}()
+	switch e.op {
+	case asyncCounterAdd:
+		counter := e.vec.(*prometheus.CounterVec).WithLabelValues(e.labelValues...)
+		if len(e.exemplar) > 0 {
+			if exemplarAdder, ok := counter.(prometheus.ExemplarAdder); ok {
+				exemplarAdder.AddWithExemplar(e.value, e.exemplar)
+				return
+			}
+		}
+		counter.Add(e.value)
+	case asyncGaugeAdd:
+		e.vec.(*prometheus.GaugeVec).WithLabelValues(e.labelValues...).Add(e.value)
+	case asyncGaugeSet:
+		e.vec.(*prometheus.GaugeVec).WithLabelValues(e.labelValues...).Set(e.value)
+	case asyncObserve:
+		record(e.vec.(labelObserver).WithLabelValues(e.labelValues...), e.value, e.exemplar)
+	}
+}
+
+// asyncLabelValues reports whether labelValues match the labels of vec, reporting a mismatch to the
+// observation error handler.
+func asyncLabelValues(vec any, labelValues []string) bool {
+	value, found := metricInfos.Load(vec)
+	if !found || len(labelValues) == len(value.(metricInfo).labelNames) {
+		return true
+	}
+	handleObservationError(vec, labelValues, errors.New("inconsistent label cardinality"))
+	return false
+}
+
+// asyncCounter is a counter that enqueues its increments onto the asynchronous dispatcher.
+type asyncCounter struct {
+	prometheus.Counter
+	dispatcher  *AsyncDispatcher
+	vec         *prometheus.CounterVec
+	labelValues []string
+}
+
+// Inc enqueues an increment by 1.
+func (c asyncCounter) Inc() { c.Add(1) }
+
+// Add enqueues an increment by v.
+func (c asyncCounter) Add(v float64) {
+	c.dispatcher.enqueue(asyncEvent{op: asyncCounterAdd, vec: c.vec, labelValues: c.labelValues, value: v})
+}
+
+// AddWithExemplar enqueues an increment by v carrying the exemplar labels.
+func (c asyncCounter) AddWithExemplar(v float64, exemplar prometheus.Labels) {
+	c.dispatcher.enqueue(asyncEvent{op: asyncCounterAdd, vec: c.vec, labelValues: c.labelValues, value: v, exemplar: exemplar})
+}
+
+// asyncGauge is a gauge that enqueues its updates onto the asynchronous dispatcher.
+type asyncGauge struct {
+	prometheus.Gauge
+	dispatcher  *AsyncDispatcher
+	vec         *prometheus.GaugeVec
+	labelValues []string
+}
+
+// Set enqueues setting the gauge to v.
+func (g asyncGauge) Set(v float64) { g.enqueue(asyncGaugeSet, v) }
+
+// Inc enqueues an increment by 1.
+func (g asyncGauge) Inc() { g.enqueue(asyncGaugeAdd, 1) }
+
+// Dec enqueues a decrement by 1.
+func (g asyncGauge) Dec() { g.enqueue(asyncGaugeAdd, -1) }
+
+// Add enqueues an increment by v.
+func (g asyncGauge) Add(v float64) { g.enqueue(asyncGaugeAdd, v) }
+
+// Sub enqueues a decrement by v.
+func (g asyncGauge) Sub(v float64) { g.enqueue(asyncGaugeAdd, -v) }
+
+// SetToCurrentTime enqueues setting the gauge to the current Unix time, taken when it is called.
+func (g asyncGauge) SetToCurrentTime() {
+	g.enqueue(asyncGaugeSet, float64(time.Now().UnixNano())/1e9)
+}
+
+// enqueue enqueues the gauge update.
+func (g asyncGauge) enqueue(op asyncOp, v float64) {
+	g.dispatcher.enqueue(asyncEvent{op: op, vec: g.vec, labelValues: g.labelValues, value: v})
+}
+
+// asyncObserver is an observer that enqueues its observations onto the asynchronous dispatcher.
+type asyncObserver struct {
+	dispatcher  *AsyncDispatcher
+	target      labelObserver
+	labelValues []string
+}
+
+// Observe enqueues observing v.
+func (o asyncObserver) Observe(v float64) {
+	o.dispatcher.enqueue(asyncEvent{op: asyncObserve, vec: o.target, labelValues: o.labelValues, value: v})
+}
+
+// ObserveWithExemplar enqueues observing v carrying the exemplar labels.
+func (o asyncObserver) ObserveWithExemplar(v float64, exemplar prometheus.Labels) {
+	o.dispatcher.enqueue(asyncEvent{op: asyncObserve, vec: o.target, labelValues: o.labelValues, value: v, exemplar: exemplar})
+}
+
+// counterWith returns an asyncCounter for the series of vec, or a discarding counter when the
+// label values are invalid.
+func (d *AsyncDispatcher) counterWith(vec *prometheus.CounterVec, labelValues []string) prometheus.Counter {
+	if !asyncLabelValues(vec, labelValues) {
+		return discardCounter
+	}
+	return asyncCounter{Counter: discardCounter, dispatcher: d, vec: vec, labelValues: labelValues}
+}
+
+// gaugeWith returns an asyncGauge for the series of vec, or a discarding gauge when the label values
+// are invalid.
+func (d *AsyncDispatcher) gaugeWith(vec *prometheus.GaugeVec, labelValues []string) prometheus.Gauge {
+	if !asyncLabelValues(vec, labelValues) {
+		return discardGauge
+	}
+	return asyncGauge{Gauge: discardGauge, dispatcher: d, vec: vec, labelValues: labelValues}
+}
+
+// observerWith returns an asyncObserver for the series of target, or a discarding observer when the
+// label values are invalid.
+func (d *AsyncDispatcher) observerWith(target labelObserver, labelValues []string) prometheus.Observer {
+	if !asyncLabelValues(target, labelValues) {
+		return discardObserver
+	}
+	return asyncObserver{dispatcher: d, target: target, labelValues: labelValues}
+}
@@ -0,0 +1,75 @@
+package prometheus
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// blockingObserver blocks the goroutine resolving its series until released, holding up the dispatcher.
+type blockingObserver struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (o blockingObserver) WithLabelValues(...string) prometheus.Observer {
+	close(o.started)
+	<-o.release
+	return discardObserver
+}
+
+func TestAsyncDispatcherDropsObservationsWhenFull(t *testing.T) {
+	useTestRegistry(t)
+	vec := GetPromCounterVec("async", "orders_total", "Counts orders", []string{"region"})
+	d := EnableAsyncRecording(2)
+	t.Cleanup(d.Close)
+	blocker := blockingObserver{started: make(chan struct{}), release: make(chan struct{})}
+	d.observerWith(blocker, nil).Observe(1)
+	<-blocker.started
+	dropped := testutil.ToFloat64(asyncDropped)
+
+	for i := 0; i < 3; i++ {
+		counterWith(vec, "eu").Inc()
+	}
+	close(blocker.release)
+	d.Flush()
+
+	if got := testutil.ToFloat64(vec.WithLabelValues("eu")); got != 2 {
+		t.Errorf("recorded increments = %v, want the 2 that fit in the buffer", got)
+	}
+	if got := testutil.ToFloat64(asyncDropped) - dropped; got != 1 {
+		t.Errorf("dropped observations = %v, want 1", got)
+	}
+}
+
+// BenchmarkAsyncRecording compares a counter increment and a histogram observation per operation, made from
+// concurrent goroutines, recorded on the calling goroutine against enqueued onto the asynchronous dispatcher.
+// The observations the dispatcher dropped because its buffer was full are reported as dropped/op.
+func BenchmarkAsyncRecording(b *testing.B) {
+	useTestRegistry(b)
+	counter := GetPromCounterVec("async", "bench_orders_total", "Counts orders", []string{"region", "status"})
+	histogram := GetPromHistogramVec("async", "bench_order_latency_millis", "Tracks order latencies", []string{"region"}, nil)
+	record := func(pb *testing.PB) {
+		for pb.Next() {
+			counterWith(counter, "eu", "success").Inc()
+			observerWith(histogram, []string{"eu"}).Observe(12)
+		}
+	}
+
+	b.Run("sync", func(b *testing.B) {
+		b.ReportAllocs()
+		b.RunParallel(record)
+	})
+	b.Run("async", func(b *testing.B) {
+		d := EnableAsyncRecording(1 << 16)
+		defer d.Close()
+		dropped := testutil.ToFloat64(asyncDropped)
+		b.ReportAllocs()
+		b.ResetTimer()
+		b.RunParallel(record)
+		b.StopTimer()
+		d.Flush()
+		b.ReportMetric((testutil.ToFloat64(asyncDropped)-dropped)/float64(b.N), "dropped/op")
+	})
+}
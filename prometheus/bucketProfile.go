@@ -0,0 +1,134 @@
+package prometheus
+
+import (
+	"sort"
+
+	"github.com/piyushkumar96/app-monitoring/constants"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// bucketProfileVec is a Prometheus collector backing one histogram with several bucket sets.
+// It registers a HistogramVec per bucket profile, each carrying a constant bucket_profile label,
+// and routes every observation to the profile chosen by a selector from its label values.
+// All profiles share the metric name and label names, so they are exposed as one metric family.
+type bucketProfileVec struct {
+	labelNames []string
+	selector   func(labels map[string]string) string
+	defaultVec *prometheus.HistogramVec
+	profiles   map[string]*prometheus.HistogramVec
+}
+
+// newBucketProfileVec creates and registers a bucketProfileVec. Observations for which the selector
+// returns an empty or unknown profile name are recorded with defaultBuckets under the "default" profile;
+// a profile named "default" overrides defaultBuckets.
+// If the same metric is already registered, the registered vec is returned. If registration fails otherwise
+// (e.g., same name with different labels), an error is logged but the vec is still returned.
+func newBucketProfileVec(namespace, name, help string, labelNames []string, defaultBuckets []float64, profiles map[string][]float64, selector func(labels map[string]string) string) *bucketProfileVec {
+	namespace, name = validateMetricName(namespace, name)
+	labelNames = withExtraLabelNames(namespace, name, labelNames)
+	if buckets, ok := profiles[constants.BucketProfileDefault]; ok {
+		defaultBuckets = buckets
+	}
+	vec := &bucketProfileVec{
+		labelNames: labelNames,
+		selector:   selector,
+		defaultVec: newProfileHistogramVec(namespace, name, help, labelNames, constants.BucketProfileDefault, defaultBuckets),
+		profiles:   make(map[string]*prometheus.HistogramVec, len(profiles)),
+	}
+	for profile, buckets := range profiles {
+		if profile == constants.BucketProfileDefault {
+			continue
+		}
+		vec.profiles[profile] = newProfileHistogramVec(namespace, name, help, labelNames, profile, buckets)
+	}
+	storeMetricInfo(vec, namespace, name, labelNames)
+	return vec
+}
+
+// newProfileHistogramVec creates and registers the HistogramVec of one bucket profile.
+func newProfileHistogramVec(namespace, name, help string, labelNames []string, profile string, buckets []float64) *prometheus.HistogramVec {
+	buckets = validateBuckets(namespace, name, nonNegativeBuckets(namespace, name, buckets))
+	constLabels := prometheus.Labels{constants.LabelBucketProfile: profile}
+	histogram := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace:   namespace,
+			Name:        name,
+			Help:        help,
+			Buckets:     buckets,
+			ConstLabels: constLabels,
+		}, labelNames,
+	)
+	histogram, err := registerCollector(histogram)
+	if err != nil {
+		logger().Error("failed to register histogram vec metric", "code", "OnHistogramMetricRegisterFailure", "profile", profile, "err", err.Error())
+	} else {
+		recordInventory(namespace, name, MetricTypeHistogram, help, labelNames, constLabels, histogramBuckets(buckets))
+	}
+	return histogram
+}
+
+// WithLabelValues returns the histogram series for the given label values in the profile chosen by the selector.
+// Like the Prometheus vecs, it panics when the number of label values doesn't match the label names.
+func (v *bucketProfileVec) WithLabelValues(labelValues ...string) prometheus.Observer {
+	labels := make(map[string]string, len(v.labelNames))
+	for i, name := range v.labelNames {
+		if i < len(labelValues) {
+			labels[name] = labelValues[i]
+		}
+	}
+	if vec, ok := v.profiles[v.selector(labels)]; ok {
+		return vec.WithLabelValues(labelValues...)
+	}
+	return v.defaultVec.WithLabelValues(labelValues...)
+}
+
+// vecs returns the HistogramVec of every profile, the default one first and the others sorted by profile name.
+func (v *bucketProfileVec) vecs() []*prometheus.HistogramVec {
+	names := make([]string, 0, len(v.profiles))
+	for name := range v.profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	vecs := make([]*prometheus.HistogramVec, 0, len(v.profiles)+1)
+	vecs = append(vecs, v.defaultVec)
+	for _, name := range names {
+		vecs = append(vecs, v.profiles[name])
+	}
+	return vecs
+}
+
+// Delete removes the series with the given labels from every profile and reports whether one existed.
+// The bucket_profile label may be omitted.
+func (v *bucketProfileVec) Delete(labels prometheus.Labels) bool {
+	variable := make(prometheus.Labels, len(v.labelNames))
+	for _, name := range v.labelNames {
+		value, ok := labels[name]
+		if !ok {
+			return false
+		}
+		variable[name] = value
+	}
+	deleted := false
+	for _, vec := range v.vecs() {
+		if vec.Delete(variable) {
+			deleted = true
+		}
+	}
+	return deleted
+}
+
+// Describe implements prometheus.Collector.
+// The profile vecs are registered individually, so the vec itself is not registered.
+func (v *bucketProfileVec) Describe(ch chan<- *prometheus.Desc) {
+	for _, vec := range v.vecs() {
+		vec.Describe(ch)
+	}
+}
+
+// Collect implements prometheus.Collector.
+func (v *bucketProfileVec) Collect(ch chan<- prometheus.Metric) {
+	for _, vec := range v.vecs() {
+		vec.Collect(ch)
+	}
+}
@@ -0,0 +1,63 @@
+package prometheus
+
+import (
+	"slices"
+	"sort"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// validateBuckets returns the bucket boundaries of a histogram as a strictly increasing slice. Unsorted or
+// duplicated boundaries (a common copy-paste config mistake) are sorted and deduplicated with a warning
+// instead of panicking on the first observation. The input is not modified.
+func validateBuckets(namespace, name string, buckets []float64) []float64 {
+	if len(buckets) == 0 {
+		return buckets
+	}
+	metric := prometheus.BuildFQName(namespace, "", name)
+
+	valid := slices.Clone(buckets)
+	if !sort.Float64sAreSorted(valid) {
+		logger().Warn("histogram buckets are not in increasing order, sorting them", "code", "OnUnsortedHistogramBuckets", "metric", metric, "buckets", buckets)
+		sort.Float64s(valid)
+	}
+
+	deduplicated := valid[:0]
+	var duplicates []float64
+	for i, bucket := range valid {
+		if i > 0 && bucket == valid[i-1] {
+			duplicates = append(duplicates, bucket)
+			continue
+		}
+		deduplicated = append(deduplicated, bucket)
+	}
+	if len(duplicates) > 0 {
+		logger().Warn("histogram buckets contain duplicates, dropping them", "code", "OnDuplicateHistogramBuckets", "metric", metric, "buckets", duplicates)
+	}
+	return deduplicated
+}
+
+// nonNegativeBuckets returns the bucket boundaries of a histogram of latencies or sizes, which can't be
+// negative, without the negative boundaries, dropping them with an error. The input is not modified.
+func nonNegativeBuckets(namespace, name string, buckets []float64) []float64 {
+	if !slices.ContainsFunc(buckets, func(bucket float64) bool { return bucket < 0 }) {
+		return buckets
+	}
+	valid := make([]float64, 0, len(buckets))
+	var negative []float64
+	for _, bucket := range buckets {
+		if bucket < 0 {
+			negative = append(negative, bucket)
+			continue
+		}
+		valid = append(valid, bucket)
+	}
+	logger().Error("histogram buckets must be non-negative, dropping negative buckets", "code", "OnInvalidHistogramBuckets", "metric", prometheus.BuildFQName(namespace, "", name), "buckets", negative)
+	return valid
+}
+
+// newNonNegativeHistogramVec creates and registers a HistogramVec of latencies or sizes, as GetPromHistogramVec
+// does, dropping the negative buckets. The built-in metrics create their histograms with it.
+func newNonNegativeHistogramVec(namespace, name, help string, labelNames []string, buckets []float64) *prometheus.HistogramVec {
+	return GetPromHistogramVec(namespace, name, help, labelNames, nonNegativeBuckets(namespace, name, buckets))
+}
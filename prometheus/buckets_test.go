@@ -0,0 +1,96 @@
+package prometheus
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/piyushkumar96/app-monitoring/models"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestValidateBuckets(t *testing.T) {
+	tests := []struct {
+		name    string
+		buckets []float64
+		want    []float64
+	}{
+		{name: "nil", buckets: nil, want: nil},
+		{name: "valid", buckets: []float64{1, 5, 10}, want: []float64{1, 5, 10}},
+		{name: "unsorted", buckets: []float64{10, 1, 5}, want: []float64{1, 5, 10}},
+		{name: "duplicated", buckets: []float64{10, 50, 50, 25}, want: []float64{10, 25, 50}},
+		{name: "negative kept", buckets: []float64{5, -10, 0}, want: []float64{-10, 0, 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := slices.Clone(tt.buckets)
+			if got := validateBuckets("test", "values", input); !slices.Equal(got, tt.want) {
+				t.Errorf("validateBuckets(%v) = %v, want %v", tt.buckets, got, tt.want)
+			}
+			if !slices.Equal(input, tt.buckets) {
+				t.Errorf("validateBuckets modified its input to %v", input)
+			}
+		})
+	}
+}
+
+func TestNonNegativeBuckets(t *testing.T) {
+	tests := []struct {
+		name    string
+		buckets []float64
+		want    []float64
+	}{
+		{name: "nil", buckets: nil, want: nil},
+		{name: "non-negative", buckets: []float64{0, 1, 5}, want: []float64{0, 1, 5}},
+		{name: "negative dropped", buckets: []float64{-5, 1, -1, 10}, want: []float64{1, 10}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := slices.Clone(tt.buckets)
+			if got := nonNegativeBuckets("test", "latency_millis", input); !slices.Equal(got, tt.want) {
+				t.Errorf("nonNegativeBuckets(%v) = %v, want %v", tt.buckets, got, tt.want)
+			}
+			if !slices.Equal(input, tt.buckets) {
+				t.Errorf("nonNegativeBuckets modified its input to %v", input)
+			}
+		})
+	}
+}
+
+// upperBounds returns the bucket upper bounds of the histogram series of the label values.
+func upperBounds(t *testing.T, vec *prometheus.HistogramVec, labelValues ...string) []float64 {
+	t.Helper()
+	var metric dto.Metric
+	if err := vec.WithLabelValues(labelValues...).(prometheus.Metric).Write(&metric); err != nil {
+		t.Fatalf("write histogram: %v", err)
+	}
+	var bounds []float64
+	for _, bucket := range metric.GetHistogram().GetBucket() {
+		bounds = append(bounds, bucket.GetUpperBound())
+	}
+	return bounds
+}
+
+func TestHistogramBuckets(t *testing.T) {
+	useTestRegistry(t)
+
+	business := NewBusinessMetrics("test", "billing")
+	balance := business.Histogram("balance_delta", "Tracks balance changes", nil, []float64{100, -100, 0, 0})
+	if got, want := upperBounds(t, balance.GetMetric()), []float64{-100, 0, 100}; !slices.Equal(got, want) {
+		t.Errorf("business histogram buckets = %v, want %v", got, want)
+	}
+
+	temperature := GetPromHistogramVec("test", "temperature_celsius", "Tracks temperatures", nil, []float64{-20, 0, 20})
+	if got, want := upperBounds(t, temperature), []float64{-20, 0, 20}; !slices.Equal(got, want) {
+		t.Errorf("generic histogram buckets = %v, want %v", got, want)
+	}
+
+	db := NewPromDatabaseMetrics(&models.DBMetricsMeta{
+		Namespace:               "test",
+		OperationsLatencyMillis: &models.MetricMeta{Labels: dbLatencyLabelNames, Buckets: []float64{50, -1, 10, 10}},
+	}).(*PromDBMetrics)
+	if got, want := upperBounds(t, db.GetOperationsLatencyMillisMetric(), "select", "postgres", "users", "false"), []float64{10, 50}; !slices.Equal(got, want) {
+		t.Errorf("latency histogram buckets = %v, want %v", got, want)
+	}
+}
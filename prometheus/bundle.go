@@ -0,0 +1,64 @@
+package prometheus
+
+import (
+	"errors"
+
+	"github.com/piyushkumar96/app-monitoring/interfaces"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// collectorsProvider is implemented by every Prometheus metrics type.
+type collectorsProvider interface {
+	Collectors() []prometheus.Collector
+}
+
+// BundleCollectors returns the collectors of every Prometheus member of a bundle (e.g. one built with
+// interfaces.MergeBundles), so they can be registered against a single registry for the metrics handler.
+// Members of other backends are skipped, and a collector shared by several members (such as the cardinality
+// protection counter) is returned once. An error is returned when members expose the same metric, which
+// registering them together would reject.
+func BundleCollectors(bundle *interfaces.Bundle) ([]prometheus.Collector, error) {
+	// Register against a scratch registry to detect metric collisions the way a real registry would
+	registry := prometheus.NewRegistry()
+	var collectors []prometheus.Collector
+	var errs []error
+	for _, member := range bundle.Members() {
+		provider, ok := member.(collectorsProvider)
+		if !ok {
+			continue
+		}
+		for _, collector := range provider.Collectors() {
+			if err := registry.Register(collector); err != nil {
+				var alreadyRegistered prometheus.AlreadyRegisteredError
+				if errors.As(err, &alreadyRegistered) && alreadyRegistered.ExistingCollector == collector {
+					continue
+				}
+				errs = append(errs, err)
+				continue
+			}
+			collectors = append(collectors, collector)
+		}
+	}
+	return collectors, errors.Join(errs...)
+}
+
+// BundleSeriesCounts returns the number of series each metric of the Prometheus members of a bundle currently
+// holds, keyed by fully-qualified metric name, as SeriesCounts counts them. The collectors returned by
+// BundleCollectors are gathered from a scratch registry, so it doesn't depend on the registry the members are
+// registered against, and metrics without series yet are left out. It returns the counts it could gather
+// together with the error, if any.
+//
+// Example:
+//
+//	counts, err := prometheus.BundleSeriesCounts(metrics)
+func BundleSeriesCounts(bundle *interfaces.Bundle) (map[string]int, error) {
+	collectors, err := BundleCollectors(bundle)
+	registry := prometheus.NewRegistry()
+	for _, collector := range collectors {
+		// Collectors are already checked for collisions by BundleCollectors
+		_ = registry.Register(collector)
+	}
+	families, gatherErr := registry.Gather()
+	return familySeriesCounts(families), errors.Join(err, gatherErr)
+}
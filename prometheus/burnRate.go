@@ -0,0 +1,84 @@
+package prometheus
+
+import (
+	"sync"
+	"time"
+
+	"github.com/piyushkumar96/app-monitoring/constants"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// defaultSLOWindow is the burn rate window used when RouterMetricsMeta.SLOWindow is unset.
+const defaultSLOWindow = time.Hour
+
+// burnRateCollector is a prometheus.Collector exposing the error budget burn rate of a service: the error
+// ratio of the outcomes recorded over a sliding window divided by the error budget (1 - target). A burn
+// rate of 1 consumes the budget exactly over the SLO period; 14.4 over one hour is the usual paging threshold
+// for a 30-day 99.9% SLO. The rate is recomputed at every scrape, so it decays once failures stop.
+type burnRateCollector struct {
+	desc    *prometheus.Desc
+	service string
+	budget  float64
+
+	// window holds the request outcomes, guarded by mu.
+	mu     sync.Mutex
+	window *slidingWindow
+}
+
+// newBurnRateCollector creates and registers a burnRateCollector for the given success ratio target
+// (between 0 and 1, exclusive) over window. An invalid target is logged and returns nil, disabling the metric.
+// If the same metric is already registered, the registered collector is returned. If registration fails
+// otherwise (e.g., same name with different labels), an error is logged but the collector is still returned.
+func newBurnRateCollector(namespace, name, help, service string, target float64, window time.Duration) *burnRateCollector {
+	if target <= 0 || target >= 1 {
+		logger().Error("SLO target must be between 0 and 1, exclusive; the error budget burn rate is not recorded", "code", "OnInvalidSLOTarget", "target", target)
+		return nil
+	}
+	if window <= 0 {
+		window = defaultSLOWindow
+	}
+	namespace, name = validateMetricName(namespace, name)
+	labelNames := []string{constants.LabelService}
+	collector := &burnRateCollector{
+		desc:    prometheus.NewDesc(prometheus.BuildFQName(namespace, "", name), help, labelNames, nil),
+		service: service,
+		budget:  1 - target,
+		window:  newSlidingWindow(window),
+	}
+	collector, err := registerCollector(collector)
+	if err != nil {
+		logger().Error("failed to register error budget burn rate metric", "code", "OnBurnRateMetricRegisterFailure", "err", err.Error())
+	} else {
+		recordInventory(namespace, name, MetricTypeGauge, help, labelNames, nil, nil)
+	}
+	return collector
+}
+
+// record adds a request outcome observed at now to the window.
+func (c *burnRateCollector) record(now time.Time, success bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.window.record(now, success)
+}
+
+// burnRate returns the burn rate of the window ending at now, which is 0 when no outcome was recorded.
+func (c *burnRateCollector) burnRate(now time.Time) float64 {
+	c.mu.Lock()
+	ratio, ok := c.window.ratio(now)
+	c.mu.Unlock()
+	if !ok {
+		return 0
+	}
+	return (1 - ratio) / c.budget
+}
+
+// Describe implements prometheus.Collector.
+func (c *burnRateCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+// Collect implements prometheus.Collector, exposing the burn rate of the window ending at the scrape time.
+func (c *burnRateCollector) Collect(ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, c.burnRate(time.Now()), c.service)
+}
@@ -0,0 +1,56 @@
+package prometheus
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	"github.com/piyushkumar96/app-monitoring/constants"
+	"github.com/piyushkumar96/app-monitoring/models"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// captureMu serializes the captures of CaptureRequestMetrics, which swap the registerer.
+var captureMu sync.Mutex
+
+// CaptureRequestMetrics serves req with handler, wrapped with WrapHandler over router metrics registered
+// against a fresh registry, and returns the metric families gathered from that registry, so a test can
+// assert that a single request produced exactly the expected metrics, isolated from every other test.
+//
+// The router metrics are created without a namespace, recording http_requests (labels: method, code, path,
+// status) and http_request_latency_millis, http_request_size_bytes and http_response_size_bytes (labels:
+// method, code, path). The path label is the http.ServeMux pattern or gin route template the request matched,
+// or its raw URL path when it matched none. Observations enqueued onto the
+// asynchronous dispatcher are flushed before gathering, and nothing is recorded in DryRun mode or while
+// metrics are disabled. Metrics recorded by handler itself, into metrics created beforehand, are not captured.
+//
+// The fresh registry is installed with SetRegisterer while the router metrics are created, then the previous
+// registerer is restored, so don't create metrics concurrently with it, e.g. from parallel tests.
+//
+// Example:
+//
+//	families, err := prometheus.CaptureRequestMetrics(mux, httptest.NewRequest(http.MethodGet, "/users/1", nil))
+func CaptureRequestMetrics(handler http.Handler, req *http.Request) ([]*dto.MetricFamily, error) {
+	registry := prometheus.NewRegistry()
+
+	captureMu.Lock()
+	previous := getRegisterer()
+	SetRegisterer(registry)
+	metrics := NewPromRouterMetrics(&models.RouterMetricsMeta{
+		HTTPRequests:              &models.MetricMeta{Labels: routerRequestsLabelNames},
+		HTTPRequestsLatencyMillis: &models.MetricMeta{Labels: routerResponseLabelNames},
+		HTTPRequestSizeBytes:      &models.MetricMeta{Labels: routerResponseLabelNames},
+		HTTPResponseSizeBytes:     &models.MetricMeta{Labels: routerResponseLabelNames},
+		NotFoundPolicy:            constants.NotFoundPolicyRaw,
+	})
+	SetRegisterer(previous)
+	captureMu.Unlock()
+
+	WrapHandler(metrics, "", handler).ServeHTTP(httptest.NewRecorder(), req)
+	if dispatcher := asyncDispatcher.Load(); dispatcher != nil {
+		dispatcher.Flush()
+	}
+	return registry.Gather()
+}
@@ -0,0 +1,68 @@
+package prometheus
+
+import (
+	"sync"
+
+	"github.com/piyushkumar96/app-monitoring/constants"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	cardinalityProtectionOnce sync.Once
+	cardinalityProtection     *prometheus.CounterVec
+)
+
+// registerCardinalityProtection registers the app_monitoring_cardinality_protection_total counter
+// shared by every cardinality guard (labels: metric, reason). It is registered once, by the first
+// constructor that enables a guard, against the registerer configured at that time.
+func registerCardinalityProtection() {
+	cardinalityProtectionOnce.Do(func() {
+		cardinalityProtection = GetPromCounterVec(constants.CardinalityProtectionNamespace, "cardinality_protection_total",
+			"Counts label values folded into a sentinel or normalized value to bound the cardinality of a metric",
+			[]string{constants.LabelMetric, constants.LabelReason})
+	})
+}
+
+// recordCardinalityProtection counts a label value of metric (its name without namespace) that a
+// cardinality guard folded or normalized for reason. Guards call it once per recorded operation.
+func recordCardinalityProtection(metric, reason string) {
+	if cardinalityProtection != nil {
+		counterWith(cardinalityProtection, metric, reason).Inc()
+	}
+}
+
+// allowedOpType returns opType when allowed is nil or contains it, and constants.OpTypeOther otherwise,
+// reporting whether it was folded.
+func allowedOpType(allowed map[string]struct{}, opType string) (string, bool) {
+	if allowed == nil {
+		return opType, false
+	}
+	if _, ok := allowed[opType]; ok {
+		return opType, false
+	}
+	return constants.OpTypeOther, true
+}
+
+// allowedStatus returns status when allowed is nil or contains it, or status is constants.Success or
+// constants.Failure, and constants.StatusOther otherwise, counting the fold against metric.
+func allowedStatus(allowed map[string]struct{}, status, metric string) string {
+	if allowed == nil || status == constants.Success || status == constants.Failure {
+		return status
+	}
+	if _, ok := allowed[status]; ok {
+		return status
+	}
+	recordCardinalityProtection(metric, constants.ReasonStatusFolded)
+	return constants.StatusOther
+}
+
+// allowlist returns the set of allowed label values (e.g. AllowedOpTypes), or nil when every value is allowed.
+// A non-nil set registers the cardinality protection counter its folds are counted in.
+func allowlist(allowed []string) map[string]struct{} {
+	if len(allowed) == 0 {
+		return nil
+	}
+	registerCardinalityProtection()
+	return stringSet(allowed)
+}
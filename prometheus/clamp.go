@@ -0,0 +1,89 @@
+package prometheus
+
+import (
+	"sync"
+	"time"
+
+	"github.com/piyushkumar96/app-monitoring/constants"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	negativeLatencyOnce         sync.Once
+	negativeLatencyObservations *prometheus.CounterVec
+)
+
+// nonNegativeLatency returns d, or 0 when d is negative, counting the negative latency in the unlabeled
+// app_monitoring_negative_latency_observations_total counter. Latencies measured against the wall clock
+// (e.g. from a start time passed in by the caller, or an injected clock) go negative when the clock is
+// stepped back, as by an NTP adjustment, and a negative observation would decrease the histogram _sum.
+// The counter is registered by the first negative latency, against the registerer configured at that time.
+func nonNegativeLatency(d time.Duration) time.Duration {
+	if d >= 0 {
+		return d
+	}
+	negativeLatencyOnce.Do(func() {
+		negativeLatencyObservations = GetPromCounterVec(constants.NegativeLatencyNamespace, "negative_latency_observations_total",
+			"Counts negative latencies, caused by clock adjustments, observed as 0", nil)
+	})
+	counterWith(negativeLatencyObservations).Inc()
+	return 0
+}
+
+// latencyClamp caps latency observations at a configured maximum and counts each capped observation,
+// so outliers caused by clock jumps or stuck dependencies don't distort histogram sums and percentiles
+// while still signalling that clamping occurred. A nil *latencyClamp performs no clamping.
+type latencyClamp struct {
+	maxMillis float64
+	clamped   *prometheus.CounterVec
+}
+
+// newLatencyClamp returns a latencyClamp registering the "<prefix>_latency_clamped_total" counter,
+// or nil when maxMillis is not positive.
+func newLatencyClamp(namespace, prefix string, maxMillis float64) *latencyClamp {
+	if maxMillis <= 0 {
+		return nil
+	}
+	return &latencyClamp{
+		maxMillis: maxMillis,
+		clamped:   GetPromCounterVec(namespace, prefix+"_latency_clamped_total", "Counts latency observations clamped to the configured maximum", nil),
+	}
+}
+
+// clamp returns millis capped at the configured maximum, counting the observation when it was capped.
+func (lc *latencyClamp) clamp(millis float64) float64 {
+	if lc == nil || millis <= lc.maxMillis {
+		return millis
+	}
+	counterWith(lc.clamped).Inc()
+	return lc.maxMillis
+}
+
+// sizeClamp caps request or response size observations at a configured maximum and counts each oversized
+// body per path, as an early signal of payload-based abuse or a bug. It only measures: the request is
+// still served. A nil *sizeClamp performs no clamping.
+type sizeClamp struct {
+	maxBytes  float64
+	oversized *prometheus.CounterVec
+}
+
+// newSizeClamp returns a sizeClamp registering the name counter (labels: path), or nil when maxBytes is not positive.
+func newSizeClamp(namespace, name, help string, maxBytes int64) *sizeClamp {
+	if maxBytes <= 0 {
+		return nil
+	}
+	return &sizeClamp{
+		maxBytes:  float64(maxBytes),
+		oversized: GetPromCounterVec(namespace, name, help, []string{constants.LabelPath}),
+	}
+}
+
+// clamp returns bytes capped at the configured maximum, counting the body for path when it was capped.
+func (sc *sizeClamp) clamp(bytes float64, path string) float64 {
+	if sc == nil || bytes <= sc.maxBytes {
+		return bytes
+	}
+	counterWith(sc.oversized, path).Inc()
+	return sc.maxBytes
+}
@@ -0,0 +1,60 @@
+package prometheus
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/piyushkumar96/app-monitoring/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// negativeLatencyCount returns the number of negative latencies observed as 0 so far.
+func negativeLatencyCount() float64 {
+	if negativeLatencyObservations == nil {
+		return 0
+	}
+	return testutil.ToFloat64(negativeLatencyObservations)
+}
+
+func TestNegativeLatenciesAreObservedAsZero(t *testing.T) {
+	t.Run("router clock going backwards", func(t *testing.T) {
+		clock := &fakeClock{now: time.Unix(1000, 0)}
+		rlm := newTestRouterMetrics(t, &models.RouterMetricsMeta{Now: clock.Now})
+		engine := gin.New()
+		engine.Use(rlm.LogMetrics("/metrics"))
+		engine.GET("/orders", func(c *gin.Context) {
+			clock.advance(-5 * time.Millisecond)
+			c.Status(http.StatusOK)
+		})
+		negative := negativeLatencyCount()
+
+		serve(engine, http.MethodGet, "/orders")
+
+		if count, sum := histogramSample(t, rlm.httpRequestsLatencyMillis, http.MethodGet, "200", "/orders"); count != 1 || sum != 0 {
+			t.Errorf("latency observations = %d with sum %vms, want 1 with sum 0ms", count, sum)
+		}
+		if got := negativeLatencyCount() - negative; got != 1 {
+			t.Errorf("negative latencies counted = %v, want 1", got)
+		}
+	})
+	t.Run("database start time in the future", func(t *testing.T) {
+		useTestRegistry(t)
+		dm := NewPromDatabaseMetrics(&models.DBMetricsMeta{
+			OperationsLatencyMillis: &models.MetricMeta{Labels: dbLatencyLabelNames},
+		}).(*PromDBMetrics)
+		labelValues := &models.DBMetricsLabelValues{OpType: "select", Source: "postgres", AdEntity: "users", IsTxn: "false"}
+		negative := negativeLatencyCount()
+
+		dm.LogMetricsPost(nil, labelValues, time.Now().Add(time.Hour))
+
+		if count, sum := histogramSample(t, dm.operationsLatencyMillis, "select", "postgres", "users", "false"); count != 1 || sum != 0 {
+			t.Errorf("latency observations = %d with sum %vms, want 1 with sum 0ms", count, sum)
+		}
+		if got := negativeLatencyCount() - negative; got != 1 {
+			t.Errorf("negative latencies counted = %v, want 1", got)
+		}
+	})
+}
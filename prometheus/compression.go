@@ -0,0 +1,58 @@
+package prometheus
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// uncompressedSizeKey is the gin context key MeasureUncompressedSize stores the uncompressed response size under.
+const uncompressedSizeKey = "app-monitoring.uncompressed-response-size"
+
+// MeasureUncompressedSize returns a Gin middleware measuring the size of responses before compression, for the
+// HTTPResponseUncompressedSizeBytes and HTTPResponseCompressionRatio metrics of the router metrics. Register it
+// after the compression middleware (e.g. gin-contrib/gzip), so that it counts the bytes handlers write before
+// they are compressed, while LogMetrics is registered before the compression middleware and records the
+// compressed size as the response size. Without it, neither metric is recorded.
+//
+// Example:
+//
+//	router.Use(routerMetrics.LogMetrics("/metrics"), gzip.Gzip(gzip.DefaultCompression), prometheus.MeasureUncompressedSize())
+func MeasureUncompressedSize() gin.HandlerFunc {
+	return func(gc *gin.Context) {
+		writer := &countingWriter{ResponseWriter: gc.Writer}
+		gc.Writer = writer
+		gc.Next()
+		gc.Writer = writer.ResponseWriter
+		gc.Set(uncompressedSizeKey, writer.size)
+	}
+}
+
+// uncompressedSize returns the uncompressed response size measured by MeasureUncompressedSize, and false
+// when it didn't measure the request.
+func uncompressedSize(gc *gin.Context) (float64, bool) {
+	value, ok := gc.Get(uncompressedSizeKey)
+	if !ok {
+		return 0, false
+	}
+	size, ok := value.(int64)
+	return float64(size), ok
+}
+
+// countingWriter wraps a gin.ResponseWriter and counts the body bytes written through it.
+type countingWriter struct {
+	gin.ResponseWriter
+	size int64
+}
+
+// Write writes to the wrapped writer and counts the bytes written.
+func (w *countingWriter) Write(data []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(data)
+	w.size += int64(n)
+	return n, err
+}
+
+// WriteString writes to the wrapped writer and counts the bytes written.
+func (w *countingWriter) WriteString(s string) (int, error) {
+	n, err := w.ResponseWriter.WriteString(s)
+	w.size += int64(n)
+	return n, err
+}
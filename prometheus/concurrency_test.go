@@ -0,0 +1,295 @@
+package prometheus
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/piyushkumar96/app-monitoring/constants"
+	"github.com/piyushkumar96/app-monitoring/models"
+
+	"github.com/gin-gonic/gin"
+	ae "github.com/piyushkumar96/app-error"
+	"github.com/piyushkumar96/generic-pubsub"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// The tests below record into every metrics type from many goroutines while the registry is scraped.
+// They assert the recorded totals, and are meant to be run with -race to catch unguarded state.
+const (
+	concurrentGoroutines = 16
+	concurrentIterations = 200
+)
+
+// hammer calls record concurrently from concurrentGoroutines goroutines, concurrentIterations times each,
+// while registry is gathered in a loop, the way scrapes race with recording.
+func hammer(t *testing.T, registry *prometheus.Registry, record func(goroutine, iteration int)) {
+	t.Helper()
+	done := make(chan struct{})
+	scraped := make(chan struct{})
+	go func() {
+		defer close(scraped)
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				if _, err := registry.Gather(); err != nil {
+					t.Errorf("gather: %v", err)
+					return
+				}
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for g := 0; g < concurrentGoroutines; g++ {
+		wg.Add(1)
+		go func(goroutine int) {
+			defer wg.Done()
+			for i := 0; i < concurrentIterations; i++ {
+				record(goroutine, i)
+			}
+		}(g)
+	}
+	wg.Wait()
+	close(done)
+	<-scraped
+}
+
+// testAppError returns an app error with code.
+func testAppError(code string) *ae.AppError {
+	return &ae.AppError{
+		ActualErr:  errors.New(code),
+		CustomErr:  &ae.CustomErr{Code: code, Message: code},
+		ErrorCodes: []string{code},
+	}
+}
+
+func TestRouterMetricsConcurrentUse(t *testing.T) {
+	registry := useTestRegistry(t)
+	appMetrics := NewPromAppMetrics(&models.AppMetricsMeta{
+		ApplicationErrorsCounter: &models.MetricMeta{Labels: []string{constants.LabelErrorCode}},
+		DistinctErrorCodes:       &models.MetricMeta{},
+	})
+	rlm := NewPromRouterMetrics(&models.RouterMetricsMeta{
+		HTTPRequests:              &models.MetricMeta{Labels: routerRequestsLabelNames},
+		HTTPRequestsLatencyMillis: &models.MetricMeta{Labels: routerResponseLabelNames},
+		HTTPRequestSizeBytes:      &models.MetricMeta{Labels: routerResponseLabelNames},
+		HTTPResponseSizeBytes:     &models.MetricMeta{Labels: routerResponseLabelNames},
+		HTTPResponseBytesTotal:    &models.MetricMeta{Labels: routerResponseLabelNames},
+		HTTPRequestsAborted:       &models.MetricMeta{Labels: routerAbortedLabelNames},
+		ErrorBudgetBurnRate:       &models.MetricMeta{},
+		SLOTarget:                 0.99,
+		SLOWindow:                 time.Minute,
+		AppErrors:                 appMetrics,
+	}).(*PromRouterMetrics)
+
+	engine := gin.New()
+	engine.Use(rlm.LogMetrics("/metrics"))
+	engine.GET("/items/:id", func(c *gin.Context) {
+		if id, _ := strconv.Atoi(c.Param("id")); id%2 == 1 {
+			_ = c.Error(testAppError("ERR_ITEM"))
+			c.String(http.StatusInternalServerError, "failed")
+			return
+		}
+		c.String(http.StatusOK, "item")
+	})
+
+	hammer(t, registry, func(_, iteration int) {
+		engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/items/"+strconv.Itoa(iteration), nil))
+	})
+
+	requests := float64(concurrentGoroutines * concurrentIterations)
+	if got := requestCount(rlm, http.MethodGet, "", "/items/:id", constants.Total); got != requests {
+		t.Errorf("total requests = %v, want %v", got, requests)
+	}
+	if got := requestCount(rlm, http.MethodGet, "200", "/items/:id", constants.Success) + requestCount(rlm, http.MethodGet, "500", "/items/:id", constants.Failure); got != requests {
+		t.Errorf("successful and failed requests = %v, want %v", got, requests)
+	}
+	if got := testutil.ToFloat64(appMetrics.(*PromAppMetrics).applicationErrorsCounter.WithLabelValues("ERR_ITEM")); got != requests/2 {
+		t.Errorf("harvested app errors = %v, want %v", got, requests/2)
+	}
+}
+
+func TestDBMetricsConcurrentUse(t *testing.T) {
+	registry := useTestRegistry(t)
+	dm := NewPromDatabaseMetrics(&models.DBMetricsMeta{
+		OperationsTotal:         &models.MetricMeta{Labels: dbTotalLabelNames},
+		OperationsLatencyMillis: &models.MetricMeta{Labels: dbLatencyLabelNames},
+		ConnWaitMillis:          &models.MetricMeta{Labels: dbLatencyLabelNames},
+		RowsAffected:            &models.MetricMeta{Labels: dbLatencyLabelNames},
+	}).(*PromDBMetrics)
+	labelValues := &models.DBMetricsLabelValues{OpType: "select", Source: "postgres", AdEntity: "users", IsTxn: "false"}
+
+	hammer(t, registry, func(_, iteration int) {
+		start := dm.LogMetricsPreWithAcquire(labelValues, time.Now())
+		switch iteration % 3 {
+		case 0:
+			dm.LogMetricsPost(nil, labelValues, start)
+		case 1:
+			dm.LogMetricsPost(testAppError("ERR_DB"), labelValues, start)
+		default:
+			dm.LogMetricsPostErr(errors.New("connection reset"), labelValues, start)
+		}
+		dm.RecordRowsAffected(labelValues, int64(iteration))
+	})
+
+	operations := float64(concurrentGoroutines * concurrentIterations)
+	if got := testutil.ToFloat64(dm.operationsTotal.WithLabelValues("select", "postgres", "users", "false", constants.Total)); got != operations {
+		t.Errorf("total operations = %v, want %v", got, operations)
+	}
+}
+
+func TestDownstreamServiceMetricsConcurrentUse(t *testing.T) {
+	registry := useTestRegistry(t)
+	dsm := NewPromDownstreamServiceMetrics(&models.DownstreamServiceMetricsMeta{
+		HTTPRequests:                &models.MetricMeta{Labels: dsRequestsLabelNames},
+		HTTPRequestsLatencyMillis:   &models.MetricMeta{Labels: dsResponseLabelNames},
+		LastCallTimestampSeconds:    &models.MetricMeta{Labels: dsTimestampLabelNames},
+		LastSuccessTimestampSeconds: &models.MetricMeta{Labels: dsTimestampLabelNames},
+		InFlight:                    &models.MetricMeta{Labels: dsServiceLabelNames},
+		BatchItemsTotal:             &models.MetricMeta{Labels: dsBatchItemsLabelNames},
+		RetryOutcomeTotal:           &models.MetricMeta{Labels: dsRetryLabelNames},
+		RetryAttempts:               &models.MetricMeta{Labels: dsRetryLabelNames},
+		PagesFetched:                &models.MetricMeta{Labels: dsPagesLabelNames},
+	}).(*PromDownstreamServiceMetrics)
+	labelValues := &models.DownstreamServiceMetricsLabelValues{Name: "billing", HTTPMethod: http.MethodPost, APIIdentifier: "charge"}
+	httpMetrics := &models.HTTPMetrics{Method: http.MethodPost, Code: http.StatusOK, ResponseTime: time.Millisecond}
+
+	hammer(t, registry, func(_, iteration int) {
+		dsm.LogMetricsPre(labelValues)
+		switch iteration % 5 {
+		case 0:
+			dsm.LogMetricsPost(true, labelValues, httpMetrics)
+		case 1:
+			dsm.LogMetricsPostResp(labelValues, &http.Response{StatusCode: http.StatusBadGateway, Body: http.NoBody}, time.Now(), nil)
+		case 2:
+			dsm.LogMetricsPostBatch(labelValues, httpMetrics, 3, 1)
+		case 3:
+			dsm.LogMetricsPostPaged(labelValues, httpMetrics, 2)
+		default:
+			dsm.LogMetricsShortCircuited(labelValues)
+			dsm.LogMetricsPost(false, labelValues, httpMetrics)
+		}
+		dsm.LogMetricsRetryOutcome(labelValues, constants.RetryOutcomeSucceededAfterRetry, iteration%3)
+	})
+
+	if got := testutil.ToFloat64(dsm.inFlight.WithLabelValues("billing")); got != 0 {
+		t.Errorf("in-flight requests = %v, want 0", got)
+	}
+}
+
+func TestCronJobMetricsConcurrentUse(t *testing.T) {
+	registry := useTestRegistry(t)
+	cjm := NewPromCronJobMetrics(&models.CronJobMetricsMeta{
+		JobExecutionTotal:         &models.MetricMeta{Labels: cronTotalLabelNames},
+		JobExecutionLatencyMillis: &models.MetricMeta{Labels: []string{constants.LabelJobName}},
+		JobConsecutiveFailures:    &models.MetricMeta{Labels: []string{constants.LabelJobName}},
+		JobLastRunSuccess:         &models.MetricMeta{Labels: []string{constants.LabelJobName}},
+		JobOverBudget:             &models.MetricMeta{Labels: []string{constants.LabelJobName}},
+		JobsActive:                &models.MetricMeta{},
+	}).(*PromCronJobMetrics)
+
+	hammer(t, registry, func(goroutine, iteration int) {
+		labelValues := &models.CronJobMetricsLabelValues{JobName: "job-" + strconv.Itoa(goroutine%4)}
+		cjm.RecordBudget(labelValues.JobName, time.Hour)
+		start := cjm.LogMetricsPre(labelValues)
+		switch iteration % 3 {
+		case 0:
+			cjm.LogMetricsPost(nil, labelValues, start)
+		case 1:
+			cjm.LogMetricsPost(testAppError("ERR_JOB"), labelValues, start)
+		default:
+			cjm.LogMetricsPostErr(errors.New("timeout"), labelValues, start)
+		}
+	})
+
+	if got := testutil.ToFloat64(cjm.jobsActive.WithLabelValues()); got != 0 {
+		t.Errorf("active jobs = %v, want 0", got)
+	}
+	executions := float64(concurrentGoroutines * concurrentIterations)
+	var total float64
+	for job := 0; job < 4; job++ {
+		total += testutil.ToFloat64(cjm.jobExecutionTotal.WithLabelValues("job-"+strconv.Itoa(job), constants.Total))
+	}
+	if total != executions {
+		t.Errorf("total executions = %v, want %v", total, executions)
+	}
+}
+
+func TestPSMetricsConcurrentUse(t *testing.T) {
+	registry := useTestRegistry(t)
+	psm := NewPromPubSubMetrics(&models.PSMetricsMeta{
+		TotalMessagesConsumed:          &models.MetricMeta{Labels: psConsumedLabelNames},
+		TotalMessagesPublished:         &models.MetricMeta{Labels: psPublishedLabelNames},
+		MessagesPublishedLatencyMillis: &models.MetricMeta{Labels: psEntityLabelNames},
+		MessagesPublishedSizeBytes:     &models.MetricMeta{Labels: psEntityLabelNames},
+		MessagesPublishedWireBytes:     &models.MetricMeta{Labels: psEntityLabelNames},
+		PublishConfirmLatencyMillis:    &models.MetricMeta{Labels: psEntityLabelNames},
+		MessagesConsumedLatencyMillis:  &models.MetricMeta{Labels: psEntityLabelNames},
+		MessageQueueMillis:             &models.MetricMeta{Labels: psEntityLabelNames},
+		MessageProcessMillis:           &models.MetricMeta{Labels: psEntityLabelNames},
+		PublishSuccessRatio:            &models.MetricMeta{Labels: psEntityLabelNames},
+		PublishConsumeRatio:            &models.MetricMeta{Labels: []string{constants.LabelEntity}},
+	}).(*PromPSMetrics)
+	labelValues := &models.PSMetricsLabelValues{Source: "orders-sub", Entity: "order", EntityOpType: "created"}
+
+	hammer(t, registry, func(_, iteration int) {
+		published := &pubsub.EventTxnData{IsPublished: iteration%4 != 0, MessageSizeInBytes: 128, TimeTakenToPublish: time.Millisecond}
+		switch iteration % 4 {
+		case 0:
+			psm.LogMetricsPre(labelValues)
+			psm.LogMetricsPostWithWireSize(labelValues, published, 64)
+		case 1:
+			start := psm.LogMetricsPre(labelValues)
+			psm.LogMetricsPostOutcome(labelValues, constants.ConsumeOutcomeSkipped, start)
+		case 2:
+			now := time.Now()
+			timestamps := psm.LogMetricsPreWithTimestamps(labelValues, now.Add(-time.Second), now)
+			psm.LogMetricsPostWithTimestamps(labelValues, timestamps)
+		default:
+			psm.LogMetricsBatch([]models.PSBatchEntry{
+				{LabelValues: labelValues, EventTxnData: published, ConfirmLatency: time.Millisecond},
+				{LabelValues: labelValues},
+			})
+		}
+	})
+
+	// Every fourth iteration records a batch of two operations
+	operations := float64(concurrentGoroutines * concurrentIterations / 4 * 5)
+	if got := testutil.ToFloat64(psm.totalMessagesPublished.WithLabelValues("order", "created", constants.Total)); got != operations {
+		t.Errorf("total operations = %v, want %v", got, operations)
+	}
+}
+
+func TestAppMetricsConcurrentUse(t *testing.T) {
+	registry := useTestRegistry(t)
+	cm := NewPromAppMetrics(&models.AppMetricsMeta{
+		ApplicationErrorsCounter: &models.MetricMeta{Labels: []string{constants.LabelErrorCode}},
+		DistinctErrorCodes:       &models.MetricMeta{},
+		ErrorRatePerMin:          &models.MetricMeta{Labels: []string{constants.LabelErrorCode}},
+	}).(*PromAppMetrics)
+
+	hammer(t, registry, func(goroutine, _ int) {
+		code := "ERR_" + strconv.Itoa(goroutine%4)
+		cm.LogMetrics([]string{code, code})
+		cm.LogMetricsUnique([]string{code, code})
+		cm.DecrementAppErrorCount(code)
+		cm.DecrementAppErrorCount(code)
+	})
+
+	for code := 0; code < 4; code++ {
+		if got := testutil.ToFloat64(cm.applicationErrorsCounter.WithLabelValues("ERR_" + strconv.Itoa(code))); got != float64(concurrentGoroutines/4*concurrentIterations) {
+			t.Errorf("errors of ERR_%d = %v, want %v", code, got, concurrentGoroutines/4*concurrentIterations)
+		}
+	}
+	if got := testutil.ToFloat64(cm.distinctErrorCodes.WithLabelValues()); got != 4 {
+		t.Errorf("distinct error codes = %v, want 4", got)
+	}
+}
@@ -0,0 +1,257 @@
+package prometheus
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/piyushkumar96/app-monitoring/interfaces"
+	"github.com/piyushkumar96/app-monitoring/models"
+
+	"gopkg.in/yaml.v3"
+)
+
+// BundleConfig describes the metrics of a Bundle in a YAML or JSON document, so labels and buckets
+// can be tuned (e.g. through a config map) without recompiling.
+//
+// Each section configures one metrics type and is keyed by the metric field names of its meta
+// (e.g. "HTTPRequestsLatencyMillis" of models.RouterMetricsMeta). A metric is enabled by listing it,
+// with its labels, buckets and quantiles. Sections that are left out are not created, and options
+// other than metrics (e.g. RouterMetricsMeta.TrackContentType) still have to be set in code.
+//
+// Example:
+//
+//	namespace: myapp
+//	router:
+//	  HTTPRequests:
+//	    labels: [method, code, path, status]
+//	  HTTPRequestsLatencyMillis:
+//	    labels: [method, code, path]
+//	    buckets: [10, 20, 40, 80, 160, 320, 640, 1280]
+//	db:
+//	  OperationsTotal:
+//	    labels: [op_type, source, entity, is_txn, status]
+type BundleConfig struct {
+	// Namespace is the metric namespace prefix shared by every section.
+	Namespace string `yaml:"namespace"`
+
+	// Router configures the metrics of models.RouterMetricsMeta.
+	Router map[string]*models.MetricMeta `yaml:"router"`
+
+	// DB configures the metrics of models.DBMetricsMeta.
+	DB map[string]*models.MetricMeta `yaml:"db"`
+
+	// Downstream configures the metrics of models.DownstreamServiceMetricsMeta.
+	Downstream map[string]*models.MetricMeta `yaml:"downstream"`
+
+	// CronJob configures the metrics of models.CronJobMetricsMeta.
+	CronJob map[string]*models.MetricMeta `yaml:"cron_job"`
+
+	// PubSub configures the metrics of models.PSMetricsMeta.
+	PubSub map[string]*models.MetricMeta `yaml:"pubsub"`
+
+	// App configures the metrics of models.AppMetricsMeta.
+	App map[string]*models.MetricMeta `yaml:"app"`
+
+	// Readiness configures the metrics of models.ReadinessMetricsMeta.
+	Readiness map[string]*models.MetricMeta `yaml:"readiness"`
+}
+
+// labelNamePattern matches valid Prometheus label names.
+var labelNamePattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// LoadConfig parses a YAML or JSON document (JSON being a subset of YAML) into a BundleConfig.
+// Unknown fields, an invalid namespace, unknown metric names, invalid or duplicate label names, buckets that are not
+// strictly increasing and quantiles outside [0, 1] are rejected with an error naming the offending field.
+//
+// Example:
+//
+//	f, err := os.Open("/etc/myapp/metrics.yaml")
+//	config, err := prometheus.LoadConfig(f)
+//	bundle, err := config.NewBundle()
+func LoadConfig(r io.Reader) (*BundleConfig, error) {
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+
+	var config BundleConfig
+	if err := decoder.Decode(&config); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("metrics config: empty document")
+		}
+		return nil, fmt.Errorf("metrics config: %w", err)
+	}
+	if _, err := config.metas(); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// NewBundle creates the Prometheus metrics of every configured section and groups them in a Bundle.
+// Members of sections that are not configured are left nil. Run Bundle.SelfTest afterwards to
+// catch label mismatches the config can't express, such as a counter missing its status label.
+func (c *BundleConfig) NewBundle() (*interfaces.Bundle, error) {
+	metas, err := c.metas()
+	if err != nil {
+		return nil, err
+	}
+
+	bundle := &interfaces.Bundle{}
+	if metas.router != nil {
+		bundle.Router = NewPromRouterMetrics(metas.router)
+	}
+	if metas.db != nil {
+		bundle.DB = NewPromDatabaseMetrics(metas.db)
+	}
+	if metas.downstream != nil {
+		bundle.Downstream = NewPromDownstreamServiceMetrics(metas.downstream)
+	}
+	if metas.cronJob != nil {
+		bundle.CronJob = NewPromCronJobMetrics(metas.cronJob)
+	}
+	if metas.pubSub != nil {
+		bundle.PubSub = NewPromPubSubMetrics(metas.pubSub)
+	}
+	if metas.app != nil {
+		bundle.App = NewPromAppMetrics(metas.app)
+	}
+	if metas.readiness != nil {
+		bundle.Readiness = NewPromReadinessMetrics(metas.readiness)
+	}
+	return bundle, nil
+}
+
+// bundleMetas holds the metas built from a BundleConfig; sections that are not configured are nil.
+type bundleMetas struct {
+	router     *models.RouterMetricsMeta
+	db         *models.DBMetricsMeta
+	downstream *models.DownstreamServiceMetricsMeta
+	cronJob    *models.CronJobMetricsMeta
+	pubSub     *models.PSMetricsMeta
+	app        *models.AppMetricsMeta
+	readiness  *models.ReadinessMetricsMeta
+}
+
+// metas validates every configured section and builds its meta.
+func (c *BundleConfig) metas() (*bundleMetas, error) {
+	var metas bundleMetas
+	var errs []error
+	collect := func(err error) {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if !validNamespace(c.Namespace) && !SanitizeMetricNames {
+		collect(fmt.Errorf("metrics config: namespace %q must match [a-zA-Z_:][a-zA-Z0-9_:]* (try %q, or set SanitizeMetricNames)", c.Namespace, sanitizeLeadingDigit(sanitizeMetricName(c.Namespace))))
+	}
+
+	var err error
+	metas.router, err = buildMeta[models.RouterMetricsMeta](c.Namespace, "router", c.Router)
+	collect(err)
+	metas.db, err = buildMeta[models.DBMetricsMeta](c.Namespace, "db", c.DB)
+	collect(err)
+	metas.downstream, err = buildMeta[models.DownstreamServiceMetricsMeta](c.Namespace, "downstream", c.Downstream)
+	collect(err)
+	metas.cronJob, err = buildMeta[models.CronJobMetricsMeta](c.Namespace, "cron_job", c.CronJob)
+	collect(err)
+	metas.pubSub, err = buildMeta[models.PSMetricsMeta](c.Namespace, "pubsub", c.PubSub)
+	collect(err)
+	metas.app, err = buildMeta[models.AppMetricsMeta](c.Namespace, "app", c.App)
+	collect(err)
+	metas.readiness, err = buildMeta[models.ReadinessMetricsMeta](c.Namespace, "readiness", c.Readiness)
+	collect(err)
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return &metas, nil
+}
+
+// buildMeta builds the meta T of a config section by setting its namespace and the *models.MetricMeta
+// field of each configured metric. It returns nil when the section is not configured.
+func buildMeta[T any](namespace, section string, metrics map[string]*models.MetricMeta) (*T, error) {
+	if metrics == nil {
+		return nil, nil
+	}
+	meta := new(T)
+	value := reflect.ValueOf(meta).Elem()
+	value.FieldByName("Namespace").SetString(namespace)
+
+	names := make([]string, 0, len(metrics))
+	for name := range metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		field := value.FieldByName(name)
+		if !field.IsValid() || field.Type() != reflect.TypeOf((*models.MetricMeta)(nil)) {
+			errs = append(errs, fmt.Errorf("metrics config: %s.%s: unknown metric (valid: %s)", section, name, strings.Join(metricFieldNames(value.Type()), ", ")))
+			continue
+		}
+		metricMeta := metrics[name]
+		if metricMeta == nil {
+			metricMeta = &models.MetricMeta{}
+		}
+		if err := validateMetricMeta(metricMeta); err != nil {
+			errs = append(errs, fmt.Errorf("metrics config: %s.%s: %w", section, name, err))
+			continue
+		}
+		field.Set(reflect.ValueOf(metricMeta))
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return meta, nil
+}
+
+// metricFieldNames returns the names of the *models.MetricMeta fields of a meta struct type.
+func metricFieldNames(metaType reflect.Type) []string {
+	var names []string
+	for i := 0; i < metaType.NumField(); i++ {
+		if metaType.Field(i).Type == reflect.TypeOf((*models.MetricMeta)(nil)) {
+			names = append(names, metaType.Field(i).Name)
+		}
+	}
+	return names
+}
+
+// validateMetricMeta checks the label names, buckets and quantiles of a configured metric.
+func validateMetricMeta(meta *models.MetricMeta) error {
+	seen := make(map[string]struct{}, len(meta.Labels))
+	for _, label := range meta.Labels {
+		if !labelNamePattern.MatchString(label) || strings.HasPrefix(label, "__") {
+			return fmt.Errorf("invalid label name %q", label)
+		}
+		if _, ok := seen[label]; ok {
+			return fmt.Errorf("duplicate label name %q", label)
+		}
+		seen[label] = struct{}{}
+	}
+	for i, bucket := range meta.Buckets {
+		if math.IsNaN(bucket) {
+			return errors.New("buckets must not be NaN")
+		}
+		if i > 0 && bucket <= meta.Buckets[i-1] {
+			return fmt.Errorf("buckets must be strictly increasing, got %v after %v", bucket, meta.Buckets[i-1])
+		}
+	}
+	for _, quantile := range meta.Quantiles {
+		if !(quantile >= 0 && quantile <= 1) {
+			return fmt.Errorf("quantile %v is outside [0, 1]", quantile)
+		}
+	}
+	if meta.QuantilesMaxAge < 0 {
+		return fmt.Errorf("quantiles max age %v is negative", meta.QuantilesMaxAge)
+	}
+	if meta.QuantilesAgeBuckets < 0 {
+		return fmt.Errorf("quantiles age buckets %d is negative", meta.QuantilesAgeBuckets)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package prometheus
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/piyushkumar96/app-monitoring/models"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// MetricDashboardInfo describes one registered metric together with its dashboard hints, as returned by DashboardSpec.
+type MetricDashboardInfo struct {
+	MetricInfo
+	// Hint is the DashboardHint of the metric's models.MetricMeta; it is zero when none was configured.
+	Hint models.DashboardHint
+}
+
+var (
+	dashboardHintsMu sync.RWMutex
+	// dashboardHints holds the configured dashboard hints, keyed by fully-qualified metric name.
+	dashboardHints = make(map[string]models.DashboardHint)
+)
+
+// DashboardSpec returns the metrics registered by this package so far, as listed by RegisteredInventory,
+// each with the DashboardHint configured on its models.MetricMeta, so that a dashboard generator can chart
+// them without hand-maintained panels. The quantile gauges of a t-digest vec carry the hint of the vec.
+//
+// The hints are metadata only: they are neither registered with Prometheus nor exposed on scrapes.
+func DashboardSpec() []MetricDashboardInfo {
+	infos := RegisteredInventory()
+	spec := make([]MetricDashboardInfo, 0, len(infos))
+
+	dashboardHintsMu.RLock()
+	defer dashboardHintsMu.RUnlock()
+	for _, info := range infos {
+		spec = append(spec, MetricDashboardInfo{MetricInfo: info, Hint: dashboardHintOf(info)})
+	}
+	return spec
+}
+
+// dashboardHintOf returns the hint recorded for info, falling back to the hint of the t-digest vec
+// for a quantile gauge (e.g. "myapp_http_request_latency_millis_p99"). dashboardHintsMu must be held.
+func dashboardHintOf(info MetricInfo) models.DashboardHint {
+	if hint, ok := dashboardHints[info.Name]; ok || info.Type != MetricTypeGauge {
+		return hint
+	}
+	i := strings.LastIndex(info.Name, "_p")
+	if i <= 0 || strings.Trim(info.Name[i+2:], "0123456789") != "" {
+		return models.DashboardHint{}
+	}
+	return dashboardHints[info.Name[:i]]
+}
+
+// recordDashboardHints records the dashboard hints of the metrics configured in meta, whose metric names
+// are mapped to their *models.MetricMeta field by metricFields. A metric configured without a hint
+// drops the hint recorded by an earlier constructor call.
+func recordDashboardHints[T any](namespace string, meta *T, metricFields map[string]string) {
+	value := reflect.ValueOf(meta).Elem()
+	namespace = withRole(namespace)
+
+	dashboardHintsMu.Lock()
+	defer dashboardHintsMu.Unlock()
+	for name, field := range metricFields {
+		metricMeta, ok := value.FieldByName(field).Interface().(*models.MetricMeta)
+		if !ok || metricMeta == nil {
+			continue
+		}
+		key := prometheus.BuildFQName(namespace, "", name)
+		if metricMeta.DashboardHint == (models.DashboardHint{}) {
+			delete(dashboardHints, key)
+			continue
+		}
+		dashboardHints[key] = metricMeta.DashboardHint
+	}
+}
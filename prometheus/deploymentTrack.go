@@ -0,0 +1,60 @@
+package prometheus
+
+import (
+	"maps"
+	"sync"
+
+	"github.com/piyushkumar96/app-monitoring/constants"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	deploymentTrackMu sync.RWMutex
+	deploymentTrack   string
+)
+
+// SetDeploymentTrack sets the deployment track (e.g. constants.DeploymentTrackCanary, DeploymentTrackStable or
+// DeploymentTrackBaseline) that metrics created afterwards carry as a "track" const label, so that canary and
+// stable instances of the same service can be compared with a single `by (track)` during progressive delivery.
+//
+// It is a shorthand for registering through prometheus.WrapRegistererWith with a "track" label, and composes
+// with a registerer set by SetRegisterer that adds other const labels; that registerer must not add "track"
+// itself. An empty track (the default) adds no label. Like SetRole, it is read at construction time, so call
+// it before creating any metrics; instances already created keep their labels.
+func SetDeploymentTrack(track string) {
+	deploymentTrackMu.Lock()
+	defer deploymentTrackMu.Unlock()
+	deploymentTrack = track
+}
+
+// getDeploymentTrack returns the deployment track metrics are currently labeled with.
+func getDeploymentTrack() string {
+	deploymentTrackMu.RLock()
+	defer deploymentTrackMu.RUnlock()
+	return deploymentTrack
+}
+
+// withDeploymentTrack returns r wrapped to add the current deployment track as a const label,
+// or r itself when no track is set.
+func withDeploymentTrack(r prometheus.Registerer) prometheus.Registerer {
+	track := getDeploymentTrack()
+	if track == "" {
+		return r
+	}
+	return prometheus.WrapRegistererWith(prometheus.Labels{constants.LabelTrack: track}, r)
+}
+
+// deploymentTrackLabels returns constLabels with the current deployment track added, leaving constLabels unchanged.
+func deploymentTrackLabels(constLabels prometheus.Labels) prometheus.Labels {
+	track := getDeploymentTrack()
+	if track == "" {
+		return constLabels
+	}
+	labels := maps.Clone(constLabels)
+	if labels == nil {
+		labels = prometheus.Labels{}
+	}
+	labels[constants.LabelTrack] = track
+	return labels
+}
@@ -0,0 +1,27 @@
+package prometheus
+
+import (
+	"os"
+	"strconv"
+)
+
+// DisabledEnvVar is the environment variable that disables all Prometheus metrics when set to a true
+// value accepted by strconv.ParseBool (e.g. "1", "true").
+const DisabledEnvVar = "APP_MONITORING_DISABLED"
+
+// Disabled makes every NewProm* constructor return the corresponding NoOp implementation, so that metrics
+// can be turned off globally (e.g. in CLI mode) without changing call sites.
+//
+// Metrics are disabled when either Disabled is true or DisabledEnvVar is set to a true value; neither
+// can re-enable what the other disabled. Both are read once per constructor call, so set Disabled
+// before creating any metrics. Instances already created are not affected by later changes.
+var Disabled bool
+
+// metricsDisabled reports whether constructors should return NoOp implementations.
+func metricsDisabled() bool {
+	if Disabled {
+		return true
+	}
+	disabled, err := strconv.ParseBool(os.Getenv(DisabledEnvVar))
+	return err == nil && disabled
+}
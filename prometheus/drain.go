@@ -0,0 +1,33 @@
+package prometheus
+
+import (
+	"strconv"
+	"sync/atomic"
+)
+
+// draining is the package-level drain flag read by router metrics with TrackDraining enabled.
+var draining atomic.Bool
+
+// SetDraining marks whether the process is draining connections, e.g. during a rolling deploy, so that
+// router metrics with RouterMetricsMeta.TrackDraining enabled record the requests served meanwhile with
+// draining="true" and SLO queries can exclude them. It is safe to call concurrently with requests being
+// served; each request reads the flag once, when it starts.
+//
+// Example:
+//
+//	<-ctx.Done()
+//	prometheus.SetDraining(true)
+//	_ = server.Shutdown(shutdownCtx)
+func SetDraining(isDraining bool) {
+	draining.Store(isDraining)
+}
+
+// Draining reports whether the process is marked as draining with SetDraining.
+func Draining() bool {
+	return draining.Load()
+}
+
+// drainingLabelValue returns the draining label value for a request starting now.
+func drainingLabelValue() string {
+	return strconv.FormatBool(draining.Load())
+}
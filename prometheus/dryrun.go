@@ -0,0 +1,129 @@
+package prometheus
+
+import (
+	"errors"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DryRun makes every metric log its observations at debug level through generic-logger (or the logger set
+// with SetLogger) instead of recording them, with the metric name, labels, operation and value. It is meant
+// for validating instrumentation (e.g. label values) while onboarding a service, before it reaches Prometheus.
+//
+// The metrics are still created and registered, but no series are recorded into them. Invalid label
+// values are reported to the observation error handler as usual. When DryRun is false, the only cost
+// left compiled in is a check of the flag per observation.
+//
+// It is read without synchronization on every observation, so set it once during startup, before metrics
+// are observed; toggling it while observations are made is a data race.
+var DryRun bool
+
+// dryRunCounter is a counter that logs what would be recorded instead of recording it.
+type dryRunCounter struct {
+	prometheus.Counter
+	observation dryRunObservation
+}
+
+// Inc logs an increment by 1.
+func (c dryRunCounter) Inc() { c.observation.log("inc", 1) }
+
+// Add logs an increment by v.
+func (c dryRunCounter) Add(v float64) { c.observation.log("add", v) }
+
+// dryRunGauge is a gauge that logs what would be recorded instead of recording it.
+type dryRunGauge struct {
+	prometheus.Gauge
+	observation dryRunObservation
+}
+
+// Set logs setting the gauge to v.
+func (g dryRunGauge) Set(v float64) { g.observation.log("set", v) }
+
+// Inc logs an increment by 1.
+func (g dryRunGauge) Inc() { g.observation.log("inc", 1) }
+
+// Dec logs a decrement by 1.
+func (g dryRunGauge) Dec() { g.observation.log("dec", 1) }
+
+// Add logs an increment by v.
+func (g dryRunGauge) Add(v float64) { g.observation.log("add", v) }
+
+// Sub logs a decrement by v.
+func (g dryRunGauge) Sub(v float64) { g.observation.log("sub", v) }
+
+// SetToCurrentTime logs setting the gauge to the current Unix time.
+func (g dryRunGauge) SetToCurrentTime() { g.observation.log("set_to_current_time", 0) }
+
+// dryRunObserver is an observer that logs what would be recorded instead of recording it.
+type dryRunObserver struct {
+	observation dryRunObservation
+}
+
+// Observe logs observing v.
+func (o dryRunObserver) Observe(v float64) { o.observation.log("observe", v) }
+
+// dryRunObservation identifies the series of a dry run observation.
+type dryRunObservation struct {
+	metric      string
+	labelNames  []string
+	labelValues []string
+}
+
+// log logs the operation on the series at debug level. The labels are only resolved into a map when
+// debug logs are enabled.
+func (o dryRunObservation) log(op string, value float64) {
+	if !debugEnabled() {
+		return
+	}
+	labels := make(map[string]string, len(o.labelNames))
+	for i, name := range o.labelNames {
+		labels[name] = o.labelValues[i]
+	}
+	logger().Debug("dry run metric observation", "code", "OnDryRunMetricObservation", "metric", o.metric, "labels", labels, "op", op, "value", value)
+}
+
+// newDryRunObservation returns the dry run observation of the series of vec for the label values.
+// ok is false when the label values don't match the metric's labels; the error is then reported
+// to the observation error handler.
+func newDryRunObservation(vec any, labelValues []string) (observation dryRunObservation, ok bool) {
+	value, found := metricInfos.Load(vec)
+	if !found {
+		return dryRunObservation{metric: "unknown"}, true
+	}
+	info := value.(metricInfo)
+	if len(labelValues) != len(info.labelNames) {
+		handleObservationError(vec, labelValues, errors.New("inconsistent label cardinality"))
+		return dryRunObservation{}, false
+	}
+	return dryRunObservation{metric: info.name, labelNames: info.labelNames, labelValues: labelValues}, true
+}
+
+// dryRunCounterWith returns a dryRunCounter for the series of vec, or a discarding counter when the
+// label values are invalid.
+func dryRunCounterWith(vec *prometheus.CounterVec, labelValues []string) prometheus.Counter {
+	observation, ok := newDryRunObservation(vec, labelValues)
+	if !ok {
+		return discardCounter
+	}
+	return dryRunCounter{Counter: discardCounter, observation: observation}
+}
+
+// dryRunGaugeWith returns a dryRunGauge for the series of vec, or a discarding gauge when the
+// label values are invalid.
+func dryRunGaugeWith(vec *prometheus.GaugeVec, labelValues []string) prometheus.Gauge {
+	observation, ok := newDryRunObservation(vec, labelValues)
+	if !ok {
+		return discardGauge
+	}
+	return dryRunGauge{Gauge: discardGauge, observation: observation}
+}
+
+// dryRunObserverWith returns a dryRunObserver for the series of target, or a discarding observer when
+// the label values are invalid.
+func dryRunObserverWith(target labelObserver, labelValues []string) prometheus.Observer {
+	observation, ok := newDryRunObservation(target, labelValues)
+	if !ok {
+		return discardObserver
+	}
+	return dryRunObserver{observation: observation}
+}
@@ -0,0 +1,51 @@
+package prometheus
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// useDryRun enables DryRun and routes the diagnostics to a slog logger writing at level into the returned
+// buffer, restoring both when the test ends.
+func useDryRun(t *testing.T, level slog.Level) *bytes.Buffer {
+	t.Helper()
+	DryRun = true
+	t.Cleanup(func() { DryRun = false })
+	return useTestLogger(t, level)
+}
+
+func TestDryRunLogsObservationsInsteadOfRecording(t *testing.T) {
+	useTestRegistry(t)
+	logs := useDryRun(t, slog.LevelDebug)
+	vec := GetPromCounterVec("dryrun", "orders_total", "Counts orders", []string{"region"})
+
+	counterWith(vec, "eu").Inc()
+
+	if got := testutil.CollectAndCount(vec); got != 0 {
+		t.Errorf("recorded series = %d, want 0", got)
+	}
+	for _, want := range []string{`"code":"OnDryRunMetricObservation"`, `"metric":"dryrun_orders_total"`, `"labels":{"region":"eu"}`, `"op":"inc"`} {
+		if !strings.Contains(logs.String(), want) {
+			t.Errorf("logs %s don't contain %s", logs.String(), want)
+		}
+	}
+}
+
+func TestDryRunSkipsObservationsWhenDebugIsDisabled(t *testing.T) {
+	useTestRegistry(t)
+	logs := useDryRun(t, slog.LevelInfo)
+	vec := GetPromCounterVec("dryrun", "orders_total", "Counts orders", []string{"region"})
+
+	counterWith(vec, "eu").Inc()
+
+	if logs.Len() != 0 {
+		t.Errorf("logged %s, want nothing at info level", logs.String())
+	}
+	if got := testutil.CollectAndCount(vec); got != 0 {
+		t.Errorf("recorded series = %d, want 0", got)
+	}
+}
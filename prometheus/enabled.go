@@ -0,0 +1,136 @@
+package prometheus
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// The metric names (without namespace) of each meta, mapped to the *models.MetricMeta field configuring them.
+// They are the names EnabledMetrics accepts.
+var (
+	routerMetricFields = map[string]string{
+		"http_requests":                            "HTTPRequests",
+		"http_request_latency_millis":              "HTTPRequestsLatencyMillis",
+		"http_ttfb_millis":                         "HTTPTTFBMillis",
+		"http_request_size_bytes":                  "HTTPRequestSizeBytes",
+		"http_response_size_bytes":                 "HTTPResponseSizeBytes",
+		"http_response_uncompressed_size_bytes":    "HTTPResponseUncompressedSizeBytes",
+		"http_response_compression_ratio":          "HTTPResponseCompressionRatio",
+		"http_request_bytes_total":                 "HTTPRequestBytesTotal",
+		"http_response_bytes_total":                "HTTPResponseBytesTotal",
+		"http_requests_rejected_concurrency_total": "HTTPRequestsRejectedConcurrency",
+		"http_requests_aborted_total":              "HTTPRequestsAborted",
+		"http_metrics_middleware_overhead_micros":  "HTTPMetricsMiddlewareOverheadMicros",
+		"slo_error_budget_burn_rate":               "ErrorBudgetBurnRate",
+	}
+	appMetricFields = map[string]string{
+		"application_errors_total": "ApplicationErrorsCounter",
+		"app_distinct_error_codes": "DistinctErrorCodes",
+		"app_error_rate_per_min":   "ErrorRatePerMin",
+	}
+	downstreamMetricFields = map[string]string{
+		"downstream_service_http_requests":                  "HTTPRequests",
+		"downstream_service_http_request_latency_millis":    "HTTPRequestsLatencyMillis",
+		"downstream_service_http_request_size_bytes":        "HTTPRequestSizeBytes",
+		"downstream_service_http_response_size_bytes":       "HTTPResponseSizeBytes",
+		"downstream_service_last_call_timestamp_seconds":    "LastCallTimestampSeconds",
+		"downstream_service_last_success_timestamp_seconds": "LastSuccessTimestampSeconds",
+		"downstream_service_http_response_parse_millis":     "ResponseParseMillis",
+		"downstream_service_upstream_latency_millis":        "UpstreamLatencyMillis",
+		"downstream_service_in_flight_requests":             "InFlight",
+		"downstream_service_batch_items_total":              "BatchItemsTotal",
+		"downstream_service_retry_outcome_total":            "RetryOutcomeTotal",
+		"downstream_service_retry_attempts":                 "RetryAttempts",
+		"downstream_service_pages_fetched":                  "PagesFetched",
+		"downstream_service_dns_millis":                     "DNSMillis",
+		"downstream_service_connect_millis":                 "ConnectMillis",
+		"downstream_service_tls_millis":                     "TLSMillis",
+	}
+	dbMetricFields = map[string]string{
+		"db_operations":                "OperationsTotal",
+		"db_operations_latency_millis": "OperationsLatencyMillis",
+		"db_conn_wait_millis":          "ConnWaitMillis",
+		"db_rows_affected":             "RowsAffected",
+	}
+	txnMetricFields = map[string]string{
+		"db_transactions_total":          "TransactionsTotal",
+		"db_transaction_duration_millis": "TransactionDurationMillis",
+	}
+	psMetricFields = map[string]string{
+		"pubsub_messages_consumed":                 "TotalMessagesConsumed",
+		"pubsub_messages_published":                "TotalMessagesPublished",
+		"pubsub_messages_published_latency_millis": "MessagesPublishedLatencyMillis",
+		"pubsub_messages_published_size_bytes":     "MessagesPublishedSizeBytes",
+		"pubsub_messages_published_wire_bytes":     "MessagesPublishedWireBytes",
+		"pubsub_publish_confirm_latency_millis":    "PublishConfirmLatencyMillis",
+		"pubsub_messages_consumed_latency_millis":  "MessagesConsumedLatencyMillis",
+		"pubsub_message_queue_millis":              "MessageQueueMillis",
+		"pubsub_message_process_millis":            "MessageProcessMillis",
+		"pubsub_publish_success_ratio":             "PublishSuccessRatio",
+		"pubsub_publisher_queue_depth":             "PublisherQueueDepth",
+		"pubsub_subscription_state":                "SubscriptionState",
+		"pubsub_publish_consume_ratio":             "PublishConsumeRatio",
+	}
+	cronJobMetricFields = map[string]string{
+		"cron_job_execution_count":          "JobExecutionTotal",
+		"cron_job_execution_latency_millis": "JobExecutionLatencyMillis",
+		"cron_job_schedule_drift_millis":    "JobScheduleDriftMillis",
+		"cron_job_consecutive_failures":     "JobConsecutiveFailures",
+		"cron_job_last_run_success":         "JobLastRunSuccess",
+		"cron_job_over_budget_total":        "JobOverBudget",
+		"cron_jobs_active_total":            "JobsActive",
+	}
+	readinessMetricFields = map[string]string{
+		"app_ready": "AppReady",
+	}
+	operationMetricFields = map[string]string{
+		"operation_duration_millis":            "OperationDurationMillis",
+		"operation_dependency_duration_millis": "DependencyDurationMillis",
+	}
+	workerPoolMetricFields = map[string]string{
+		"worker_queue_wait_millis": "QueueWaitMillis",
+		"worker_exec_millis":       "ExecMillis",
+		"worker_tasks_total":       "TasksTotal",
+		"worker_queue_depth":       "QueueDepth",
+	}
+)
+
+// withEnabledMetrics applies the EnabledMetrics allowlist of a meta: it returns a copy of meta whose
+// *models.MetricMeta fields are set to nil for every metric missing from enabled. Fields that are
+// already nil stay nil, so the allowlist can only narrow what the fields enable. An empty allowlist
+// returns meta unchanged. Unknown metric names are logged and ignored.
+func withEnabledMetrics[T any](meta *T, enabled []string, metricFields map[string]string) *T {
+	if len(enabled) == 0 {
+		return meta
+	}
+
+	keep := make(map[string]struct{}, len(enabled))
+	for _, name := range enabled {
+		field, ok := metricFields[name]
+		if !ok {
+			logger().Error("unknown metric in enabled metrics", "code", "OnUnknownEnabledMetric", "metric", name, "valid", strings.Join(knownMetricNames(metricFields), ", "))
+			continue
+		}
+		keep[field] = struct{}{}
+	}
+
+	filtered := *meta
+	value := reflect.ValueOf(&filtered).Elem()
+	for _, field := range metricFieldNames(value.Type()) {
+		if _, ok := keep[field]; !ok {
+			value.FieldByName(field).SetZero()
+		}
+	}
+	return &filtered
+}
+
+// knownMetricNames returns the sorted metric names of metricFields.
+func knownMetricNames(metricFields map[string]string) []string {
+	names := make([]string, 0, len(metricFields))
+	for name := range metricFields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
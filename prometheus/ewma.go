@@ -0,0 +1,131 @@
+package prometheus
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ewmaRatePruneThreshold is the per-minute rate below which a decayed series is dropped at scrape time,
+// so label values that stopped occurring don't accumulate forever.
+const ewmaRatePruneThreshold = 0.001
+
+// ewmaRate is the exponentially weighted moving average of the event rate of one label combination.
+type ewmaRate struct {
+	labelValues []string
+	perMin      float64
+	updated     time.Time
+}
+
+// ewmaRateVec is a Prometheus collector exposing, per label combination, an exponentially weighted
+// moving average of the events recorded per minute. The average decays continuously with the
+// configured window as its time constant, and is evaluated at scrape time, so a series that stops
+// receiving events falls back towards zero instead of keeping its last value.
+type ewmaRateVec struct {
+	desc       *prometheus.Desc
+	labelNames []string
+	window     time.Duration
+
+	// rates holds the average of each label combination, keyed by the joined label values and guarded by mu.
+	mu    sync.Mutex
+	rates map[string]*ewmaRate
+}
+
+// newEWMARateVec creates and registers a new ewmaRateVec averaging over the given window.
+// If the same metric is already registered, the registered vec is returned. If registration fails otherwise
+// (e.g., same name with different labels), an error is logged but the vec is still returned.
+func newEWMARateVec(namespace, name, help string, labelNames []string, window time.Duration) *ewmaRateVec {
+	namespace, name = validateMetricName(namespace, name)
+	vec := &ewmaRateVec{
+		desc:       prometheus.NewDesc(prometheus.BuildFQName(namespace, "", name), help, labelNames, nil),
+		labelNames: labelNames,
+		window:     window,
+		rates:      make(map[string]*ewmaRate),
+	}
+	vec, err := registerCollector(vec)
+	if err != nil {
+		logger().Error("failed to register ewma rate vec metric", "code", "OnEWMARateVecMetricRegisterFailure", "err", err.Error())
+	} else {
+		recordInventory(namespace, name, MetricTypeGauge, help, labelNames, nil, nil)
+	}
+	return vec
+}
+
+// inc records one event at now for the given label values.
+// Like the Prometheus vecs, it panics when the number of label values doesn't match the label names.
+func (v *ewmaRateVec) inc(now time.Time, labelValues ...string) {
+	if len(labelValues) != len(v.labelNames) {
+		panic(fmt.Errorf("inconsistent label cardinality: expected %d label values but got %d in %#v", len(v.labelNames), len(labelValues), labelValues))
+	}
+	key := strings.Join(labelValues, labelKeySeparator)
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	rate, ok := v.rates[key]
+	if !ok {
+		rate = &ewmaRate{labelValues: append([]string(nil), labelValues...), updated: now}
+		v.rates[key] = rate
+	}
+	rate.perMin = v.decayed(rate, now) + 1/v.window.Minutes()
+	rate.updated = now
+}
+
+// decayed returns the average of rate decayed from its last update to now.
+func (v *ewmaRateVec) decayed(rate *ewmaRate, now time.Time) float64 {
+	elapsed := now.Sub(rate.updated)
+	if elapsed <= 0 {
+		return rate.perMin
+	}
+	return rate.perMin * math.Exp(-float64(elapsed)/float64(v.window))
+}
+
+// Delete removes the series with the given labels and reports whether it existed.
+func (v *ewmaRateVec) Delete(labels prometheus.Labels) bool {
+	labelValues := make([]string, len(v.labelNames))
+	for i, name := range v.labelNames {
+		value, ok := labels[name]
+		if !ok {
+			return false
+		}
+		labelValues[i] = value
+	}
+	key := strings.Join(labelValues, labelKeySeparator)
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if _, ok := v.rates[key]; !ok {
+		return false
+	}
+	delete(v.rates, key)
+	return true
+}
+
+// Describe implements prometheus.Collector.
+func (v *ewmaRateVec) Describe(ch chan<- *prometheus.Desc) {
+	ch <- v.desc
+}
+
+// Collect implements prometheus.Collector, exposing each average decayed to the scrape time.
+// Series that decayed below ewmaRatePruneThreshold are dropped.
+func (v *ewmaRateVec) Collect(ch chan<- prometheus.Metric) {
+	now := time.Now()
+	v.mu.Lock()
+	metrics := make([]prometheus.Metric, 0, len(v.rates))
+	for key, rate := range v.rates {
+		perMin := v.decayed(rate, now)
+		if perMin < ewmaRatePruneThreshold {
+			delete(v.rates, key)
+			continue
+		}
+		metrics = append(metrics, prometheus.MustNewConstMetric(v.desc, prometheus.GaugeValue, perMin, rate.labelValues...))
+	}
+	v.mu.Unlock()
+
+	for _, metric := range metrics {
+		ch <- metric
+	}
+}
@@ -0,0 +1,42 @@
+package prometheus
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/piyushkumar96/app-monitoring/constants"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// requestIDExemplar returns the exemplar labels carrying the request ID held by value, or nil when
+// value is not a non-empty string or fmt.Stringer, or is not valid UTF-8. IDs are truncated so the
+// exemplar stays within prometheus.ExemplarMaxRunes, which would otherwise make the observation panic.
+func requestIDExemplar(value any) prometheus.Labels {
+	var requestID string
+	switch v := value.(type) {
+	case string:
+		requestID = v
+	case fmt.Stringer:
+		requestID = v.String()
+	}
+	if requestID == "" || !utf8.ValidString(requestID) {
+		return nil
+	}
+	if maxRunes := prometheus.ExemplarMaxRunes - utf8.RuneCountInString(constants.LabelRequestID); utf8.RuneCountInString(requestID) > maxRunes {
+		requestID = string([]rune(requestID)[:maxRunes])
+	}
+	return prometheus.Labels{constants.LabelRequestID: requestID}
+}
+
+// incWithExemplar increments counter by 1, attaching the exemplar labels when there are some and the
+// counter supports exemplars (dry run and discarding counters don't).
+func incWithExemplar(counter prometheus.Counter, exemplar prometheus.Labels) {
+	if len(exemplar) > 0 {
+		if exemplarAdder, ok := counter.(prometheus.ExemplarAdder); ok {
+			exemplarAdder.AddWithExemplar(1, exemplar)
+			return
+		}
+	}
+	counter.Inc()
+}
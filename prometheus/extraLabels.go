@@ -0,0 +1,107 @@
+package prometheus
+
+import (
+	"reflect"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"github.com/piyushkumar96/app-monitoring/models"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	extraLabelsMu sync.RWMutex
+	// extraLabels holds the extra label names declared on the metrics, keyed by fully-qualified metric name.
+	extraLabels = make(map[string][]string)
+
+	// extraLabelsDeclared is set once a metric declares extra labels, so the label values of the metrics
+	// of applications that don't use them are left untouched.
+	extraLabelsDeclared atomic.Bool
+)
+
+// recordExtraLabels records the extra label names declared on the metrics configured in meta, whose metric
+// names are mapped to their *models.MetricMeta field by metricFields, so that the vecs created afterwards
+// get them appended to their labels. Extra labels that are empty, repeated or already among the labels of
+// the metric are dropped with an error. A metric configured without extra labels drops the extra labels
+// recorded by an earlier constructor call.
+func recordExtraLabels[T any](namespace string, meta *T, metricFields map[string]string) {
+	value := reflect.ValueOf(meta).Elem()
+	namespace = withRole(namespace)
+
+	extraLabelsMu.Lock()
+	defer extraLabelsMu.Unlock()
+	for name, field := range metricFields {
+		metricMeta, ok := value.FieldByName(field).Interface().(*models.MetricMeta)
+		if !ok || metricMeta == nil {
+			continue
+		}
+		key := prometheus.BuildFQName(namespace, "", name)
+		var declared []string
+		for _, label := range metricMeta.ExtraLabels {
+			if label == "" || slices.Contains(metricMeta.Labels, label) || slices.Contains(declared, label) {
+				logger().Error("extra label is empty or already declared, dropping it", "code", "OnInvalidExtraLabel", "metric", key, "label", label)
+				continue
+			}
+			declared = append(declared, label)
+		}
+		if len(declared) == 0 {
+			delete(extraLabels, key)
+			continue
+		}
+		extraLabels[key] = declared
+		extraLabelsDeclared.Store(true)
+	}
+}
+
+// withExtraLabelNames returns labelNames with the extra label names recorded for the metric appended.
+func withExtraLabelNames(namespace, name string, labelNames []string) []string {
+	if !extraLabelsDeclared.Load() {
+		return labelNames
+	}
+	extraLabelsMu.RLock()
+	declared := extraLabels[prometheus.BuildFQName(namespace, "", name)]
+	extraLabelsMu.RUnlock()
+	if len(declared) == 0 {
+		return labelNames
+	}
+	return append(slices.Clip(labelNames), declared...)
+}
+
+// declaredExtraLabels returns the extra label names labelNames end with, as recorded for the metric.
+func declaredExtraLabels(fqName string, labelNames []string) []string {
+	if !extraLabelsDeclared.Load() {
+		return nil
+	}
+	extraLabelsMu.RLock()
+	declared := extraLabels[fqName]
+	extraLabelsMu.RUnlock()
+	if len(declared) == 0 || len(labelNames) < len(declared) || !slices.Equal(labelNames[len(labelNames)-len(declared):], declared) {
+		return nil
+	}
+	return declared
+}
+
+// extraLabelValues returns labelValues with the values of the extra labels declared on vec appended, taken
+// from extra in declared order, with the missing ones left empty. Label values that already include the
+// extra labels, or don't match the labels of vec without them, are returned unchanged.
+func extraLabelValues(vec any, labelValues []string, extra map[string]string) []string {
+	if !extraLabelsDeclared.Load() {
+		return labelValues
+	}
+	value, found := metricInfos.Load(vec)
+	if !found {
+		return labelValues
+	}
+	info := value.(metricInfo)
+	if len(info.extraLabels) == 0 || len(labelValues) != len(info.labelNames)-len(info.extraLabels) {
+		return labelValues
+	}
+	values := make([]string, len(labelValues), len(info.labelNames))
+	copy(values, labelValues)
+	for _, name := range info.extraLabels {
+		values = append(values, extra[name])
+	}
+	return values
+}
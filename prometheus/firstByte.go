@@ -0,0 +1,46 @@
+package prometheus
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// firstByteWriter wraps a gin.ResponseWriter and calls onFirstByte, with the response status, right before
+// the first bytes of the response (its headers, then body) are written. For streamed responses such as SSE
+// or downloads, that is the time to first byte, well before the handler returns.
+type firstByteWriter struct {
+	gin.ResponseWriter
+	onFirstByte func(status int)
+	wrote       bool
+}
+
+// firstByte calls onFirstByte on the first write of the response.
+func (w *firstByteWriter) firstByte() {
+	if !w.wrote {
+		w.wrote = true
+		w.onFirstByte(w.ResponseWriter.Status())
+	}
+}
+
+// Write notes the first byte and writes to the wrapped writer.
+func (w *firstByteWriter) Write(data []byte) (int, error) {
+	w.firstByte()
+	return w.ResponseWriter.Write(data)
+}
+
+// WriteString notes the first byte and writes to the wrapped writer.
+func (w *firstByteWriter) WriteString(s string) (int, error) {
+	w.firstByte()
+	return w.ResponseWriter.WriteString(s)
+}
+
+// WriteHeaderNow notes the first byte and writes the headers of the wrapped writer.
+func (w *firstByteWriter) WriteHeaderNow() {
+	w.firstByte()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+// Flush notes the first byte and flushes the wrapped writer, which writes the headers if not written yet.
+func (w *firstByteWriter) Flush() {
+	w.firstByte()
+	w.ResponseWriter.Flush()
+}
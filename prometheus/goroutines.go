@@ -0,0 +1,40 @@
+package prometheus
+
+import (
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// runningGoroutines is the number of goroutines started by this package that haven't returned yet.
+var runningGoroutines atomic.Int64
+
+// RunningGoroutines returns the number of goroutines started by this package that are still running, e.g. to
+// check in a test that none is leaked once the background features are stopped. The long-lived ones are the
+// goroutines of the asynchronous dispatcher and of the TTL reapers, which AsyncDispatcher.Close and
+// TTLReaper.Stop stop and wait for; the others collect the series of a metric vec and return with the call
+// that started them.
+//
+// Example:
+//
+//	dispatcher := prometheus.EnableAsyncRecording(0)
+//	dispatcher.Close()
+//	if n := prometheus.RunningGoroutines(); n != 0 {
+//	    t.Fatalf("%d goroutines leaked", n)
+//	}
+func RunningGoroutines() int {
+	return int(runningGoroutines.Load())
+}
+
+// collectAsync returns a channel the metrics of collector are collected onto from a new goroutine, closed once
+// they all are. The goroutine is no longer counted by RunningGoroutines by the time the channel is closed.
+func collectAsync(collector prometheus.Collector) <-chan prometheus.Metric {
+	ch := make(chan prometheus.Metric)
+	runningGoroutines.Add(1)
+	go func() {
+		collector.Collect(ch)
+		runningGoroutines.Add(-1)
+		close(ch)
+	}()
+	return ch
+}
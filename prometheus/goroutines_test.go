@@ -0,0 +1,56 @@
+package prometheus
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+// waitForGoroutines waits up to a second for the number of goroutines of the process to drop to want,
+// returning the last count.
+func waitForGoroutines(want int) int {
+	deadline := time.Now().Add(time.Second)
+	for {
+		n := runtime.NumGoroutine()
+		if n <= want || time.Now().After(deadline) {
+			return n
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestBackgroundFeaturesDontLeakGoroutines(t *testing.T) {
+	useTestRegistry(t)
+	before, running := runtime.NumGoroutine(), RunningGoroutines()
+	vec := GetPromGaugeVec("goroutines", "connections", "Tracks open connections", []string{"pool"})
+
+	dispatcher := EnableAsyncRecording(0)
+	gaugeWith(vec, "primary").Set(3)
+	dispatcher.Flush()
+	reapers := []*TTLReaper{NewTTLReaper(time.Hour, time.Millisecond), NewTTLReaper(time.Hour, time.Hour)}
+	for _, reaper := range reapers {
+		reaper.Register(vec)
+		reaper.Start()
+		reaper.Start()
+	}
+	if got := RunningGoroutines() - running; got != 3 {
+		t.Errorf("running goroutines = %d, want 3 (the dispatcher and the reapers)", got)
+	}
+	if samples := gaugeSamples(vec); len(samples) != 1 {
+		t.Errorf("collected samples = %d, want 1", len(samples))
+	}
+
+	dispatcher.Close()
+	dispatcher.Close()
+	for _, reaper := range reapers {
+		reaper.Stop()
+		reaper.Stop()
+	}
+
+	if got := RunningGoroutines(); got != running {
+		t.Errorf("running goroutines after stopping = %d, want %d", got, running)
+	}
+	if got := waitForGoroutines(before); got > before {
+		t.Errorf("process goroutines after stopping = %d, want at most %d", got, before)
+	}
+}
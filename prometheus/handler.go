@@ -0,0 +1,131 @@
+package prometheus
+
+import (
+	"encoding/json"
+	"math"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// SuppressCreatedTimestamps makes Handler strip the created timestamps of counters, histograms and summaries
+// from the exposition, so that no "_created" series (OpenMetrics) or created timestamp fields (protobuf) are
+// served. Enable it when older Prometheus, VictoriaMetrics or recording rules choke on "_created" series.
+//
+// Without created timestamps, a scraper can't tell when a counter started, so Prometheus can't insert the
+// zero sample its created timestamp ingestion relies on, and the first increase of a new series is only
+// visible from its second scrape. Like Disabled, it is read once per Handler call, so set it before
+// creating the handler.
+var SuppressCreatedTimestamps bool
+
+// Handler returns an http.Handler serving the metrics of gatherer in the format negotiated with the scraper
+// from its Accept header: the Prometheus text or protobuf format, OpenMetrics (which carries exemplars), or,
+// when application/json is preferred over them, a JSON snapshot of the current values as returned by
+// CurrentValues, e.g. {"myapp_http_requests":{"{code=\"200\",method=\"GET\"}":42}}. Created timestamps are
+// stripped when SuppressCreatedTimestamps is set. A nil gatherer serves prometheus.DefaultGatherer.
+//
+// Example:
+//
+//	prometheus.SuppressCreatedTimestamps = true
+//	router.GET("/metrics", gin.WrapH(prometheus.Handler(nil)))
+func Handler(gatherer prometheus.Gatherer) http.Handler {
+	if gatherer == nil {
+		gatherer = prometheus.DefaultGatherer
+	}
+	if SuppressCreatedTimestamps {
+		gatherer = withoutCreatedTimestamps{gatherer}
+	}
+	exposition := promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{EnableOpenMetrics: true})
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if prefersJSON(r.Header.Get("Accept")) {
+			serveJSONSnapshot(w, gatherer)
+			return
+		}
+		exposition.ServeHTTP(w, r)
+	})
+}
+
+// expositionFormats are the media types of the formats promhttp negotiates.
+var expositionFormats = map[string]struct{}{
+	"text/plain":                      {},
+	"application/openmetrics-text":    {},
+	"application/vnd.google.protobuf": {},
+}
+
+// prefersJSON reports whether an Accept header lists application/json with a quality above zero and not
+// below the quality of every exposition format it lists. Wildcards don't select JSON, so scrapers sending
+// "*/*" keep the Prometheus text format.
+func prefersJSON(accept string) bool {
+	jsonQuality, expositionQuality := 0.0, 0.0
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(mediaRange)
+		if err != nil {
+			continue
+		}
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+		if mediaType == "application/json" {
+			jsonQuality = max(jsonQuality, quality)
+		} else if _, ok := expositionFormats[mediaType]; ok {
+			expositionQuality = max(expositionQuality, quality)
+		}
+	}
+	return jsonQuality > 0 && jsonQuality >= expositionQuality
+}
+
+// serveJSONSnapshot serves the current values of gatherer as JSON, leaving out the NaN and infinite
+// values JSON can't represent. Like promhttp, it responds 500 Internal Server Error when gathering fails.
+func serveJSONSnapshot(w http.ResponseWriter, gatherer prometheus.Gatherer) {
+	values, err := CurrentValues(gatherer)
+	if err != nil {
+		http.Error(w, "An error has occurred while gathering metrics:\n\n"+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	for name, labelSets := range values {
+		for labelSet, value := range labelSets {
+			if math.IsNaN(value) || math.IsInf(value, 0) {
+				delete(labelSets, labelSet)
+			}
+		}
+		if len(labelSets) == 0 {
+			delete(values, name)
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(values)
+}
+
+// withoutCreatedTimestamps is a prometheus.Gatherer that clears the created timestamps of the metric
+// families gathered by the wrapped gatherer.
+type withoutCreatedTimestamps struct {
+	prometheus.Gatherer
+}
+
+// Gather gathers the wrapped gatherer and clears the created timestamps of its counters, histograms
+// and summaries. The families are built afresh on every Gather, so clearing them in place is safe.
+func (g withoutCreatedTimestamps) Gather() ([]*dto.MetricFamily, error) {
+	families, err := g.Gatherer.Gather()
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			if counter := metric.GetCounter(); counter != nil {
+				counter.CreatedTimestamp = nil
+			}
+			if histogram := metric.GetHistogram(); histogram != nil {
+				histogram.CreatedTimestamp = nil
+			}
+			if summary := metric.GetSummary(); summary != nil {
+				summary.CreatedTimestamp = nil
+			}
+		}
+	}
+	return families, err
+}
@@ -0,0 +1,191 @@
+package prometheus
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/piyushkumar96/app-monitoring/constants"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// HealthComponent reports the health of the components it covers, keyed by component name, as one of
+// constants.HealthUp, constants.HealthDegraded or constants.HealthDown. It is called on every health
+// request, so it must be cheap and safe for concurrent use. The metrics types provide components reading
+// their gauges (see PromReadinessMetrics.HealthComponent); any other check can be plugged in as a func.
+type HealthComponent func() map[string]string
+
+// healthResponse is the JSON body served by HealthHandler.
+type healthResponse struct {
+	Status     string            `json:"status"`
+	Components map[string]string `json:"components"`
+}
+
+// HealthHandler returns an http.Handler serving the aggregated health of components as JSON, e.g.
+// {"status":"degraded","components":{"db":"up","pubsub:orders":"degraded"}}. The overall status is down
+// when a component is down, degraded when a component is degraded, and up otherwise, including when there
+// are no components. It responds 503 Service Unavailable when down and 200 OK otherwise, so probes and the
+// dashboards built on the same gauges agree. When two components report the same name, the worst status wins.
+//
+// Example:
+//
+//	router.GET("/health", gin.WrapH(prometheus.HealthHandler(
+//	    readinessMetrics.HealthComponent(),
+//	    psMetrics.HealthComponent(),
+//	    dsMetrics.HealthComponent(5*time.Minute),
+//	    func() map[string]string { return map[string]string{"cache": cacheHealth()} },
+//	)))
+func HealthHandler(components ...HealthComponent) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		response := healthResponse{Status: constants.HealthUp, Components: make(map[string]string)}
+		for _, component := range components {
+			for name, status := range component() {
+				if current, ok := response.Components[name]; !ok || healthSeverity(status) > healthSeverity(current) {
+					response.Components[name] = status
+				}
+				if healthSeverity(status) > healthSeverity(response.Status) {
+					response.Status = status
+				}
+			}
+		}
+
+		code := http.StatusOK
+		if response.Status == constants.HealthDown {
+			code = http.StatusServiceUnavailable
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		_ = json.NewEncoder(w).Encode(response)
+	})
+}
+
+// healthSeverity orders the health statuses from up (0) to down (2). Unknown statuses count as degraded.
+func healthSeverity(status string) int {
+	switch status {
+	case constants.HealthUp:
+		return 0
+	case constants.HealthDown:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// HealthComponent returns a HealthComponent reading the app_ready gauge: each component is up when its
+// gauge is 1 and down otherwise. Components are named after their label values, joined with "/" in label
+// name order when the gauge has several labels. It reports no components when AppReady is not configured.
+func (rm *PromReadinessMetrics) HealthComponent() HealthComponent {
+	return func() map[string]string {
+		components := make(map[string]string)
+		for _, sample := range gaugeSamples(rm.appReady) {
+			status := constants.HealthDown
+			if sample.value == 1 {
+				status = constants.HealthUp
+			}
+			components[joinedLabelValues(sample.labels)] = status
+		}
+		return components
+	}
+}
+
+// HealthComponent returns a HealthComponent reading the pubsub_subscription_state gauge: the component
+// "pubsub:<source>" (the label values of the series) of each subscription client is up when connected, degraded when connecting and down
+// when disconnected. It reports no components when SubscriptionState is not configured.
+func (psm *PromPSMetrics) HealthComponent() HealthComponent {
+	return func() map[string]string {
+		components := make(map[string]string)
+		for _, sample := range gaugeSamples(psm.subscriptionState) {
+			status := constants.HealthDown
+			switch int(sample.value) {
+			case constants.SubscriptionStateConnected:
+				status = constants.HealthUp
+			case constants.SubscriptionStateConnecting:
+				status = constants.HealthDegraded
+			}
+			components["pubsub:"+joinedLabelValues(sample.labels)] = status
+		}
+		return components
+	}
+}
+
+// HealthComponent returns a HealthComponent reading the last call and last success timestamp gauges: the
+// component "downstream:<service>/<api>" of each downstream API is degraded when it was called more than
+// maxFailingFor after its last successful call (or has never succeeded), and up otherwise. A dependency
+// failing only degrades the service, so it is never reported down. It reports no components unless both
+// LastCallTimestampSeconds and LastSuccessTimestampSeconds are configured.
+func (dsm *PromDownstreamServiceMetrics) HealthComponent(maxFailingFor time.Duration) HealthComponent {
+	return func() map[string]string {
+		components := make(map[string]string)
+		if dsm.lastCallTimestampSeconds == nil || dsm.lastSuccessTimestampSeconds == nil {
+			return components
+		}
+		// The series of an API split by extra labels are merged, keeping the latest timestamps
+		lastSuccess := make(map[string]float64)
+		for _, sample := range gaugeSamples(dsm.lastSuccessTimestampSeconds) {
+			name := downstreamHealthName(sample.labels)
+			lastSuccess[name] = max(lastSuccess[name], sample.value)
+		}
+		lastCall := make(map[string]float64)
+		for _, sample := range gaugeSamples(dsm.lastCallTimestampSeconds) {
+			name := downstreamHealthName(sample.labels)
+			lastCall[name] = max(lastCall[name], sample.value)
+		}
+		for name, call := range lastCall {
+			status := constants.HealthUp
+			if success, ok := lastSuccess[name]; !ok || call-success > maxFailingFor.Seconds() {
+				status = constants.HealthDegraded
+			}
+			components[name] = status
+		}
+		return components
+	}
+}
+
+// downstreamHealthName returns the health component name of a downstream API from its series labels.
+func downstreamHealthName(labels map[string]string) string {
+	return "downstream:" + labels[constants.LabelService] + "/" + labels[constants.LabelAPI]
+}
+
+// gaugeSample is the value and labels of one series of a gauge vec.
+type gaugeSample struct {
+	labels map[string]string
+	value  float64
+}
+
+// gaugeSamples returns the current series of vec, or none when vec is nil.
+func gaugeSamples(vec *prometheus.GaugeVec) []gaugeSample {
+	if vec == nil {
+		return nil
+	}
+	var samples []gaugeSample
+	for metric := range collectAsync(vec) {
+		var m dto.Metric
+		if err := metric.Write(&m); err != nil {
+			continue
+		}
+		labels := make(map[string]string, len(m.GetLabel()))
+		for _, pair := range m.GetLabel() {
+			labels[pair.GetName()] = pair.GetValue()
+		}
+		samples = append(samples, gaugeSample{labels: labels, value: m.GetGauge().GetValue()})
+	}
+	return samples
+}
+
+// joinedLabelValues returns the label values of a series joined with "/", in label name order.
+func joinedLabelValues(labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	values := make([]string, len(names))
+	for i, name := range names {
+		values[i] = labels[name]
+	}
+	return strings.Join(values, "/")
+}
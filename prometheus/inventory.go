@@ -0,0 +1,153 @@
+package prometheus
+
+import (
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// Metric types reported in MetricInfo.Type.
+const (
+	MetricTypeCounter   = "counter"
+	MetricTypeGauge     = "gauge"
+	MetricTypeHistogram = "histogram"
+	MetricTypeSummary   = "summary"
+)
+
+// MetricInfo describes one metric registered by this package, as returned by RegisteredInventory.
+type MetricInfo struct {
+	// Name is the fully-qualified metric name, e.g. "myapp_http_request_latency_millis".
+	Name string
+	// Type is one of MetricTypeCounter, MetricTypeGauge, MetricTypeHistogram or MetricTypeSummary.
+	Type string
+	// Help is the help text of the metric.
+	Help string
+	// Labels are the variable label names, in the metric's label order.
+	Labels []string
+	// ConstLabels are the labels set by this package with a fixed value, e.g. "bucket_profile" or "track".
+	// Const labels added by a wrapping registerer (prometheus.WrapRegistererWith) are not included.
+	ConstLabels map[string]string
+	// Buckets are the bucket boundaries of a histogram; nil for other types.
+	Buckets []float64
+}
+
+var (
+	inventoryMu sync.RWMutex
+	// inventory holds the MetricInfo of every registered metric, keyed by name and const labels,
+	// so that constructing the same metric again doesn't add a duplicate entry.
+	inventory = make(map[string]MetricInfo)
+)
+
+// RegisteredInventory returns the metrics registered by this package so far, sorted by name, e.g. to feed a
+// metrics catalog or audit what a service emits without scraping it. Metrics whose registration failed are
+// not included. A t-digest vec is listed as one gauge per quantile, and a histogram with bucket profiles
+// as one histogram per profile.
+//
+// The returned slice and its fields are copies, so it is safe to modify them.
+func RegisteredInventory() []MetricInfo {
+	inventoryMu.RLock()
+	infos := make([]MetricInfo, 0, len(inventory))
+	for _, info := range inventory {
+		infos = append(infos, copyMetricInfo(info))
+	}
+	inventoryMu.RUnlock()
+
+	sort.Slice(infos, func(i, j int) bool {
+		if infos[i].Name != infos[j].Name {
+			return infos[i].Name < infos[j].Name
+		}
+		return inventoryKey(infos[i]) < inventoryKey(infos[j])
+	})
+	return infos
+}
+
+// SeriesCounts returns the number of series each metric registered by this package currently holds, i.e.
+// its distinct label value combinations, keyed by fully-qualified metric name, e.g. to see which metric is
+// the cardinality hog before Prometheus complains. It gathers the registry the metrics are registered against
+// (see SetRegisterer) and counts the series of every metric listed by RegisteredInventory, so metrics without
+// series yet are reported with 0. A histogram or summary series counts once, whatever its bucket or quantile
+// count. Metrics that failed to gather are logged and counted from what could be gathered.
+//
+// Example:
+//
+//	for name, count := range prometheus.SeriesCounts() {
+//	    log.Printf("%s: %d series", name, count)
+//	}
+func SeriesCounts() map[string]int {
+	families, err := getGatherer().Gather()
+	if err != nil {
+		logger().Error("failed to gather metrics for the series counts", "code", "OnSeriesCountGatherFailure", "err", err.Error())
+	}
+	counts := familySeriesCounts(families)
+
+	inventoryMu.RLock()
+	defer inventoryMu.RUnlock()
+	seriesCounts := make(map[string]int, len(inventory))
+	for _, info := range inventory {
+		seriesCounts[info.Name] = counts[info.Name]
+	}
+	return seriesCounts
+}
+
+// SeriesCount returns the number of series the metric registered by this package under the fully-qualified
+// name metricName currently holds, as SeriesCounts counts them, or 0 when there is no such metric.
+func SeriesCount(metricName string) int {
+	return SeriesCounts()[metricName]
+}
+
+// familySeriesCounts returns the number of series of each gathered metric family, keyed by name.
+func familySeriesCounts(families []*dto.MetricFamily) map[string]int {
+	counts := make(map[string]int, len(families))
+	for _, family := range families {
+		counts[family.GetName()] += len(family.GetMetric())
+	}
+	return counts
+}
+
+// recordInventory adds a registered metric to the inventory, replacing an entry with the same name and const labels.
+func recordInventory(namespace, name, metricType, help string, labelNames []string, constLabels prometheus.Labels, buckets []float64) {
+	info := copyMetricInfo(MetricInfo{
+		Name:        prometheus.BuildFQName(namespace, "", name),
+		Type:        metricType,
+		Help:        help,
+		Labels:      labelNames,
+		ConstLabels: deploymentTrackLabels(constLabels),
+		Buckets:     buckets,
+	})
+
+	inventoryMu.Lock()
+	defer inventoryMu.Unlock()
+	inventory[inventoryKey(info)] = info
+}
+
+// inventoryKey returns the key identifying info in the inventory: its name followed by its sorted const labels.
+func inventoryKey(info MetricInfo) string {
+	var b strings.Builder
+	b.WriteString(info.Name)
+	for _, name := range slices.Sorted(maps.Keys(info.ConstLabels)) {
+		b.WriteString(labelKeySeparator + name + "=" + info.ConstLabels[name])
+	}
+	return b.String()
+}
+
+// copyMetricInfo returns info with its slices and map copied.
+func copyMetricInfo(info MetricInfo) MetricInfo {
+	info.Labels = slices.Clone(info.Labels)
+	info.ConstLabels = maps.Clone(info.ConstLabels)
+	info.Buckets = slices.Clone(info.Buckets)
+	return info
+}
+
+// histogramBuckets returns the bucket boundaries a histogram created with buckets uses,
+// which are prometheus.DefBuckets when none are given.
+func histogramBuckets(buckets []float64) []float64 {
+	if len(buckets) == 0 {
+		return prometheus.DefBuckets
+	}
+	return buckets
+}
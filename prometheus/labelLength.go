@@ -0,0 +1,64 @@
+package prometheus
+
+import (
+	"unicode/utf8"
+
+	"github.com/piyushkumar96/app-monitoring/constants"
+)
+
+// MaxLabelValueLength is the maximum length, in bytes, of the label values recorded by this package. A
+// longer value, such as a raw URL path or an error message passed as a label by mistake, is truncated to
+// the limit, ending with constants.LabelValueTruncatedMarker, and counted in
+// app_monitoring_cardinality_protection_total with the reason label_truncated, so that a pathologically
+// long value can't bloat memory or break dashboards. Truncation keeps whole UTF-8 characters, and a
+// non-positive limit disables it. It applies to every observation, so set it during startup, before
+// metrics are observed.
+var MaxLabelValueLength = 256
+
+// truncateLabelValues returns labelValues, or a copy of them with the values longer than
+// MaxLabelValueLength truncated, counting each truncated value against the metric of vec. The label
+// values of the cardinality protection counter itself are never truncated.
+func truncateLabelValues(vec any, labelValues []string) []string {
+	maxLength := MaxLabelValueLength
+	if maxLength <= 0 {
+		return labelValues
+	}
+	truncated, copied := labelValues, false
+	for i, value := range labelValues {
+		if len(value) <= maxLength {
+			continue
+		}
+		registerCardinalityProtection()
+		// The label values of the protection counter are set by this package; counting them would recurse.
+		if vec == any(cardinalityProtection) {
+			return labelValues
+		}
+		if !copied {
+			truncated, copied = append([]string(nil), labelValues...), true
+		}
+		truncated[i] = truncateLabelValue(value, maxLength)
+		recordCardinalityProtection(truncatedMetric(vec), constants.ReasonLabelTruncated)
+	}
+	return truncated
+}
+
+// truncateLabelValue returns value cut to at most maxLength bytes, ending with the truncation marker.
+// The marker alone is returned when maxLength leaves no room for anything else.
+func truncateLabelValue(value string, maxLength int) string {
+	cut := maxLength - len(constants.LabelValueTruncatedMarker)
+	if cut <= 0 {
+		return constants.LabelValueTruncatedMarker[:min(maxLength, len(constants.LabelValueTruncatedMarker))]
+	}
+	for cut > 0 && !utf8.RuneStart(value[cut]) {
+		cut--
+	}
+	return value[:cut] + constants.LabelValueTruncatedMarker
+}
+
+// truncatedMetric returns the name, without namespace, a label value truncated for vec is counted against.
+func truncatedMetric(vec any) string {
+	if info, ok := metricInfos.Load(vec); ok {
+		return info.(metricInfo).metric
+	}
+	return "unknown"
+}
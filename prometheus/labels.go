@@ -0,0 +1,120 @@
+package prometheus
+
+import (
+	"slices"
+
+	"github.com/piyushkumar96/app-monitoring/constants"
+)
+
+// labelKeySeparator joins label values into a single map key.
+// It is a byte that can't appear in valid UTF-8 label values.
+const labelKeySeparator = "\xff"
+
+// Conventional label names of the metrics, in the order their label values are recorded.
+var (
+	routerRequestsLabelNames = []string{constants.LabelMethod, constants.LabelCode, constants.LabelPath, constants.LabelStatus}
+	routerResponseLabelNames = []string{constants.LabelMethod, constants.LabelCode, constants.LabelPath}
+	routerAbortedLabelNames  = []string{constants.LabelPath, constants.LabelCode}
+	dbTotalLabelNames        = []string{constants.LabelOpType, constants.LabelSource, constants.LabelEntity, constants.LabelIsTxn, constants.LabelStatus}
+	dbLatencyLabelNames      = []string{constants.LabelOpType, constants.LabelSource, constants.LabelEntity, constants.LabelIsTxn}
+	dsRequestsLabelNames     = []string{constants.LabelService, constants.LabelMethod, constants.LabelCode, constants.LabelAPI, constants.LabelStatus}
+	dsResponseLabelNames     = []string{constants.LabelService, constants.LabelMethod, constants.LabelCode, constants.LabelAPI}
+	dsTimestampLabelNames    = []string{constants.LabelService, constants.LabelAPI}
+	dsBatchItemsLabelNames   = []string{constants.LabelService, constants.LabelAPI, constants.LabelStatus}
+	dsRetryLabelNames        = []string{constants.LabelService, constants.LabelAPI, constants.LabelOutcome}
+	dsPagesLabelNames        = []string{constants.LabelService, constants.LabelAPI}
+	dsServiceLabelNames      = []string{constants.LabelService}
+	cronTotalLabelNames      = []string{constants.LabelJobName, constants.LabelStatus}
+	psConsumedLabelNames     = []string{constants.LabelSource, constants.LabelEntity, constants.LabelOpType, constants.LabelStatus, constants.LabelErrorCode}
+	psPublishedLabelNames    = []string{constants.LabelEntity, constants.LabelOpType, constants.LabelStatus}
+	psEntityLabelNames       = []string{constants.LabelEntity, constants.LabelOpType}
+	operationLabelNames      = []string{constants.LabelOperation, constants.LabelOutcome}
+	operationDepLabelNames   = []string{constants.LabelOperation, constants.LabelKind, constants.LabelName, constants.LabelOutcome}
+	txnTotalLabelNames       = []string{constants.LabelSource, constants.LabelOutcome}
+	workerTaskLabelNames     = []string{constants.LabelTaskType}
+	workerTotalLabelNames    = []string{constants.LabelTaskType, constants.LabelStatus}
+)
+
+// conventionalLabelOrder returns labels with the conventional label names rearranged into the order
+// their values are recorded in, so the declared order of labels doesn't matter. The other labels, such as
+// optional labels, keep their position. Labels that don't declare every conventional name exactly once
+// are returned unchanged, and their values are recorded positionally as before; since values may then
+// land under the wrong label names, the mismatch is logged as a warning for metric.
+// Prometheus sorts labels by name when exposing them, so the reordering doesn't change the exposed series.
+func conventionalLabelOrder(metric string, labels []string, conventional []string) []string {
+	positions := make([]int, 0, len(conventional))
+	for i, name := range labels {
+		if slices.Contains(conventional, name) {
+			positions = append(positions, i)
+		}
+	}
+	if len(positions) != len(conventional) {
+		warnUnconventionalLabels(metric, labels, conventional)
+		return labels
+	}
+	ordered := slices.Clone(labels)
+	for i, pos := range positions {
+		ordered[pos] = conventional[i]
+	}
+	if !sameLabelSet(ordered, labels) {
+		warnUnconventionalLabels(metric, labels, conventional)
+		return labels
+	}
+	return ordered
+}
+
+// warnUnconventionalLabels logs that the labels declared for metric don't hold every conventional
+// label name exactly once, so their values are recorded positionally.
+func warnUnconventionalLabels(metric string, labels, conventional []string) {
+	logger().Warn("metric labels don't declare each conventional label once, recording label values positionally", "code", "OnUnconventionalMetricLabels", "metric", metric, "labels", labels, "conventional", conventional)
+}
+
+// sameLabelSet reports whether a and b hold the same label names, regardless of their order.
+func sameLabelSet(a, b []string) bool {
+	sortedA, sortedB := slices.Clone(a), slices.Clone(b)
+	slices.Sort(sortedA)
+	slices.Sort(sortedB)
+	return slices.Equal(sortedA, sortedB)
+}
+
+// optionalLabel is a label whose value is only recorded when its name is declared
+// in the labels a metric was configured with.
+type optionalLabel struct {
+	name  string
+	value string
+}
+
+// withOptionalLabels merges optional label values into the positional base label values.
+// Each optional label is placed at the position its name is declared in labels, and the
+// base values fill the remaining positions in order. Optional labels that are not declared
+// are skipped, so metrics that don't use them keep their existing positional layout.
+func withOptionalLabels(labels []string, base []string, optional ...optionalLabel) []string {
+	if len(optional) == 0 {
+		return base
+	}
+	values := make([]string, 0, len(labels))
+	next := 0
+	for _, name := range labels {
+		if idx := slices.IndexFunc(optional, func(o optionalLabel) bool { return o.name == name }); idx >= 0 {
+			values = append(values, optional[idx].value)
+			continue
+		}
+		if next < len(base) {
+			values = append(values, base[next])
+			next++
+		}
+	}
+	// Keep any surplus base values so label count mismatches still surface as they did before.
+	return append(values, base[next:]...)
+}
+
+// requireLabel reports whether label is declared in labels.
+// When it is not, an error naming the metric and the feature that needs the label is logged,
+// so the caller can disable the feature instead of panicking on every observation.
+func requireLabel(metric, feature, label string, labels []string) bool {
+	if slices.Contains(labels, label) {
+		return true
+	}
+	logger().Error("metric label required by feature is not configured", "code", "OnRequiredMetricLabelMissing", "metric", metric, "feature", feature, "label", label)
+	return false
+}
@@ -0,0 +1,61 @@
+package prometheus
+
+import (
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestConventionalLabelOrder(t *testing.T) {
+	tests := []struct {
+		name     string
+		labels   []string
+		want     []string
+		fallback bool
+	}{
+		{name: "conventional order", labels: []string{"method", "code", "path", "status"}, want: []string{"method", "code", "path", "status"}},
+		{name: "swapped", labels: []string{"code", "method", "status", "path"}, want: []string{"method", "code", "path", "status"}},
+		{name: "optional labels keep their position", labels: []string{"path", "api_version", "method", "status", "code"}, want: []string{"method", "api_version", "code", "path", "status"}},
+		{name: "missing conventional label", labels: []string{"path", "method", "code"}, want: []string{"path", "method", "code"}, fallback: true},
+		{name: "duplicated conventional label", labels: []string{"path", "method", "code", "status", "method"}, want: []string{"path", "method", "code", "status", "method"}, fallback: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs := useTestLogger(t, slog.LevelWarn)
+			if got := conventionalLabelOrder("http_requests", tt.labels, routerRequestsLabelNames); !slices.Equal(got, tt.want) {
+				t.Errorf("conventionalLabelOrder(%q) = %q, want %q", tt.labels, got, tt.want)
+			}
+			// Falling back to positional values is reported, since they may land under the wrong label names
+			if warned := strings.Contains(logs.String(), `"code":"OnUnconventionalMetricLabels"`); warned != tt.fallback {
+				t.Errorf("warned = %v, want %v; logs: %s", warned, tt.fallback, logs.String())
+			}
+			if tt.fallback && !strings.Contains(logs.String(), `"metric":"http_requests"`) {
+				t.Errorf("warning doesn't name the metric; logs: %s", logs.String())
+			}
+		})
+	}
+}
+
+// BenchmarkLabelOrdering compares recording with label names rearranged once, at construction, into the
+// conventional order (what the constructors do) against resolving the label values by name on every
+// observation with a prometheus.Labels map.
+func BenchmarkLabelOrdering(b *testing.B) {
+	declared := []string{"status", "path", "code", "method"}
+	vec := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "bench_http_requests"}, conventionalLabelOrder("bench_http_requests", declared, routerRequestsLabelNames))
+
+	b.Run("conventional order", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			vec.WithLabelValues("GET", "200", "/users/:id", "success").Inc()
+		}
+	})
+	b.Run("labels map", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			vec.With(prometheus.Labels{"method": "GET", "code": "200", "path": "/users/:id", "status": "success"}).Inc()
+		}
+	})
+}
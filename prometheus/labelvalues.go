@@ -0,0 +1,178 @@
+package prometheus
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// LabelValuesError reports an observation whose label values don't match the labels its metric was
+// configured with, e.g. because MetricMeta.Labels declares fewer labels than the metric records.
+// Unlike the Prometheus panic it replaces, it names the misconfigured metric.
+type LabelValuesError struct {
+	// Metric is the fully-qualified metric name, e.g. "myapp_db_operations".
+	Metric string
+	// Expected is the number of labels the metric was configured with.
+	Expected int
+	// Actual is the number of label values the observation was made with.
+	Actual int
+	// Err is the underlying error reported by the metric vec.
+	Err error
+}
+
+// Error returns a message naming the metric and the expected and actual label counts.
+func (e *LabelValuesError) Error() string {
+	return fmt.Sprintf("metric %q expects %d label values but got %d: %v", e.Metric, e.Expected, e.Actual, e.Err)
+}
+
+// Unwrap returns the underlying error reported by the metric vec.
+func (e *LabelValuesError) Unwrap() error {
+	return e.Err
+}
+
+// metricInfo describes a metric vec created by this package, for reporting label values errors
+// and logging dry run observations.
+type metricInfo struct {
+	name        string
+	metric      string
+	labelNames  []string
+	extraLabels []string
+}
+
+var (
+	// metricInfos holds the metricInfo of every metric vec created by this package, keyed by vec.
+	metricInfos sync.Map
+
+	observationErrorHandlerMu sync.RWMutex
+	observationErrorHandler   = func(err error) { panic(err) }
+)
+
+// Discarding metrics returned instead of a series when label values are invalid.
+// They are not registered, so whatever is recorded into them is dropped.
+var (
+	discardCounter  = prometheus.NewCounter(prometheus.CounterOpts{Name: "discarded"})
+	discardGauge    = prometheus.NewGauge(prometheus.GaugeOpts{Name: "discarded"})
+	discardObserver = prometheus.NewHistogram(prometheus.HistogramOpts{Name: "discarded"})
+)
+
+// SetObservationErrorHandler sets the handler invalid observations are reported to as a *LabelValuesError.
+// The observation itself is dropped. By default the handler panics with the error, failing fast like
+// Prometheus does but naming the misconfigured metric; SelfTest relies on that panic to report errors.
+// Set a handler that logs instead to keep serving with a misconfigured metric.
+//
+// Example:
+//
+//	prometheus.SetObservationErrorHandler(func(err error) {
+//		l.Logger.Error("invalid metric observation", "err", err.Error())
+//	})
+func SetObservationErrorHandler(handler func(err error)) {
+	observationErrorHandlerMu.Lock()
+	defer observationErrorHandlerMu.Unlock()
+	observationErrorHandler = handler
+}
+
+// handleObservationError reports an invalid observation of vec to the observation error handler.
+func handleObservationError(vec any, labelValues []string, err error) {
+	labelsErr := &LabelValuesError{Metric: "unknown", Expected: -1, Actual: len(labelValues), Err: err}
+	if info, ok := metricInfos.Load(vec); ok {
+		labelsErr.Metric = info.(metricInfo).name
+		labelsErr.Expected = len(info.(metricInfo).labelNames)
+	}
+
+	observationErrorHandlerMu.RLock()
+	handler := observationErrorHandler
+	observationErrorHandlerMu.RUnlock()
+	handler(labelsErr)
+}
+
+// storeMetricInfo records the name and label names of a metric vec created by this package.
+func storeMetricInfo(vec any, namespace, name string, labelNames []string) {
+	fqName := prometheus.BuildFQName(namespace, "", name)
+	metricInfos.Store(vec, metricInfo{name: fqName, metric: name, labelNames: labelNames, extraLabels: declaredExtraLabels(fqName, labelNames)})
+	attachSeriesLimiter(vec, fqName)
+}
+
+// counterWith returns the counter of vec for the label values. Invalid label values are reported
+// to the observation error handler, and a counter that is not exposed is returned instead.
+func counterWith(vec *prometheus.CounterVec, labelValues ...string) prometheus.Counter {
+	labelValues = extraLabelValues(vec, labelValues, nil)
+	labelValues = truncateLabelValues(vec, labelValues)
+	labelValues = limitNewSeries(vec, labelValues)
+	touchSeries(vec, labelValues)
+	if DryRun {
+		return dryRunCounterWith(vec, labelValues)
+	}
+	if dispatcher := asyncDispatcher.Load(); dispatcher != nil {
+		return dispatcher.counterWith(vec, labelValues)
+	}
+	cache := seriesCacheFor(vec)
+	if counter, ok := cache.load(labelValues); ok {
+		return counter.(prometheus.Counter)
+	}
+	counter, err := vec.GetMetricWithLabelValues(labelValues...)
+	if err != nil {
+		handleObservationError(vec, labelValues, err)
+		return discardCounter
+	}
+	cache.store(labelValues, counter)
+	return counter
+}
+
+// gaugeWith returns the gauge of vec for the label values. Invalid label values are reported
+// to the observation error handler, and a gauge that is not exposed is returned instead.
+func gaugeWith(vec *prometheus.GaugeVec, labelValues ...string) prometheus.Gauge {
+	labelValues = extraLabelValues(vec, labelValues, nil)
+	labelValues = truncateLabelValues(vec, labelValues)
+	labelValues = limitNewSeries(vec, labelValues)
+	touchSeries(vec, labelValues)
+	if DryRun {
+		return dryRunGaugeWith(vec, labelValues)
+	}
+	if dispatcher := asyncDispatcher.Load(); dispatcher != nil {
+		return dispatcher.gaugeWith(vec, labelValues)
+	}
+	cache := seriesCacheFor(vec)
+	if gauge, ok := cache.load(labelValues); ok {
+		return gauge.(prometheus.Gauge)
+	}
+	gauge, err := vec.GetMetricWithLabelValues(labelValues...)
+	if err != nil {
+		handleObservationError(vec, labelValues, err)
+		return discardGauge
+	}
+	cache.store(labelValues, gauge)
+	return gauge
+}
+
+// observerWith returns the observer of target for the label values. Invalid label values are reported
+// to the observation error handler, and an observer that is not exposed is returned instead.
+func observerWith(target labelObserver, labelValues []string) (observer prometheus.Observer) {
+	labelValues = extraLabelValues(target, labelValues, nil)
+	labelValues = truncateLabelValues(target, labelValues)
+	labelValues = limitNewSeries(target, labelValues)
+	touchSeries(target, labelValues)
+	if DryRun {
+		return dryRunObserverWith(target, labelValues)
+	}
+	if dispatcher := asyncDispatcher.Load(); dispatcher != nil {
+		return dispatcher.observerWith(target, labelValues)
+	}
+	cache := observerCacheFor(target)
+	if cached, ok := cache.load(labelValues); ok {
+		return cached.(prometheus.Observer)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err, ok := r.(error)
+			if !ok {
+				err = fmt.Errorf("%v", r)
+			}
+			handleObservationError(target, labelValues, err)
+			observer = discardObserver
+		}
+	}()
+	observer = target.WithLabelValues(labelValues...)
+	cache.store(labelValues, observer)
+	return observer
+}
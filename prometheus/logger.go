@@ -0,0 +1,50 @@
+package prometheus
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+
+	l "github.com/piyushkumar96/generic-logger"
+)
+
+// diagnosticLogger is the subset of the generic-logger and slog loggers the package logs its diagnostics with.
+type diagnosticLogger interface {
+	Debug(msg string, args ...any)
+	Warn(msg string, args ...any)
+	Error(msg string, args ...any)
+}
+
+// slogLogger is the logger set by SetLogger, or nil to log through generic-logger.
+var slogLogger atomic.Pointer[slog.Logger]
+
+// SetLogger routes the package's diagnostic logs (e.g. metric registration failures, invalid buckets or
+// metric names, dry run observations) through logger, so they flow into a log/slog pipeline. They are
+// logged with the same messages and key-value pairs, including the "code" key. A nil logger restores the
+// default, generic-logger's logger().
+//
+// Call it during startup, before the metrics are created, so registration diagnostics are routed too.
+//
+// Example:
+//
+//	prometheus.SetLogger(slog.New(slog.NewJSONHandler(os.Stdout, nil)))
+func SetLogger(logger *slog.Logger) {
+	slogLogger.Store(logger)
+}
+
+// logger returns the logger the package's diagnostics are logged with.
+func logger() diagnosticLogger {
+	if logger := slogLogger.Load(); logger != nil {
+		return logger
+	}
+	return l.Logger
+}
+
+// debugEnabled reports whether the package's debug diagnostics are logged, so that costly debug logs can
+// be skipped. generic-logger doesn't expose its level, so its debug logs are assumed to be enabled.
+func debugEnabled() bool {
+	if logger := slogLogger.Load(); logger != nil {
+		return logger.Enabled(context.Background(), slog.LevelDebug)
+	}
+	return true
+}
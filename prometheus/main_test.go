@@ -0,0 +1,72 @@
+package prometheus
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	l "github.com/piyushkumar96/generic-logger"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestMain(m *testing.M) {
+	l.Init()
+	gin.SetMode(gin.TestMode)
+	os.Exit(m.Run())
+}
+
+// useTestRegistry registers the metrics created by the test against a fresh registry, restoring the
+// previous registerer when the test ends. Tests using it must not run in parallel.
+func useTestRegistry(t testing.TB) *prometheus.Registry {
+	t.Helper()
+	registry := prometheus.NewRegistry()
+	previous := getRegisterer()
+	SetRegisterer(registry)
+	t.Cleanup(func() { SetRegisterer(previous) })
+	return registry
+}
+
+// useTestLogger routes the package's diagnostics to a slog logger writing JSON at level into the returned
+// buffer, restoring the default logger when the test ends.
+func useTestLogger(t testing.TB, level slog.Level) *bytes.Buffer {
+	t.Helper()
+	var logs bytes.Buffer
+	SetLogger(slog.New(slog.NewJSONHandler(&logs, &slog.HandlerOptions{Level: level})))
+	t.Cleanup(func() { SetLogger(nil) })
+	return &logs
+}
+
+// helpTexts gathers registry, returning the HELP text of each metric family by name.
+func helpTexts(t *testing.T, registry *prometheus.Registry) map[string]string {
+	t.Helper()
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("gather: %v", err)
+	}
+	help := make(map[string]string, len(families))
+	for _, family := range families {
+		help[family.GetName()] = family.GetHelp()
+	}
+	return help
+}
+
+// assertHelpTexts fails the test unless registry exposes exactly the metric families of want, with
+// their HELP texts.
+func assertHelpTexts(t *testing.T, registry *prometheus.Registry, want map[string]string) {
+	t.Helper()
+	got := helpTexts(t, registry)
+	for name, wantHelp := range want {
+		if gotHelp, ok := got[name]; !ok {
+			t.Errorf("metric %s isn't registered", name)
+		} else if gotHelp != wantHelp {
+			t.Errorf("help of %s = %q, want %q", name, gotHelp, wantHelp)
+		}
+	}
+	for name := range got {
+		if _, ok := want[name]; !ok {
+			t.Errorf("unexpected metric %s with help %q", name, got[name])
+		}
+	}
+}
@@ -1,7 +1,13 @@
 package prometheus
 
 import (
-	l "github.com/piyushkumar96/generic-logger"
+	"errors"
+	"time"
+
+	"github.com/piyushkumar96/app-monitoring/constants"
+	"github.com/piyushkumar96/app-monitoring/models"
+
+	ae "github.com/piyushkumar96/app-error"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -17,8 +23,15 @@ import (
 //   - buckets: Histogram bucket boundaries (e.g., []float64{10, 50, 100, 500, 1000})
 //
 // Returns a HistogramVec that can be used to observe values with different label combinations.
-// If registration fails (e.g., duplicate metric), an error is logged but the histogram is still returned.
+// If the same metric is already registered, the registered histogram is returned. If registration fails otherwise
+// (e.g., same name with different labels), an error is logged but the histogram is still returned.
+// An invalid fully-qualified name is logged with a suggested sanitized name (see SanitizeMetricNames).
+// Unsorted or duplicated buckets are sorted and deduplicated with a warning naming the metric. Negative
+// buckets are kept, for histograms of values that can be negative.
 func GetPromHistogramVec(namespace, name, help string, labelNames []string, buckets []float64) *prometheus.HistogramVec {
+	namespace, name = validateMetricName(namespace, name)
+	labelNames = withExtraLabelNames(namespace, name, labelNames)
+	buckets = validateBuckets(namespace, name, buckets)
 	histogram := prometheus.NewHistogramVec(
 		prometheus.HistogramOpts{
 			Namespace: namespace,
@@ -27,9 +40,13 @@ func GetPromHistogramVec(namespace, name, help string, labelNames []string, buck
 			Buckets:   buckets,
 		}, labelNames,
 	)
-	if err := prometheus.Register(histogram); err != nil {
-		l.Logger.Error("failed to register histogram vec metric", "code", "OnHistogramMetricRegisterFailure", "err", err.Error())
+	histogram, err := registerCollector(histogram)
+	if err != nil {
+		logger().Error("failed to register histogram vec metric", "code", "OnHistogramMetricRegisterFailure", "err", err.Error())
+	} else {
+		recordInventory(namespace, name, MetricTypeHistogram, help, labelNames, nil, histogramBuckets(buckets))
 	}
+	storeMetricInfo(histogram, namespace, name, labelNames)
 	return histogram
 }
 
@@ -44,8 +61,12 @@ func GetPromHistogramVec(namespace, name, help string, labelNames []string, buck
 //   - labelNames: Slice of label names for the metric dimensions
 //
 // Returns a SummaryVec that can be used to observe values with different label combinations.
-// If registration fails (e.g., duplicate metric), an error is logged but the summary is still returned.
+// If the same metric is already registered, the registered summary is returned. If registration fails otherwise
+// (e.g., same name with different labels), an error is logged but the summary is still returned.
+// An invalid fully-qualified name is logged with a suggested sanitized name (see SanitizeMetricNames).
 func GetPromSummaryVec(namespace, name, help string, labelNames []string) *prometheus.SummaryVec {
+	namespace, name = validateMetricName(namespace, name)
+	labelNames = withExtraLabelNames(namespace, name, labelNames)
 	summary := prometheus.NewSummaryVec(
 		prometheus.SummaryOpts{
 			Namespace: namespace,
@@ -53,9 +74,13 @@ func GetPromSummaryVec(namespace, name, help string, labelNames []string) *prome
 			Help:      help,
 		}, labelNames,
 	)
-	if err := prometheus.Register(summary); err != nil {
-		l.Logger.Error("failed to register summary vec metric", "code", "OnSummaryVecMetricRegisterFailure", "err", err.Error())
+	summary, err := registerCollector(summary)
+	if err != nil {
+		logger().Error("failed to register summary vec metric", "code", "OnSummaryVecMetricRegisterFailure", "err", err.Error())
+	} else {
+		recordInventory(namespace, name, MetricTypeSummary, help, labelNames, nil, nil)
 	}
+	storeMetricInfo(summary, namespace, name, labelNames)
 	return summary
 }
 
@@ -70,8 +95,12 @@ func GetPromSummaryVec(namespace, name, help string, labelNames []string) *prome
 //   - labelNames: Slice of label names for the metric dimensions
 //
 // Returns a CounterVec that can be used to increment counts with different label combinations.
-// If registration fails (e.g., duplicate metric), an error is logged but the counter is still returned.
+// If the same metric is already registered, the registered counter is returned. If registration fails otherwise
+// (e.g., same name with different labels), an error is logged but the counter is still returned.
+// An invalid fully-qualified name is logged with a suggested sanitized name (see SanitizeMetricNames).
 func GetPromCounterVec(namespace, name, help string, labelNames []string) *prometheus.CounterVec {
+	namespace, name = validateMetricName(namespace, name)
+	labelNames = withExtraLabelNames(namespace, name, labelNames)
 	counter := prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Namespace: namespace,
@@ -79,9 +108,13 @@ func GetPromCounterVec(namespace, name, help string, labelNames []string) *prome
 			Help:      help,
 		}, labelNames,
 	)
-	if err := prometheus.Register(counter); err != nil {
-		l.Logger.Error("failed to register counter vec metric", "code", "OnCounterVecMetricRegisterFailure", "err", err.Error())
+	counter, err := registerCollector(counter)
+	if err != nil {
+		logger().Error("failed to register counter vec metric", "code", "OnCounterVecMetricRegisterFailure", "err", err.Error())
+	} else {
+		recordInventory(namespace, name, MetricTypeCounter, help, labelNames, nil, nil)
 	}
+	storeMetricInfo(counter, namespace, name, labelNames)
 	return counter
 }
 
@@ -96,8 +129,12 @@ func GetPromCounterVec(namespace, name, help string, labelNames []string) *prome
 //   - labelNames: Slice of label names for the metric dimensions
 //
 // Returns a GaugeVec that can be used to set, increment, or decrement values with different label combinations.
-// If registration fails (e.g., duplicate metric), an error is logged but the gauge is still returned.
+// If the same metric is already registered, the registered gauge is returned. If registration fails otherwise
+// (e.g., same name with different labels), an error is logged but the gauge is still returned.
+// An invalid fully-qualified name is logged with a suggested sanitized name (see SanitizeMetricNames).
 func GetPromGaugeVec(namespace, name, help string, labelNames []string) *prometheus.GaugeVec {
+	namespace, name = validateMetricName(namespace, name)
+	labelNames = withExtraLabelNames(namespace, name, labelNames)
 	gauge := prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Namespace: namespace,
@@ -105,9 +142,13 @@ func GetPromGaugeVec(namespace, name, help string, labelNames []string) *prometh
 			Help:      help,
 		}, labelNames,
 	)
-	if err := prometheus.Register(gauge); err != nil {
-		l.Logger.Error("failed to register gaugevec metric", "code", "OnGaugeVecMetricRegisterFailure", "err", err.Error())
+	gauge, err := registerCollector(gauge)
+	if err != nil {
+		logger().Error("failed to register gaugevec metric", "code", "OnGaugeVecMetricRegisterFailure", "err", err.Error())
+	} else {
+		recordInventory(namespace, name, MetricTypeGauge, help, labelNames, nil, nil)
 	}
+	storeMetricInfo(gauge, namespace, name, labelNames)
 	return gauge
 }
 
@@ -125,3 +166,74 @@ func GetPromGaugeVec(namespace, name, help string, labelNames []string) *prometh
 func GetPromExponentialBuckets(start, factor float64, count int) []float64 {
 	return prometheus.ExponentialBuckets(start, factor, count)
 }
+
+// metricHelp returns the help text configured for a metric, or defaultHelp when none is configured.
+func metricHelp(metricMeta *models.MetricMeta, defaultHelp string) string {
+	if metricMeta.Help != "" {
+		return metricMeta.Help
+	}
+	return defaultHelp
+}
+
+// isFailure reports whether an operation outcome counts as a failure: appErr is non-nil and, when a
+// failure predicate is configured, the predicate doesn't report it as an expected error.
+func isFailure(appErr *ae.AppError, failurePredicate func(*ae.AppError) bool) bool {
+	if appErr == nil {
+		return false
+	}
+	return failurePredicate == nil || failurePredicate(appErr)
+}
+
+// errAppError returns the app error an operation outcome err is recorded as: nil when err is nil, the
+// app error err wraps, if any, or else an app error with code constants.ErrorCodeUnknown wrapping err,
+// so that a failure predicate can still inspect err through ActualErr.
+func errAppError(err error) *ae.AppError {
+	if err == nil {
+		return nil
+	}
+	var appErr *ae.AppError
+	if errors.As(err, &appErr) && appErr != nil {
+		return appErr
+	}
+	return &ae.AppError{
+		ActualErr:  err,
+		CustomErr:  &ae.CustomErr{Code: constants.ErrorCodeUnknown, Message: err.Error()},
+		ErrorCodes: []string{constants.ErrorCodeUnknown},
+	}
+}
+
+// durationMillis returns d in fractional milliseconds, so a 1.5ms operation is observed as 1.5
+// rather than truncated to 1, and sub-millisecond operations keep their resolution. A negative d
+// is returned as 0 (see nonNegativeLatency).
+func durationMillis(d time.Duration) float64 {
+	return float64(nonNegativeLatency(d)) / float64(time.Millisecond)
+}
+
+// durationMicros converts d to fractional microseconds, returning a negative d as 0 (see nonNegativeLatency).
+func durationMicros(d time.Duration) float64 {
+	return float64(nonNegativeLatency(d)) / float64(time.Microsecond)
+}
+
+// slaBucketPercentages are the percentages of the SLA used as bucket boundaries by GetPromSLABuckets.
+// They are dense around 100% so the histogram resolves the SLA boundary precisely.
+var slaBucketPercentages = []float64{10, 25, 50, 75, 90, 100, 110, 125, 150, 200, 400}
+
+// GetPromSLABuckets generates histogram bucket boundaries clustered around a latency SLA.
+// This gives fine resolution where it matters for alerting (just below and above the SLA)
+// while still covering fast requests and severe outliers.
+//
+// Parameters:
+//   - slaMillis: The latency SLA in milliseconds, e.g. 300 for "p99 < 300ms" (must be > 0)
+//
+// The buckets are slaMillis multiplied by each of 0.1, 0.25, 0.5, 0.75, 0.9, 1, 1.1, 1.25, 1.5, 2 and 4.
+//
+// Example: GetPromSLABuckets(300) returns []float64{30, 75, 150, 225, 270, 300, 330, 375, 450, 600, 1200}
+//
+// Returns a slice of float64 bucket boundaries suitable for use with GetPromHistogramVec.
+func GetPromSLABuckets(slaMillis float64) []float64 {
+	buckets := make([]float64, len(slaBucketPercentages))
+	for i, percentage := range slaBucketPercentages {
+		buckets[i] = slaMillis * percentage / 100
+	}
+	return buckets
+}
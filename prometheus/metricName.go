@@ -0,0 +1,63 @@
+package prometheus
+
+import (
+	"regexp"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// SanitizeMetricNames makes the constructors register metrics whose fully-qualified name is not a valid
+// Prometheus metric name under a sanitized name instead, replacing every invalid character with "_"
+// (e.g. namespace "my-app" registers "my_app_http_requests_total"). When false (the default), such
+// metrics are still registered as given, which fails, and an error suggesting the sanitized name is
+// logged. Like Disabled, it is read once per constructor call, so set it before creating any metrics.
+var SanitizeMetricNames bool
+
+var (
+	validMetricName        = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+	invalidMetricNameChars = regexp.MustCompile(`[^a-zA-Z0-9_:]`)
+)
+
+// validateMetricName returns the namespace and name to register a metric with. When the fully-qualified
+// name is not a valid Prometheus metric name, an error suggesting the sanitized name is logged and the
+// inputs are returned unchanged, or, when SanitizeMetricNames is set, the sanitized namespace and name
+// are returned with a warning. The role set with SetRole is appended to the namespace first.
+func validateMetricName(namespace, name string) (string, string) {
+	namespace = withRole(namespace)
+	metric := prometheus.BuildFQName(namespace, "", name)
+	if validMetricName.MatchString(metric) {
+		return namespace, name
+	}
+	sanitizedNamespace, sanitizedName := sanitizeMetricName(namespace), sanitizeMetricName(name)
+	if sanitizedNamespace == "" {
+		sanitizedName = sanitizeLeadingDigit(sanitizedName)
+	} else {
+		sanitizedNamespace = sanitizeLeadingDigit(sanitizedNamespace)
+	}
+	suggestion := prometheus.BuildFQName(sanitizedNamespace, "", sanitizedName)
+
+	if !SanitizeMetricNames {
+		logger().Error("metric name must match [a-zA-Z_:][a-zA-Z0-9_:]*, use the suggested name or set SanitizeMetricNames", "code", "OnInvalidMetricName", "metric", metric, "suggestion", suggestion)
+		return namespace, name
+	}
+	logger().Warn("metric name is not a valid Prometheus metric name, registering it under the sanitized name", "code", "OnSanitizedMetricName", "metric", metric, "sanitized", suggestion)
+	return sanitizedNamespace, sanitizedName
+}
+
+// validNamespace reports whether namespace can prefix a valid Prometheus metric name.
+func validNamespace(namespace string) bool {
+	return namespace == "" || validMetricName.MatchString(namespace)
+}
+
+// sanitizeMetricName replaces every character not allowed in a Prometheus metric name with "_".
+func sanitizeMetricName(s string) string {
+	return invalidMetricNameChars.ReplaceAllString(s, "_")
+}
+
+// sanitizeLeadingDigit prefixes s with "_" when it starts with a digit, which metric names can't.
+func sanitizeLeadingDigit(s string) string {
+	if s != "" && s[0] >= '0' && s[0] <= '9' {
+		return "_" + s
+	}
+	return s
+}
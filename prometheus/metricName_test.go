@@ -0,0 +1,77 @@
+package prometheus
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/piyushkumar96/app-monitoring/models"
+)
+
+// useSanitizeMetricNames sets SanitizeMetricNames to sanitize, restoring it when the test ends.
+func useSanitizeMetricNames(t *testing.T, sanitize bool) {
+	t.Helper()
+	previous := SanitizeMetricNames
+	SanitizeMetricNames = sanitize
+	t.Cleanup(func() { SanitizeMetricNames = previous })
+}
+
+func TestValidateMetricName(t *testing.T) {
+	tests := []struct {
+		name          string
+		namespace     string
+		metric        string
+		sanitize      bool
+		wantNamespace string
+		wantMetric    string
+		wantLog       string
+	}{
+		{name: "valid", namespace: "my_app", metric: "http_requests", wantNamespace: "my_app", wantMetric: "http_requests"},
+		{name: "dashed namespace", namespace: "my-app", metric: "http_requests", wantNamespace: "my-app", wantMetric: "http_requests", wantLog: `"code":"OnInvalidMetricName","metric":"my-app_http_requests","suggestion":"my_app_http_requests"`},
+		{name: "dashed namespace sanitized", namespace: "my-app", metric: "http_requests", sanitize: true, wantNamespace: "my_app", wantMetric: "http_requests", wantLog: `"code":"OnSanitizedMetricName","metric":"my-app_http_requests","sanitized":"my_app_http_requests"`},
+		{name: "dotted namespace sanitized", namespace: "my.app", metric: "http_requests", sanitize: true, wantNamespace: "my_app", wantMetric: "http_requests", wantLog: `"sanitized":"my_app_http_requests"`},
+		{name: "leading digit sanitized", namespace: "1app", metric: "http_requests", sanitize: true, wantNamespace: "_1app", wantMetric: "http_requests", wantLog: `"sanitized":"_1app_http_requests"`},
+		{name: "leading digit without namespace sanitized", metric: "9lives", sanitize: true, wantMetric: "_9lives", wantLog: `"sanitized":"_9lives"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs := useTestLogger(t, slog.LevelWarn)
+			useSanitizeMetricNames(t, tt.sanitize)
+
+			namespace, metric := validateMetricName(tt.namespace, tt.metric)
+			if namespace != tt.wantNamespace || metric != tt.wantMetric {
+				t.Errorf("validateMetricName(%q, %q) = %q, %q, want %q, %q", tt.namespace, tt.metric, namespace, metric, tt.wantNamespace, tt.wantMetric)
+			}
+			if tt.wantLog == "" && logs.Len() != 0 {
+				t.Errorf("logged %s for a valid name, want nothing", logs.String())
+			}
+			if !strings.Contains(logs.String(), tt.wantLog) {
+				t.Errorf("logs %s don't contain %s", logs.String(), tt.wantLog)
+			}
+		})
+	}
+}
+
+func TestConstructorsSanitizeDashedNamespace(t *testing.T) {
+	registry := useTestRegistry(t)
+	useTestLogger(t, slog.LevelWarn)
+	useSanitizeMetricNames(t, true)
+	NewPromCronJobMetrics(&models.CronJobMetricsMeta{
+		Namespace:         "my-app",
+		JobExecutionTotal: &models.MetricMeta{Labels: cronTotalLabelNames},
+	}).LogMetricsPre(&models.CronJobMetricsLabelValues{JobName: "sync_orders"})
+
+	if help := helpTexts(t, registry); help["my_app_cron_job_execution_count"] == "" {
+		t.Errorf("metrics %v don't include my_app_cron_job_execution_count", help)
+	}
+}
+
+func TestLoadConfigRejectsDashedNamespace(t *testing.T) {
+	useSanitizeMetricNames(t, false)
+
+	_, err := LoadConfig(strings.NewReader("namespace: my-app\n"))
+
+	if err == nil || !strings.Contains(err.Error(), `namespace "my-app"`) || !strings.Contains(err.Error(), `try "my_app"`) {
+		t.Errorf("LoadConfig() error = %v, want one naming the namespace and suggesting my_app", err)
+	}
+}
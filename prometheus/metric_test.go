@@ -0,0 +1,41 @@
+package prometheus
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDurationMillis(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want float64
+	}{
+		{d: 0, want: 0},
+		{d: 250 * time.Microsecond, want: 0.25},
+		{d: 1500 * time.Microsecond, want: 1.5},
+		{d: 2 * time.Millisecond, want: 2},
+		{d: 1234567 * time.Nanosecond, want: 1.234567},
+		{d: 3 * time.Second, want: 3000},
+	}
+	for _, tt := range tests {
+		if got := durationMillis(tt.d); got != tt.want {
+			t.Errorf("durationMillis(%v) = %v, want %v", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestDurationMicros(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want float64
+	}{
+		{d: 0, want: 0},
+		{d: 1500 * time.Nanosecond, want: 1.5},
+		{d: 1500 * time.Microsecond, want: 1500},
+	}
+	for _, tt := range tests {
+		if got := durationMicros(tt.d); got != tt.want {
+			t.Errorf("durationMicros(%v) = %v, want %v", tt.d, got, tt.want)
+		}
+	}
+}
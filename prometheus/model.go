@@ -1,45 +1,167 @@
 package prometheus
 
-import "github.com/prometheus/client_golang/prometheus"
+import (
+	"sync"
+	"time"
+
+	"github.com/piyushkumar96/app-monitoring/interfaces"
+
+	"github.com/gin-gonic/gin"
+	ae "github.com/piyushkumar96/app-error"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// The metrics types below are shared across goroutines and must stay safe for concurrent use.
+// The Prometheus vecs are safe on their own, and the configuration set by the constructors is only read
+// afterwards. State updated while recording, such as the burn rate window of the router metrics or the
+// failure streaks of the cron job metrics, must be guarded by a mutex held by the struct that owns it,
+// and that mutex must not be held while calling back into user code.
 
 // PromRouterMetrics holds the registered Prometheus metrics for router-level monitoring.
 // It implements interfaces.RouterMetricsInterface.
 type PromRouterMetrics struct {
-	httpRequests              *prometheus.CounterVec
-	httpRequestsLatencyMillis *prometheus.HistogramVec
-	httpRequestSizeBytes      *prometheus.HistogramVec
-	httpResponseSizeBytes     *prometheus.HistogramVec
+	idle                         bool
+	httpRequests                 *prometheus.CounterVec
+	httpRequestsLabels           []string
+	normalizeMethod              bool
+	versionExtractor             func(path string) string
+	routeGroupExtractor          func(path string) string
+	trackHandlerName             bool
+	clientBucketFn               func(gc *gin.Context) string
+	trackContentType             bool
+	callerHeader                 string
+	allowedCallers               map[string]struct{}
+	trackDraining                bool
+	trackPartialResponses        bool
+	requestIDKey                 any
+	extraLabelsKey               any
+	notFoundPolicy               string
+	skipPaths                    map[string]struct{}
+	acceptableStatuses           map[string]map[int]struct{}
+	httpRequestsLatencyMillis    *prometheus.HistogramVec
+	httpRequestsLatencyDigest    *TDigestVec
+	httpRequestsLatencyByProfile *bucketProfileVec
+	httpRequestsLatencyLabels    []string
+	httpTTFBMillis               *prometheus.HistogramVec
+	httpTTFBMillisLabels         []string
+	latencyClamp                 *latencyClamp
+	httpRequestSizeBytes         *prometheus.HistogramVec
+	httpResponseSizeBytes        *prometheus.HistogramVec
+	httpResponseUncompressedSize *prometheus.HistogramVec
+	httpResponseCompressionRatio *prometheus.HistogramVec
+	requestSizeClamp             *sizeClamp
+	responseSizeClamp            *sizeClamp
+	httpRequestBytesTotal        *prometheus.CounterVec
+	httpResponseBytesTotal       *prometheus.CounterVec
+	httpRequestsRejected         *prometheus.CounterVec
+	httpRequestsAborted          *prometheus.CounterVec
+	middlewareOverheadMicros     *prometheus.HistogramVec
+	errorBudgetBurnRate          *burnRateCollector
+	appErrors                    interface{ LogMetricsUnique(errCodes []string) }
+	now                          func() time.Time
 }
 
 // PromAppMetrics holds the registered Prometheus metrics for application-level monitoring.
 // It implements interfaces.AppMetricsInterface.
 type PromAppMetrics struct {
 	applicationErrorsCounter *prometheus.GaugeVec
+	distinctErrorCodes       *prometheus.GaugeVec
+	errorRatePerMin          *ewmaRateVec
+	errorCodeSanitizer       func(string) string
+
+	// activeErrorCodes holds the per-code counts backing distinctErrorCodes, guarded by mu.
+	mu               sync.Mutex
+	activeErrorCodes map[string]int
 }
 
 // PromDownstreamServiceMetrics holds the registered Prometheus metrics for downstream service monitoring.
 // It implements interfaces.DownstreamServiceMetricsInterface.
 type PromDownstreamServiceMetrics struct {
-	httpRequests              *prometheus.CounterVec
-	httpRequestsLatencyMillis *prometheus.HistogramVec
-	httpRequestSizeBytes      *prometheus.HistogramVec
-	httpResponseSizeBytes     *prometheus.HistogramVec
+	httpRequests                *prometheus.CounterVec
+	httpRequestsLabels          []string
+	httpRequestsLatencyMillis   *prometheus.HistogramVec
+	httpRequestsLatencyDigest   *TDigestVec
+	latencyClamp                *latencyClamp
+	httpRequestSizeBytes        *prometheus.HistogramVec
+	httpResponseSizeBytes       *prometheus.HistogramVec
+	lastCallTimestampSeconds    *prometheus.GaugeVec
+	lastSuccessTimestampSeconds *prometheus.GaugeVec
+	responseParseMillis         *prometheus.HistogramVec
+	upstreamLatencyMillis       *prometheus.HistogramVec
+	upstreamLatencyHeader       string
+	inFlight                    *prometheus.GaugeVec
+	batchItemsTotal             *prometheus.CounterVec
+	retryOutcomeTotal           *prometheus.CounterVec
+	retryAttempts               *prometheus.HistogramVec
+	pagesFetched                *prometheus.HistogramVec
+	dnsMillis                   *prometheus.HistogramVec
+	connectMillis               *prometheus.HistogramVec
+	tlsMillis                   *prometheus.HistogramVec
+	httpRequestsLatencyLabels   []string
+	trackAttempt                bool
+	serviceNameNormalizer       func(string) string
+	allowedServices             map[string]struct{}
+	allowedStatuses             map[string]struct{}
+	failurePredicate            func(*ae.AppError) bool
 }
 
 // PromDBMetrics holds the registered Prometheus metrics for database monitoring.
 // It implements interfaces.DBMetricsInterface.
 type PromDBMetrics struct {
-	operationsTotal         *prometheus.CounterVec
-	operationsLatencyMillis *prometheus.HistogramVec
+	operationsTotal               *prometheus.CounterVec
+	operationsTotalLabels         []string
+	operationsLatencyMillis       *prometheus.HistogramVec
+	operationsLatencyMillisLabels []string
+	operationsLatencyDigest       *TDigestVec
+	latencyClamp                  *latencyClamp
+	connWaitMillis                *prometheus.HistogramVec
+	connWaitMillisLabels          []string
+	rowsAffected                  *prometheus.HistogramVec
+	rowsAffectedLabels            []string
+	failurePredicate              func(*ae.AppError) bool
+	allowedOpTypes                map[string]struct{}
+	allowedStatuses               map[string]struct{}
 }
 
 // PromPSMetrics holds the registered Prometheus metrics for pub/sub monitoring.
 // It implements interfaces.PSMetricsInterface.
 type PromPSMetrics struct {
-	totalMessagesConsumed          *prometheus.CounterVec
-	totalMessagesPublished         *prometheus.CounterVec
-	messagesPublishedLatencyMillis *prometheus.HistogramVec
-	messagesPublishedSizeBytes     *prometheus.HistogramVec
+	totalMessagesConsumed                *prometheus.CounterVec
+	totalMessagesConsumedLabels          []string
+	totalMessagesPublished               *prometheus.CounterVec
+	totalMessagesPublishedLabels         []string
+	messagesPublishedLatencyMillis       *prometheus.HistogramVec
+	messagesPublishedLatencyDigest       *TDigestVec
+	messagesPublishedLatencyMillisLabels []string
+	latencyClamp                         *latencyClamp
+	messagesPublishedSizeBytes           *prometheus.HistogramVec
+	messagesPublishedSizeBytesLabels     []string
+	messagesPublishedWireBytes           *prometheus.HistogramVec
+	messagesPublishedWireBytesLabels     []string
+	publishConfirmLatencyMillis          *prometheus.HistogramVec
+	publishConfirmLatencyMillisLabels    []string
+	messagesConsumedLatencyMillis        *prometheus.HistogramVec
+	messagesConsumedLatencyMillisLabels  []string
+	messageQueueMillis                   *prometheus.HistogramVec
+	messageQueueMillisLabels             []string
+	messageProcessMillis                 *prometheus.HistogramVec
+	messageProcessMillisLabels           []string
+	publishSuccessRatio                  *successRatioVec
+	publisherQueueDepth                  *prometheus.GaugeVec
+	subscriptionState                    *prometheus.GaugeVec
+	publishConsumeRatio                  *prometheus.GaugeVec
+	allowedOpTypes                       map[string]struct{}
+	allowedStatuses                      map[string]struct{}
+
+	// publishConsumeCounts holds the per-entity counts backing publishConsumeRatio, guarded by mu.
+	mu                   sync.Mutex
+	publishConsumeCounts map[string]*publishConsumeCount
+}
+
+// publishConsumeCount holds the number of messages of an entity published successfully and consumed.
+type publishConsumeCount struct {
+	published float64
+	consumed  float64
 }
 
 // PromCronJobMetrics holds the registered Prometheus metrics for cron job monitoring.
@@ -47,4 +169,62 @@ type PromPSMetrics struct {
 type PromCronJobMetrics struct {
 	jobExecutionTotal         *prometheus.CounterVec
 	jobExecutionLatencyMillis *prometheus.HistogramVec
+	jobExecutionLatencyDigest *TDigestVec
+	jobExecutionLatencyByJob  *bucketProfileVec
+	latencyClamp              *latencyClamp
+	jobScheduleDriftMillis    *prometheus.HistogramVec
+	jobConsecutiveFailures    *prometheus.GaugeVec
+	jobLastRunSuccess         *prometheus.GaugeVec
+	jobOverBudget             *prometheus.CounterVec
+	jobsActive                *prometheus.GaugeVec
+	failurePredicate          func(*ae.AppError) bool
+	pushGatewayURL            string
+	pushGroupingLabels        map[string]string
+	allowedStatuses           map[string]struct{}
+
+	// consecutiveFailures holds the current failure streak of each job backing jobConsecutiveFailures, and
+	// budgets the time budget of each job set with RecordBudget, both guarded by mu.
+	mu                  sync.Mutex
+	consecutiveFailures map[string]int
+	budgets             map[string]time.Duration
+}
+
+// PromReadinessMetrics holds the registered Prometheus metrics for component readiness.
+// It implements interfaces.ReadinessMetricsInterface.
+type PromReadinessMetrics struct {
+	appReady *prometheus.GaugeVec
+}
+
+// PromOperationMetrics holds the registered Prometheus metrics for logical operation monitoring,
+// along with the metrics that dependency observations are routed to.
+// It implements interfaces.OperationMetricsInterface.
+type PromOperationMetrics struct {
+	operationDurationMillis  *prometheus.HistogramVec
+	dependencyDurationMillis *prometheus.HistogramVec
+	db                       interfaces.DBMetricsInterface
+	downstream               interfaces.DownstreamServiceMetricsInterface
+}
+
+// PromWorkerPoolMetrics holds the registered Prometheus metrics for worker pool monitoring.
+// It implements interfaces.WorkerPoolMetricsInterface.
+type PromWorkerPoolMetrics struct {
+	queueWaitMillis *prometheus.HistogramVec
+	execMillis      *prometheus.HistogramVec
+	tasksTotal      *prometheus.CounterVec
+	queueDepth      *prometheus.GaugeVec
+}
+
+// PromREDMetrics holds the registered Prometheus metrics of the RED method (Rate, Errors, Duration).
+// It implements interfaces.REDMetricsInterface.
+type PromREDMetrics struct {
+	requestsTotal         *prometheus.CounterVec
+	requestDurationMillis *prometheus.HistogramVec
+	durationMetric        string
+}
+
+// PromTxnMetrics holds the registered Prometheus metrics for database transaction monitoring.
+// It implements interfaces.TxnMetricsInterface.
+type PromTxnMetrics struct {
+	transactionsTotal         *prometheus.CounterVec
+	transactionDurationMillis *prometheus.HistogramVec
 }
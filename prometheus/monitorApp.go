@@ -1,41 +1,95 @@
 package prometheus
 
 import (
+	"errors"
+	"strings"
+	"time"
+
 	"github.com/piyushkumar96/app-monitoring/interfaces"
 	"github.com/piyushkumar96/app-monitoring/models"
 
+	ae "github.com/piyushkumar96/app-error"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+// defaultErrorRateWindow is the moving average time constant used when AppMetricsMeta.ErrorRateWindow is unset.
+const defaultErrorRateWindow = time.Minute
+
 // NewPromAppMetrics creates and registers Prometheus application-level metrics.
 // It initializes an ApplicationErrorsCounter gauge for tracking application errors by error code.
 //
 // The ApplicationErrorsCounter metric tracks the count of errors at the application level,
 // allowing you to monitor error rates and identify problematic error codes.
 //
+// Optionally, DistinctErrorCodes exposes the number of distinct error codes currently active,
+// a proxy for error diversity during incidents, and ErrorRatePerMin exposes a moving average of each
+// error code's rate that is ready to alert on without per-code rate() rules.
+//
 // Parameters:
 //   - meta: Configuration containing the namespace and metric settings.
 //     Set ApplicationErrorsCounter to nil to disable error tracking.
+//     A nil meta (e.g. an omitted config section) returns the NoOp implementation.
 //
 // Returns an interfaces.AppMetricsInterface instance that can be used to log and query error metrics.
 func NewPromAppMetrics(meta *models.AppMetricsMeta) interfaces.AppMetricsInterface {
-	var appErrorsCounter *prometheus.GaugeVec
+	if metricsDisabled() || meta == nil {
+		return NewNoOpPromAppMetrics()
+	}
+	meta = withEnabledMetrics(meta, meta.EnabledMetrics, appMetricFields)
+	recordDashboardHints(meta.Namespace, meta, appMetricFields)
+	recordSeriesRateLimits(meta.Namespace, meta, appMetricFields)
+
+	var appErrorsCounter, distinctErrorCodes *prometheus.GaugeVec
+	var errorRatePerMin *ewmaRateVec
 	if meta.ApplicationErrorsCounter != nil {
-		appErrorsCounter = GetPromGaugeVec(meta.Namespace, "application_errors_total", "Tracks the counts of app errors at application level", meta.ApplicationErrorsCounter.Labels)
+		appErrorsCounter = GetPromGaugeVec(meta.Namespace, "application_errors_total", metricHelp(meta.ApplicationErrorsCounter, "Tracks the counts of app errors at application level"), meta.ApplicationErrorsCounter.Labels)
+	}
+	if meta.DistinctErrorCodes != nil {
+		distinctErrorCodes = GetPromGaugeVec(meta.Namespace, "app_distinct_error_codes", metricHelp(meta.DistinctErrorCodes, "Tracks the number of distinct application error codes currently active"), nil)
+	}
+	if meta.ErrorRatePerMin != nil {
+		errorRateWindow := meta.ErrorRateWindow
+		if errorRateWindow <= 0 {
+			errorRateWindow = defaultErrorRateWindow
+		}
+		errorRatePerMin = newEWMARateVec(meta.Namespace, "app_error_rate_per_min", metricHelp(meta.ErrorRatePerMin, "Tracks the moving average rate of application errors per minute"), meta.ErrorRatePerMin.Labels, errorRateWindow)
 	}
 	return &PromAppMetrics{
 		applicationErrorsCounter: appErrorsCounter,
+		distinctErrorCodes:       distinctErrorCodes,
+		errorRatePerMin:          errorRatePerMin,
+		errorCodeSanitizer:       meta.ErrorCodeSanitizer,
+		activeErrorCodes:         make(map[string]int),
 	}
 }
 
 // LogMetrics increments the application error counter for each provided error code.
 // Call this method when application errors occur to track them in Prometheus.
+// Every element is counted, so a code repeated in errCodes is incremented once per occurrence;
+// use LogMetricsUnique to count each distinct code only once.
 func (cm *PromAppMetrics) LogMetrics(errCodes []string) {
+	errCodes = cm.sanitizeErrorCodes(errCodes)
 	if cm.applicationErrorsCounter != nil {
 		for _, errCode := range errCodes {
-			cm.applicationErrorsCounter.WithLabelValues(errCode).Inc()
+			gaugeWith(cm.applicationErrorsCounter, errCode).Inc()
+		}
+	}
+	cm.trackErrorCodes(errCodes, 1)
+	cm.trackErrorRates(errCodes)
+}
+
+// LogMetricsUnique increments the application error counter once for each distinct error code.
+// Unlike LogMetrics, repeated codes within the same call are counted only once, which avoids
+// double-counting when a single logical error aggregates codes that may repeat.
+func (cm *PromAppMetrics) LogMetricsUnique(errCodes []string) {
+	distinct := dedupErrorCodes(cm.sanitizeErrorCodes(errCodes))
+	if cm.applicationErrorsCounter != nil {
+		for _, errCode := range distinct {
+			gaugeWith(cm.applicationErrorsCounter, errCode).Inc()
 		}
 	}
+	cm.trackErrorCodes(distinct, 1)
+	cm.trackErrorRates(distinct)
 }
 
 // GetApplicationErrorsCounterMetric returns the underlying Prometheus GaugeVec
@@ -48,5 +102,184 @@ func (cm *PromAppMetrics) GetApplicationErrorsCounterMetric() *prometheus.GaugeV
 // DecrementAppErrorCount decrements the application error counter for a specific error code.
 // Use this when an error condition has been resolved or corrected.
 func (cm *PromAppMetrics) DecrementAppErrorCount(errCode string) {
-	cm.applicationErrorsCounter.WithLabelValues(errCode).Dec()
+	if cm.errorCodeSanitizer != nil {
+		errCode = cm.errorCodeSanitizer(errCode)
+	}
+	if cm.applicationErrorsCounter != nil {
+		gaugeWith(cm.applicationErrorsCounter, errCode).Dec()
+	}
+	cm.trackErrorCodes([]string{errCode}, -1)
+}
+
+// GetDistinctErrorCodesMetric returns the underlying Prometheus GaugeVec
+// for the distinct error codes gauge. This can be used for advanced operations.
+//
+// Returns nil if the metric was not configured during initialization.
+func (cm *PromAppMetrics) GetDistinctErrorCodesMetric() *prometheus.GaugeVec {
+	return cm.distinctErrorCodes
+}
+
+// GetErrorRatePerMinMetric returns the collector backing the error rate gauge.
+// It can be registered against additional registries or gathered directly.
+//
+// Returns nil if the metric was not configured during initialization.
+func (cm *PromAppMetrics) GetErrorRatePerMinMetric() prometheus.Collector {
+	if cm.errorRatePerMin == nil {
+		return nil
+	}
+	return cm.errorRatePerMin
+}
+
+// Collectors returns every collector registered by the application metrics, skipping the metrics that were not configured.
+func (cm *PromAppMetrics) Collectors() []prometheus.Collector {
+	var collectors []prometheus.Collector
+	if cm.applicationErrorsCounter != nil {
+		collectors = append(collectors, cm.applicationErrorsCounter)
+	}
+	if cm.distinctErrorCodes != nil {
+		collectors = append(collectors, cm.distinctErrorCodes)
+	}
+	if cm.errorRatePerMin != nil {
+		collectors = append(collectors, cm.errorRatePerMin)
+	}
+	return collectors
+}
+
+// sanitizeErrorCodes returns errCodes mapped through the configured ErrorCodeSanitizer, or errCodes
+// unchanged when none is set.
+func (cm *PromAppMetrics) sanitizeErrorCodes(errCodes []string) []string {
+	if cm.errorCodeSanitizer == nil {
+		return errCodes
+	}
+	sanitized := make([]string, len(errCodes))
+	for i, errCode := range errCodes {
+		sanitized[i] = cm.errorCodeSanitizer(errCode)
+	}
+	return sanitized
+}
+
+// DefaultErrorCodeSanitizer strips the dynamic data trailing an error code: numbers and UUIDs, along with
+// the separators ('_', '-', ':', '.') before them, e.g. "ERR_USER_12345" becomes "ERR_USER" and
+// "ERR_ORDER-550e8400-e29b-41d4-a716-446655440000" becomes "ERR_ORDER". Codes ending with a meaningful number
+// (e.g. "HTTP_404") lose it too, so use a custom sanitizer for those. A code made only of such data is
+// returned as is. It can be set as AppMetricsMeta.ErrorCodeSanitizer.
+func DefaultErrorCodeSanitizer(code string) string {
+	sanitized := code
+	for {
+		trimmed := strings.TrimRight(sanitized, errorCodeSeparators)
+		if len(trimmed) >= uuidLength && isUUID(trimmed[len(trimmed)-uuidLength:]) {
+			trimmed = trimmed[:len(trimmed)-uuidLength]
+		}
+		trimmed = strings.TrimRight(trimmed, "0123456789")
+		if trimmed == sanitized {
+			break
+		}
+		sanitized = trimmed
+	}
+	if sanitized == "" {
+		return code
+	}
+	return sanitized
+}
+
+// errorCodeSeparators are the characters separating the dynamic data of an error code from the code.
+const errorCodeSeparators = "_-:."
+
+// uuidLength is the length of a UUID in its canonical 8-4-4-4-12 hexadecimal form.
+const uuidLength = 36
+
+// isUUID reports whether s is a UUID in its canonical 8-4-4-4-12 hexadecimal form.
+func isUUID(s string) bool {
+	for i := 0; i < len(s); i++ {
+		switch i {
+		case 8, 13, 18, 23:
+			if s[i] != '-' {
+				return false
+			}
+		default:
+			if !strings.ContainsRune("0123456789abcdefABCDEF", rune(s[i])) {
+				return false
+			}
+		}
+	}
+	return len(s) == uuidLength
+}
+
+// trackErrorCodes adjusts the per-code counts by delta and updates the distinct error codes gauge
+// to the number of codes whose count is above zero. It does nothing when the gauge is disabled.
+func (cm *PromAppMetrics) trackErrorCodes(errCodes []string, delta int) {
+	if cm.distinctErrorCodes == nil {
+		return
+	}
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	for _, errCode := range errCodes {
+		count := cm.activeErrorCodes[errCode] + delta
+		if count > 0 {
+			cm.activeErrorCodes[errCode] = count
+		} else {
+			delete(cm.activeErrorCodes, errCode)
+		}
+	}
+	gaugeWith(cm.distinctErrorCodes).Set(float64(len(cm.activeErrorCodes)))
+}
+
+// trackErrorRates adds one increment per error code to the error rate moving averages.
+// It does nothing when the gauge is disabled.
+func (cm *PromAppMetrics) trackErrorRates(errCodes []string) {
+	if cm.errorRatePerMin == nil {
+		return
+	}
+	now := time.Now()
+	for _, errCode := range errCodes {
+		cm.errorRatePerMin.inc(now, errCode)
+	}
+}
+
+// ErrorCodesFromChain returns the error codes of appErr and of every AppError wrapped in its
+// underlying error chain (found with errors.As), outermost first and without duplicates.
+// Passing the result to LogMetrics records root-cause codes, not just the outermost one.
+//
+// Example:
+//
+//	appMetrics.LogMetrics(prom.ErrorCodesFromChain(appErr))
+//
+// Returns nil if appErr is nil.
+func ErrorCodesFromChain(appErr *ae.AppError) []string {
+	var errCodes []string
+	visited := make(map[*ae.AppError]struct{})
+	for appErr != nil {
+		if _, ok := visited[appErr]; ok {
+			break
+		}
+		visited[appErr] = struct{}{}
+		if appErr.CustomErr != nil && appErr.CustomErr.Code != "" {
+			errCodes = append(errCodes, appErr.CustomErr.Code)
+		}
+		errCodes = append(errCodes, appErr.ErrorCodes...)
+
+		var wrapped *ae.AppError
+		if !errors.As(appErr.ActualErr, &wrapped) {
+			break
+		}
+		appErr = wrapped
+	}
+	if errCodes == nil {
+		return nil
+	}
+	return dedupErrorCodes(errCodes)
+}
+
+// dedupErrorCodes returns the distinct error codes in errCodes, preserving their first-seen order.
+func dedupErrorCodes(errCodes []string) []string {
+	seen := make(map[string]struct{}, len(errCodes))
+	distinct := make([]string, 0, len(errCodes))
+	for _, errCode := range errCodes {
+		if _, ok := seen[errCode]; ok {
+			continue
+		}
+		seen[errCode] = struct{}{}
+		distinct = append(distinct, errCode)
+	}
+	return distinct
 }
@@ -0,0 +1,98 @@
+package prometheus
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/piyushkumar96/app-monitoring/constants"
+	"github.com/piyushkumar96/app-monitoring/models"
+
+	ae "github.com/piyushkumar96/app-error"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestErrorCodesFromChain(t *testing.T) {
+	root := &ae.AppError{
+		ActualErr:  errors.New("connection refused"),
+		CustomErr:  &ae.CustomErr{Code: "ERR_DB_CONN"},
+		ErrorCodes: []string{"ERR_DB_CONN", "ERR_DB"},
+	}
+	middle := &ae.AppError{
+		ActualErr: fmt.Errorf("loading user: %w", root),
+		CustomErr: &ae.CustomErr{Code: "ERR_USER_LOAD"},
+	}
+	outer := &ae.AppError{
+		ActualErr:  middle,
+		CustomErr:  &ae.CustomErr{Code: "ERR_CHECKOUT"},
+		ErrorCodes: []string{"ERR_CHECKOUT", "ERR_DB"},
+	}
+	cyclic := &ae.AppError{CustomErr: &ae.CustomErr{Code: "ERR_CYCLE"}}
+	cyclic.ActualErr = cyclic
+
+	tests := []struct {
+		name   string
+		appErr *ae.AppError
+		want   []string
+	}{
+		{name: "nil", appErr: nil, want: nil},
+		{name: "no codes", appErr: &ae.AppError{ActualErr: errors.New("boom")}, want: nil},
+		{name: "single", appErr: root, want: []string{"ERR_DB_CONN", "ERR_DB"}},
+		{name: "nested outermost first without duplicates", appErr: outer, want: []string{"ERR_CHECKOUT", "ERR_DB", "ERR_USER_LOAD", "ERR_DB_CONN"}},
+		{name: "cycle", appErr: cyclic, want: []string{"ERR_CYCLE"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ErrorCodesFromChain(tt.appErr); !slices.Equal(got, tt.want) {
+				t.Errorf("ErrorCodesFromChain() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefaultErrorCodeSanitizer(t *testing.T) {
+	tests := []struct {
+		code string
+		want string
+	}{
+		{code: "ERR_USER", want: "ERR_USER"},
+		{code: "ERR_USER_12345", want: "ERR_USER"},
+		{code: "ERR_USER-42", want: "ERR_USER"},
+		{code: "ERR_TIMEOUT:1700000000", want: "ERR_TIMEOUT"},
+		{code: "ERR_ORDER-550e8400-e29b-41d4-a716-446655440000", want: "ERR_ORDER"},
+		{code: "ERR_ORDER_550E8400-E29B-41D4-A716-446655440000", want: "ERR_ORDER"},
+		{code: "ERR_ORDER_550e8400-e29b-41d4-a716-446655440000_3", want: "ERR_ORDER"},
+		{code: "ERR_ORDER_deadbeef", want: "ERR_ORDER_deadbeef"},
+		{code: "HTTP_404", want: "HTTP"},
+		{code: "12345", want: "12345"},
+		{code: "550e8400-e29b-41d4-a716-446655440000", want: "550e8400-e29b-41d4-a716-446655440000"},
+		{code: "", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			if got := DefaultErrorCodeSanitizer(tt.code); got != tt.want {
+				t.Errorf("DefaultErrorCodeSanitizer(%q) = %q, want %q", tt.code, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogMetricsSanitizesErrorCodes(t *testing.T) {
+	useTestRegistry(t)
+	cm := NewPromAppMetrics(&models.AppMetricsMeta{
+		ApplicationErrorsCounter: &models.MetricMeta{Labels: []string{constants.LabelErrorCode}},
+		ErrorCodeSanitizer:       DefaultErrorCodeSanitizer,
+	}).(*PromAppMetrics)
+
+	cm.LogMetrics([]string{"ERR_USER_12345"})
+	cm.LogMetrics([]string{"ERR_USER_67890"})
+	cm.DecrementAppErrorCount("ERR_USER_12345")
+
+	if got := testutil.ToFloat64(cm.applicationErrorsCounter.WithLabelValues("ERR_USER")); got != 1 {
+		t.Errorf("errors with code ERR_USER = %v, want 1", got)
+	}
+	if got := testutil.CollectAndCount(cm.applicationErrorsCounter); got != 1 {
+		t.Errorf("error code series = %d, want 1", got)
+	}
+}
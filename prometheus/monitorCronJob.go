@@ -17,51 +17,265 @@ import (
 // The metrics track:
 //   - JobExecutionTotal: Counter for total/success/failure job executions
 //   - JobExecutionLatencyMillis: Histogram for job execution duration in milliseconds
+//   - JobScheduleDriftMillis: Histogram for job start delay relative to the schedule in milliseconds
+//   - JobConsecutiveFailures: Gauge for the number of consecutive failed executions of each job
+//   - JobLastRunSuccess: Gauge for whether the latest execution of each job succeeded (1) or failed (0)
+//   - JobOverBudget: Counter for executions that exceeded the time budget of their job
+//   - JobsActive: Gauge for the number of executions in progress across all jobs
+//
+// When meta.PushGatewayURL is set, the metrics of each job are also pushed to the Pushgateway after its runs.
 //
 // Parameters:
 //   - meta: Configuration containing the namespace and metric settings.
 //     Set individual metric configs to nil to disable them.
+//     A nil meta (e.g. an omitted config section) returns the NoOp implementation.
 //
 // Returns an interfaces.CronJobMetricsInterface instance that can be used to log job execution metrics.
 func NewPromCronJobMetrics(meta *models.CronJobMetricsMeta) interfaces.CronJobMetricsInterface {
-	var jobExecutionTotal *prometheus.CounterVec
-	var jobExecutionLatencyMillis *prometheus.HistogramVec
+	if metricsDisabled() || meta == nil {
+		return NewNoOpPromCronJobMetrics()
+	}
+	meta = withEnabledMetrics(meta, meta.EnabledMetrics, cronJobMetricFields)
+	recordDashboardHints(meta.Namespace, meta, cronJobMetricFields)
+	recordSeriesRateLimits(meta.Namespace, meta, cronJobMetricFields)
+	recordExtraLabels(meta.Namespace, meta, cronJobMetricFields)
+
+	var jobExecutionTotal, jobOverBudget *prometheus.CounterVec
+	var jobExecutionLatencyMillis, jobScheduleDriftMillis *prometheus.HistogramVec
+	var jobConsecutiveFailures, jobLastRunSuccess, jobsActive *prometheus.GaugeVec
+	var jobExecutionLatencyDigest *TDigestVec
+	var jobExecutionLatencyByJob *bucketProfileVec
+	var latencyClamp *latencyClamp
 
 	if meta.JobExecutionTotal != nil {
-		jobExecutionTotal = GetPromCounterVec(meta.Namespace, "cron_job_execution_count", "Number of times cron jobs executed for total/success/failure", meta.JobExecutionTotal.Labels)
+		labels := conventionalLabelOrder("cron_job_execution_count", meta.JobExecutionTotal.Labels, cronTotalLabelNames)
+		jobExecutionTotal = GetPromCounterVec(meta.Namespace, "cron_job_execution_count", metricHelp(meta.JobExecutionTotal, "Number of times cron jobs executed for total/success/failure"), labels)
 	}
 	if meta.JobExecutionLatencyMillis != nil {
-		jobExecutionLatencyMillis = GetPromHistogramVec(meta.Namespace, "cron_job_execution_latency_millis", "Tracks the latencies for cron jobs run", meta.JobExecutionLatencyMillis.Labels, meta.JobExecutionLatencyMillis.Buckets)
+		if len(meta.JobExecutionLatencyMillis.Quantiles) > 0 {
+			jobExecutionLatencyDigest = GetPromTDigestVecWithMaxAge(meta.Namespace, "cron_job_execution_latency_millis", metricHelp(meta.JobExecutionLatencyMillis, "Tracks the latencies of cron job executions"), meta.JobExecutionLatencyMillis.Labels, meta.JobExecutionLatencyMillis.Quantiles, meta.JobExecutionLatencyMillis.QuantilesMaxAge, meta.JobExecutionLatencyMillis.QuantilesAgeBuckets)
+		} else if len(meta.JobLatencyBuckets) > 0 && len(meta.JobExecutionLatencyMillis.Labels) > 0 {
+			jobLabel := meta.JobExecutionLatencyMillis.Labels[0]
+			jobExecutionLatencyByJob = newBucketProfileVec(meta.Namespace, "cron_job_execution_latency_millis", metricHelp(meta.JobExecutionLatencyMillis, "Tracks the latencies of cron job executions"), meta.JobExecutionLatencyMillis.Labels, metricBuckets(meta.JobExecutionLatencyMillis), meta.JobLatencyBuckets, func(labels map[string]string) string {
+				return labels[jobLabel]
+			})
+		} else {
+			jobExecutionLatencyMillis = newNonNegativeHistogramVec(meta.Namespace, "cron_job_execution_latency_millis", metricHelp(meta.JobExecutionLatencyMillis, "Tracks the latencies of cron job executions"), meta.JobExecutionLatencyMillis.Labels, metricBuckets(meta.JobExecutionLatencyMillis))
+		}
+		latencyClamp = newLatencyClamp(meta.Namespace, "cron_job_execution", meta.MaxLatencyMillis)
+	}
+	if meta.JobScheduleDriftMillis != nil {
+		jobScheduleDriftMillis = newNonNegativeHistogramVec(meta.Namespace, "cron_job_schedule_drift_millis", metricHelp(meta.JobScheduleDriftMillis, "Tracks how late cron jobs start relative to their schedule"), meta.JobScheduleDriftMillis.Labels, metricBuckets(meta.JobScheduleDriftMillis))
+	}
+	if meta.JobConsecutiveFailures != nil {
+		jobConsecutiveFailures = GetPromGaugeVec(meta.Namespace, "cron_job_consecutive_failures", metricHelp(meta.JobConsecutiveFailures, "Tracks the number of consecutive failed executions of cron jobs"), meta.JobConsecutiveFailures.Labels)
+	}
+	if meta.JobLastRunSuccess != nil {
+		jobLastRunSuccess = GetPromGaugeVec(meta.Namespace, "cron_job_last_run_success", metricHelp(meta.JobLastRunSuccess, "Tracks whether the latest execution of cron jobs succeeded (1) or failed (0)"), meta.JobLastRunSuccess.Labels)
+	}
+	if meta.JobOverBudget != nil {
+		jobOverBudget = GetPromCounterVec(meta.Namespace, "cron_job_over_budget_total", metricHelp(meta.JobOverBudget, "Number of cron job executions that took longer than the time budget of their job"), meta.JobOverBudget.Labels)
+	}
+	if meta.JobsActive != nil {
+		jobsActive = GetPromGaugeVec(meta.Namespace, "cron_jobs_active_total", metricHelp(meta.JobsActive, "Tracks the number of cron job executions in progress across all jobs"), nil)
 	}
 
 	return &PromCronJobMetrics{
 		jobExecutionTotal:         jobExecutionTotal,
 		jobExecutionLatencyMillis: jobExecutionLatencyMillis,
+		jobExecutionLatencyDigest: jobExecutionLatencyDigest,
+		jobExecutionLatencyByJob:  jobExecutionLatencyByJob,
+		latencyClamp:              latencyClamp,
+		jobScheduleDriftMillis:    jobScheduleDriftMillis,
+		jobConsecutiveFailures:    jobConsecutiveFailures,
+		jobLastRunSuccess:         jobLastRunSuccess,
+		jobOverBudget:             jobOverBudget,
+		jobsActive:                jobsActive,
+		failurePredicate:          meta.FailurePredicate,
+		allowedStatuses:           allowlist(meta.AllowedStatuses),
+		pushGatewayURL:            meta.PushGatewayURL,
+		pushGroupingLabels:        meta.PushGroupingLabels,
+		consecutiveFailures:       make(map[string]int),
+		budgets:                   make(map[string]time.Duration),
 	}
 }
 
 // LogMetricsPre should be called at the start of a cron job execution.
-// It increments the total execution counter and returns the start time for latency calculation.
+// It increments the total execution counter and the active executions, and returns the start time for latency calculation.
 func (cjm *PromCronJobMetrics) LogMetricsPre(cjMetricsLabelValues *models.CronJobMetricsLabelValues) time.Time {
-	if cjm.jobExecutionTotal != nil {
-		cjm.jobExecutionTotal.WithLabelValues(cjMetricsLabelValues.JobName, constants.Total).Inc()
+	cjm.logPre(cjMetricsLabelValues)
+	if cjm.jobsActive != nil {
+		gaugeWith(cjm.jobsActive).Inc()
 	}
 	return time.Now()
 }
 
 // LogMetricsPost should be called after a cron job execution completes.
-// It records the success/failure status and the execution latency.
+// It records the success/failure status and the execution latency, updates the consecutive failures of the job,
+// and decrements the active executions. Call it for every LogMetricsPre, also when the job panics (TrackCronJob
+// and BeginCronJob do), or the active executions stay raised.
 func (cjm *PromCronJobMetrics) LogMetricsPost(appErr *ae.AppError, cjMetricsLabelValues *models.CronJobMetricsLabelValues, opsExecTime time.Time) {
+	if cjm.jobsActive != nil {
+		gaugeWith(cjm.jobsActive).Dec()
+	}
+	cjm.logPost(appErr, cjMetricsLabelValues, time.Since(opsExecTime))
+}
+
+// LogMetricsPostErr should be called after a cron job execution completes, in place of LogMetricsPost,
+// by code returning a plain error rather than an *ae.AppError. The execution is recorded as LogMetricsPost
+// records it: a success if err is nil, else a failure unless FailurePredicate reports it as expected.
+// The predicate is passed the app error err wraps, or an app error with code UNKNOWN whose ActualErr is err.
+func (cjm *PromCronJobMetrics) LogMetricsPostErr(err error, cjMetricsLabelValues *models.CronJobMetricsLabelValues, opsExecTime time.Time) {
+	cjm.LogMetricsPost(errAppError(err), cjMetricsLabelValues, opsExecTime)
+}
+
+// ObserveLatency records a cron job execution whose duration was already measured elsewhere,
+// without the LogMetricsPre/LogMetricsPost pair. It increments the total and success/failure
+// counters and observes the duration into the latency histogram.
+func (cjm *PromCronJobMetrics) ObserveLatency(appErr *ae.AppError, cjMetricsLabelValues *models.CronJobMetricsLabelValues, duration time.Duration) {
+	cjm.logPre(cjMetricsLabelValues)
+	cjm.logPost(appErr, cjMetricsLabelValues, duration)
+}
+
+// ObserveWithStatus records a cron job execution whose duration was already measured elsewhere, as
+// ObserveLatency does, with a status supplied by the caller (e.g. "skipped" for a run that found nothing
+// to do) instead of one derived from an error. Only the success and failure statuses update the
+// consecutive failures and last run success of the job. Statuses outside AllowedStatuses are recorded as "other".
+func (cjm *PromCronJobMetrics) ObserveWithStatus(cjMetricsLabelValues *models.CronJobMetricsLabelValues, status string, duration time.Duration) {
+	cjm.logPre(cjMetricsLabelValues)
+	cjm.logStatus(cjMetricsLabelValues, allowedStatus(cjm.allowedStatuses, status, "cron_job_execution_count"), duration)
+}
+
+// RecordScheduleDrift should be called by the scheduler integration when a job starts.
+// It observes how late the job fired (actual - expected) into the schedule drift histogram,
+// which surfaces scheduler backpressure separately from job execution time.
+// Jobs that fire early are recorded as zero drift.
+//
+// Parameters:
+//   - jobName: The name of the cron job.
+//   - expected: The time the schedule intended the job to start.
+//   - actual: The time the job actually started.
+func (cjm *PromCronJobMetrics) RecordScheduleDrift(jobName string, expected, actual time.Time) {
+	if cjm.jobScheduleDriftMillis == nil {
+		return
+	}
+	drift := actual.Sub(expected)
+	if drift < 0 {
+		drift = 0
+	}
+	observe(cjm.jobScheduleDriftMillis, "cron_job_schedule_drift_millis", durationMillis(drift), jobName)
+}
+
+// RecordBudget sets the time budget of a job, typically the interval before its next fire, so that runs
+// taking longer than budget are counted in the over budget counter by LogMetricsPost and ObserveLatency.
+// Alerting on the counter flags jobs that can't keep up with their schedule. A budget that is not positive
+// removes the budget of the job.
+//
+// Example:
+//
+//	cronMetrics.RecordBudget("sync_orders", 5*time.Minute) // runs every 5 minutes
+func (cjm *PromCronJobMetrics) RecordBudget(jobName string, budget time.Duration) {
+	if cjm.jobOverBudget == nil {
+		return
+	}
+	cjm.mu.Lock()
+	defer cjm.mu.Unlock()
+	if budget <= 0 {
+		delete(cjm.budgets, jobName)
+		return
+	}
+	cjm.budgets[jobName] = budget
+}
+
+// logPre increments the total execution counter for one job run.
+func (cjm *PromCronJobMetrics) logPre(cjMetricsLabelValues *models.CronJobMetricsLabelValues) {
 	if cjm.jobExecutionTotal != nil {
-		if appErr != nil {
-			cjm.jobExecutionTotal.WithLabelValues(cjMetricsLabelValues.JobName, constants.Failure).Inc()
-		} else {
-			cjm.jobExecutionTotal.WithLabelValues(cjMetricsLabelValues.JobName, constants.Success).Inc()
-		}
+		counterWith(cjm.jobExecutionTotal, extraLabelValues(cjm.jobExecutionTotal, []string{cjMetricsLabelValues.JobName, constants.Total}, cjMetricsLabelValues.ExtraLabels)...).Inc()
+	}
+}
+
+// logPost records the success/failure status and latency of one completed job run.
+func (cjm *PromCronJobMetrics) logPost(appErr *ae.AppError, cjMetricsLabelValues *models.CronJobMetricsLabelValues, duration time.Duration) {
+	status := constants.Success
+	if isFailure(appErr, cjm.failurePredicate) {
+		status = constants.Failure
+	}
+	cjm.logStatus(cjMetricsLabelValues, status, duration)
+}
+
+// logStatus records the status and latency of one completed job run. The failure streak and last run
+// success of the job are only updated for the success and failure statuses.
+func (cjm *PromCronJobMetrics) logStatus(cjMetricsLabelValues *models.CronJobMetricsLabelValues, status string, duration time.Duration) {
+	failed := status == constants.Failure
+	outcome := failed || status == constants.Success
+	if cjm.jobExecutionTotal != nil {
+		counterWith(cjm.jobExecutionTotal, extraLabelValues(cjm.jobExecutionTotal, []string{cjMetricsLabelValues.JobName, status}, cjMetricsLabelValues.ExtraLabels)...).Inc()
 	}
 	if cjm.jobExecutionLatencyMillis != nil {
-		cjm.jobExecutionLatencyMillis.WithLabelValues(cjMetricsLabelValues.JobName).Observe(float64(time.Since(opsExecTime).Milliseconds()))
+		observe(cjm.jobExecutionLatencyMillis, "cron_job_execution_latency_millis", cjm.latencyClamp.clamp(durationMillis(duration)), extraLabelValues(cjm.jobExecutionLatencyMillis, []string{cjMetricsLabelValues.JobName}, cjMetricsLabelValues.ExtraLabels)...)
+	}
+	if cjm.jobExecutionLatencyDigest != nil {
+		observe(cjm.jobExecutionLatencyDigest, "cron_job_execution_latency_millis", cjm.latencyClamp.clamp(durationMillis(duration)), extraLabelValues(cjm.jobExecutionLatencyDigest, []string{cjMetricsLabelValues.JobName}, cjMetricsLabelValues.ExtraLabels)...)
+	}
+	if cjm.jobExecutionLatencyByJob != nil {
+		observe(cjm.jobExecutionLatencyByJob, "cron_job_execution_latency_millis", cjm.latencyClamp.clamp(durationMillis(duration)), extraLabelValues(cjm.jobExecutionLatencyByJob, []string{cjMetricsLabelValues.JobName}, cjMetricsLabelValues.ExtraLabels)...)
+	}
+	if cjm.jobConsecutiveFailures != nil && outcome {
+		cjm.recordConsecutiveFailures(cjMetricsLabelValues.JobName, failed)
+	}
+	if cjm.jobLastRunSuccess != nil && outcome {
+		lastRunSuccess := 1.0
+		if failed {
+			lastRunSuccess = 0
+		}
+		gaugeWith(cjm.jobLastRunSuccess, extraLabelValues(cjm.jobLastRunSuccess, []string{cjMetricsLabelValues.JobName}, cjMetricsLabelValues.ExtraLabels)...).Set(lastRunSuccess)
+	}
+	if cjm.jobOverBudget != nil && cjm.overBudget(cjMetricsLabelValues.JobName, duration) {
+		counterWith(cjm.jobOverBudget, extraLabelValues(cjm.jobOverBudget, []string{cjMetricsLabelValues.JobName}, cjMetricsLabelValues.ExtraLabels)...).Inc()
 	}
+	cjm.push(cjMetricsLabelValues.JobName)
+}
+
+// push pushes the series of jobName, and the series of no job, to the Pushgateway when one is configured.
+// Failures are logged, so a run is never failed by its metrics. The placeholder job of SelfTest is not pushed.
+func (cjm *PromCronJobMetrics) push(jobName string) {
+	if cjm.pushGatewayURL == "" || jobName == selfTestLabelValue {
+		return
+	}
+	registry := prometheus.NewRegistry()
+	for _, collector := range cjm.Collectors() {
+		if err := registry.Register(collector); err != nil {
+			logger().Error("failed to register cron job metrics for push", "code", "OnCronJobMetricsPushFailure", "job", jobName, "err", err.Error())
+			return
+		}
+	}
+	gatherer := labelGatherer{Gatherer: registry, name: constants.LabelJobName, value: jobName}
+	if err := Push(cjm.pushGatewayURL, jobName, cjm.pushGroupingLabels, gatherer); err != nil {
+		logger().Error("failed to push cron job metrics", "code", "OnCronJobMetricsPushFailure", "job", jobName, "err", err.Error())
+	}
+}
+
+// overBudget reports whether a run of jobName that took duration exceeded the budget of the job.
+// Jobs without a budget are never over budget.
+func (cjm *PromCronJobMetrics) overBudget(jobName string, duration time.Duration) bool {
+	cjm.mu.Lock()
+	defer cjm.mu.Unlock()
+	budget, ok := cjm.budgets[jobName]
+	return ok && duration > budget
+}
+
+// recordConsecutiveFailures extends the failure streak of a job when failed, resets it otherwise,
+// and sets the consecutive failures gauge to the streak. Alerting on the gauge is simpler than
+// deriving streaks from the execution counters in PromQL.
+func (cjm *PromCronJobMetrics) recordConsecutiveFailures(jobName string, failed bool) {
+	cjm.mu.Lock()
+	defer cjm.mu.Unlock()
+	streak := 0
+	if failed {
+		streak = cjm.consecutiveFailures[jobName] + 1
+	}
+	cjm.consecutiveFailures[jobName] = streak
+	gaugeWith(cjm.jobConsecutiveFailures, jobName).Set(float64(streak))
 }
 
 // GetJobExecutionTotalMetric returns the underlying Prometheus CounterVec
@@ -75,3 +289,100 @@ func (cjm *PromCronJobMetrics) GetJobExecutionTotalMetric() *prometheus.CounterV
 func (cjm *PromCronJobMetrics) GetJobExecutionLatencyMillisMetric() *prometheus.HistogramVec {
 	return cjm.jobExecutionLatencyMillis
 }
+
+// GetJobExecutionLatencyDigestMetric returns the underlying TDigestVec
+// for the job execution latency, when it is backed by a t-digest. This can be used for advanced operations.
+//
+// Returns nil if the metric was not configured with quantiles during initialization.
+func (cjm *PromCronJobMetrics) GetJobExecutionLatencyDigestMetric() *TDigestVec {
+	return cjm.jobExecutionLatencyDigest
+}
+
+// GetJobExecutionLatencyByJobMetric returns the HistogramVec of each bucket profile of the job execution
+// latency, keyed by job name (and "default" for the jobs without their own buckets).
+//
+// Returns nil if the metric was not configured with JobLatencyBuckets during initialization.
+func (cjm *PromCronJobMetrics) GetJobExecutionLatencyByJobMetric() map[string]*prometheus.HistogramVec {
+	if cjm.jobExecutionLatencyByJob == nil {
+		return nil
+	}
+	vecs := make(map[string]*prometheus.HistogramVec, len(cjm.jobExecutionLatencyByJob.profiles)+1)
+	vecs[constants.BucketProfileDefault] = cjm.jobExecutionLatencyByJob.defaultVec
+	for profile, vec := range cjm.jobExecutionLatencyByJob.profiles {
+		vecs[profile] = vec
+	}
+	return vecs
+}
+
+// GetJobScheduleDriftMillisMetric returns the underlying Prometheus HistogramVec
+// for the job schedule drift. This can be used for advanced operations.
+func (cjm *PromCronJobMetrics) GetJobScheduleDriftMillisMetric() *prometheus.HistogramVec {
+	return cjm.jobScheduleDriftMillis
+}
+
+// GetJobConsecutiveFailuresMetric returns the underlying Prometheus GaugeVec
+// for the consecutive failures of each job. This can be used for advanced operations.
+//
+// Returns nil if the metric was not configured during initialization.
+func (cjm *PromCronJobMetrics) GetJobConsecutiveFailuresMetric() *prometheus.GaugeVec {
+	return cjm.jobConsecutiveFailures
+}
+
+// GetJobLastRunSuccessMetric returns the underlying Prometheus GaugeVec
+// for the outcome of the latest run of each job. This can be used for advanced operations.
+//
+// Returns nil if the metric was not configured during initialization.
+func (cjm *PromCronJobMetrics) GetJobLastRunSuccessMetric() *prometheus.GaugeVec {
+	return cjm.jobLastRunSuccess
+}
+
+// GetJobOverBudgetMetric returns the underlying Prometheus CounterVec
+// for the executions that exceeded the budget of their job. This can be used for advanced operations.
+//
+// Returns nil if the metric was not configured during initialization.
+func (cjm *PromCronJobMetrics) GetJobOverBudgetMetric() *prometheus.CounterVec {
+	return cjm.jobOverBudget
+}
+
+// GetJobsActiveMetric returns the underlying Prometheus GaugeVec
+// for the executions in progress across all jobs. This can be used for advanced operations.
+//
+// Returns nil if the metric was not configured during initialization.
+func (cjm *PromCronJobMetrics) GetJobsActiveMetric() *prometheus.GaugeVec {
+	return cjm.jobsActive
+}
+
+// Collectors returns every collector registered by the cron job metrics, skipping the metrics that were not configured.
+func (cjm *PromCronJobMetrics) Collectors() []prometheus.Collector {
+	var collectors []prometheus.Collector
+	if cjm.jobExecutionTotal != nil {
+		collectors = append(collectors, cjm.jobExecutionTotal)
+	}
+	if cjm.jobExecutionLatencyMillis != nil {
+		collectors = append(collectors, cjm.jobExecutionLatencyMillis)
+	}
+	if cjm.jobExecutionLatencyDigest != nil {
+		collectors = append(collectors, cjm.jobExecutionLatencyDigest)
+	}
+	if cjm.jobExecutionLatencyByJob != nil {
+		for _, vec := range cjm.jobExecutionLatencyByJob.vecs() {
+			collectors = append(collectors, vec)
+		}
+	}
+	if cjm.jobScheduleDriftMillis != nil {
+		collectors = append(collectors, cjm.jobScheduleDriftMillis)
+	}
+	if cjm.jobConsecutiveFailures != nil {
+		collectors = append(collectors, cjm.jobConsecutiveFailures)
+	}
+	if cjm.jobLastRunSuccess != nil {
+		collectors = append(collectors, cjm.jobLastRunSuccess)
+	}
+	if cjm.jobOverBudget != nil {
+		collectors = append(collectors, cjm.jobOverBudget)
+	}
+	if cjm.jobsActive != nil {
+		collectors = append(collectors, cjm.jobsActive)
+	}
+	return collectors
+}
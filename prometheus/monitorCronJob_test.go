@@ -0,0 +1,40 @@
+package prometheus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/piyushkumar96/app-monitoring/constants"
+	"github.com/piyushkumar96/app-monitoring/models"
+)
+
+func TestCronJobMetricsHelpTexts(t *testing.T) {
+	registry := useTestRegistry(t)
+	jobLabels := []string{constants.LabelJobName}
+	cjm := NewPromCronJobMetrics(&models.CronJobMetricsMeta{
+		Namespace:                 "test",
+		JobExecutionTotal:         &models.MetricMeta{Labels: cronTotalLabelNames},
+		JobExecutionLatencyMillis: &models.MetricMeta{Labels: jobLabels},
+		JobScheduleDriftMillis:    &models.MetricMeta{Labels: jobLabels},
+		JobConsecutiveFailures:    &models.MetricMeta{Labels: jobLabels},
+		JobLastRunSuccess:         &models.MetricMeta{Labels: jobLabels},
+		JobOverBudget:             &models.MetricMeta{Labels: jobLabels},
+		JobsActive:                &models.MetricMeta{},
+	})
+	labelValues := &models.CronJobMetricsLabelValues{JobName: "sync_orders"}
+	now := time.Now()
+	cjm.RecordBudget(labelValues.JobName, time.Millisecond)
+	cjm.RecordScheduleDrift(labelValues.JobName, now, now.Add(time.Second))
+	cjm.LogMetricsPre(labelValues)
+	cjm.ObserveLatency(testAppError("ERR_SYNC"), labelValues, time.Second)
+
+	assertHelpTexts(t, registry, map[string]string{
+		"test_cron_job_execution_count":          "Number of times cron jobs executed for total/success/failure",
+		"test_cron_job_execution_latency_millis": "Tracks the latencies of cron job executions",
+		"test_cron_job_schedule_drift_millis":    "Tracks how late cron jobs start relative to their schedule",
+		"test_cron_job_consecutive_failures":     "Tracks the number of consecutive failed executions of cron jobs",
+		"test_cron_job_last_run_success":         "Tracks whether the latest execution of cron jobs succeeded (1) or failed (0)",
+		"test_cron_job_over_budget_total":        "Number of cron job executions that took longer than the time budget of their job",
+		"test_cron_jobs_active_total":            "Tracks the number of cron job executions in progress across all jobs",
+	})
+}
@@ -17,10 +17,13 @@ import (
 // The metrics track:
 //   - OperationsTotal: Counter for total/success/failure database operations
 //   - OperationsLatencyMillis: Histogram for operation duration in milliseconds
+//   - ConnWaitMillis: Histogram for time spent acquiring a pooled connection in milliseconds
+//   - RowsAffected: Histogram for the rows affected by write operations
 //
 // Parameters:
 //   - meta: Configuration containing the namespace and metric settings.
 //     Set individual metric configs to nil to disable them.
+//     A nil meta (e.g. an omitted config section) returns the NoOp implementation.
 //
 // Returns an interfaces.DBMetricsInterface instance that can be used to log database operation metrics.
 //
@@ -37,19 +40,60 @@ import (
 //	    },
 //	})
 func NewPromDatabaseMetrics(meta *models.DBMetricsMeta) interfaces.DBMetricsInterface {
+	if metricsDisabled() || meta == nil {
+		return NewNoOpPromDBMetrics()
+	}
+	meta = withEnabledMetrics(meta, meta.EnabledMetrics, dbMetricFields)
+	recordDashboardHints(meta.Namespace, meta, dbMetricFields)
+	recordSeriesRateLimits(meta.Namespace, meta, dbMetricFields)
+	recordExtraLabels(meta.Namespace, meta, dbMetricFields)
+
 	var operationsTotal *prometheus.CounterVec
-	var operationsLatencyMillis *prometheus.HistogramVec
+	var operationsLatencyMillis, connWaitMillis, rowsAffected *prometheus.HistogramVec
+	var operationsLatencyDigest *TDigestVec
+	var operationsTotalLabels, operationsLatencyMillisLabels, connWaitMillisLabels, rowsAffectedLabels []string
+	var latencyClamp *latencyClamp
 
 	if meta.OperationsTotal != nil {
-		operationsTotal = GetPromCounterVec(meta.Namespace, "db_operations", "Number of times DB operations executed for total/success/failure", meta.OperationsTotal.Labels)
+		labels := conventionalLabelOrder("db_operations", meta.OperationsTotal.Labels, dbTotalLabelNames)
+		operationsTotal = GetPromCounterVec(meta.Namespace, "db_operations", metricHelp(meta.OperationsTotal, "Number of times DB operations executed for total/success/failure"), labels)
+		operationsTotalLabels = labels
 	}
 	if meta.OperationsLatencyMillis != nil {
-		operationsLatencyMillis = GetPromHistogramVec(meta.Namespace, "db_operations_latency_millis", "Tracks the latencies for database operations", meta.OperationsLatencyMillis.Labels, meta.OperationsLatencyMillis.Buckets)
+		labels := conventionalLabelOrder("db_operations_latency_millis", meta.OperationsLatencyMillis.Labels, dbLatencyLabelNames)
+		if len(meta.OperationsLatencyMillis.Quantiles) > 0 {
+			operationsLatencyDigest = GetPromTDigestVecWithMaxAge(meta.Namespace, "db_operations_latency_millis", metricHelp(meta.OperationsLatencyMillis, "Tracks the latencies for database operations"), labels, meta.OperationsLatencyMillis.Quantiles, meta.OperationsLatencyMillis.QuantilesMaxAge, meta.OperationsLatencyMillis.QuantilesAgeBuckets)
+		} else {
+			operationsLatencyMillis = newNonNegativeHistogramVec(meta.Namespace, "db_operations_latency_millis", metricHelp(meta.OperationsLatencyMillis, "Tracks the latencies for database operations"), labels, metricBuckets(meta.OperationsLatencyMillis))
+		}
+		operationsLatencyMillisLabels = labels
+		latencyClamp = newLatencyClamp(meta.Namespace, "db_operations", meta.MaxLatencyMillis)
+	}
+	if meta.ConnWaitMillis != nil {
+		labels := conventionalLabelOrder("db_conn_wait_millis", meta.ConnWaitMillis.Labels, dbLatencyLabelNames)
+		connWaitMillis = newNonNegativeHistogramVec(meta.Namespace, "db_conn_wait_millis", metricHelp(meta.ConnWaitMillis, "Tracks the time spent waiting to acquire a pooled database connection"), labels, metricBuckets(meta.ConnWaitMillis))
+		connWaitMillisLabels = labels
+	}
+	if meta.RowsAffected != nil {
+		labels := conventionalLabelOrder("db_rows_affected", meta.RowsAffected.Labels, dbLatencyLabelNames)
+		rowsAffected = newNonNegativeHistogramVec(meta.Namespace, "db_rows_affected", metricHelp(meta.RowsAffected, "Tracks the number of rows affected by database write operations"), labels, metricBuckets(meta.RowsAffected))
+		rowsAffectedLabels = labels
 	}
 
 	return &PromDBMetrics{
-		operationsTotal:         operationsTotal,
-		operationsLatencyMillis: operationsLatencyMillis,
+		operationsTotal:               operationsTotal,
+		operationsTotalLabels:         operationsTotalLabels,
+		operationsLatencyMillis:       operationsLatencyMillis,
+		operationsLatencyDigest:       operationsLatencyDigest,
+		operationsLatencyMillisLabels: operationsLatencyMillisLabels,
+		latencyClamp:                  latencyClamp,
+		connWaitMillis:                connWaitMillis,
+		connWaitMillisLabels:          connWaitMillisLabels,
+		rowsAffected:                  rowsAffected,
+		rowsAffectedLabels:            rowsAffectedLabels,
+		failurePredicate:              meta.FailurePredicate,
+		allowedOpTypes:                allowlist(meta.AllowedOpTypes),
+		allowedStatuses:               allowlist(meta.AllowedStatuses),
 	}
 }
 
@@ -61,29 +105,175 @@ func NewPromDatabaseMetrics(meta *models.DBMetricsMeta) interfaces.DBMetricsInte
 //
 // Returns the start time to be passed to LogMetricsPost for latency calculation.
 func (dm *PromDBMetrics) LogMetricsPre(dbMetricsLabelValues *models.DBMetricsLabelValues) time.Time {
-	if dm.operationsTotal != nil {
-		dm.operationsTotal.WithLabelValues(string(dbMetricsLabelValues.OpType), string(dbMetricsLabelValues.Source), string(dbMetricsLabelValues.AdEntity), dbMetricsLabelValues.IsTxn, constants.Total).Inc()
-	}
+	dm.logPre(dbMetricsLabelValues)
 	return time.Now()
 }
 
+// LogMetricsPreWithAcquire should be called once a pooled connection was acquired, right before
+// executing the database operation. It observes the time spent acquiring the connection since
+// acquireStart into the connection wait histogram, then does what LogMetricsPre does, so pool
+// contention is kept apart from the operation latency.
+//
+// Parameters:
+//   - dbMetricsLabelValues: Label values containing operation details (type, source, entity, transaction flag).
+//   - acquireStart: The time the connection was requested from the pool.
+//
+// Returns the start time to be passed to LogMetricsPost for latency calculation.
+//
+// Example:
+//
+//	acquireStart := time.Now()
+//	conn, err := pool.Acquire(ctx)
+//	start := dbMetrics.LogMetricsPreWithAcquire(labelValues, acquireStart)
+//	err = conn.QueryRow(ctx, query).Scan(&row)
+//	dbMetrics.LogMetricsPost(appErr, labelValues, start)
+func (dm *PromDBMetrics) LogMetricsPreWithAcquire(dbMetricsLabelValues *models.DBMetricsLabelValues, acquireStart time.Time) time.Time {
+	if dm.connWaitMillis != nil {
+		dbMetricsLabelValues := dm.withAllowedOpType(dbMetricsLabelValues, "")
+		observe(dm.connWaitMillis, "db_conn_wait_millis", durationMillis(time.Since(acquireStart)),
+			extraLabelValues(dm.connWaitMillis, withOptionalLabels(dm.connWaitMillisLabels,
+				[]string{dbMetricsLabelValues.OpType, dbMetricsLabelValues.Source, dbMetricsLabelValues.AdEntity, dbMetricsLabelValues.IsTxn},
+				dm.optionalLabels(dbMetricsLabelValues)...), dbMetricsLabelValues.ExtraLabels)...)
+	}
+	return dm.LogMetricsPre(dbMetricsLabelValues)
+}
+
 // LogMetricsPost should be called after a database operation completes.
 // It records the success/failure status and the operation latency.
 //
 // Parameters:
-//   - appErr: The error returned by the operation (nil for success, non-nil for failure).
+//   - appErr: The error returned by the operation (nil for success, non-nil for failure unless FailurePredicate reports it as expected).
 //   - dbMetricsLabelValues: Label values containing operation details.
 //   - opsExecTime: The start time returned by LogMetricsPre.
 func (dm *PromDBMetrics) LogMetricsPost(appErr *ae.AppError, dbMetricsLabelValues *models.DBMetricsLabelValues, opsExecTime time.Time) {
+	dm.logPost(appErr, dbMetricsLabelValues, time.Since(opsExecTime))
+}
+
+// LogMetricsPostErr should be called after a database operation completes, in place of LogMetricsPost,
+// by code returning a plain error rather than an *ae.AppError. The operation is recorded as LogMetricsPost
+// records it: a success if err is nil, else a failure unless FailurePredicate reports it as expected.
+// The predicate is passed the app error err wraps, or an app error with code UNKNOWN whose ActualErr is err.
+func (dm *PromDBMetrics) LogMetricsPostErr(err error, dbMetricsLabelValues *models.DBMetricsLabelValues, opsExecTime time.Time) {
+	dm.logPost(errAppError(err), dbMetricsLabelValues, time.Since(opsExecTime))
+}
+
+// ObserveLatency records a database operation whose duration was already measured elsewhere
+// (e.g. by a tracing span), without the LogMetricsPre/LogMetricsPost pair.
+// It increments the total and success/failure counters and observes the duration into the latency histogram.
+//
+// Parameters:
+//   - appErr: The error returned by the operation (nil for success, non-nil for failure unless FailurePredicate reports it as expected).
+//   - dbMetricsLabelValues: Label values containing operation details.
+//   - duration: The measured duration of the operation.
+func (dm *PromDBMetrics) ObserveLatency(appErr *ae.AppError, dbMetricsLabelValues *models.DBMetricsLabelValues, duration time.Duration) {
+	dm.logPre(dbMetricsLabelValues)
+	dm.logPost(appErr, dbMetricsLabelValues, duration)
+}
+
+// ObserveWithStatus records a database operation whose duration was already measured elsewhere, as
+// ObserveLatency does, with a status supplied by the caller (e.g. "cached") instead of one derived from
+// an error. It increments the total counter and the counter with the status, and observes the duration
+// into the latency histogram. Statuses outside AllowedStatuses are recorded as "other".
+//
+// Example:
+//
+//	if row, ok := cache.Get(key); ok {
+//	    dbMetrics.ObserveWithStatus(labelValues, "cached", time.Since(start))
+//	    return row, nil
+//	}
+func (dm *PromDBMetrics) ObserveWithStatus(dbMetricsLabelValues *models.DBMetricsLabelValues, status string, duration time.Duration) {
+	dm.logPre(dbMetricsLabelValues)
+	dbMetricsLabelValues = dm.withAllowedOpType(dbMetricsLabelValues, "db_operations")
+	if dm.operationsTotal != nil {
+		status = allowedStatus(dm.allowedStatuses, status, "db_operations")
+		counterWith(dm.operationsTotal, dm.totalLabelValues(dbMetricsLabelValues, status)...).Inc()
+	}
+	dm.observeLatency(dbMetricsLabelValues, duration)
+}
+
+// logPre increments the total operations counter for one operation.
+func (dm *PromDBMetrics) logPre(dbMetricsLabelValues *models.DBMetricsLabelValues) {
+	dbMetricsLabelValues = dm.withAllowedOpType(dbMetricsLabelValues, "")
+	if dm.operationsTotal != nil {
+		counterWith(dm.operationsTotal, dm.totalLabelValues(dbMetricsLabelValues, constants.Total)...).Inc()
+	}
+}
+
+// logPost records the success/failure status and latency of one completed operation.
+func (dm *PromDBMetrics) logPost(appErr *ae.AppError, dbMetricsLabelValues *models.DBMetricsLabelValues, duration time.Duration) {
+	dbMetricsLabelValues = dm.withAllowedOpType(dbMetricsLabelValues, "db_operations")
 	if dm.operationsTotal != nil {
-		if appErr != nil {
-			dm.operationsTotal.WithLabelValues(string(dbMetricsLabelValues.OpType), string(dbMetricsLabelValues.Source), dbMetricsLabelValues.AdEntity, dbMetricsLabelValues.IsTxn, constants.Failure).Inc()
+		if isFailure(appErr, dm.failurePredicate) {
+			incWithExemplar(counterWith(dm.operationsTotal, dm.totalLabelValues(dbMetricsLabelValues, constants.Failure)...), requestIDExemplar(dbMetricsLabelValues.RequestID))
 		} else {
-			dm.operationsTotal.WithLabelValues(string(dbMetricsLabelValues.OpType), string(dbMetricsLabelValues.Source), dbMetricsLabelValues.AdEntity, dbMetricsLabelValues.IsTxn, constants.Success).Inc()
+			counterWith(dm.operationsTotal, dm.totalLabelValues(dbMetricsLabelValues, constants.Success)...).Inc()
 		}
 	}
+	dm.observeLatency(dbMetricsLabelValues, duration)
+}
+
+// observeLatency observes the duration of one completed operation into the latency histograms.
+func (dm *PromDBMetrics) observeLatency(dbMetricsLabelValues *models.DBMetricsLabelValues, duration time.Duration) {
 	if dm.operationsLatencyMillis != nil {
-		dm.operationsLatencyMillis.WithLabelValues(string(dbMetricsLabelValues.OpType), string(dbMetricsLabelValues.Source), dbMetricsLabelValues.AdEntity, dbMetricsLabelValues.IsTxn).Observe(float64(time.Since(opsExecTime).Milliseconds()))
+		observe(dm.operationsLatencyMillis, "db_operations_latency_millis", dm.latencyClamp.clamp(durationMillis(duration)), dm.latencyLabelValues(dm.operationsLatencyMillis, dbMetricsLabelValues)...)
+	}
+	if dm.operationsLatencyDigest != nil {
+		observe(dm.operationsLatencyDigest, "db_operations_latency_millis", dm.latencyClamp.clamp(durationMillis(duration)), dm.latencyLabelValues(dm.operationsLatencyDigest, dbMetricsLabelValues)...)
+	}
+}
+
+// withAllowedOpType returns dbMetricsLabelValues, or a copy with its op type folded into constants.OpTypeOther
+// when it is outside AllowedOpTypes. A fold is counted against metric unless metric is empty, so that an
+// operation logged with a pre and a post call is counted once, by the post.
+func (dm *PromDBMetrics) withAllowedOpType(dbMetricsLabelValues *models.DBMetricsLabelValues, metric string) *models.DBMetricsLabelValues {
+	opType, folded := allowedOpType(dm.allowedOpTypes, dbMetricsLabelValues.OpType)
+	if !folded {
+		return dbMetricsLabelValues
+	}
+	if metric != "" {
+		recordCardinalityProtection(metric, constants.ReasonOpTypeFolded)
+	}
+	labelValues := *dbMetricsLabelValues
+	labelValues.OpType = opType
+	return &labelValues
+}
+
+// RecordRowsAffected records the number of rows a completed write operation affected, e.g. from
+// sql.Result.RowsAffected, so that bulk updates touching far more (or fewer) rows than expected stand out.
+// interfaces.InstrumentExec records it already; call it when the operation is recorded otherwise.
+func (dm *PromDBMetrics) RecordRowsAffected(dbMetricsLabelValues *models.DBMetricsLabelValues, rows int64) {
+	if dm.rowsAffected == nil {
+		return
+	}
+	dbMetricsLabelValues = dm.withAllowedOpType(dbMetricsLabelValues, "")
+	observe(dm.rowsAffected, "db_rows_affected", float64(rows),
+		extraLabelValues(dm.rowsAffected, withOptionalLabels(dm.rowsAffectedLabels,
+			[]string{dbMetricsLabelValues.OpType, dbMetricsLabelValues.Source, dbMetricsLabelValues.AdEntity, dbMetricsLabelValues.IsTxn},
+			dm.optionalLabels(dbMetricsLabelValues)...), dbMetricsLabelValues.ExtraLabels)...)
+}
+
+// totalLabelValues returns the label values for the operations counter with the given status,
+// including the optional and extra labels declared in its configured labels.
+func (dm *PromDBMetrics) totalLabelValues(dbMetricsLabelValues *models.DBMetricsLabelValues, status string) []string {
+	return extraLabelValues(dm.operationsTotal, withOptionalLabels(dm.operationsTotalLabels,
+		[]string{dbMetricsLabelValues.OpType, dbMetricsLabelValues.Source, dbMetricsLabelValues.AdEntity, dbMetricsLabelValues.IsTxn, status},
+		dm.optionalLabels(dbMetricsLabelValues)...), dbMetricsLabelValues.ExtraLabels)
+}
+
+// latencyLabelValues returns the label values for the operations latency histogram or digest vec,
+// including the optional and extra labels declared in its configured labels.
+func (dm *PromDBMetrics) latencyLabelValues(vec any, dbMetricsLabelValues *models.DBMetricsLabelValues) []string {
+	return extraLabelValues(vec, withOptionalLabels(dm.operationsLatencyMillisLabels,
+		[]string{dbMetricsLabelValues.OpType, dbMetricsLabelValues.Source, dbMetricsLabelValues.AdEntity, dbMetricsLabelValues.IsTxn},
+		dm.optionalLabels(dbMetricsLabelValues)...), dbMetricsLabelValues.ExtraLabels)
+}
+
+// optionalLabels returns the optional labels supported by the database metrics.
+func (dm *PromDBMetrics) optionalLabels(dbMetricsLabelValues *models.DBMetricsLabelValues) []optionalLabel {
+	return []optionalLabel{
+		{name: constants.LabelStatement, value: dbMetricsLabelValues.Statement},
+		{name: constants.LabelShard, value: dbMetricsLabelValues.Shard},
+		{name: constants.LabelTarget, value: dbMetricsLabelValues.Target},
 	}
 }
 
@@ -102,3 +292,48 @@ func (dm *PromDBMetrics) GetOperationsTotalMetric() *prometheus.CounterVec {
 func (dm *PromDBMetrics) GetOperationsLatencyMillisMetric() *prometheus.HistogramVec {
 	return dm.operationsLatencyMillis
 }
+
+// GetOperationsLatencyDigestMetric returns the underlying TDigestVec
+// for the database operation latency, when it is backed by a t-digest. This can be used for advanced operations.
+//
+// Returns nil if the metric was not configured with quantiles during initialization.
+func (dm *PromDBMetrics) GetOperationsLatencyDigestMetric() *TDigestVec {
+	return dm.operationsLatencyDigest
+}
+
+// GetConnWaitMillisMetric returns the underlying Prometheus HistogramVec
+// for the connection acquisition wait time. This can be used for advanced operations.
+//
+// Returns nil if the metric was not configured during initialization.
+func (dm *PromDBMetrics) GetConnWaitMillisMetric() *prometheus.HistogramVec {
+	return dm.connWaitMillis
+}
+
+// GetRowsAffectedMetric returns the underlying Prometheus HistogramVec
+// for the rows affected by write operations. This can be used for advanced operations.
+//
+// Returns nil if the metric was not configured during initialization.
+func (dm *PromDBMetrics) GetRowsAffectedMetric() *prometheus.HistogramVec {
+	return dm.rowsAffected
+}
+
+// Collectors returns every collector registered by the database metrics, skipping the metrics that were not configured.
+func (dm *PromDBMetrics) Collectors() []prometheus.Collector {
+	var collectors []prometheus.Collector
+	if dm.operationsTotal != nil {
+		collectors = append(collectors, dm.operationsTotal)
+	}
+	if dm.operationsLatencyMillis != nil {
+		collectors = append(collectors, dm.operationsLatencyMillis)
+	}
+	if dm.operationsLatencyDigest != nil {
+		collectors = append(collectors, dm.operationsLatencyDigest)
+	}
+	if dm.connWaitMillis != nil {
+		collectors = append(collectors, dm.connWaitMillis)
+	}
+	if dm.rowsAffected != nil {
+		collectors = append(collectors, dm.rowsAffected)
+	}
+	return collectors
+}
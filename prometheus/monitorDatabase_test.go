@@ -0,0 +1,30 @@
+package prometheus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/piyushkumar96/app-monitoring/models"
+)
+
+func TestDBMetricsHelpTexts(t *testing.T) {
+	registry := useTestRegistry(t)
+	dm := NewPromDatabaseMetrics(&models.DBMetricsMeta{
+		Namespace:               "test",
+		OperationsTotal:         &models.MetricMeta{Labels: dbTotalLabelNames},
+		OperationsLatencyMillis: &models.MetricMeta{Labels: dbLatencyLabelNames},
+		ConnWaitMillis:          &models.MetricMeta{Labels: dbLatencyLabelNames},
+		RowsAffected:            &models.MetricMeta{Labels: dbLatencyLabelNames},
+	})
+	labelValues := &models.DBMetricsLabelValues{OpType: "update", Source: "postgres", AdEntity: "orders", IsTxn: "false"}
+	start := dm.LogMetricsPreWithAcquire(labelValues, time.Now())
+	dm.LogMetricsPost(nil, labelValues, start)
+	dm.RecordRowsAffected(labelValues, 3)
+
+	assertHelpTexts(t, registry, map[string]string{
+		"test_db_operations":                "Number of times DB operations executed for total/success/failure",
+		"test_db_operations_latency_millis": "Tracks the latencies for database operations",
+		"test_db_conn_wait_millis":          "Tracks the time spent waiting to acquire a pooled database connection",
+		"test_db_rows_affected":             "Tracks the number of rows affected by database write operations",
+	})
+}
@@ -1,81 +1,564 @@
 package prometheus
 
 import (
+	"errors"
+	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/piyushkumar96/app-monitoring/constants"
+	"github.com/piyushkumar96/app-monitoring/httputil"
 	"github.com/piyushkumar96/app-monitoring/interfaces"
 	"github.com/piyushkumar96/app-monitoring/models"
 
+	ae "github.com/piyushkumar96/app-error"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+// defaultUpstreamLatencyHeader is the response header used when DownstreamServiceMetricsMeta.UpstreamLatencyHeader is unset.
+const defaultUpstreamLatencyHeader = "X-Response-Time"
+
 // NewPromDownstreamServiceMetrics creates and registers Prometheus metrics for downstream HTTP service calls.
 // It initializes counters for request counts and histograms for latencies and payload sizes.
 //
 // The metrics track:
-//   - HTTPRequests: Counter for total/success/failure HTTP requests to downstream services
+//   - HTTPRequests: Counter for total/success/failure/short_circuited HTTP requests to downstream services
 //   - HTTPRequestsLatencyMillis: Histogram for request latency in milliseconds
 //   - HTTPRequestSizeBytes: Histogram for request body size in bytes
 //   - HTTPResponseSizeBytes: Histogram for response body size in bytes
+//   - LastCallTimestampSeconds: Gauge for the Unix time of the last call per service and API
+//   - LastSuccessTimestampSeconds: Gauge for the Unix time of the last successful call per service and API
+//   - ResponseParseMillis: Histogram for response body decode time in milliseconds
+//   - UpstreamLatencyMillis: Histogram for the processing time reported by downstream services in milliseconds
+//   - InFlight: Gauge for the number of outstanding calls per service
+//   - BatchItemsTotal: Counter for the succeeded/failed items of batch calls
+//   - RetryOutcomeTotal: Counter for the final outcomes of retried calls
+//   - RetryAttempts: Histogram for the attempts made by retried calls
+//   - PagesFetched: Histogram for the pages fetched by paginated calls
+//   - DNSMillis, ConnectMillis, TLSMillis: Histograms for the connection setup phases of calls in milliseconds
 //
 // Parameters:
 //   - meta: Configuration containing the namespace and metric settings.
 //     Set individual metric configs to nil to disable them.
+//     A nil meta (e.g. an omitted config section) returns the NoOp implementation.
 //
 // Returns an interfaces.DownstreamServiceMetricsInterface instance for logging downstream call metrics.
 func NewPromDownstreamServiceMetrics(meta *models.DownstreamServiceMetricsMeta) interfaces.DownstreamServiceMetricsInterface {
-	var httpRequests *prometheus.CounterVec
-	var httpRequestsLatencyMillis, httpRequestSizeBytes, httpResponseSizeBytes *prometheus.HistogramVec
+	if metricsDisabled() || meta == nil {
+		return NewNoOpPromDownstreamServiceMetrics()
+	}
+	meta = withEnabledMetrics(meta, meta.EnabledMetrics, downstreamMetricFields)
+	recordDashboardHints(meta.Namespace, meta, downstreamMetricFields)
+	recordSeriesRateLimits(meta.Namespace, meta, downstreamMetricFields)
+	recordExtraLabels(meta.Namespace, meta, downstreamMetricFields)
+
+	var httpRequests, batchItemsTotal, retryOutcomeTotal *prometheus.CounterVec
+	var httpRequestsLatencyMillis, httpRequestSizeBytes, httpResponseSizeBytes, responseParseMillis, upstreamLatencyMillis, retryAttempts, pagesFetched *prometheus.HistogramVec
+	var dnsMillis, connectMillis, tlsMillis *prometheus.HistogramVec
+	var httpRequestsLatencyDigest *TDigestVec
+	var lastCallTimestampSeconds, lastSuccessTimestampSeconds, inFlight *prometheus.GaugeVec
+	var latencyClamp *latencyClamp
+	var httpRequestsLabels, httpRequestsLatencyLabels []string
+	var trackAttempt bool
 
+	if meta.ServiceNameNormalizer != nil {
+		registerCardinalityProtection()
+	}
 	if meta.HTTPRequests != nil {
-		httpRequests = GetPromCounterVec(meta.Namespace, "downstream_service_http_requests", "Tracks the number of HTTP requests at downstream service level", meta.HTTPRequests.Labels)
+		labels := conventionalLabelOrder("downstream_service_http_requests", meta.HTTPRequests.Labels, dsRequestsLabelNames)
+		httpRequests = GetPromCounterVec(meta.Namespace, "downstream_service_http_requests", metricHelp(meta.HTTPRequests, "Tracks the number of HTTP requests at downstream service level"), labels)
+		httpRequestsLabels = labels
 	}
 	if meta.HTTPRequestsLatencyMillis != nil {
-		httpRequestsLatencyMillis = GetPromHistogramVec(meta.Namespace, "downstream_service_http_request_latency_millis", "Tracks the latencies for HTTP requests at downstream service level", meta.HTTPRequestsLatencyMillis.Labels, meta.HTTPRequestsLatencyMillis.Buckets)
+		labels := conventionalLabelOrder("downstream_service_http_request_latency_millis", meta.HTTPRequestsLatencyMillis.Labels, dsResponseLabelNames)
+		if len(meta.HTTPRequestsLatencyMillis.Quantiles) > 0 {
+			httpRequestsLatencyDigest = GetPromTDigestVecWithMaxAge(meta.Namespace, "downstream_service_http_request_latency_millis", metricHelp(meta.HTTPRequestsLatencyMillis, "Tracks the latencies for HTTP requests at downstream service level"), labels, meta.HTTPRequestsLatencyMillis.Quantiles, meta.HTTPRequestsLatencyMillis.QuantilesMaxAge, meta.HTTPRequestsLatencyMillis.QuantilesAgeBuckets)
+		} else {
+			httpRequestsLatencyMillis = newNonNegativeHistogramVec(meta.Namespace, "downstream_service_http_request_latency_millis", metricHelp(meta.HTTPRequestsLatencyMillis, "Tracks the latencies for HTTP requests at downstream service level"), labels, metricBuckets(meta.HTTPRequestsLatencyMillis))
+		}
+		latencyClamp = newLatencyClamp(meta.Namespace, "downstream_service_http_request", meta.MaxLatencyMillis)
+		httpRequestsLatencyLabels = labels
+		trackAttempt = meta.TrackAttempt && requireLabel("downstream_service_http_request_latency_millis", "attempt tracking", constants.LabelAttempt, labels)
 	}
 	if meta.HTTPRequestSizeBytes != nil {
-		httpRequestSizeBytes = GetPromHistogramVec(meta.Namespace, "downstream_service_http_request_size_bytes", "Tracks the size of HTTP requests at downstream service level.", meta.HTTPRequestSizeBytes.Labels, meta.HTTPRequestSizeBytes.Buckets)
+		labels := conventionalLabelOrder("downstream_service_http_request_size_bytes", meta.HTTPRequestSizeBytes.Labels, dsResponseLabelNames)
+		httpRequestSizeBytes = newNonNegativeHistogramVec(meta.Namespace, "downstream_service_http_request_size_bytes", metricHelp(meta.HTTPRequestSizeBytes, "Tracks the size of HTTP requests at downstream service level"), labels, metricBuckets(meta.HTTPRequestSizeBytes))
 	}
 	if meta.HTTPResponseSizeBytes != nil {
-		httpResponseSizeBytes = GetPromHistogramVec(meta.Namespace, "downstream_service_http_response_size_bytes", "Tracks the size of HTTP responses at downstream service level", meta.HTTPResponseSizeBytes.Labels, meta.HTTPResponseSizeBytes.Buckets)
+		labels := conventionalLabelOrder("downstream_service_http_response_size_bytes", meta.HTTPResponseSizeBytes.Labels, dsResponseLabelNames)
+		httpResponseSizeBytes = newNonNegativeHistogramVec(meta.Namespace, "downstream_service_http_response_size_bytes", metricHelp(meta.HTTPResponseSizeBytes, "Tracks the size of HTTP responses at downstream service level"), labels, metricBuckets(meta.HTTPResponseSizeBytes))
+	}
+	if meta.LastCallTimestampSeconds != nil {
+		labels := conventionalLabelOrder("downstream_service_last_call_timestamp_seconds", meta.LastCallTimestampSeconds.Labels, dsTimestampLabelNames)
+		lastCallTimestampSeconds = GetPromGaugeVec(meta.Namespace, "downstream_service_last_call_timestamp_seconds", metricHelp(meta.LastCallTimestampSeconds, "Tracks the Unix time of the last call to each downstream service API"), labels)
+	}
+	if meta.LastSuccessTimestampSeconds != nil {
+		labels := conventionalLabelOrder("downstream_service_last_success_timestamp_seconds", meta.LastSuccessTimestampSeconds.Labels, dsTimestampLabelNames)
+		lastSuccessTimestampSeconds = GetPromGaugeVec(meta.Namespace, "downstream_service_last_success_timestamp_seconds", metricHelp(meta.LastSuccessTimestampSeconds, "Tracks the Unix time of the last successful call to each downstream service API"), labels)
+	}
+	if meta.ResponseParseMillis != nil {
+		labels := conventionalLabelOrder("downstream_service_http_response_parse_millis", meta.ResponseParseMillis.Labels, dsTimestampLabelNames)
+		responseParseMillis = newNonNegativeHistogramVec(meta.Namespace, "downstream_service_http_response_parse_millis", metricHelp(meta.ResponseParseMillis, "Tracks the time spent decoding HTTP response bodies of downstream service calls"), labels, metricBuckets(meta.ResponseParseMillis))
+	}
+	if meta.UpstreamLatencyMillis != nil {
+		labels := conventionalLabelOrder("downstream_service_upstream_latency_millis", meta.UpstreamLatencyMillis.Labels, dsTimestampLabelNames)
+		upstreamLatencyMillis = newNonNegativeHistogramVec(meta.Namespace, "downstream_service_upstream_latency_millis", metricHelp(meta.UpstreamLatencyMillis, "Tracks the processing time reported by downstream services in their response headers"), labels, metricBuckets(meta.UpstreamLatencyMillis))
+	}
+	upstreamLatencyHeader := meta.UpstreamLatencyHeader
+	if upstreamLatencyHeader == "" {
+		upstreamLatencyHeader = defaultUpstreamLatencyHeader
+	}
+	if meta.InFlight != nil {
+		inFlight = GetPromGaugeVec(meta.Namespace, "downstream_service_in_flight_requests", metricHelp(meta.InFlight, "Tracks the number of outstanding HTTP requests to each downstream service"), meta.InFlight.Labels)
+	}
+	if meta.BatchItemsTotal != nil {
+		labels := conventionalLabelOrder("downstream_service_batch_items_total", meta.BatchItemsTotal.Labels, dsBatchItemsLabelNames)
+		batchItemsTotal = GetPromCounterVec(meta.Namespace, "downstream_service_batch_items_total", metricHelp(meta.BatchItemsTotal, "Tracks the number of succeeded/failed items of batch calls at downstream service level"), labels)
+	}
+	if meta.RetryOutcomeTotal != nil {
+		labels := conventionalLabelOrder("downstream_service_retry_outcome_total", meta.RetryOutcomeTotal.Labels, dsRetryLabelNames)
+		retryOutcomeTotal = GetPromCounterVec(meta.Namespace, "downstream_service_retry_outcome_total", metricHelp(meta.RetryOutcomeTotal, "Tracks the final outcomes of retried calls at downstream service level"), labels)
+	}
+	if meta.RetryAttempts != nil {
+		labels := conventionalLabelOrder("downstream_service_retry_attempts", meta.RetryAttempts.Labels, dsRetryLabelNames)
+		retryAttempts = newNonNegativeHistogramVec(meta.Namespace, "downstream_service_retry_attempts", metricHelp(meta.RetryAttempts, "Tracks the number of attempts made by retried calls at downstream service level"), labels, metricBuckets(meta.RetryAttempts))
+	}
+	if meta.PagesFetched != nil {
+		labels := conventionalLabelOrder("downstream_service_pages_fetched", meta.PagesFetched.Labels, dsPagesLabelNames)
+		pagesFetched = newNonNegativeHistogramVec(meta.Namespace, "downstream_service_pages_fetched", metricHelp(meta.PagesFetched, "Tracks the number of pages fetched by paginated calls at downstream service level"), labels, metricBuckets(meta.PagesFetched))
+	}
+	if meta.DNSMillis != nil {
+		labels := conventionalLabelOrder("downstream_service_dns_millis", meta.DNSMillis.Labels, dsServiceLabelNames)
+		dnsMillis = newNonNegativeHistogramVec(meta.Namespace, "downstream_service_dns_millis", metricHelp(meta.DNSMillis, "Tracks the DNS lookup time of downstream service calls, zero on reused connections"), labels, metricBuckets(meta.DNSMillis))
+	}
+	if meta.ConnectMillis != nil {
+		labels := conventionalLabelOrder("downstream_service_connect_millis", meta.ConnectMillis.Labels, dsServiceLabelNames)
+		connectMillis = newNonNegativeHistogramVec(meta.Namespace, "downstream_service_connect_millis", metricHelp(meta.ConnectMillis, "Tracks the TCP connect time of downstream service calls, zero on reused connections"), labels, metricBuckets(meta.ConnectMillis))
+	}
+	if meta.TLSMillis != nil {
+		labels := conventionalLabelOrder("downstream_service_tls_millis", meta.TLSMillis.Labels, dsServiceLabelNames)
+		tlsMillis = newNonNegativeHistogramVec(meta.Namespace, "downstream_service_tls_millis", metricHelp(meta.TLSMillis, "Tracks the TLS handshake time of downstream service calls, zero on reused or plain-text connections"), labels, metricBuckets(meta.TLSMillis))
 	}
 
 	return &PromDownstreamServiceMetrics{
-		httpRequests:              httpRequests,
-		httpRequestsLatencyMillis: httpRequestsLatencyMillis,
-		httpRequestSizeBytes:      httpRequestSizeBytes,
-		httpResponseSizeBytes:     httpResponseSizeBytes,
+		httpRequests:                httpRequests,
+		httpRequestsLatencyMillis:   httpRequestsLatencyMillis,
+		httpRequestsLatencyDigest:   httpRequestsLatencyDigest,
+		latencyClamp:                latencyClamp,
+		httpRequestsLabels:          httpRequestsLabels,
+		httpRequestsLatencyLabels:   httpRequestsLatencyLabels,
+		trackAttempt:                trackAttempt,
+		httpRequestSizeBytes:        httpRequestSizeBytes,
+		httpResponseSizeBytes:       httpResponseSizeBytes,
+		lastCallTimestampSeconds:    lastCallTimestampSeconds,
+		lastSuccessTimestampSeconds: lastSuccessTimestampSeconds,
+		responseParseMillis:         responseParseMillis,
+		upstreamLatencyMillis:       upstreamLatencyMillis,
+		upstreamLatencyHeader:       upstreamLatencyHeader,
+		inFlight:                    inFlight,
+		batchItemsTotal:             batchItemsTotal,
+		retryOutcomeTotal:           retryOutcomeTotal,
+		retryAttempts:               retryAttempts,
+		pagesFetched:                pagesFetched,
+		dnsMillis:                   dnsMillis,
+		connectMillis:               connectMillis,
+		tlsMillis:                   tlsMillis,
+		serviceNameNormalizer:       meta.ServiceNameNormalizer,
+		allowedServices:             allowlist(meta.AllowedServices),
+		allowedStatuses:             allowlist(meta.AllowedStatuses),
+		failurePredicate:            meta.FailurePredicate,
 	}
 }
 
 // LogMetricsPre should be called before making a downstream service HTTP call.
-// It increments the total request counter for the service.
+// It increments the total request counter and the in-flight calls for the service.
 func (dsm *PromDownstreamServiceMetrics) LogMetricsPre(dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues) {
+	dsm.logPre(dssMetricsLabelValues)
+	if dsm.inFlight != nil {
+		gaugeWith(dsm.inFlight, extraLabelValues(dsm.inFlight, []string{dsm.serviceName(dssMetricsLabelValues)}, dssMetricsLabelValues.ExtraLabels)...).Inc()
+	}
+}
+
+// logPre increments the total request counter for one call.
+func (dsm *PromDownstreamServiceMetrics) logPre(dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues) {
 	if dsm.httpRequests != nil {
-		dsm.httpRequests.WithLabelValues(string(dssMetricsLabelValues.Name), dssMetricsLabelValues.HTTPMethod, "", dssMetricsLabelValues.APIIdentifier, constants.Total).Inc()
+		counterWith(dsm.httpRequests, dsm.requestsLabelValues(dssMetricsLabelValues, dssMetricsLabelValues.HTTPMethod, "", constants.Total)...).Inc()
 	}
 }
 
+// incRequests increments the requests counter for the outcome of one call, with the request ID as
+// exemplar of failures when set.
+func (dsm *PromDownstreamServiceMetrics) incRequests(dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues, method, code, status string) {
+	counter := counterWith(dsm.httpRequests, dsm.requestsLabelValues(dssMetricsLabelValues, method, code, status)...)
+	if status == constants.Failure {
+		incWithExemplar(counter, requestIDExemplar(dssMetricsLabelValues.RequestID))
+		return
+	}
+	counter.Inc()
+}
+
 // LogMetricsPost should be called after a downstream service HTTP call completes.
-// It records the success/failure status, latency, and payload sizes.
+// It records the success/failure status, latency, payload sizes, and last call timestamps,
+// and decrements the in-flight calls for the service.
 func (dsm *PromDownstreamServiceMetrics) LogMetricsPost(success bool, dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues, httpMetrics *models.HTTPMetrics) {
+	if dsm.inFlight != nil {
+		gaugeWith(dsm.inFlight, extraLabelValues(dsm.inFlight, []string{dsm.serviceName(dssMetricsLabelValues)}, dssMetricsLabelValues.ExtraLabels)...).Dec()
+	}
+	dsm.recordServiceNormalization(dssMetricsLabelValues)
 	httpCodeStr := strconv.Itoa(httpMetrics.Code)
+	status := callStatus(success)
 	if dsm.httpRequests != nil {
-		if success {
-			dsm.httpRequests.WithLabelValues(string(dssMetricsLabelValues.Name), httpMetrics.Method, httpCodeStr, dssMetricsLabelValues.APIIdentifier, constants.Success).Inc()
-		} else {
-			dsm.httpRequests.WithLabelValues(string(dssMetricsLabelValues.Name), httpMetrics.Method, httpCodeStr, dssMetricsLabelValues.APIIdentifier, constants.Failure).Inc()
-		}
+		dsm.incRequests(dssMetricsLabelValues, httpMetrics.Method, httpCodeStr, status)
 	}
 	if dsm.httpRequestsLatencyMillis != nil {
-		dsm.httpRequestsLatencyMillis.WithLabelValues(string(dssMetricsLabelValues.Name), httpMetrics.Method, httpCodeStr, dssMetricsLabelValues.APIIdentifier).Observe(float64(httpMetrics.ResponseTime.Milliseconds()))
+		observeWithExemplar(dsm.httpRequestsLatencyMillis, "downstream_service_http_request_latency_millis", dsm.latencyClamp.clamp(durationMillis(httpMetrics.ResponseTime)), requestIDExemplar(dssMetricsLabelValues.RequestID), dsm.latencyLabelValues(dsm.httpRequestsLatencyMillis, dssMetricsLabelValues, httpMetrics.Method, httpCodeStr, status)...)
+	}
+	if dsm.httpRequestsLatencyDigest != nil {
+		observe(dsm.httpRequestsLatencyDigest, "downstream_service_http_request_latency_millis", dsm.latencyClamp.clamp(durationMillis(httpMetrics.ResponseTime)), dsm.latencyLabelValues(dsm.httpRequestsLatencyDigest, dssMetricsLabelValues, httpMetrics.Method, httpCodeStr, status)...)
 	}
 	if dsm.httpRequestSizeBytes != nil {
-		dsm.httpRequestSizeBytes.WithLabelValues(string(dssMetricsLabelValues.Name), httpMetrics.Method, httpCodeStr, dssMetricsLabelValues.APIIdentifier).Observe(float64(httpMetrics.RequestBodySizeBytes))
+		observe(dsm.httpRequestSizeBytes, "downstream_service_http_request_size_bytes", float64(httpMetrics.RequestBodySizeBytes), extraLabelValues(dsm.httpRequestSizeBytes, []string{dsm.serviceName(dssMetricsLabelValues), httpMetrics.Method, httpCodeStr, dssMetricsLabelValues.APIIdentifier}, dssMetricsLabelValues.ExtraLabels)...)
 	}
 	if dsm.httpResponseSizeBytes != nil {
-		dsm.httpResponseSizeBytes.WithLabelValues(string(dssMetricsLabelValues.Name), httpMetrics.Method, httpCodeStr, dssMetricsLabelValues.APIIdentifier).Observe(float64(httpMetrics.ResponseBodySizeBytes))
+		observe(dsm.httpResponseSizeBytes, "downstream_service_http_response_size_bytes", float64(httpMetrics.ResponseBodySizeBytes), extraLabelValues(dsm.httpResponseSizeBytes, []string{dsm.serviceName(dssMetricsLabelValues), httpMetrics.Method, httpCodeStr, dssMetricsLabelValues.APIIdentifier}, dssMetricsLabelValues.ExtraLabels)...)
+	}
+	dsm.recordCallTimestamps(success, dssMetricsLabelValues)
+}
+
+// LogMetricsPostResp should be called after a downstream service HTTP call completes, in place of
+// LogMetricsPost. It derives the HTTP metrics from the call instead of taking them from the caller:
+//   - method and request size from resp.Request, falling back to the label values' HTTPMethod
+//   - code and response size (Content-Length) from resp; sizes are 0 when unknown
+//   - latency as the time since start
+//   - the upstream latency from the UpstreamLatencyHeader response header, when present and parseable
+//
+// The call is successful when err is nil and the status code is below 400, or when err wraps an *ae.AppError
+// that FailurePredicate reports as expected. resp may be nil when err is set.
+func (dsm *PromDownstreamServiceMetrics) LogMetricsPostResp(dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues, resp *http.Response, start time.Time, err error) {
+	httpMetrics := httpMetricsFromResponse(dssMetricsLabelValues.HTTPMethod, resp, start)
+	success := err == nil && resp != nil && resp.StatusCode < http.StatusBadRequest
+	var appErr *ae.AppError
+	if err != nil && errors.As(err, &appErr) && !isFailure(appErr, dsm.failurePredicate) {
+		success = true
+	}
+	dsm.LogMetricsPost(success, dssMetricsLabelValues, httpMetrics)
+	if dsm.upstreamLatencyMillis != nil && resp != nil {
+		if latency, ok := httputil.ParseLatencyHeader(resp.Header.Get(dsm.upstreamLatencyHeader)); ok {
+			dsm.RecordUpstreamLatency(dssMetricsLabelValues, latency)
+		}
+	}
+}
+
+// LogMetricsPostBatch should be called after a downstream call carrying a batch of items completes, in place of
+// LogMetricsPost, when the downstream service reports a result per item. It records the call as LogMetricsPost
+// does, successful when its status code is between 1 and 399, and adds succeeded and failed to the success and
+// failure series of the batch items counter, so that item-level failures of a call answered with 200 are seen.
+//
+// Example:
+//
+//	resp, err := client.Do(req)
+//	// decode the per-item results of resp into results
+//	dsMetrics.LogMetricsPostBatch(labelValues, httpMetrics, results.Succeeded(), results.Failed())
+func (dsm *PromDownstreamServiceMetrics) LogMetricsPostBatch(dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues, httpMetrics *models.HTTPMetrics, succeeded, failed int) {
+	dsm.LogMetricsPost(httpMetrics.Code > 0 && httpMetrics.Code < http.StatusBadRequest, dssMetricsLabelValues, httpMetrics)
+	if dsm.batchItemsTotal == nil {
+		return
+	}
+	service := dsm.serviceName(dssMetricsLabelValues)
+	counterWith(dsm.batchItemsTotal, extraLabelValues(dsm.batchItemsTotal, []string{service, dssMetricsLabelValues.APIIdentifier, constants.Success}, dssMetricsLabelValues.ExtraLabels)...).Add(float64(max(succeeded, 0)))
+	counterWith(dsm.batchItemsTotal, extraLabelValues(dsm.batchItemsTotal, []string{service, dssMetricsLabelValues.APIIdentifier, constants.Failure}, dssMetricsLabelValues.ExtraLabels)...).Add(float64(max(failed, 0)))
+}
+
+// LogMetricsPostPaged should be called after a paginated downstream call completes, in place of LogMetricsPost,
+// with httpMetrics covering the whole logical call and pages the number of pages fetched for it. It records the
+// call as LogMetricsPost does, successful when its status code is between 1 and 399, and observes pages into
+// the pages fetched histogram, so that logical calls fanning out into many physical requests are seen.
+//
+// Example:
+//
+//	items, pages, err := client.ListAll(ctx)
+//	dsMetrics.LogMetricsPostPaged(labelValues, httpMetrics, pages)
+func (dsm *PromDownstreamServiceMetrics) LogMetricsPostPaged(dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues, httpMetrics *models.HTTPMetrics, pages int) {
+	dsm.LogMetricsPost(httpMetrics.Code > 0 && httpMetrics.Code < http.StatusBadRequest, dssMetricsLabelValues, httpMetrics)
+	if dsm.pagesFetched == nil {
+		return
+	}
+	observe(dsm.pagesFetched, "downstream_service_pages_fetched", float64(max(pages, 0)), extraLabelValues(dsm.pagesFetched, []string{dsm.serviceName(dssMetricsLabelValues), dssMetricsLabelValues.APIIdentifier}, dssMetricsLabelValues.ExtraLabels)...)
+}
+
+// LogMetricsRetryOutcome records the final outcome of a call made with retries, once the retry loop is done:
+// constants.RetryOutcomeNoRetryNeeded when the first attempt succeeded, RetryOutcomeSucceededAfterRetry when
+// a retry succeeded, and RetryOutcomeExhausted when every attempt failed. It increments the retry outcome
+// counter and observes attempts, the number of attempts made including the first, into the retry attempts
+// histogram. Each attempt is still recorded with the LogMetricsPre/LogMetricsPost pair. An unknown outcome
+// is recorded as exhausted, so that a caller-built outcome can't add unbounded values to the outcome label.
+//
+// Example:
+//
+//	attempts, err := retry.Do(ctx, call)
+//	outcome := constants.RetryOutcomeExhausted
+//	if err == nil && attempts == 1 {
+//	    outcome = constants.RetryOutcomeNoRetryNeeded
+//	} else if err == nil {
+//	    outcome = constants.RetryOutcomeSucceededAfterRetry
+//	}
+//	dsMetrics.LogMetricsRetryOutcome(labelValues, outcome, attempts)
+func (dsm *PromDownstreamServiceMetrics) LogMetricsRetryOutcome(dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues, outcome string, attempts int) {
+	if dsm.retryOutcomeTotal == nil && dsm.retryAttempts == nil {
+		return
+	}
+	service := dsm.serviceName(dssMetricsLabelValues)
+	outcome = retryOutcome(outcome)
+	if dsm.retryOutcomeTotal != nil {
+		counterWith(dsm.retryOutcomeTotal, extraLabelValues(dsm.retryOutcomeTotal, []string{service, dssMetricsLabelValues.APIIdentifier, outcome}, dssMetricsLabelValues.ExtraLabels)...).Inc()
+	}
+	if dsm.retryAttempts != nil {
+		observe(dsm.retryAttempts, "downstream_service_retry_attempts", float64(max(attempts, 0)), extraLabelValues(dsm.retryAttempts, []string{service, dssMetricsLabelValues.APIIdentifier, outcome}, dssMetricsLabelValues.ExtraLabels)...)
+	}
+}
+
+// retryOutcome returns the outcome label value of a retry outcome, or constants.RetryOutcomeExhausted for an
+// unknown outcome.
+func retryOutcome(outcome string) string {
+	switch outcome {
+	case constants.RetryOutcomeNoRetryNeeded, constants.RetryOutcomeSucceededAfterRetry, constants.RetryOutcomeExhausted:
+		return outcome
+	}
+	return constants.RetryOutcomeExhausted
+}
+
+// httpMetricsFromResponse builds the HTTP metrics of a downstream call from its response.
+// Unknown content lengths (-1) are recorded as 0.
+func httpMetricsFromResponse(method string, resp *http.Response, start time.Time) *models.HTTPMetrics {
+	httpMetrics := &models.HTTPMetrics{
+		Method:       method,
+		ResponseTime: time.Since(start),
+	}
+	if resp == nil {
+		return httpMetrics
+	}
+	httpMetrics.Code = resp.StatusCode
+	httpMetrics.ResponseBodySizeBytes = max(resp.ContentLength, 0)
+	if req := resp.Request; req != nil {
+		if req.Method != "" {
+			httpMetrics.Method = req.Method
+		}
+		if req.URL != nil {
+			httpMetrics.URL = req.URL.Path
+		}
+		httpMetrics.RequestBodySizeBytes = max(req.ContentLength, 0)
+	}
+	return httpMetrics
+}
+
+// serviceName returns the service label value for a call, mapped through the configured
+// ServiceNameNormalizer when one is set, and folded into constants.ServiceOther when it is outside AllowedServices.
+func (dsm *PromDownstreamServiceMetrics) serviceName(dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues) string {
+	name := dsm.normalizedServiceName(dssMetricsLabelValues)
+	if !dsm.allowedService(name) {
+		return constants.ServiceOther
+	}
+	return name
+}
+
+// normalizedServiceName returns the Name of a call mapped through the configured ServiceNameNormalizer when one is set.
+func (dsm *PromDownstreamServiceMetrics) normalizedServiceName(dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues) string {
+	if dsm.serviceNameNormalizer != nil {
+		return dsm.serviceNameNormalizer(dssMetricsLabelValues.Name)
+	}
+	return dssMetricsLabelValues.Name
+}
+
+// recordServiceNormalization counts the call in the cardinality protection counter when the configured
+// ServiceNameNormalizer changes its service name, and when the name is folded for being outside
+// AllowedServices. It is called once per call, by LogMetricsPost and ObserveLatency.
+func (dsm *PromDownstreamServiceMetrics) recordServiceNormalization(dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues) {
+	name := dsm.normalizedServiceName(dssMetricsLabelValues)
+	if dsm.serviceNameNormalizer != nil && name != dssMetricsLabelValues.Name {
+		recordCardinalityProtection("downstream_service_http_requests", constants.ReasonServiceNormalized)
+	}
+	if !dsm.allowedService(name) {
+		recordCardinalityProtection("downstream_service_http_requests", constants.ReasonServiceFolded)
+	}
+}
+
+// allowedService reports whether a normalized service name is recorded as-is: every name is when AllowedServices is empty.
+func (dsm *PromDownstreamServiceMetrics) allowedService(name string) bool {
+	if dsm.allowedServices == nil {
+		return true
+	}
+	_, ok := dsm.allowedServices[name]
+	return ok
+}
+
+// requestsLabelValues returns the label values for the request counter. The idempotent label is
+// only recorded when "idempotent" is declared in its configured labels.
+func (dsm *PromDownstreamServiceMetrics) requestsLabelValues(dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues, method, code, status string) []string {
+	return extraLabelValues(dsm.httpRequests, withOptionalLabels(dsm.httpRequestsLabels,
+		[]string{dsm.serviceName(dssMetricsLabelValues), method, code, dssMetricsLabelValues.APIIdentifier, status},
+		optionalLabel{name: constants.LabelIdempotent, value: idempotentMethod(method)}), dssMetricsLabelValues.ExtraLabels)
+}
+
+// latencyLabelValues returns the label values for the latency histogram or digest vec. The status label
+// is only recorded when "status" is declared in its configured labels, which splits the latencies
+// of successful and failed calls into separate series, and likewise for the idempotent and attempt labels.
+func (dsm *PromDownstreamServiceMetrics) latencyLabelValues(vec any, dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues, method, code, status string) []string {
+	return extraLabelValues(vec, withOptionalLabels(dsm.httpRequestsLatencyLabels,
+		[]string{dsm.serviceName(dssMetricsLabelValues), method, code, dssMetricsLabelValues.APIIdentifier},
+		optionalLabel{name: constants.LabelStatus, value: status},
+		optionalLabel{name: constants.LabelIdempotent, value: idempotentMethod(method)},
+		optionalLabel{name: constants.LabelAttempt, value: dsm.attemptLabel(dssMetricsLabelValues.Attempt)}), dssMetricsLabelValues.ExtraLabels)
+}
+
+// attemptLabel returns the attempt label value for the 1-based attempt number of a call: "1" for a first
+// attempt (or an unset one), "2" for the first retry, and constants.AttemptCapped from the third attempt
+// on. It is empty when attempt tracking is disabled.
+func (dsm *PromDownstreamServiceMetrics) attemptLabel(attempt int) string {
+	if !dsm.trackAttempt {
+		return ""
+	}
+	if attempt >= constants.MaxLabeledAttempt {
+		return constants.AttemptCapped
+	}
+	return strconv.Itoa(max(attempt, 1))
+}
+
+// idempotentMethods are the idempotent request methods defined by RFC 7231, section 4.2.2.
+var idempotentMethods = map[string]struct{}{
+	http.MethodGet:     {},
+	http.MethodHead:    {},
+	http.MethodOptions: {},
+	http.MethodTrace:   {},
+	http.MethodPut:     {},
+	http.MethodDelete:  {},
+}
+
+// idempotentMethod returns the idempotent label value for a request method: "true" for the methods
+// RFC 7231 defines as idempotent, and "false" for the others (POST, PATCH, CONNECT and unknown methods),
+// whose retries can't be assumed safe.
+func idempotentMethod(method string) string {
+	_, ok := idempotentMethods[strings.ToUpper(method)]
+	return strconv.FormatBool(ok)
+}
+
+// callStatus returns the status label value for a downstream call outcome.
+func callStatus(success bool) string {
+	if success {
+		return constants.Success
+	}
+	return constants.Failure
+}
+
+// recordCallTimestamps sets the last call gauge, and the last success gauge for successful calls, to the current time.
+// Unlike counters, these give an absolute recency signal that rate() can't provide when traffic drops to zero.
+func (dsm *PromDownstreamServiceMetrics) recordCallTimestamps(success bool, dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues) {
+	if dsm.lastCallTimestampSeconds != nil {
+		gaugeWith(dsm.lastCallTimestampSeconds, extraLabelValues(dsm.lastCallTimestampSeconds, []string{dsm.serviceName(dssMetricsLabelValues), dssMetricsLabelValues.APIIdentifier}, dssMetricsLabelValues.ExtraLabels)...).SetToCurrentTime()
+	}
+	if dsm.lastSuccessTimestampSeconds != nil && success {
+		gaugeWith(dsm.lastSuccessTimestampSeconds, extraLabelValues(dsm.lastSuccessTimestampSeconds, []string{dsm.serviceName(dssMetricsLabelValues), dssMetricsLabelValues.APIIdentifier}, dssMetricsLabelValues.ExtraLabels)...).SetToCurrentTime()
+	}
+}
+
+// ObserveLatency records a downstream call whose duration was already measured elsewhere,
+// without the LogMetricsPre/LogMetricsPost pair. It increments the total and success/failure
+// counters and observes the duration into the latency histogram. The size histograms are not
+// observed, and the code label is left empty, since neither is known from a duration alone.
+// The call has already completed, so the in-flight calls are left unchanged.
+func (dsm *PromDownstreamServiceMetrics) ObserveLatency(success bool, dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues, duration time.Duration) {
+	dsm.ObserveWithStatus(dssMetricsLabelValues, callStatus(success), duration)
+}
+
+// ObserveWithStatus records a downstream call whose duration was already measured elsewhere, as ObserveLatency
+// does, with a status supplied by the caller (e.g. "throttled" for a 429 response, "cached" for a call served
+// by a client-side cache) instead of success or failure. The status is recorded by the request counter, and by
+// the latency histogram when "status" is declared in its labels; only the success status updates the last
+// success timestamp. Statuses outside AllowedStatuses are recorded as "other".
+//
+// Example:
+//
+//	if resp.StatusCode == http.StatusTooManyRequests {
+//	    dsMetrics.ObserveWithStatus(labelValues, "throttled", time.Since(start))
+//	}
+func (dsm *PromDownstreamServiceMetrics) ObserveWithStatus(dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues, status string, duration time.Duration) {
+	dsm.logPre(dssMetricsLabelValues)
+	dsm.recordServiceNormalization(dssMetricsLabelValues)
+	status = allowedStatus(dsm.allowedStatuses, status, "downstream_service_http_requests")
+	if dsm.httpRequests != nil {
+		dsm.incRequests(dssMetricsLabelValues, dssMetricsLabelValues.HTTPMethod, "", status)
+	}
+	if dsm.httpRequestsLatencyMillis != nil {
+		observeWithExemplar(dsm.httpRequestsLatencyMillis, "downstream_service_http_request_latency_millis", dsm.latencyClamp.clamp(durationMillis(duration)), requestIDExemplar(dssMetricsLabelValues.RequestID), dsm.latencyLabelValues(dsm.httpRequestsLatencyMillis, dssMetricsLabelValues, dssMetricsLabelValues.HTTPMethod, "", status)...)
+	}
+	if dsm.httpRequestsLatencyDigest != nil {
+		observe(dsm.httpRequestsLatencyDigest, "downstream_service_http_request_latency_millis", dsm.latencyClamp.clamp(durationMillis(duration)), dsm.latencyLabelValues(dsm.httpRequestsLatencyDigest, dssMetricsLabelValues, dssMetricsLabelValues.HTTPMethod, "", status)...)
+	}
+	dsm.recordCallTimestamps(status == constants.Success, dssMetricsLabelValues)
+}
+
+// LogMetricsShortCircuited records a downstream call that a circuit breaker rejected (failed fast) without
+// calling the downstream service. It increments the total counter and the counter with the short_circuited
+// status, so rejected calls can be told apart from calls that genuinely failed downstream. No latency, size
+// or call timestamp is recorded, as no call was made, and the in-flight calls are left unchanged; call it
+// instead of the LogMetricsPre/LogMetricsPost pair.
+//
+// Example:
+//
+//	if !breaker.Allow() {
+//	    dsMetrics.LogMetricsShortCircuited(labelValues)
+//	    return nil, ErrCircuitOpen
+//	}
+func (dsm *PromDownstreamServiceMetrics) LogMetricsShortCircuited(dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues) {
+	dsm.logPre(dssMetricsLabelValues)
+	dsm.recordServiceNormalization(dssMetricsLabelValues)
+	if dsm.httpRequests != nil {
+		dsm.incRequests(dssMetricsLabelValues, dssMetricsLabelValues.HTTPMethod, "", constants.ShortCircuited)
+	}
+}
+
+// RecordParseTime records the time spent decoding the response body of a downstream call, which the
+// HTTP latency doesn't include, so a slow dependency can be told apart from slow deserialization.
+//
+// Example:
+//
+//	parseStart := time.Now()
+//	err = json.NewDecoder(resp.Body).Decode(&result)
+//	dsMetrics.RecordParseTime(labelValues, time.Since(parseStart))
+func (dsm *PromDownstreamServiceMetrics) RecordParseTime(dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues, duration time.Duration) {
+	if dsm.responseParseMillis != nil {
+		observe(dsm.responseParseMillis, "downstream_service_http_response_parse_millis", durationMillis(duration), extraLabelValues(dsm.responseParseMillis, []string{dsm.serviceName(dssMetricsLabelValues), dssMetricsLabelValues.APIIdentifier}, dssMetricsLabelValues.ExtraLabels)...)
+	}
+}
+
+// RecordUpstreamLatency records the processing time a downstream service reported for a call, typically in
+// a response header. LogMetricsPostResp records it from the UpstreamLatencyHeader response header already;
+// call it when the call is recorded with LogMetricsPost or the latency is reported differently.
+//
+// Example:
+//
+//	if latency, ok := httputil.ParseLatencyHeader(resp.Header.Get("X-Response-Time")); ok {
+//		dsMetrics.RecordUpstreamLatency(labelValues, latency)
+//	}
+func (dsm *PromDownstreamServiceMetrics) RecordUpstreamLatency(dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues, latency time.Duration) {
+	if dsm.upstreamLatencyMillis != nil {
+		observe(dsm.upstreamLatencyMillis, "downstream_service_upstream_latency_millis", durationMillis(latency), extraLabelValues(dsm.upstreamLatencyMillis, []string{dsm.serviceName(dssMetricsLabelValues), dssMetricsLabelValues.APIIdentifier}, dssMetricsLabelValues.ExtraLabels)...)
+	}
+}
+
+// RecordConnectionSetup records the DNS lookup, TCP connect and TLS handshake times of a downstream call,
+// as captured by an interfaces.RoundTripper with TraceConnections set. Each phase is observed even when
+// zero, so calls on reused connections show up at the bottom of the histograms.
+func (dsm *PromDownstreamServiceMetrics) RecordConnectionSetup(dssMetricsLabelValues *models.DownstreamServiceMetricsLabelValues, times models.ConnectionSetupTimes) {
+	service := dsm.serviceName(dssMetricsLabelValues)
+	if dsm.dnsMillis != nil {
+		observe(dsm.dnsMillis, "downstream_service_dns_millis", durationMillis(times.DNS), extraLabelValues(dsm.dnsMillis, []string{service}, dssMetricsLabelValues.ExtraLabels)...)
+	}
+	if dsm.connectMillis != nil {
+		observe(dsm.connectMillis, "downstream_service_connect_millis", durationMillis(times.Connect), extraLabelValues(dsm.connectMillis, []string{service}, dssMetricsLabelValues.ExtraLabels)...)
+	}
+	if dsm.tlsMillis != nil {
+		observe(dsm.tlsMillis, "downstream_service_tls_millis", durationMillis(times.TLS), extraLabelValues(dsm.tlsMillis, []string{service}, dssMetricsLabelValues.ExtraLabels)...)
 	}
 }
 
@@ -91,6 +574,14 @@ func (dsm *PromDownstreamServiceMetrics) GetHTTPRequestsLatencyMillisMetric() *p
 	return dsm.httpRequestsLatencyMillis
 }
 
+// GetHTTPRequestsLatencyDigestMetric returns the underlying TDigestVec
+// for the HTTP request latency, when it is backed by a t-digest. This can be used for advanced operations.
+//
+// Returns nil if the metric was not configured with quantiles during initialization.
+func (dsm *PromDownstreamServiceMetrics) GetHTTPRequestsLatencyDigestMetric() *TDigestVec {
+	return dsm.httpRequestsLatencyDigest
+}
+
 // GetHTTPRequestSizeBytesMetric returns the underlying Prometheus HistogramVec
 // for the HTTP request size. This can be used for advanced operations.
 func (dsm *PromDownstreamServiceMetrics) GetHTTPRequestSizeBytesMetric() *prometheus.HistogramVec {
@@ -102,3 +593,150 @@ func (dsm *PromDownstreamServiceMetrics) GetHTTPRequestSizeBytesMetric() *promet
 func (dsm *PromDownstreamServiceMetrics) GetHTTPResponseSizeBytesMetric() *prometheus.HistogramVec {
 	return dsm.httpResponseSizeBytes
 }
+
+// GetLastCallTimestampSecondsMetric returns the underlying Prometheus GaugeVec
+// for the last call timestamp. This can be used for advanced operations.
+func (dsm *PromDownstreamServiceMetrics) GetLastCallTimestampSecondsMetric() *prometheus.GaugeVec {
+	return dsm.lastCallTimestampSeconds
+}
+
+// GetLastSuccessTimestampSecondsMetric returns the underlying Prometheus GaugeVec
+// for the last successful call timestamp. This can be used for advanced operations.
+func (dsm *PromDownstreamServiceMetrics) GetLastSuccessTimestampSecondsMetric() *prometheus.GaugeVec {
+	return dsm.lastSuccessTimestampSeconds
+}
+
+// GetResponseParseMillisMetric returns the underlying Prometheus HistogramVec
+// for the response parse time. This can be used for advanced operations.
+func (dsm *PromDownstreamServiceMetrics) GetResponseParseMillisMetric() *prometheus.HistogramVec {
+	return dsm.responseParseMillis
+}
+
+// GetUpstreamLatencyMillisMetric returns the underlying Prometheus HistogramVec
+// for the upstream-reported latency. This can be used for advanced operations.
+//
+// Returns nil if the metric was not configured during initialization.
+func (dsm *PromDownstreamServiceMetrics) GetUpstreamLatencyMillisMetric() *prometheus.HistogramVec {
+	return dsm.upstreamLatencyMillis
+}
+
+// GetInFlightMetric returns the underlying Prometheus GaugeVec
+// for the outstanding calls per service. This can be used for advanced operations.
+//
+// Returns nil if the metric was not configured during initialization.
+func (dsm *PromDownstreamServiceMetrics) GetInFlightMetric() *prometheus.GaugeVec {
+	return dsm.inFlight
+}
+
+// GetBatchItemsTotalMetric returns the underlying Prometheus CounterVec
+// for the succeeded/failed items of batch calls. This can be used for advanced operations.
+//
+// Returns nil if the metric was not configured during initialization.
+func (dsm *PromDownstreamServiceMetrics) GetBatchItemsTotalMetric() *prometheus.CounterVec {
+	return dsm.batchItemsTotal
+}
+
+// GetRetryOutcomeTotalMetric returns the underlying Prometheus CounterVec
+// for the final outcomes of retried calls. This can be used for advanced operations.
+//
+// Returns nil if the metric was not configured during initialization.
+func (dsm *PromDownstreamServiceMetrics) GetRetryOutcomeTotalMetric() *prometheus.CounterVec {
+	return dsm.retryOutcomeTotal
+}
+
+// GetRetryAttemptsMetric returns the underlying Prometheus HistogramVec
+// for the attempts made by retried calls. This can be used for advanced operations.
+//
+// Returns nil if the metric was not configured during initialization.
+func (dsm *PromDownstreamServiceMetrics) GetRetryAttemptsMetric() *prometheus.HistogramVec {
+	return dsm.retryAttempts
+}
+
+// GetPagesFetchedMetric returns the underlying Prometheus HistogramVec
+// for the pages fetched by paginated calls. This can be used for advanced operations.
+//
+// Returns nil if the metric was not configured during initialization.
+func (dsm *PromDownstreamServiceMetrics) GetPagesFetchedMetric() *prometheus.HistogramVec {
+	return dsm.pagesFetched
+}
+
+// GetDNSMillisMetric returns the underlying Prometheus HistogramVec
+// for the DNS lookup time. This can be used for advanced operations.
+//
+// Returns nil if the metric was not configured during initialization.
+func (dsm *PromDownstreamServiceMetrics) GetDNSMillisMetric() *prometheus.HistogramVec {
+	return dsm.dnsMillis
+}
+
+// GetConnectMillisMetric returns the underlying Prometheus HistogramVec
+// for the TCP connect time. This can be used for advanced operations.
+//
+// Returns nil if the metric was not configured during initialization.
+func (dsm *PromDownstreamServiceMetrics) GetConnectMillisMetric() *prometheus.HistogramVec {
+	return dsm.connectMillis
+}
+
+// GetTLSMillisMetric returns the underlying Prometheus HistogramVec
+// for the TLS handshake time. This can be used for advanced operations.
+//
+// Returns nil if the metric was not configured during initialization.
+func (dsm *PromDownstreamServiceMetrics) GetTLSMillisMetric() *prometheus.HistogramVec {
+	return dsm.tlsMillis
+}
+
+// Collectors returns every collector registered by the downstream service metrics, skipping the metrics that were not configured.
+func (dsm *PromDownstreamServiceMetrics) Collectors() []prometheus.Collector {
+	var collectors []prometheus.Collector
+	if dsm.httpRequests != nil {
+		collectors = append(collectors, dsm.httpRequests)
+	}
+	if dsm.httpRequestsLatencyMillis != nil {
+		collectors = append(collectors, dsm.httpRequestsLatencyMillis)
+	}
+	if dsm.httpRequestsLatencyDigest != nil {
+		collectors = append(collectors, dsm.httpRequestsLatencyDigest)
+	}
+	if dsm.httpRequestSizeBytes != nil {
+		collectors = append(collectors, dsm.httpRequestSizeBytes)
+	}
+	if dsm.httpResponseSizeBytes != nil {
+		collectors = append(collectors, dsm.httpResponseSizeBytes)
+	}
+	if dsm.lastCallTimestampSeconds != nil {
+		collectors = append(collectors, dsm.lastCallTimestampSeconds)
+	}
+	if dsm.lastSuccessTimestampSeconds != nil {
+		collectors = append(collectors, dsm.lastSuccessTimestampSeconds)
+	}
+	if dsm.responseParseMillis != nil {
+		collectors = append(collectors, dsm.responseParseMillis)
+	}
+	if dsm.upstreamLatencyMillis != nil {
+		collectors = append(collectors, dsm.upstreamLatencyMillis)
+	}
+	if dsm.inFlight != nil {
+		collectors = append(collectors, dsm.inFlight)
+	}
+	if dsm.batchItemsTotal != nil {
+		collectors = append(collectors, dsm.batchItemsTotal)
+	}
+	if dsm.retryOutcomeTotal != nil {
+		collectors = append(collectors, dsm.retryOutcomeTotal)
+	}
+	if dsm.retryAttempts != nil {
+		collectors = append(collectors, dsm.retryAttempts)
+	}
+	if dsm.pagesFetched != nil {
+		collectors = append(collectors, dsm.pagesFetched)
+	}
+	if dsm.dnsMillis != nil {
+		collectors = append(collectors, dsm.dnsMillis)
+	}
+	if dsm.connectMillis != nil {
+		collectors = append(collectors, dsm.connectMillis)
+	}
+	if dsm.tlsMillis != nil {
+		collectors = append(collectors, dsm.tlsMillis)
+	}
+	return collectors
+}
@@ -0,0 +1,174 @@
+package prometheus
+
+import (
+	"time"
+
+	"github.com/piyushkumar96/app-monitoring/constants"
+	"github.com/piyushkumar96/app-monitoring/interfaces"
+	"github.com/piyushkumar96/app-monitoring/models"
+
+	ae "github.com/piyushkumar96/app-error"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// NewPromOperationMetrics creates and registers Prometheus metrics for logical operations that span
+// several dependencies (database, cache, downstream services), such as a repository method.
+//
+// The metrics track:
+//   - OperationDurationMillis: Histogram for the outer operation duration in milliseconds, by outcome
+//   - DependencyDurationMillis: Histogram for each dependency call duration in milliseconds, by kind and name
+//
+// Dependency calls given label values for their kind are also routed to the matching metrics of deps,
+// so existing dashboards keep working:
+//   - constants.DependencyDB: deps.DB.ObserveLatency with labelValues.DB
+//   - constants.DependencyDownstream: deps.Downstream.ObserveLatency with labelValues.Downstream
+//
+// Other kinds, including constants.DependencyCache, and calls without label values for their kind
+// are only recorded in DependencyDurationMillis.
+//
+// Parameters:
+//   - meta: Configuration containing the namespace and metric settings.
+//     Set individual metric configs to nil to disable them.
+//     A nil meta (e.g. an omitted config section) returns the NoOp implementation.
+//   - deps: The metrics that dependency observations are routed to. It may be nil, as may any of its fields.
+//
+// Returns an interfaces.OperationMetricsInterface instance that can be used to time operations.
+//
+// Example:
+//
+//	opMetrics := prometheus.NewPromOperationMetrics(&models.OperationMetricsMeta{
+//	    Namespace: "myapp",
+//	    OperationDurationMillis: &models.MetricMeta{
+//	        Labels:  []string{"operation", "outcome"},
+//	        Buckets: prometheus.GetPromExponentialBuckets(5, 2, 10),
+//	    },
+//	}, &interfaces.Bundle{DB: dbMetrics})
+//
+//	op := opMetrics.Start("get_user")
+//	dep := op.Dependency(constants.DependencyDB, "users", &models.DependencyLabelValues{
+//	    DB: &models.DBMetricsLabelValues{Source: "user_repo", OpType: "select", AdEntity: "users"},
+//	})
+//	user, appErr := repo.findUser(id)
+//	dep.End(appErr)
+//	op.End(appErr)
+func NewPromOperationMetrics(meta *models.OperationMetricsMeta, deps *interfaces.Bundle) interfaces.OperationMetricsInterface {
+	if metricsDisabled() || meta == nil {
+		return NewNoOpPromOperationMetrics()
+	}
+	meta = withEnabledMetrics(meta, meta.EnabledMetrics, operationMetricFields)
+	recordDashboardHints(meta.Namespace, meta, operationMetricFields)
+	recordSeriesRateLimits(meta.Namespace, meta, operationMetricFields)
+
+	var operationDurationMillis, dependencyDurationMillis *prometheus.HistogramVec
+	if meta.OperationDurationMillis != nil {
+		labels := conventionalLabelOrder("operation_duration_millis", meta.OperationDurationMillis.Labels, operationLabelNames)
+		operationDurationMillis = newNonNegativeHistogramVec(meta.Namespace, "operation_duration_millis", metricHelp(meta.OperationDurationMillis, "Tracks the duration of logical operations spanning several dependencies"), labels, metricBuckets(meta.OperationDurationMillis))
+	}
+	if meta.DependencyDurationMillis != nil {
+		labels := conventionalLabelOrder("operation_dependency_duration_millis", meta.DependencyDurationMillis.Labels, operationDepLabelNames)
+		dependencyDurationMillis = newNonNegativeHistogramVec(meta.Namespace, "operation_dependency_duration_millis", metricHelp(meta.DependencyDurationMillis, "Tracks the duration of dependency calls made by logical operations"), labels, metricBuckets(meta.DependencyDurationMillis))
+	}
+
+	om := &PromOperationMetrics{
+		operationDurationMillis:  operationDurationMillis,
+		dependencyDurationMillis: dependencyDurationMillis,
+	}
+	if deps != nil {
+		om.db = deps.DB
+		om.downstream = deps.Downstream
+	}
+	return om
+}
+
+// Start begins timing the named operation and returns its handle.
+func (om *PromOperationMetrics) Start(operation string) interfaces.OperationHandle {
+	return &promOperationHandle{metrics: om, operation: operation, start: time.Now()}
+}
+
+// promOperationHandle times a single operation started by PromOperationMetrics.Start.
+type promOperationHandle struct {
+	metrics   *PromOperationMetrics
+	operation string
+	start     time.Time
+}
+
+// Dependency begins timing a dependency call of the operation and returns its handle.
+func (oh *promOperationHandle) Dependency(kind, name string, labelValues *models.DependencyLabelValues) interfaces.DependencyHandle {
+	return &promDependencyHandle{operation: oh, kind: kind, name: name, labelValues: labelValues, start: time.Now()}
+}
+
+// End records the operation duration with its outcome.
+func (oh *promOperationHandle) End(appErr *ae.AppError) {
+	if oh.metrics.operationDurationMillis != nil {
+		observe(oh.metrics.operationDurationMillis, "operation_duration_millis", durationMillis(time.Since(oh.start)), oh.operation, operationOutcome(appErr))
+	}
+}
+
+// promDependencyHandle times a single dependency call started by promOperationHandle.Dependency.
+type promDependencyHandle struct {
+	operation   *promOperationHandle
+	kind        string
+	name        string
+	labelValues *models.DependencyLabelValues
+	start       time.Time
+}
+
+// End records the dependency call duration with its outcome and routes it to the matching dependency metrics.
+func (dh *promDependencyHandle) End(appErr *ae.AppError) {
+	duration := time.Since(dh.start)
+	om := dh.operation.metrics
+	if om.dependencyDurationMillis != nil {
+		observe(om.dependencyDurationMillis, "operation_dependency_duration_millis", durationMillis(duration), dh.operation.operation, dh.kind, dh.name, operationOutcome(appErr))
+	}
+
+	if dh.labelValues == nil {
+		return
+	}
+	switch dh.kind {
+	case constants.DependencyDB:
+		if om.db != nil && dh.labelValues.DB != nil {
+			om.db.ObserveLatency(appErr, dh.labelValues.DB, duration)
+		}
+	case constants.DependencyDownstream:
+		if om.downstream != nil && dh.labelValues.Downstream != nil {
+			om.downstream.ObserveLatency(appErr == nil, dh.labelValues.Downstream, duration)
+		}
+	}
+}
+
+// operationOutcome returns the outcome label value for an operation or dependency call.
+func operationOutcome(appErr *ae.AppError) string {
+	if appErr != nil {
+		return constants.Failure
+	}
+	return constants.Success
+}
+
+// GetOperationDurationMillisMetric returns the underlying Prometheus HistogramVec
+// for the operation duration. This can be used for advanced operations.
+//
+// Returns nil if the metric was not configured during initialization.
+func (om *PromOperationMetrics) GetOperationDurationMillisMetric() *prometheus.HistogramVec {
+	return om.operationDurationMillis
+}
+
+// GetDependencyDurationMillisMetric returns the underlying Prometheus HistogramVec
+// for the dependency call duration. This can be used for advanced operations.
+//
+// Returns nil if the metric was not configured during initialization.
+func (om *PromOperationMetrics) GetDependencyDurationMillisMetric() *prometheus.HistogramVec {
+	return om.dependencyDurationMillis
+}
+
+// Collectors returns every collector registered by the operation metrics, skipping the metrics that were not configured.
+// The db and downstream metrics dependency observations are routed to are not included; use their own Collectors.
+func (om *PromOperationMetrics) Collectors() []prometheus.Collector {
+	var collectors []prometheus.Collector
+	if om.operationDurationMillis != nil {
+		collectors = append(collectors, om.operationDurationMillis)
+	}
+	if om.dependencyDurationMillis != nil {
+		collectors = append(collectors, om.dependencyDurationMillis)
+	}
+	return collectors
+}
@@ -0,0 +1,43 @@
+package prometheus
+
+import (
+	"testing"
+
+	"github.com/piyushkumar96/app-monitoring/constants"
+	"github.com/piyushkumar96/app-monitoring/interfaces"
+	"github.com/piyushkumar96/app-monitoring/models"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestOperationDependencyRoutedWithCallerLabelValues(t *testing.T) {
+	useTestRegistry(t)
+	dm := NewPromDatabaseMetrics(&models.DBMetricsMeta{
+		OperationsTotal:         &models.MetricMeta{Labels: dbTotalLabelNames},
+		OperationsLatencyMillis: &models.MetricMeta{Labels: dbLatencyLabelNames},
+	}).(*PromDBMetrics)
+	om := NewPromOperationMetrics(&models.OperationMetricsMeta{
+		DependencyDurationMillis: &models.MetricMeta{Labels: operationDepLabelNames},
+	}, &interfaces.Bundle{DB: dm}).(*PromOperationMetrics)
+
+	op := om.Start("get_user")
+	op.Dependency(constants.DependencyDB, "users", &models.DependencyLabelValues{
+		DB: &models.DBMetricsLabelValues{OpType: "select", Source: "user_repo", AdEntity: "users", IsTxn: "false"},
+	}).End(nil)
+	// Without label values for its kind, the call is only recorded in the dependency histogram
+	op.Dependency(constants.DependencyDB, "sessions", nil).End(nil)
+	op.End(nil)
+
+	if count, _ := histogramSample(t, dm.operationsLatencyMillis, "select", "user_repo", "users", "false"); count != 1 {
+		t.Errorf("db latency observations with the caller label values = %d, want 1", count)
+	}
+	if got := testutil.CollectAndCount(dm.operationsLatencyMillis); got != 1 {
+		t.Errorf("db latency series = %d, want 1", got)
+	}
+	if got := testutil.ToFloat64(dm.operationsTotal.WithLabelValues("select", "user_repo", "users", "false", constants.Success)); got != 1 {
+		t.Errorf("db successful operations = %v, want 1", got)
+	}
+	if got := testutil.CollectAndCount(om.dependencyDurationMillis); got != 2 {
+		t.Errorf("dependency duration series = %d, want 2", got)
+	}
+}
@@ -1,6 +1,7 @@
 package prometheus
 
 import (
+	"strconv"
 	"time"
 
 	"github.com/piyushkumar96/app-monitoring/constants"
@@ -11,6 +12,9 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+// defaultPublishSuccessRatioWindow is the sliding window length used when PSMetricsMeta.PublishSuccessRatioWindow is unset.
+const defaultPublishSuccessRatioWindow = 5 * time.Minute
+
 // NewPromPubSubMetrics creates and registers Prometheus metrics for pub/sub messaging operations.
 // It initializes counters for message counts and histograms for latencies and message sizes.
 //
@@ -19,45 +23,145 @@ import (
 //   - TotalMessagesPublished: Counter for published messages (total/success/failure)
 //   - MessagesPublishedLatencyMillis: Histogram for publish latency in milliseconds
 //   - MessagesPublishedSizeBytes: Histogram for published message size in bytes
+//   - MessagesPublishedWireBytes: Histogram for published message on-the-wire size in bytes
+//   - PublishConfirmLatencyMillis: Histogram for publisher confirmation latency in milliseconds
+//   - MessagesConsumedLatencyMillis: Histogram for consumed message processing latency in milliseconds
+//   - MessageQueueMillis: Histogram for the time consumed messages spent in the broker in milliseconds
+//   - MessageProcessMillis: Histogram for the time since consumed messages were received in milliseconds
+//   - PublishSuccessRatio: Gauge for the publish success ratio over a sliding window
+//   - PublisherQueueDepth: Gauge for the number of messages buffered by async publishers
+//   - SubscriptionState: Gauge for the connection state of subscription clients
+//   - PublishConsumeRatio: Gauge for the ratio of consumed to successfully published messages per entity
 //
 // Parameters:
 //   - meta: Configuration containing the namespace and metric settings.
 //     Set individual metric configs to nil to disable them.
+//     A nil meta (e.g. an omitted config section) returns the NoOp implementation.
 //
 // Returns an interfaces.PSMetricsInterface instance for logging pub/sub messaging metrics.
 func NewPromPubSubMetrics(meta *models.PSMetricsMeta) interfaces.PSMetricsInterface {
+	if metricsDisabled() || meta == nil {
+		return NewNoOpPromPSMetrics()
+	}
+	meta = withEnabledMetrics(meta, meta.EnabledMetrics, psMetricFields)
+	recordDashboardHints(meta.Namespace, meta, psMetricFields)
+	recordSeriesRateLimits(meta.Namespace, meta, psMetricFields)
+	recordExtraLabels(meta.Namespace, meta, psMetricFields)
+
 	var totalMessagesConsumed, totalMessagesPublished *prometheus.CounterVec
-	var messagesPublishedLatencyMillis, messagesPublishedSizeBytes *prometheus.HistogramVec
+	var messagesPublishedLatencyMillis, messagesPublishedSizeBytes, messagesPublishedWireBytes, publishConfirmLatencyMillis, messagesConsumedLatencyMillis *prometheus.HistogramVec
+	var messageQueueMillis, messageProcessMillis *prometheus.HistogramVec
+	var messagesPublishedLatencyDigest *TDigestVec
+	var latencyClamp *latencyClamp
+	var publishSuccessRatio *successRatioVec
+	var publisherQueueDepth, subscriptionState, publishConsumeRatio *prometheus.GaugeVec
+	var totalMessagesConsumedLabels, totalMessagesPublishedLabels, messagesPublishedLatencyMillisLabels, messagesPublishedSizeBytesLabels, messagesPublishedWireBytesLabels, publishConfirmLatencyMillisLabels, messagesConsumedLatencyMillisLabels []string
+	var messageQueueMillisLabels, messageProcessMillisLabels []string
 	if meta.TotalMessagesConsumed != nil {
-		totalMessagesConsumed = GetPromCounterVec(meta.Namespace, "pubsub_messages_consumed", "Number of messages consumed for total/success/failure scenario", meta.TotalMessagesConsumed.Labels)
+		labels := conventionalLabelOrder("pubsub_messages_consumed", meta.TotalMessagesConsumed.Labels, psConsumedLabelNames)
+		totalMessagesConsumed = GetPromCounterVec(meta.Namespace, "pubsub_messages_consumed", metricHelp(meta.TotalMessagesConsumed, "Number of messages consumed for total/success/failure scenario"), labels)
+		totalMessagesConsumedLabels = labels
 	}
 	if meta.TotalMessagesPublished != nil {
-		totalMessagesPublished = GetPromCounterVec(meta.Namespace, "pubsub_messages_published", "Tracks the number of published messages at pubSub service level", meta.TotalMessagesPublished.Labels)
+		labels := conventionalLabelOrder("pubsub_messages_published", meta.TotalMessagesPublished.Labels, psPublishedLabelNames)
+		totalMessagesPublished = GetPromCounterVec(meta.Namespace, "pubsub_messages_published", metricHelp(meta.TotalMessagesPublished, "Tracks the number of published messages at pubSub service level"), labels)
+		totalMessagesPublishedLabels = labels
 	}
 	if meta.MessagesPublishedLatencyMillis != nil {
-		messagesPublishedLatencyMillis = GetPromHistogramVec(meta.Namespace, "pubsub_messages_published_latency_millis", "Tracks the latencies to publish message at pubSub service level", meta.MessagesPublishedLatencyMillis.Labels, meta.MessagesPublishedLatencyMillis.Buckets)
+		labels := conventionalLabelOrder("pubsub_messages_published_latency_millis", meta.MessagesPublishedLatencyMillis.Labels, psEntityLabelNames)
+		messagesPublishedLatencyMillisLabels = labels
+		if len(meta.MessagesPublishedLatencyMillis.Quantiles) > 0 {
+			messagesPublishedLatencyDigest = GetPromTDigestVecWithMaxAge(meta.Namespace, "pubsub_messages_published_latency_millis", metricHelp(meta.MessagesPublishedLatencyMillis, "Tracks the latencies to publish messages at pubSub service level"), labels, meta.MessagesPublishedLatencyMillis.Quantiles, meta.MessagesPublishedLatencyMillis.QuantilesMaxAge, meta.MessagesPublishedLatencyMillis.QuantilesAgeBuckets)
+		} else {
+			messagesPublishedLatencyMillis = newNonNegativeHistogramVec(meta.Namespace, "pubsub_messages_published_latency_millis", metricHelp(meta.MessagesPublishedLatencyMillis, "Tracks the latencies to publish messages at pubSub service level"), labels, metricBuckets(meta.MessagesPublishedLatencyMillis))
+		}
+		latencyClamp = newLatencyClamp(meta.Namespace, "pubsub_messages_published", meta.MaxLatencyMillis)
 	}
 	if meta.MessagesPublishedSizeBytes != nil {
-		messagesPublishedSizeBytes = GetPromHistogramVec(meta.Namespace, "pubsub_messages_published_size_bytes", "Tracks the message size pubSub service level", meta.MessagesPublishedSizeBytes.Labels, meta.MessagesPublishedSizeBytes.Buckets)
+		labels := conventionalLabelOrder("pubsub_messages_published_size_bytes", meta.MessagesPublishedSizeBytes.Labels, psEntityLabelNames)
+		messagesPublishedSizeBytesLabels = labels
+		messagesPublishedSizeBytes = newNonNegativeHistogramVec(meta.Namespace, "pubsub_messages_published_size_bytes", metricHelp(meta.MessagesPublishedSizeBytes, "Tracks the size of published messages at pubSub service level"), labels, metricBuckets(meta.MessagesPublishedSizeBytes))
+	}
+	if meta.MessagesPublishedWireBytes != nil {
+		labels := conventionalLabelOrder("pubsub_messages_published_wire_bytes", meta.MessagesPublishedWireBytes.Labels, psEntityLabelNames)
+		messagesPublishedWireBytesLabels = labels
+		messagesPublishedWireBytes = newNonNegativeHistogramVec(meta.Namespace, "pubsub_messages_published_wire_bytes", metricHelp(meta.MessagesPublishedWireBytes, "Tracks the on-the-wire size of published messages at pubSub service level"), labels, metricBuckets(meta.MessagesPublishedWireBytes))
+	}
+	if meta.PublishConfirmLatencyMillis != nil {
+		labels := conventionalLabelOrder("pubsub_publish_confirm_latency_millis", meta.PublishConfirmLatencyMillis.Labels, psEntityLabelNames)
+		publishConfirmLatencyMillisLabels = labels
+		publishConfirmLatencyMillis = newNonNegativeHistogramVec(meta.Namespace, "pubsub_publish_confirm_latency_millis", metricHelp(meta.PublishConfirmLatencyMillis, "Tracks the time brokers take to confirm published messages at pubSub service level"), labels, metricBuckets(meta.PublishConfirmLatencyMillis))
+	}
+	if meta.MessagesConsumedLatencyMillis != nil {
+		labels := conventionalLabelOrder("pubsub_messages_consumed_latency_millis", meta.MessagesConsumedLatencyMillis.Labels, psEntityLabelNames)
+		messagesConsumedLatencyMillisLabels = labels
+		messagesConsumedLatencyMillis = newNonNegativeHistogramVec(meta.Namespace, "pubsub_messages_consumed_latency_millis", metricHelp(meta.MessagesConsumedLatencyMillis, "Tracks the time taken to process consumed messages at pubSub service level"), labels, metricBuckets(meta.MessagesConsumedLatencyMillis))
+	}
+	if meta.MessageQueueMillis != nil {
+		labels := conventionalLabelOrder("pubsub_message_queue_millis", meta.MessageQueueMillis.Labels, psEntityLabelNames)
+		messageQueueMillisLabels = labels
+		messageQueueMillis = newNonNegativeHistogramVec(meta.Namespace, "pubsub_message_queue_millis", metricHelp(meta.MessageQueueMillis, "Tracks the time consumed messages spent in the broker between their publish and receive at pubSub service level"), labels, metricBuckets(meta.MessageQueueMillis))
+	}
+	if meta.MessageProcessMillis != nil {
+		labels := conventionalLabelOrder("pubsub_message_process_millis", meta.MessageProcessMillis.Labels, psEntityLabelNames)
+		messageProcessMillisLabels = labels
+		messageProcessMillis = newNonNegativeHistogramVec(meta.Namespace, "pubsub_message_process_millis", metricHelp(meta.MessageProcessMillis, "Tracks the time taken to process consumed messages since their receive at pubSub service level"), labels, metricBuckets(meta.MessageProcessMillis))
+	}
+	if meta.PublishSuccessRatio != nil {
+		window := meta.PublishSuccessRatioWindow
+		if window <= 0 {
+			window = defaultPublishSuccessRatioWindow
+		}
+		labels := conventionalLabelOrder("pubsub_publish_success_ratio", meta.PublishSuccessRatio.Labels, psEntityLabelNames)
+		publishSuccessRatio = newSuccessRatioVec(meta.Namespace, "pubsub_publish_success_ratio", metricHelp(meta.PublishSuccessRatio, "Tracks the ratio of successfully published messages over a sliding window"), labels, window)
+	}
+	if meta.PublisherQueueDepth != nil {
+		publisherQueueDepth = GetPromGaugeVec(meta.Namespace, "pubsub_publisher_queue_depth", metricHelp(meta.PublisherQueueDepth, "Tracks the number of messages buffered by async publishers before sending"), meta.PublisherQueueDepth.Labels)
+	}
+	if meta.SubscriptionState != nil {
+		subscriptionState = GetPromGaugeVec(meta.Namespace, "pubsub_subscription_state", metricHelp(meta.SubscriptionState, "Tracks the connection state of subscription clients (0 disconnected, 1 connecting, 2 connected)"), meta.SubscriptionState.Labels)
+	}
+	if meta.PublishConsumeRatio != nil &&
+		requireLabel("pubsub_messages_consumed", "publish/consume ratio", constants.LabelEntity, totalMessagesConsumedLabels) &&
+		requireLabel("pubsub_messages_published", "publish/consume ratio", constants.LabelEntity, totalMessagesPublishedLabels) {
+		publishConsumeRatio = GetPromGaugeVec(meta.Namespace, "pubsub_publish_consume_ratio", metricHelp(meta.PublishConsumeRatio, "Tracks the ratio of consumed to successfully published messages per entity"), meta.PublishConsumeRatio.Labels)
 	}
 
 	return &PromPSMetrics{
-		totalMessagesConsumed:          totalMessagesConsumed,
-		totalMessagesPublished:         totalMessagesPublished,
-		messagesPublishedLatencyMillis: messagesPublishedLatencyMillis,
-		messagesPublishedSizeBytes:     messagesPublishedSizeBytes,
+		totalMessagesConsumed:                totalMessagesConsumed,
+		totalMessagesConsumedLabels:          totalMessagesConsumedLabels,
+		totalMessagesPublished:               totalMessagesPublished,
+		totalMessagesPublishedLabels:         totalMessagesPublishedLabels,
+		messagesPublishedLatencyMillis:       messagesPublishedLatencyMillis,
+		messagesPublishedLatencyDigest:       messagesPublishedLatencyDigest,
+		messagesPublishedLatencyMillisLabels: messagesPublishedLatencyMillisLabels,
+		latencyClamp:                         latencyClamp,
+		messagesPublishedSizeBytes:           messagesPublishedSizeBytes,
+		messagesPublishedSizeBytesLabels:     messagesPublishedSizeBytesLabels,
+		messagesPublishedWireBytes:           messagesPublishedWireBytes,
+		messagesPublishedWireBytesLabels:     messagesPublishedWireBytesLabels,
+		publishConfirmLatencyMillis:          publishConfirmLatencyMillis,
+		publishConfirmLatencyMillisLabels:    publishConfirmLatencyMillisLabels,
+		messagesConsumedLatencyMillis:        messagesConsumedLatencyMillis,
+		messagesConsumedLatencyMillisLabels:  messagesConsumedLatencyMillisLabels,
+		messageQueueMillis:                   messageQueueMillis,
+		messageQueueMillisLabels:             messageQueueMillisLabels,
+		messageProcessMillis:                 messageProcessMillis,
+		messageProcessMillisLabels:           messageProcessMillisLabels,
+		publishSuccessRatio:                  publishSuccessRatio,
+		publisherQueueDepth:                  publisherQueueDepth,
+		subscriptionState:                    subscriptionState,
+		publishConsumeRatio:                  publishConsumeRatio,
+		allowedOpTypes:                       allowlist(meta.AllowedOpTypes),
+		allowedStatuses:                      allowlist(meta.AllowedStatuses),
+		publishConsumeCounts:                 make(map[string]*publishConsumeCount),
 	}
 }
 
 // LogMetricsPre should be called before publishing a message or when starting to process a consumed message.
 // It increments the total message counters and returns the start time for latency calculation.
 func (psm *PromPSMetrics) LogMetricsPre(psMetricsLabelValues *models.PSMetricsLabelValues) time.Time {
-	if psm.totalMessagesPublished != nil {
-		psm.totalMessagesPublished.WithLabelValues(psMetricsLabelValues.Entity, psMetricsLabelValues.EntityOpType, constants.Total).Inc()
-	}
-	if psm.totalMessagesConsumed != nil {
-		psm.totalMessagesConsumed.WithLabelValues(string(psMetricsLabelValues.Source), psMetricsLabelValues.Entity, psMetricsLabelValues.EntityOpType, constants.Total, "").Inc()
-	}
+	psm.logPre(psMetricsLabelValues)
 	return time.Now()
 }
 
@@ -65,28 +169,332 @@ func (psm *PromPSMetrics) LogMetricsPre(psMetricsLabelValues *models.PSMetricsLa
 // It records the success/failure status, latency, and message size for publishing operations,
 // and success/failure status for consumption operations.
 func (psm *PromPSMetrics) LogMetricsPost(psMetricsLabelValues *models.PSMetricsLabelValues, eventTxnData *pubsub.EventTxnData) {
+	psm.logBatch([]models.PSBatchEntry{{LabelValues: psMetricsLabelValues, EventTxnData: eventTxnData}}, false)
+}
+
+// LogMetricsPostWithWireSize should be called after publishing a message over a compressing transport,
+// in place of LogMetricsPost. It records what LogMetricsPost records, with eventTxnData.MessageSizeInBytes
+// as the serialized size, and observes wireSizeBytes, the size actually sent, into the wire size histogram.
+// A zero wireSizeBytes (unknown) or a nil eventTxnData (consumed message) records no wire size.
+func (psm *PromPSMetrics) LogMetricsPostWithWireSize(psMetricsLabelValues *models.PSMetricsLabelValues, eventTxnData *pubsub.EventTxnData, wireSizeBytes int) {
+	psm.logBatch([]models.PSBatchEntry{{LabelValues: psMetricsLabelValues, EventTxnData: eventTxnData, WireSizeBytes: wireSizeBytes}}, false)
+}
+
+// LogMetricsPostOutcome should be called after processing a consumed message, in place of LogMetricsPost,
+// by consumers distinguishing more outcomes than success and failure. The outcome, one of the
+// constants.ConsumeOutcome* values, is recorded as the status of the consumed messages counter, with
+// ErrorCode as its error code, so that e.g. skipped (filtered) messages don't count as failures and
+// dead-lettered ones are told apart from redelivered ones. constants.Success and constants.Failure are recorded
+// as LogMetricsPost records them, and an unknown outcome is recorded as a failure.
+// The time since startTime, as returned by LogMetricsPre, is observed into the consume latency histogram
+// outside replay mode.
+//
+// Example:
+//
+//	start := psMetrics.LogMetricsPre(labelValues)
+//	if !wanted(msg) {
+//		psMetrics.LogMetricsPostOutcome(labelValues, constants.ConsumeOutcomeSkipped, start)
+//		return msg.Ack()
+//	}
+func (psm *PromPSMetrics) LogMetricsPostOutcome(psMetricsLabelValues *models.PSMetricsLabelValues, outcome string, startTime time.Time) {
+	psMetricsLabelValues = psm.withAllowedOpType(psMetricsLabelValues, "pubsub_messages_consumed")
+	live := !psMetricsLabelValues.ReplayMode
+	if psm.publishConsumeRatio != nil && live {
+		psm.recordPublishConsume(psMetricsLabelValues.Entity, false)
+	}
+	if psm.totalMessagesConsumed != nil {
+		counterWith(psm.totalMessagesConsumed, psm.consumedLabelValues(psMetricsLabelValues, consumeStatus(outcome), psMetricsLabelValues.ErrorCode)...).Inc()
+	}
+	if psm.messagesConsumedLatencyMillis != nil && live {
+		observe(psm.messagesConsumedLatencyMillis, "pubsub_messages_consumed_latency_millis", durationMillis(time.Since(startTime)), psm.entityLabelValues(psm.messagesConsumedLatencyMillis, psm.messagesConsumedLatencyMillisLabels, psMetricsLabelValues)...)
+	}
+}
+
+// LogMetricsPreWithTimestamps should be called when starting to process a consumed message, in place of
+// LogMetricsPre, by consumers that know when the broker received the message (receiveTime) and, optionally,
+// when it was published (publishTime). It increments the total message counters as LogMetricsPre does and
+// returns the timestamps to pass to LogMetricsPostWithTimestamps. Zero timestamps are unknown.
+//
+// Example:
+//
+//	timestamps := psMetrics.LogMetricsPreWithTimestamps(labelValues, msg.PublishTime, msg.ReceiveTime)
+//	defer psMetrics.LogMetricsPostWithTimestamps(labelValues, timestamps)
+func (psm *PromPSMetrics) LogMetricsPreWithTimestamps(psMetricsLabelValues *models.PSMetricsLabelValues, publishTime, receiveTime time.Time) models.PSMessageTimestamps {
+	psm.logPre(psMetricsLabelValues)
+	return models.PSMessageTimestamps{PublishTime: publishTime, ReceiveTime: receiveTime}
+}
+
+// LogMetricsPostWithTimestamps should be called after processing a consumed message, in place of LogMetricsPost.
+// It records the consumption as LogMetricsPost does, then decomposes the consumer lag into the time the message
+// spent in the broker, from its publish to its receive timestamp, into the queue histogram, and the time taken
+// to process it since its receive timestamp into the process histogram. Each histogram is skipped when one of
+// its timestamps is unknown, and neither is recorded in replay mode. A receive timestamp earlier than the
+// publish timestamp, due to clock skew between hosts, is observed as 0 and counted in
+// app_monitoring_negative_latency_observations_total.
+func (psm *PromPSMetrics) LogMetricsPostWithTimestamps(psMetricsLabelValues *models.PSMetricsLabelValues, timestamps models.PSMessageTimestamps) {
+	psm.logPost(psMetricsLabelValues, nil, 0)
+	if psMetricsLabelValues.ReplayMode || timestamps.ReceiveTime.IsZero() {
+		return
+	}
+	psMetricsLabelValues = psm.withAllowedOpType(psMetricsLabelValues, "")
+	if psm.messageQueueMillis != nil && !timestamps.PublishTime.IsZero() {
+		observe(psm.messageQueueMillis, "pubsub_message_queue_millis", durationMillis(timestamps.ReceiveTime.Sub(timestamps.PublishTime)), psm.entityLabelValues(psm.messageQueueMillis, psm.messageQueueMillisLabels, psMetricsLabelValues)...)
+	}
+	if psm.messageProcessMillis != nil {
+		observe(psm.messageProcessMillis, "pubsub_message_process_millis", durationMillis(time.Since(timestamps.ReceiveTime)), psm.entityLabelValues(psm.messageProcessMillis, psm.messageProcessMillisLabels, psMetricsLabelValues)...)
+	}
+}
+
+// LogMetricsBatch records a batch of completed pub/sub operations in one call.
+// For each entry it records what LogMetricsPre and LogMetricsPost would record together:
+// the total counters, then the outcome, latency and size from the entry's label values and event data.
+// Use it in hot loops (e.g. consumers draining a channel) to reduce per-message call overhead.
+// LogMetricsPost and LogMetricsPostWithWireSize record through the same path, as a batch of one entry.
+func (psm *PromPSMetrics) LogMetricsBatch(entries []models.PSBatchEntry) {
+	psm.logBatch(entries, true)
+}
+
+// logBatch records the outcome of each entry, preceded by its total counters when pre is set,
+// so that the single-entry Post methods, whose totals were counted by LogMetricsPre, share the batch path.
+func (psm *PromPSMetrics) logBatch(entries []models.PSBatchEntry, pre bool) {
+	for _, entry := range entries {
+		if pre {
+			psm.logPre(entry.LabelValues)
+		}
+		psm.logPost(entry.LabelValues, entry.EventTxnData, entry.WireSizeBytes)
+		if entry.EventTxnData != nil && entry.ConfirmLatency > 0 {
+			psm.RecordPublishConfirmLatency(entry.LabelValues, entry.ConfirmLatency)
+		}
+	}
+}
+
+// ObserveLatency records a publish whose duration was already measured elsewhere,
+// without the LogMetricsPre/LogMetricsPost pair. It increments the total and success/failure
+// publish counters and observes the duration into the publish latency histogram.
+// The message size histogram is not observed, since the size is not known from a duration alone.
+// In replay mode, only the counters are recorded.
+func (psm *PromPSMetrics) ObserveLatency(published bool, psMetricsLabelValues *models.PSMetricsLabelValues, duration time.Duration) {
+	status := constants.Failure
+	if published {
+		status = constants.Success
+	}
+	psm.ObserveWithStatus(psMetricsLabelValues, status, duration)
+}
+
+// ObserveWithStatus records a publish whose duration was already measured elsewhere, as ObserveLatency does,
+// with a status supplied by the caller (e.g. "throttled" for a publish the broker pushed back on) instead of
+// success or failure. Only the success and failure statuses update the publish success ratio, and only the
+// success status counts as published in the publish/consume ratio. Statuses outside AllowedStatuses are
+// recorded as "other".
+func (psm *PromPSMetrics) ObserveWithStatus(psMetricsLabelValues *models.PSMetricsLabelValues, status string, duration time.Duration) {
+	psMetricsLabelValues = psm.withAllowedOpType(psMetricsLabelValues, "pubsub_messages_published")
+	published := status == constants.Success
+	if psm.totalMessagesPublished != nil {
+		counterWith(psm.totalMessagesPublished, psm.publishedLabelValues(psMetricsLabelValues, constants.Total)...).Inc()
+		status = allowedStatus(psm.allowedStatuses, status, "pubsub_messages_published")
+		counterWith(psm.totalMessagesPublished, psm.publishedLabelValues(psMetricsLabelValues, status)...).Inc()
+	}
+	if psMetricsLabelValues.ReplayMode {
+		return
+	}
+	if psm.messagesPublishedLatencyMillis != nil {
+		observe(psm.messagesPublishedLatencyMillis, "pubsub_messages_published_latency_millis", psm.latencyClamp.clamp(durationMillis(duration)), psm.entityLabelValues(psm.messagesPublishedLatencyMillis, psm.messagesPublishedLatencyMillisLabels, psMetricsLabelValues)...)
+	}
+	if psm.messagesPublishedLatencyDigest != nil {
+		observe(psm.messagesPublishedLatencyDigest, "pubsub_messages_published_latency_millis", psm.latencyClamp.clamp(durationMillis(duration)), psm.entityLabelValues(psm.messagesPublishedLatencyDigest, psm.messagesPublishedLatencyMillisLabels, psMetricsLabelValues)...)
+	}
+	if psm.publishSuccessRatio != nil && (published || status == constants.Failure) {
+		psm.recordPublishOutcome(psMetricsLabelValues, published)
+	}
+	if psm.publishConsumeRatio != nil && published {
+		psm.recordPublishConsume(psMetricsLabelValues.Entity, true)
+	}
+}
+
+// RecordPublishConfirmLatency records the time between sending a message and the broker confirming it,
+// for brokers with publisher confirms. Unlike TimeTakenToPublish, which covers serializing and sending,
+// it isolates the broker-side acknowledgement. Call it once the confirmation arrives; when the broker
+// doesn't confirm publishes, don't call it. Like the publish latency, it is not recorded in replay mode.
+//
+// Example:
+//
+//	sent := time.Now()
+//	confirmation := channel.PublishWithDeferredConfirm(...)
+//	if confirmation.Wait() {
+//		psMetrics.RecordPublishConfirmLatency(labelValues, time.Since(sent))
+//	}
+func (psm *PromPSMetrics) RecordPublishConfirmLatency(psMetricsLabelValues *models.PSMetricsLabelValues, latency time.Duration) {
+	if psm.publishConfirmLatencyMillis == nil || psMetricsLabelValues.ReplayMode {
+		return
+	}
+	psMetricsLabelValues = psm.withAllowedOpType(psMetricsLabelValues, "")
+	observe(psm.publishConfirmLatencyMillis, "pubsub_publish_confirm_latency_millis", durationMillis(latency), psm.entityLabelValues(psm.publishConfirmLatencyMillis, psm.publishConfirmLatencyMillisLabels, psMetricsLabelValues)...)
+}
+
+// SetPublisherQueueDepth records the number of messages currently buffered by an async publisher
+// for the given entity. Publishers should call it whenever they enqueue or dequeue messages;
+// combined with the publish latency, it tells whether slowness is broker-side or buffer-side.
+func (psm *PromPSMetrics) SetPublisherQueueDepth(entity string, depth int) {
+	if psm.publisherQueueDepth != nil {
+		gaugeWith(psm.publisherQueueDepth, entity).Set(float64(depth))
+	}
+}
+
+// SetSubscriptionState records the connection state of the subscription client for the given source
+// (see the constants.SubscriptionState* values). Clients should call it on every connection lifecycle
+// event, so that broker connectivity problems surface before they show up as consume failures.
+func (psm *PromPSMetrics) SetSubscriptionState(source string, state int) {
+	if psm.subscriptionState != nil {
+		gaugeWith(psm.subscriptionState, source).Set(float64(state))
+	}
+}
+
+// logPre increments the total message counters for one operation.
+func (psm *PromPSMetrics) logPre(psMetricsLabelValues *models.PSMetricsLabelValues) {
+	psMetricsLabelValues = psm.withAllowedOpType(psMetricsLabelValues, "")
+	if psm.totalMessagesPublished != nil {
+		counterWith(psm.totalMessagesPublished, psm.publishedLabelValues(psMetricsLabelValues, constants.Total)...).Inc()
+	}
+	if psm.totalMessagesConsumed != nil {
+		counterWith(psm.totalMessagesConsumed, psm.consumedLabelValues(psMetricsLabelValues, constants.Total, "")...).Inc()
+	}
+}
+
+// logPost records the outcome, latency and sizes of one completed operation; a zero wireSizeBytes records
+// no wire size. Operations in replay mode are left out of the latency histograms and the ratio gauges.
+func (psm *PromPSMetrics) logPost(psMetricsLabelValues *models.PSMetricsLabelValues, eventTxnData *pubsub.EventTxnData, wireSizeBytes int) {
+	if eventTxnData != nil {
+		psMetricsLabelValues = psm.withAllowedOpType(psMetricsLabelValues, "pubsub_messages_published")
+	} else {
+		psMetricsLabelValues = psm.withAllowedOpType(psMetricsLabelValues, "pubsub_messages_consumed")
+	}
 	if psm.totalMessagesPublished != nil && eventTxnData != nil {
 		if eventTxnData.IsPublished {
-			psm.totalMessagesPublished.WithLabelValues(psMetricsLabelValues.Entity, psMetricsLabelValues.EntityOpType, constants.Success).Inc()
+			counterWith(psm.totalMessagesPublished, psm.publishedLabelValues(psMetricsLabelValues, constants.Success)...).Inc()
 		} else {
-			psm.totalMessagesPublished.WithLabelValues(psMetricsLabelValues.Entity, psMetricsLabelValues.EntityOpType, constants.Failure).Inc()
+			counterWith(psm.totalMessagesPublished, psm.publishedLabelValues(psMetricsLabelValues, constants.Failure)...).Inc()
 		}
 	}
-	if psm.messagesPublishedLatencyMillis != nil && eventTxnData != nil {
-		psm.messagesPublishedLatencyMillis.WithLabelValues(psMetricsLabelValues.Entity, psMetricsLabelValues.EntityOpType).Observe(float64(eventTxnData.TimeTakenToPublish.Milliseconds()))
+	live := !psMetricsLabelValues.ReplayMode
+	if psm.messagesPublishedLatencyMillis != nil && eventTxnData != nil && live {
+		observe(psm.messagesPublishedLatencyMillis, "pubsub_messages_published_latency_millis", psm.latencyClamp.clamp(durationMillis(eventTxnData.TimeTakenToPublish)), psm.entityLabelValues(psm.messagesPublishedLatencyMillis, psm.messagesPublishedLatencyMillisLabels, psMetricsLabelValues)...)
+	}
+	if psm.messagesPublishedLatencyDigest != nil && eventTxnData != nil && live {
+		observe(psm.messagesPublishedLatencyDigest, "pubsub_messages_published_latency_millis", psm.latencyClamp.clamp(durationMillis(eventTxnData.TimeTakenToPublish)), psm.entityLabelValues(psm.messagesPublishedLatencyDigest, psm.messagesPublishedLatencyMillisLabels, psMetricsLabelValues)...)
 	}
 	if psm.messagesPublishedSizeBytes != nil && eventTxnData != nil {
-		psm.messagesPublishedSizeBytes.WithLabelValues(psMetricsLabelValues.Entity, psMetricsLabelValues.EntityOpType).Observe(float64(eventTxnData.MessageSizeInBytes))
+		observe(psm.messagesPublishedSizeBytes, "pubsub_messages_published_size_bytes", float64(eventTxnData.MessageSizeInBytes), psm.entityLabelValues(psm.messagesPublishedSizeBytes, psm.messagesPublishedSizeBytesLabels, psMetricsLabelValues)...)
+	}
+	if psm.messagesPublishedWireBytes != nil && eventTxnData != nil && wireSizeBytes > 0 {
+		observe(psm.messagesPublishedWireBytes, "pubsub_messages_published_wire_bytes", float64(wireSizeBytes), psm.entityLabelValues(psm.messagesPublishedWireBytes, psm.messagesPublishedWireBytesLabels, psMetricsLabelValues)...)
+	}
+	if psm.publishSuccessRatio != nil && eventTxnData != nil && live {
+		psm.recordPublishOutcome(psMetricsLabelValues, eventTxnData.IsPublished)
+	}
+	if psm.publishConsumeRatio != nil && (eventTxnData == nil || eventTxnData.IsPublished) && live {
+		psm.recordPublishConsume(psMetricsLabelValues.Entity, eventTxnData != nil)
 	}
 	if psm.totalMessagesConsumed != nil {
 		if psMetricsLabelValues.ErrorCode != "" {
-			psm.totalMessagesConsumed.WithLabelValues(string(psMetricsLabelValues.Source), psMetricsLabelValues.Entity, psMetricsLabelValues.EntityOpType, constants.Failure, psMetricsLabelValues.ErrorCode).Inc()
+			counterWith(psm.totalMessagesConsumed, psm.consumedLabelValues(psMetricsLabelValues, constants.Failure, psMetricsLabelValues.ErrorCode)...).Inc()
 		} else {
-			psm.totalMessagesConsumed.WithLabelValues(string(psMetricsLabelValues.Source), psMetricsLabelValues.Entity, psMetricsLabelValues.EntityOpType, constants.Success, psMetricsLabelValues.ErrorCode).Inc()
+			counterWith(psm.totalMessagesConsumed, psm.consumedLabelValues(psMetricsLabelValues, constants.Success, psMetricsLabelValues.ErrorCode)...).Inc()
 		}
 	}
 }
 
+// recordPublishOutcome adds a publish outcome to the sliding window of its entity and op type.
+// The publish success ratio is computed from that window at scrape time.
+func (psm *PromPSMetrics) recordPublishOutcome(psMetricsLabelValues *models.PSMetricsLabelValues, published bool) {
+	psm.publishSuccessRatio.record(time.Now(), published, psMetricsLabelValues.Entity, psMetricsLabelValues.EntityOpType)
+}
+
+// recordPublishConsume counts a successful publish (published) or a consumed message of an entity
+// and updates the publish/consume ratio gauge once the entity has published messages.
+// Consumed messages are those posted without event data, as consumers do.
+func (psm *PromPSMetrics) recordPublishConsume(entity string, published bool) {
+	psm.mu.Lock()
+	counts, ok := psm.publishConsumeCounts[entity]
+	if !ok {
+		counts = &publishConsumeCount{}
+		psm.publishConsumeCounts[entity] = counts
+	}
+	if published {
+		counts.published++
+	} else {
+		counts.consumed++
+	}
+	if counts.published == 0 {
+		psm.mu.Unlock()
+		return
+	}
+	ratio := counts.consumed / counts.published
+	psm.mu.Unlock()
+
+	// The gauge is set outside mu, since invalid label values reach the observation error handler.
+	gaugeWith(psm.publishConsumeRatio, entity).Set(ratio)
+}
+
+// withAllowedOpType returns psMetricsLabelValues, or a copy with its entity op type folded into
+// constants.OpTypeOther when it is outside AllowedOpTypes. A fold is counted against metric unless
+// metric is empty, so that an operation logged with a pre and a post call is counted once, by the post.
+func (psm *PromPSMetrics) withAllowedOpType(psMetricsLabelValues *models.PSMetricsLabelValues, metric string) *models.PSMetricsLabelValues {
+	opType, folded := allowedOpType(psm.allowedOpTypes, psMetricsLabelValues.EntityOpType)
+	if !folded {
+		return psMetricsLabelValues
+	}
+	if metric != "" {
+		recordCardinalityProtection(metric, constants.ReasonOpTypeFolded)
+	}
+	labelValues := *psMetricsLabelValues
+	labelValues.EntityOpType = opType
+	return &labelValues
+}
+
+// publishedLabelValues returns the label values for the published messages counter,
+// including the optional and extra labels declared in its configured labels.
+func (psm *PromPSMetrics) publishedLabelValues(psMetricsLabelValues *models.PSMetricsLabelValues, status string) []string {
+	return extraLabelValues(psm.totalMessagesPublished, withOptionalLabels(psm.totalMessagesPublishedLabels,
+		[]string{psMetricsLabelValues.Entity, psMetricsLabelValues.EntityOpType, status},
+		psm.optionalLabels(psMetricsLabelValues)...), psMetricsLabelValues.ExtraLabels)
+}
+
+// consumeStatus returns the status label value of a consume outcome, or constants.Failure for an unknown
+// outcome, so that a caller-built outcome can't add unbounded values to the status label.
+func consumeStatus(outcome string) string {
+	switch outcome {
+	case constants.ConsumeOutcomeAcked, constants.ConsumeOutcomeNacked, constants.ConsumeOutcomeSkipped, constants.ConsumeOutcomeDeadLettered, constants.Success:
+		return outcome
+	}
+	return constants.Failure
+}
+
+// consumedLabelValues returns the label values for the consumed messages counter,
+// including the optional and extra labels declared in its configured labels and the consumer group, which only applies to it.
+func (psm *PromPSMetrics) consumedLabelValues(psMetricsLabelValues *models.PSMetricsLabelValues, status, errCode string) []string {
+	return extraLabelValues(psm.totalMessagesConsumed, withOptionalLabels(psm.totalMessagesConsumedLabels,
+		[]string{psMetricsLabelValues.Source, psMetricsLabelValues.Entity, psMetricsLabelValues.EntityOpType, status, errCode},
+		append(psm.optionalLabels(psMetricsLabelValues),
+			optionalLabel{name: constants.LabelConsumerGroup, value: psMetricsLabelValues.ConsumerGroup})...), psMetricsLabelValues.ExtraLabels)
+}
+
+// entityLabelValues returns the label values for a latency or size vec configured with labels,
+// including the optional labels declared in them and the extra labels declared on vec.
+func (psm *PromPSMetrics) entityLabelValues(vec any, labels []string, psMetricsLabelValues *models.PSMetricsLabelValues) []string {
+	return extraLabelValues(vec, withOptionalLabels(labels,
+		[]string{psMetricsLabelValues.Entity, psMetricsLabelValues.EntityOpType},
+		psm.optionalLabels(psMetricsLabelValues)...), psMetricsLabelValues.ExtraLabels)
+}
+
+// optionalLabels returns the optional labels supported by the pub/sub metrics.
+func (psm *PromPSMetrics) optionalLabels(psMetricsLabelValues *models.PSMetricsLabelValues) []optionalLabel {
+	return []optionalLabel{
+		{name: constants.LabelPartition, value: psMetricsLabelValues.Partition},
+		{name: constants.LabelReplay, value: strconv.FormatBool(psMetricsLabelValues.ReplayMode)},
+		{name: constants.LabelFormat, value: psMetricsLabelValues.Format},
+	}
+}
+
 // GetTotalMessagesConsumedMetric returns the underlying Prometheus CounterVec
 // for the messages consumed counter. This can be used for advanced operations.
 func (psm *PromPSMetrics) GetTotalMessagesConsumedMetric() *prometheus.CounterVec {
@@ -105,8 +513,139 @@ func (psm *PromPSMetrics) GetMessagesPublishedLatencyMillisMetric() *prometheus.
 	return psm.messagesPublishedLatencyMillis
 }
 
+// GetMessagesPublishedLatencyDigestMetric returns the underlying TDigestVec
+// for the message publish latency, when it is backed by a t-digest. This can be used for advanced operations.
+//
+// Returns nil if the metric was not configured with quantiles during initialization.
+func (psm *PromPSMetrics) GetMessagesPublishedLatencyDigestMetric() *TDigestVec {
+	return psm.messagesPublishedLatencyDigest
+}
+
 // GetMessagesPublishedSizeBytesMetric returns the underlying Prometheus HistogramVec
 // for the published message size. This can be used for advanced operations.
 func (psm *PromPSMetrics) GetMessagesPublishedSizeBytesMetric() *prometheus.HistogramVec {
 	return psm.messagesPublishedSizeBytes
 }
+
+// GetMessagesPublishedWireBytesMetric returns the underlying Prometheus HistogramVec
+// for the published message on-the-wire size. This can be used for advanced operations.
+//
+// Returns nil if the metric was not configured during initialization.
+func (psm *PromPSMetrics) GetMessagesPublishedWireBytesMetric() *prometheus.HistogramVec {
+	return psm.messagesPublishedWireBytes
+}
+
+// GetPublishConfirmLatencyMillisMetric returns the underlying Prometheus HistogramVec
+// for the publisher confirmation latency. This can be used for advanced operations.
+//
+// Returns nil if the metric was not configured during initialization.
+func (psm *PromPSMetrics) GetPublishConfirmLatencyMillisMetric() *prometheus.HistogramVec {
+	return psm.publishConfirmLatencyMillis
+}
+
+// GetMessagesConsumedLatencyMillisMetric returns the underlying Prometheus HistogramVec
+// for the consumed message processing latency. This can be used for advanced operations.
+//
+// Returns nil if the metric was not configured during initialization.
+func (psm *PromPSMetrics) GetMessagesConsumedLatencyMillisMetric() *prometheus.HistogramVec {
+	return psm.messagesConsumedLatencyMillis
+}
+
+// GetMessageQueueMillisMetric returns the underlying Prometheus HistogramVec
+// for the time consumed messages spent in the broker. This can be used for advanced operations.
+//
+// Returns nil if the metric was not configured during initialization.
+func (psm *PromPSMetrics) GetMessageQueueMillisMetric() *prometheus.HistogramVec {
+	return psm.messageQueueMillis
+}
+
+// GetMessageProcessMillisMetric returns the underlying Prometheus HistogramVec
+// for the time taken to process consumed messages since their receive. This can be used for advanced operations.
+//
+// Returns nil if the metric was not configured during initialization.
+func (psm *PromPSMetrics) GetMessageProcessMillisMetric() *prometheus.HistogramVec {
+	return psm.messageProcessMillis
+}
+
+// GetPublishSuccessRatioMetric returns the collector backing the publish success ratio gauge.
+// It can be registered against additional registries or gathered directly.
+//
+// Returns nil if the metric was not configured during initialization.
+func (psm *PromPSMetrics) GetPublishSuccessRatioMetric() prometheus.Collector {
+	if psm.publishSuccessRatio == nil {
+		return nil
+	}
+	return psm.publishSuccessRatio
+}
+
+// GetPublisherQueueDepthMetric returns the underlying Prometheus GaugeVec
+// for the publisher queue depth. This can be used for advanced operations.
+//
+// Returns nil if the metric was not configured during initialization.
+func (psm *PromPSMetrics) GetPublisherQueueDepthMetric() *prometheus.GaugeVec {
+	return psm.publisherQueueDepth
+}
+
+// GetSubscriptionStateMetric returns the underlying Prometheus GaugeVec
+// for the subscription state. This can be used for advanced operations.
+//
+// Returns nil if the metric was not configured during initialization.
+func (psm *PromPSMetrics) GetSubscriptionStateMetric() *prometheus.GaugeVec {
+	return psm.subscriptionState
+}
+
+// GetPublishConsumeRatioMetric returns the underlying Prometheus GaugeVec
+// for the publish/consume ratio. This can be used for advanced operations.
+//
+// Returns nil if the metric was not configured during initialization.
+func (psm *PromPSMetrics) GetPublishConsumeRatioMetric() *prometheus.GaugeVec {
+	return psm.publishConsumeRatio
+}
+
+// Collectors returns every collector registered by the pub/sub metrics, skipping the metrics that were not configured.
+func (psm *PromPSMetrics) Collectors() []prometheus.Collector {
+	var collectors []prometheus.Collector
+	if psm.totalMessagesConsumed != nil {
+		collectors = append(collectors, psm.totalMessagesConsumed)
+	}
+	if psm.totalMessagesPublished != nil {
+		collectors = append(collectors, psm.totalMessagesPublished)
+	}
+	if psm.messagesPublishedLatencyMillis != nil {
+		collectors = append(collectors, psm.messagesPublishedLatencyMillis)
+	}
+	if psm.messagesPublishedLatencyDigest != nil {
+		collectors = append(collectors, psm.messagesPublishedLatencyDigest)
+	}
+	if psm.messagesPublishedSizeBytes != nil {
+		collectors = append(collectors, psm.messagesPublishedSizeBytes)
+	}
+	if psm.messagesPublishedWireBytes != nil {
+		collectors = append(collectors, psm.messagesPublishedWireBytes)
+	}
+	if psm.publishConfirmLatencyMillis != nil {
+		collectors = append(collectors, psm.publishConfirmLatencyMillis)
+	}
+	if psm.messagesConsumedLatencyMillis != nil {
+		collectors = append(collectors, psm.messagesConsumedLatencyMillis)
+	}
+	if psm.messageQueueMillis != nil {
+		collectors = append(collectors, psm.messageQueueMillis)
+	}
+	if psm.messageProcessMillis != nil {
+		collectors = append(collectors, psm.messageProcessMillis)
+	}
+	if psm.publishSuccessRatio != nil {
+		collectors = append(collectors, psm.publishSuccessRatio)
+	}
+	if psm.publisherQueueDepth != nil {
+		collectors = append(collectors, psm.publisherQueueDepth)
+	}
+	if psm.subscriptionState != nil {
+		collectors = append(collectors, psm.subscriptionState)
+	}
+	if psm.publishConsumeRatio != nil {
+		collectors = append(collectors, psm.publishConsumeRatio)
+	}
+	return collectors
+}
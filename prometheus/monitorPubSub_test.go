@@ -0,0 +1,106 @@
+package prometheus
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/piyushkumar96/app-monitoring/constants"
+	"github.com/piyushkumar96/app-monitoring/models"
+
+	"github.com/piyushkumar96/generic-pubsub"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestPublishSuccessRatioComputedAtScrapeTime(t *testing.T) {
+	useTestRegistry(t)
+	psm := NewPromPubSubMetrics(&models.PSMetricsMeta{
+		TotalMessagesConsumed:     &models.MetricMeta{Labels: psConsumedLabelNames},
+		TotalMessagesPublished:    &models.MetricMeta{Labels: psPublishedLabelNames},
+		PublishSuccessRatio:       &models.MetricMeta{Labels: psEntityLabelNames},
+		PublishSuccessRatioWindow: time.Minute,
+		PublishConsumeRatio:       &models.MetricMeta{Labels: []string{constants.LabelEntity}},
+	}).(*PromPSMetrics)
+	orders := &models.PSMetricsLabelValues{Entity: "order", EntityOpType: "created"}
+
+	for _, published := range []bool{true, true, true, false} {
+		psm.LogMetricsPost(orders, &pubsub.EventTxnData{IsPublished: published})
+	}
+	// An outcome older than the window no longer counts, so its series is dropped at scrape time
+	psm.publishSuccessRatio.record(time.Now().Add(-2*time.Minute), false, "invoice", "created")
+
+	want := `
+# HELP pubsub_publish_success_ratio Tracks the ratio of successfully published messages over a sliding window
+# TYPE pubsub_publish_success_ratio gauge
+pubsub_publish_success_ratio{entity="order",op_type="created"} 0.75
+`
+	if err := testutil.CollectAndCompare(psm.GetPublishSuccessRatioMetric(), strings.NewReader(want)); err != nil {
+		t.Error(err)
+	}
+	if got := testutil.CollectAndCount(psm.GetPublishSuccessRatioMetric()); got != 1 {
+		t.Errorf("series after the stale window was dropped = %d, want 1", got)
+	}
+
+	psm.LogMetricsPost(orders, nil)
+	if got := testutil.ToFloat64(psm.publishConsumeRatio.WithLabelValues("order")); got != 1.0/3 {
+		t.Errorf("publish/consume ratio = %v, want %v", got, 1.0/3)
+	}
+}
+
+func TestPSMetricsBatchMatchesSingleEntryCalls(t *testing.T) {
+	published := &models.PSMetricsLabelValues{Source: "orders-pub", Entity: "order", EntityOpType: "created"}
+	consumed := &models.PSMetricsLabelValues{Source: "orders-sub", Entity: "order", EntityOpType: "created", ErrorCode: "ERR_DECODE"}
+	entries := []models.PSBatchEntry{
+		{LabelValues: published, EventTxnData: &pubsub.EventTxnData{IsPublished: true, MessageSizeInBytes: 256, TimeTakenToPublish: 3 * time.Millisecond}},
+		{LabelValues: published, EventTxnData: &pubsub.EventTxnData{IsPublished: false, MessageSizeInBytes: 64, TimeTakenToPublish: time.Millisecond}, WireSizeBytes: 32},
+		{LabelValues: consumed},
+	}
+
+	record := func(t *testing.T, log func(psm *PromPSMetrics)) string {
+		registry := useTestRegistry(t)
+		psm := NewPromPubSubMetrics(&models.PSMetricsMeta{
+			TotalMessagesConsumed:          &models.MetricMeta{Labels: psConsumedLabelNames},
+			TotalMessagesPublished:         &models.MetricMeta{Labels: psPublishedLabelNames},
+			MessagesPublishedLatencyMillis: &models.MetricMeta{Labels: psEntityLabelNames},
+			MessagesPublishedSizeBytes:     &models.MetricMeta{Labels: psEntityLabelNames},
+			MessagesPublishedWireBytes:     &models.MetricMeta{Labels: psEntityLabelNames},
+			PublishSuccessRatio:            &models.MetricMeta{Labels: psEntityLabelNames},
+			PublishConsumeRatio:            &models.MetricMeta{Labels: []string{constants.LabelEntity}},
+		}).(*PromPSMetrics)
+		log(psm)
+		families, err := registry.Gather()
+		if err != nil {
+			t.Fatalf("Gather() error = %v", err)
+		}
+		// Creation timestamps differ between the two runs, so they are left out of the comparison
+		for _, family := range families {
+			for _, metric := range family.GetMetric() {
+				if metric.Counter != nil {
+					metric.Counter.CreatedTimestamp = nil
+				}
+				if metric.Histogram != nil {
+					metric.Histogram.CreatedTimestamp = nil
+				}
+			}
+		}
+		return fmt.Sprint(families)
+	}
+
+	single := record(t, func(psm *PromPSMetrics) {
+		for _, entry := range entries {
+			psm.LogMetricsPre(entry.LabelValues)
+			if entry.WireSizeBytes > 0 {
+				psm.LogMetricsPostWithWireSize(entry.LabelValues, entry.EventTxnData, entry.WireSizeBytes)
+			} else {
+				psm.LogMetricsPost(entry.LabelValues, entry.EventTxnData)
+			}
+		}
+	})
+	batch := record(t, func(psm *PromPSMetrics) {
+		psm.LogMetricsBatch(entries)
+	})
+	if single != batch {
+		t.Errorf("series recorded by LogMetricsBatch differ from single-entry calls:\nbatch:  %s\nsingle: %s", batch, single)
+	}
+}
@@ -1,8 +1,11 @@
 package prometheus
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/piyushkumar96/app-monitoring/constants"
@@ -10,6 +13,7 @@ import (
 	"github.com/piyushkumar96/app-monitoring/models"
 
 	"github.com/gin-gonic/gin"
+	ae "github.com/piyushkumar96/app-error"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -21,10 +25,18 @@ import (
 //   - HTTPRequestsLatencyMillis: Histogram for request latency in milliseconds
 //   - HTTPRequestSizeBytes: Histogram for request body size in bytes
 //   - HTTPResponseSizeBytes: Histogram for response body size in bytes
+//   - HTTPResponseUncompressedSizeBytes: Histogram for response body size before compression in bytes
+//   - HTTPResponseCompressionRatio: Histogram for the ratio of compressed to uncompressed response body size
+//   - HTTPRequestBytesTotal: Counter for cumulative request bytes
+//   - HTTPResponseBytesTotal: Counter for cumulative response bytes
+//   - HTTPRequestsRejectedConcurrency: Counter for requests rejected by a concurrency limiter
+//   - HTTPRequestsAborted: Counter for requests aborted by a middleware before reaching their handler
+//   - HTTPMetricsMiddlewareOverheadMicros: Histogram for the middleware's own overhead in microseconds
 //
 // Parameters:
 //   - meta: Configuration containing the namespace and metric settings.
 //     Set individual metric configs to nil to disable them.
+//     A nil meta (e.g. an omitted config section) returns the NoOp implementation.
 //
 // Returns an interfaces.RouterMetricsInterface instance for logging HTTP endpoint metrics.
 //
@@ -41,27 +53,192 @@ import (
 //	    },
 //	})
 func NewPromRouterMetrics(meta *models.RouterMetricsMeta) interfaces.RouterMetricsInterface {
-	var httpRequests *prometheus.CounterVec
-	var httpRequestsLatencyMillis, httpRequestSizeBytes, httpResponseSizeBytes *prometheus.HistogramVec
+	if metricsDisabled() || meta == nil {
+		return NewNoOpPromRouterMetrics()
+	}
+	meta = withEnabledMetrics(meta, meta.EnabledMetrics, routerMetricFields)
+	recordDashboardHints(meta.Namespace, meta, routerMetricFields)
+	recordSeriesRateLimits(meta.Namespace, meta, routerMetricFields)
+	recordExtraLabels(meta.Namespace, meta, routerMetricFields)
+
+	var httpRequests, httpRequestBytesTotal, httpResponseBytesTotal, httpRequestsRejected, httpRequestsAborted *prometheus.CounterVec
+	var httpRequestsLatencyMillis, httpTTFBMillis, httpRequestSizeBytes, httpResponseSizeBytes, middlewareOverheadMicros *prometheus.HistogramVec
+	var httpResponseUncompressedSize, httpResponseCompressionRatio *prometheus.HistogramVec
+	var httpRequestsLatencyDigest *TDigestVec
+	var httpRequestsLatencyByProfile *bucketProfileVec
 
+	var httpRequestsLabels []string
+	var versionExtractor, routeGroupExtractor func(path string) string
+	var latencyClamp *latencyClamp
+	var trackHandlerName, trackContentType, trackDraining bool
+	var clientBucketFn func(gc *gin.Context) string
+	var callerHeader string
+	var httpRequestsLatencyLabels, httpTTFBMillisLabels []string
+
+	if !meta.DisableMethodNormalization {
+		registerCardinalityProtection()
+	}
 	if meta.HTTPRequests != nil {
-		httpRequests = GetPromCounterVec(meta.Namespace, "http_requests", "Tracks the number of HTTP requests at application level", meta.HTTPRequests.Labels)
+		labels := conventionalLabelOrder("http_requests", meta.HTTPRequests.Labels, routerRequestsLabelNames)
+		httpRequests = GetPromCounterVec(meta.Namespace, "http_requests", metricHelp(meta.HTTPRequests, "Tracks the number of HTTP requests at application level"), labels)
+		httpRequestsLabels = labels
+
+		if meta.TrackAPIVersion || meta.VersionExtractor != nil {
+			if requireLabel("http_requests", "api version tracking", constants.LabelAPIVersion, httpRequestsLabels) {
+				versionExtractor = meta.VersionExtractor
+				if versionExtractor == nil {
+					versionExtractor = DefaultVersionExtractor
+				}
+			}
+		}
+		if meta.TrackRouteGroup || meta.RouteGroupExtractor != nil {
+			if requireLabel("http_requests", "route group tracking", constants.LabelRouteGroup, httpRequestsLabels) {
+				routeGroupExtractor = meta.RouteGroupExtractor
+				if routeGroupExtractor == nil {
+					routeGroupExtractor = DefaultRouteGroupExtractor
+				}
+			}
+		}
+		if meta.TrackHandlerName {
+			trackHandlerName = requireLabel("http_requests", "handler name tracking", constants.LabelHandler, httpRequestsLabels)
+		}
+		if meta.ClientBucketFn != nil && requireLabel("http_requests", "client bucket tracking", constants.LabelClientBucket, httpRequestsLabels) {
+			clientBucketFn = meta.ClientBucketFn
+		}
+		if meta.TrackContentType {
+			trackContentType = requireLabel("http_requests", "content type tracking", constants.LabelContentType, httpRequestsLabels)
+			if trackContentType {
+				registerCardinalityProtection()
+			}
+		}
+		if meta.CallerHeader != "" && requireLabel("http_requests", "caller tracking", constants.LabelCaller, httpRequestsLabels) {
+			callerHeader = meta.CallerHeader
+			registerCardinalityProtection()
+		}
+		if meta.TrackDraining {
+			trackDraining = requireLabel("http_requests", "draining tracking", constants.LabelDraining, httpRequestsLabels)
+		}
 	}
 	if meta.HTTPRequestsLatencyMillis != nil {
-		httpRequestsLatencyMillis = GetPromHistogramVec(meta.Namespace, "http_request_latency_millis", "Tracks the latencies for HTTP requests at application level", meta.HTTPRequestsLatencyMillis.Labels, meta.HTTPRequestsLatencyMillis.Buckets)
+		labels := conventionalLabelOrder("http_request_latency_millis", meta.HTTPRequestsLatencyMillis.Labels, routerResponseLabelNames)
+		if len(meta.HTTPRequestsLatencyMillis.Quantiles) > 0 {
+			httpRequestsLatencyDigest = GetPromTDigestVecWithMaxAge(meta.Namespace, "http_request_latency_millis", metricHelp(meta.HTTPRequestsLatencyMillis, "Tracks the latencies for HTTP requests at application level"), labels, meta.HTTPRequestsLatencyMillis.Quantiles, meta.HTTPRequestsLatencyMillis.QuantilesMaxAge, meta.HTTPRequestsLatencyMillis.QuantilesAgeBuckets)
+		} else if len(meta.LatencyBucketProfiles) > 0 && meta.LatencyBucketProfileSelector != nil {
+			httpRequestsLatencyByProfile = newBucketProfileVec(meta.Namespace, "http_request_latency_millis", metricHelp(meta.HTTPRequestsLatencyMillis, "Tracks the latencies for HTTP requests at application level"), labels, metricBuckets(meta.HTTPRequestsLatencyMillis), meta.LatencyBucketProfiles, meta.LatencyBucketProfileSelector)
+		} else {
+			httpRequestsLatencyMillis = newNonNegativeHistogramVec(meta.Namespace, "http_request_latency_millis", metricHelp(meta.HTTPRequestsLatencyMillis, "Tracks the latencies for HTTP requests at application level"), labels, metricBuckets(meta.HTTPRequestsLatencyMillis))
+		}
+		latencyClamp = newLatencyClamp(meta.Namespace, "http_request", meta.MaxLatencyMillis)
+		httpRequestsLatencyLabels = labels
+	}
+	if meta.HTTPTTFBMillis != nil {
+		httpTTFBMillisLabels = conventionalLabelOrder("http_ttfb_millis", meta.HTTPTTFBMillis.Labels, routerResponseLabelNames)
+		httpTTFBMillis = newNonNegativeHistogramVec(meta.Namespace, "http_ttfb_millis", metricHelp(meta.HTTPTTFBMillis, "Tracks the time until the first byte of HTTP responses at application level"), httpTTFBMillisLabels, metricBuckets(meta.HTTPTTFBMillis))
 	}
 	if meta.HTTPRequestSizeBytes != nil {
-		httpRequestSizeBytes = GetPromHistogramVec(meta.Namespace, "http_request_size_bytes", "Tracks the size of HTTP requests at application level.", meta.HTTPRequestSizeBytes.Labels, meta.HTTPRequestSizeBytes.Buckets)
+		labels := conventionalLabelOrder("http_request_size_bytes", meta.HTTPRequestSizeBytes.Labels, routerResponseLabelNames)
+		httpRequestSizeBytes = newNonNegativeHistogramVec(meta.Namespace, "http_request_size_bytes", metricHelp(meta.HTTPRequestSizeBytes, "Tracks the size of HTTP requests at application level"), labels, metricBuckets(meta.HTTPRequestSizeBytes))
 	}
 	if meta.HTTPResponseSizeBytes != nil {
-		httpResponseSizeBytes = GetPromHistogramVec(meta.Namespace, "http_response_size_bytes", "Tracks the size of HTTP responses at application level", meta.HTTPResponseSizeBytes.Labels, meta.HTTPResponseSizeBytes.Buckets)
+		labels := conventionalLabelOrder("http_response_size_bytes", meta.HTTPResponseSizeBytes.Labels, routerResponseLabelNames)
+		httpResponseSizeBytes = newNonNegativeHistogramVec(meta.Namespace, "http_response_size_bytes", metricHelp(meta.HTTPResponseSizeBytes, "Tracks the size of HTTP responses at application level"), labels, metricBuckets(meta.HTTPResponseSizeBytes))
+	}
+	if meta.HTTPResponseUncompressedSizeBytes != nil {
+		labels := conventionalLabelOrder("http_response_uncompressed_size_bytes", meta.HTTPResponseUncompressedSizeBytes.Labels, routerResponseLabelNames)
+		httpResponseUncompressedSize = newNonNegativeHistogramVec(meta.Namespace, "http_response_uncompressed_size_bytes", metricHelp(meta.HTTPResponseUncompressedSizeBytes, "Tracks the size of HTTP responses before compression at application level"), labels, metricBuckets(meta.HTTPResponseUncompressedSizeBytes))
+	}
+	if meta.HTTPResponseCompressionRatio != nil {
+		httpResponseCompressionRatio = newNonNegativeHistogramVec(meta.Namespace, "http_response_compression_ratio", metricHelp(meta.HTTPResponseCompressionRatio, "Tracks the ratio of the compressed to the uncompressed size of HTTP responses at application level"), meta.HTTPResponseCompressionRatio.Labels, metricBuckets(meta.HTTPResponseCompressionRatio))
+	}
+	if meta.HTTPRequestBytesTotal != nil {
+		labels := conventionalLabelOrder("http_request_bytes_total", meta.HTTPRequestBytesTotal.Labels, routerResponseLabelNames)
+		httpRequestBytesTotal = GetPromCounterVec(meta.Namespace, "http_request_bytes_total", metricHelp(meta.HTTPRequestBytesTotal, "Tracks the cumulative bytes of HTTP requests at application level"), labels)
+	}
+	if meta.HTTPResponseBytesTotal != nil {
+		labels := conventionalLabelOrder("http_response_bytes_total", meta.HTTPResponseBytesTotal.Labels, routerResponseLabelNames)
+		httpResponseBytesTotal = GetPromCounterVec(meta.Namespace, "http_response_bytes_total", metricHelp(meta.HTTPResponseBytesTotal, "Tracks the cumulative bytes of HTTP responses at application level"), labels)
+	}
+	if meta.HTTPRequestsRejectedConcurrency != nil {
+		httpRequestsRejected = GetPromCounterVec(meta.Namespace, "http_requests_rejected_concurrency_total", metricHelp(meta.HTTPRequestsRejectedConcurrency, "Tracks the number of HTTP requests rejected by a concurrency limiter"), meta.HTTPRequestsRejectedConcurrency.Labels)
+	}
+	if meta.HTTPRequestsAborted != nil {
+		labels := conventionalLabelOrder("http_requests_aborted_total", meta.HTTPRequestsAborted.Labels, routerAbortedLabelNames)
+		httpRequestsAborted = GetPromCounterVec(meta.Namespace, "http_requests_aborted_total", metricHelp(meta.HTTPRequestsAborted, "Tracks the number of HTTP requests aborted by a middleware before reaching their handler"), labels)
+	}
+	if meta.HTTPMetricsMiddlewareOverheadMicros != nil {
+		middlewareOverheadMicros = newNonNegativeHistogramVec(meta.Namespace, "http_metrics_middleware_overhead_micros", metricHelp(meta.HTTPMetricsMiddlewareOverheadMicros, "Tracks the time the HTTP metrics middleware spends on its own bookkeeping per request"), nil, metricBuckets(meta.HTTPMetricsMiddlewareOverheadMicros))
+	}
+
+	var errorBudgetBurnRate *burnRateCollector
+	if meta.ErrorBudgetBurnRate != nil {
+		service := meta.SLOService
+		if service == "" {
+			service = meta.Namespace
+		}
+		errorBudgetBurnRate = newBurnRateCollector(meta.Namespace, "slo_error_budget_burn_rate", metricHelp(meta.ErrorBudgetBurnRate, "Tracks the rate at which HTTP request failures consume the error budget of the SLO"), service, meta.SLOTarget, meta.SLOWindow)
+	}
+
+	requestSizeClamp := newSizeClamp(meta.Namespace, "http_oversized_requests_total", "Counts HTTP requests whose size exceeded the configured maximum", meta.MaxRequestBytes)
+	responseSizeClamp := newSizeClamp(meta.Namespace, "http_oversized_responses_total", "Counts HTTP responses whose size exceeded the configured maximum", meta.MaxResponseBytes)
+
+	notFoundPolicy := meta.NotFoundPolicy
+	if notFoundPolicy == "" {
+		notFoundPolicy = constants.NotFoundPolicyGroup
 	}
 
+	now := meta.Now
+	if now == nil {
+		now = time.Now
+	}
+
+	// The middleware has nothing to record when every metric it observes is disabled
+	idle := httpRequests == nil && httpRequestsLatencyMillis == nil && httpRequestsLatencyDigest == nil && httpTTFBMillis == nil &&
+		httpRequestsLatencyByProfile == nil && httpRequestSizeBytes == nil && httpResponseSizeBytes == nil &&
+		httpResponseUncompressedSize == nil && httpResponseCompressionRatio == nil &&
+		httpRequestBytesTotal == nil && httpResponseBytesTotal == nil && httpRequestsAborted == nil &&
+		requestSizeClamp == nil && responseSizeClamp == nil && middlewareOverheadMicros == nil &&
+		errorBudgetBurnRate == nil && meta.AppErrors == nil
+
 	return &PromRouterMetrics{
-		httpRequests:              httpRequests,
-		httpRequestsLatencyMillis: httpRequestsLatencyMillis,
-		httpRequestSizeBytes:      httpRequestSizeBytes,
-		httpResponseSizeBytes:     httpResponseSizeBytes,
+		idle:                         idle,
+		httpRequests:                 httpRequests,
+		httpRequestsLabels:           httpRequestsLabels,
+		normalizeMethod:              !meta.DisableMethodNormalization,
+		versionExtractor:             versionExtractor,
+		routeGroupExtractor:          routeGroupExtractor,
+		trackHandlerName:             trackHandlerName,
+		clientBucketFn:               clientBucketFn,
+		trackContentType:             trackContentType,
+		callerHeader:                 callerHeader,
+		allowedCallers:               stringSet(meta.AllowedCallers),
+		trackDraining:                trackDraining,
+		trackPartialResponses:        meta.TrackPartialResponses,
+		requestIDKey:                 meta.RequestIDKey,
+		extraLabelsKey:               meta.ExtraLabelsKey,
+		skipPaths:                    stringSet(meta.SkipPaths),
+		acceptableStatuses:           routeStatusSets(meta.AcceptableStatuses),
+		notFoundPolicy:               notFoundPolicy,
+		httpRequestsLatencyMillis:    httpRequestsLatencyMillis,
+		httpRequestsLatencyDigest:    httpRequestsLatencyDigest,
+		httpRequestsLatencyByProfile: httpRequestsLatencyByProfile,
+		httpRequestsLatencyLabels:    httpRequestsLatencyLabels,
+		httpTTFBMillis:               httpTTFBMillis,
+		httpTTFBMillisLabels:         httpTTFBMillisLabels,
+		latencyClamp:                 latencyClamp,
+		httpRequestSizeBytes:         httpRequestSizeBytes,
+		httpResponseSizeBytes:        httpResponseSizeBytes,
+		httpResponseUncompressedSize: httpResponseUncompressedSize,
+		httpResponseCompressionRatio: httpResponseCompressionRatio,
+		requestSizeClamp:             requestSizeClamp,
+		responseSizeClamp:            responseSizeClamp,
+		httpRequestBytesTotal:        httpRequestBytesTotal,
+		httpResponseBytesTotal:       httpResponseBytesTotal,
+		httpRequestsRejected:         httpRequestsRejected,
+		httpRequestsAborted:          httpRequestsAborted,
+		middlewareOverheadMicros:     middlewareOverheadMicros,
+		errorBudgetBurnRate:          errorBudgetBurnRate,
+		appErrors:                    meta.AppErrors,
+		now:                          now,
 	}
 }
 
@@ -70,9 +247,21 @@ func NewPromRouterMetrics(meta *models.RouterMetricsMeta) interfaces.RouterMetri
 //
 // The middleware:
 //   - Skips metrics collection for the metrics endpoint itself (to avoid self-referential metrics)
+//     and for the configured SkipPaths (e.g. health checks and pprof)
+//   - Records requests matching no route according to NotFoundPolicy: under the "<unmatched>" path
+//     (the default), not at all, or under their raw URL path
+//   - Only calls the next handler, skipping all bookkeeping, when every metric it records is disabled,
+//     no AppErrors are harvested and the request context carries no span annotator
 //   - Increments total request count before processing
-//   - Records success/failure based on HTTP status code (2XX = success)
+//   - Records success/failure based on HTTP status code (2XX and the AcceptableStatuses of the route = success)
+//   - Records requests abandoned by the client (cancelled request context or status 499)
+//     as client_canceled instead of failure, so client disconnects don't inflate the error rate
+//   - Records 2XX responses that fail after writing body bytes as partial, when TrackPartialResponses is set
+//   - Adds a span event with the route, latency and status when the request context carries an
+//     interfaces.SpanAnnotator
 //   - Measures request latency, request size, and response size
+//   - Counts requests aborted by a later middleware (c.Abort()) before reaching their handler
+//   - Measures its own overhead (the time spent outside gc.Next()), when HTTPMetricsMiddlewareOverheadMicros is set
 //
 // Parameters:
 //   - metricsPath: The path where Prometheus metrics are exposed (e.g., "/metrics").
@@ -87,27 +276,122 @@ func NewPromRouterMetrics(meta *models.RouterMetricsMeta) interfaces.RouterMetri
 //	router.Use(routerMetrics.LogMetrics("/metrics"))
 func (rlm *PromRouterMetrics) LogMetrics(metricsPath string) gin.HandlerFunc {
 	return func(gc *gin.Context) {
-		// Skip metrics collection for the metrics endpoint itself
-		if gc.Request.URL.Path == metricsPath {
+		// Skip metrics collection for the metrics endpoint itself and the configured skip paths
+		if rlm.skipPath(metricsPath, gc.Request.URL.Path) {
+			gc.Next()
+			return
+		}
+
+		// Skip all bookkeeping when there is nothing to record for the request
+		if rlm.idle && interfaces.SpanAnnotatorFromContext(gc.Request.Context()) == nil {
 			gc.Next()
 			return
 		}
 
-		start := time.Now()
+		// Skip requests matching no route under NotFoundPolicyDrop
+		urlPath, ok := rlm.routePath(gc.FullPath(), gc.Request.URL.Path)
+		if !ok {
+			gc.Next()
+			return
+		}
+
+		start := rlm.now()
 		reqSize := float64(computeApproximateRequestSize(gc.Request))
-		urlPath := gc.FullPath()
+		method := gc.Request.Method
+		if rlm.normalizeMethod {
+			method = normalizeHTTPMethod(method)
+			if method == constants.MethodOther {
+				recordCardinalityProtection("http_requests", constants.ReasonMethodFolded)
+			}
+		}
 
-		if rlm.httpRequests != nil {
-			// Increment total request counter before processing
-			rlm.httpRequests.WithLabelValues(gc.Request.Method, "", urlPath, constants.Total).Inc()
+		// Resolve the optional labels of the request counter once per request
+		var counterLabels []optionalLabel
+		if rlm.versionExtractor != nil {
+			counterLabels = append(counterLabels, optionalLabel{name: constants.LabelAPIVersion, value: rlm.versionExtractor(urlPath)})
+		}
+		if rlm.routeGroupExtractor != nil {
+			counterLabels = append(counterLabels, optionalLabel{name: constants.LabelRouteGroup, value: rlm.routeGroupExtractor(urlPath)})
+		}
+		if rlm.trackHandlerName {
+			counterLabels = append(counterLabels, optionalLabel{name: constants.LabelHandler, value: gc.HandlerName()})
+		}
+		if rlm.clientBucketFn != nil {
+			counterLabels = append(counterLabels, optionalLabel{name: constants.LabelClientBucket, value: rlm.clientBucketFn(gc)})
+		}
+		var latencyLabels []optionalLabel
+		if rlm.callerHeader != "" {
+			caller := optionalLabel{name: constants.LabelCaller, value: rlm.caller(gc.GetHeader(rlm.callerHeader))}
+			counterLabels = append(counterLabels, caller)
+			latencyLabels = append(latencyLabels, caller)
+		}
+		if rlm.trackDraining {
+			draining := optionalLabel{name: constants.LabelDraining, value: drainingLabelValue()}
+			counterLabels = append(counterLabels, draining)
+			latencyLabels = append(latencyLabels, draining)
+		}
+
+		// Increment total request counter before processing. The content type and the extra label values
+		// are only known once the handler has returned, so with content type tracking or an extra labels key
+		// the total is counted afterwards to keep the same label values on the total, success and failure series.
+		countTotalAfter := rlm.trackContentType || rlm.extraLabelsKey != nil
+		if rlm.httpRequests != nil && !countTotalAfter {
+			counterWith(rlm.httpRequests, withOptionalLabels(rlm.httpRequestsLabels, []string{method, "", urlPath, constants.Total}, counterLabels...)...).Inc()
+		}
+
+		// Record write errors so responses cut short mid-stream can be told apart from complete ones
+		var partialWriter *partialResponseWriter
+		if rlm.trackPartialResponses {
+			partialWriter = &partialResponseWriter{ResponseWriter: gc.Writer}
+			gc.Writer = partialWriter
 		}
 
-		// Pass request to the next handler in chain
+		// Observe the time to first byte when the handler first writes the response
+		var ttfbWriter *firstByteWriter
+		if rlm.httpTTFBMillis != nil {
+			ttfbWriter = &firstByteWriter{ResponseWriter: gc.Writer, onFirstByte: func(status int) {
+				rlm.observeTTFB(rlm.now().Sub(start), method, strconv.Itoa(status), urlPath, latencyLabels, rlm.requestExtraLabels(gc))
+			}}
+			gc.Writer = ttfbWriter
+		}
+
+		// Pass request to the next handler in chain, timing it when the middleware overhead is measured
+		var nextStart, nextEnd time.Time
+		if rlm.middlewareOverheadMicros != nil {
+			nextStart = rlm.now()
+		}
 		gc.Next()
+		if rlm.middlewareOverheadMicros != nil {
+			nextEnd = rlm.now()
+		}
+
+		if ttfbWriter != nil {
+			gc.Writer = ttfbWriter.ResponseWriter
+		}
+		if partialWriter != nil {
+			gc.Writer = partialWriter.ResponseWriter
+		}
+
+		if rlm.appErrors != nil {
+			rlm.harvestAppErrors(gc)
+		}
+
+		if rlm.trackContentType {
+			contentType := normalizeContentType(gc.Writer.Header().Get("Content-Type"))
+			if contentType == constants.ContentTypeOther {
+				recordCardinalityProtection("http_requests", constants.ReasonContentTypeFolded)
+			}
+			counterLabels = append(counterLabels, optionalLabel{name: constants.LabelContentType, value: contentType})
+		}
+		extra := rlm.requestExtraLabels(gc)
+		if rlm.httpRequests != nil && countTotalAfter {
+			counterWith(rlm.httpRequests, extraLabelValues(rlm.httpRequests, withOptionalLabels(rlm.httpRequestsLabels, []string{method, "", urlPath, constants.Total}, counterLabels...), extra)...).Inc()
+		}
 
 		// Collect response metrics after handler completes
 		httpCode := strconv.Itoa(gc.Writer.Status())
-		elapsed := float64(time.Since(start)) / float64(time.Millisecond)
+		end := rlm.now()
+		elapsed := durationMillis(end.Sub(start))
 		respSize := float64(gc.Writer.Size())
 
 		// Parse HTTP code for success/failure determination
@@ -116,30 +400,397 @@ func (rlm *PromRouterMetrics) LogMetrics(metricsPath string) gin.HandlerFunc {
 			httpCodeInt = 0
 		}
 
-		// Record success/failure based on HTTP status code
+		// Determine success/failure based on HTTP status code
+		isSuccess := rlm.isSuccessCode(urlPath, int(httpCodeInt))
+		var status string
+		if isSuccess && partialWriter != nil && isPartialResponse(gc, partialWriter) {
+			status = constants.Partial
+		} else if isSuccess {
+			status = constants.Success
+		} else if httpCodeInt == constants.HTTPStatusClientClosedRequest || errors.Is(gc.Request.Context().Err(), context.Canceled) {
+			status = constants.ClientCanceled
+		} else {
+			status = constants.Failure
+		}
+		// Record the outcome, with the request ID as exemplar of failures when configured
+		var exemplar prometheus.Labels
+		if rlm.requestIDKey != nil {
+			exemplar = requestIDExemplar(contextValue(gc, rlm.requestIDKey))
+		}
 		if rlm.httpRequests != nil {
-			if httpCodeInt >= constants.HTTPStatus2XXMinValue && httpCodeInt <= constants.HTTPStatus2XXMaxValue {
-				rlm.httpRequests.WithLabelValues(gc.Request.Method, httpCode, urlPath, constants.Success).Inc()
+			counter := counterWith(rlm.httpRequests, extraLabelValues(rlm.httpRequests, withOptionalLabels(rlm.httpRequestsLabels, []string{method, httpCode, urlPath, status}, counterLabels...), extra)...)
+			if status == constants.Failure {
+				incWithExemplar(counter, exemplar)
 			} else {
-				rlm.httpRequests.WithLabelValues(gc.Request.Method, httpCode, urlPath, constants.Failure).Inc()
+				counter.Inc()
 			}
 		}
+		rlm.recordSLOOutcome(end, status)
 
-		// Record latency histogram
+		// Annotate the request span, when a span annotator is carried by the request context
+		interfaces.AnnotateSpan(gc.Request.Context(), method+" "+urlPath, end.Sub(start), status)
+
+		// Record latency histogram, with the request ID as exemplar when configured
+		latencyLabelValues := withOptionalLabels(rlm.httpRequestsLatencyLabels, []string{method, httpCode, urlPath}, latencyLabels...)
 		if rlm.httpRequestsLatencyMillis != nil {
-			rlm.httpRequestsLatencyMillis.WithLabelValues(gc.Request.Method, httpCode, urlPath).Observe(elapsed)
+			observeWithExemplar(rlm.httpRequestsLatencyMillis, "http_request_latency_millis", rlm.latencyClamp.clamp(elapsed), exemplar, extraLabelValues(rlm.httpRequestsLatencyMillis, latencyLabelValues, extra)...)
+		}
+		if rlm.httpRequestsLatencyDigest != nil {
+			observe(rlm.httpRequestsLatencyDigest, "http_request_latency_millis", rlm.latencyClamp.clamp(elapsed), extraLabelValues(rlm.httpRequestsLatencyDigest, latencyLabelValues, extra)...)
+		}
+		if rlm.httpRequestsLatencyByProfile != nil {
+			observeWithExemplar(rlm.httpRequestsLatencyByProfile, "http_request_latency_millis", rlm.latencyClamp.clamp(elapsed), exemplar, extraLabelValues(rlm.httpRequestsLatencyByProfile, latencyLabelValues, extra)...)
 		}
 
-		// Record request size histogram
+		// Responses without a body are written by gin after the middleware returns, so their first byte
+		// is recorded as the full latency
+		if ttfbWriter != nil && !ttfbWriter.wrote {
+			rlm.observeTTFB(end.Sub(start), method, httpCode, urlPath, latencyLabels, extra)
+		}
+
+		// Record request size histogram, counting oversized requests
+		clampedReqSize := rlm.requestSizeClamp.clamp(reqSize, urlPath)
 		if rlm.httpRequestSizeBytes != nil {
-			rlm.httpRequestSizeBytes.WithLabelValues(gc.Request.Method, httpCode, urlPath).Observe(reqSize)
+			observe(rlm.httpRequestSizeBytes, "http_request_size_bytes", clampedReqSize, extraLabelValues(rlm.httpRequestSizeBytes, []string{method, httpCode, urlPath}, extra)...)
 		}
 
-		// Record response size histogram
+		// Record response size histogram, counting oversized responses
+		clampedRespSize := rlm.responseSizeClamp.clamp(respSize, urlPath)
 		if rlm.httpResponseSizeBytes != nil {
-			rlm.httpResponseSizeBytes.WithLabelValues(gc.Request.Method, httpCode, urlPath).Observe(respSize)
+			observe(rlm.httpResponseSizeBytes, "http_response_size_bytes", clampedRespSize, extraLabelValues(rlm.httpResponseSizeBytes, []string{method, httpCode, urlPath}, extra)...)
+		}
+
+		// Record the size before compression and the compression ratio, when MeasureUncompressedSize measured them
+		if rlm.httpResponseUncompressedSize != nil || rlm.httpResponseCompressionRatio != nil {
+			if uncompressed, ok := uncompressedSize(gc); ok {
+				if rlm.httpResponseUncompressedSize != nil {
+					observe(rlm.httpResponseUncompressedSize, "http_response_uncompressed_size_bytes", uncompressed, extraLabelValues(rlm.httpResponseUncompressedSize, []string{method, httpCode, urlPath}, extra)...)
+				}
+				if rlm.httpResponseCompressionRatio != nil && uncompressed > 0 {
+					observe(rlm.httpResponseCompressionRatio, "http_response_compression_ratio", max(respSize, 0)/uncompressed, extraLabelValues(rlm.httpResponseCompressionRatio, []string{urlPath}, extra)...)
+				}
+			}
+		}
+
+		// Record cumulative request and response bytes
+		if rlm.httpRequestBytesTotal != nil {
+			counterWith(rlm.httpRequestBytesTotal, extraLabelValues(rlm.httpRequestBytesTotal, []string{method, httpCode, urlPath}, extra)...).Add(reqSize)
+		}
+		if rlm.httpResponseBytesTotal != nil && respSize > 0 {
+			counterWith(rlm.httpResponseBytesTotal, extraLabelValues(rlm.httpResponseBytesTotal, []string{method, httpCode, urlPath}, extra)...).Add(respSize)
+		}
+
+		// Record requests short-circuited by a middleware calling c.Abort()
+		if rlm.httpRequestsAborted != nil && gc.IsAborted() {
+			counterWith(rlm.httpRequestsAborted, extraLabelValues(rlm.httpRequestsAborted, []string{urlPath, httpCode}, extra)...).Inc()
+		}
+
+		// Record the middleware's own overhead: the bookkeeping before and after gc.Next()
+		if rlm.middlewareOverheadMicros != nil {
+			observe(rlm.middlewareOverheadMicros, "http_metrics_middleware_overhead_micros", durationMicros(nextStart.Sub(start)+rlm.now().Sub(nextEnd)))
+		}
+	}
+}
+
+// RecordConcurrencyRejection increments the concurrency rejection counter for path.
+// A concurrency limiter should call it right before rejecting a request (typically with 503),
+// so capacity rejections can be told apart from genuine errors. It is independent of LogMetrics
+// and of the limiter implementation; when the limiter runs after LogMetrics, the rejected request
+// is also recorded by LogMetrics with its 503 status.
+//
+// Example:
+//
+//	router.Use(func(c *gin.Context) {
+//	    select {
+//	    case sem <- struct{}{}:
+//	        defer func() { <-sem }()
+//	        c.Next()
+//	    default:
+//	        routerMetrics.RecordConcurrencyRejection(c.FullPath())
+//	        c.AbortWithStatus(http.StatusServiceUnavailable)
+//	    }
+//	})
+func (rlm *PromRouterMetrics) RecordConcurrencyRejection(path string) {
+	if rlm.httpRequestsRejected != nil {
+		counterWith(rlm.httpRequestsRejected, path).Inc()
+	}
+}
+
+// LogBatch records completed requests captured outside the gin middleware, such as the request log of a
+// load-test harness, into the same counters and histograms, e.g. to validate dashboards offline. It is
+// intended for offline replay and testing, not for production traffic, which LogMetrics records.
+//
+// Each entry is recorded as the middleware would record it, with its URL as the path label; use route
+// templates (e.g. "/users/:id") to match the series of live traffic. The status is derived from the code:
+// 2XX and the AcceptableStatuses of the URL are success, 499 is client_canceled and anything else is failure. Labels that only the middleware
+// can resolve are recorded empty (handler, content type, client bucket) or as unknown (caller), and no exemplars,
+// span events, aborted requests or middleware overhead are recorded.
+func (rlm *PromRouterMetrics) LogBatch(entries []models.HTTPMetrics) {
+	for _, entry := range entries {
+		rlm.logEntry(entry, constants.CallerUnknown)
+	}
+}
+
+// logEntry records one completed request of LogBatch or WrapHandler, with caller as the caller label value.
+func (rlm *PromRouterMetrics) logEntry(entry models.HTTPMetrics, caller string) {
+	method := entry.Method
+	if rlm.normalizeMethod {
+		method = normalizeHTTPMethod(method)
+		if method == constants.MethodOther {
+			recordCardinalityProtection("http_requests", constants.ReasonMethodFolded)
 		}
 	}
+	urlPath := entry.URL
+	httpCode := strconv.Itoa(entry.Code)
+
+	var counterLabels, latencyLabels []optionalLabel
+	if rlm.versionExtractor != nil {
+		counterLabels = append(counterLabels, optionalLabel{name: constants.LabelAPIVersion, value: rlm.versionExtractor(urlPath)})
+	}
+	if rlm.routeGroupExtractor != nil {
+		counterLabels = append(counterLabels, optionalLabel{name: constants.LabelRouteGroup, value: rlm.routeGroupExtractor(urlPath)})
+	}
+	if rlm.trackHandlerName {
+		counterLabels = append(counterLabels, optionalLabel{name: constants.LabelHandler})
+	}
+	if rlm.clientBucketFn != nil {
+		counterLabels = append(counterLabels, optionalLabel{name: constants.LabelClientBucket})
+	}
+	if rlm.trackContentType {
+		counterLabels = append(counterLabels, optionalLabel{name: constants.LabelContentType})
+	}
+	if rlm.callerHeader != "" {
+		callerLabel := optionalLabel{name: constants.LabelCaller, value: caller}
+		counterLabels = append(counterLabels, callerLabel)
+		latencyLabels = append(latencyLabels, callerLabel)
+	}
+	if rlm.trackDraining {
+		draining := optionalLabel{name: constants.LabelDraining, value: drainingLabelValue()}
+		counterLabels = append(counterLabels, draining)
+		latencyLabels = append(latencyLabels, draining)
+	}
+
+	status := constants.Failure
+	if rlm.isSuccessCode(urlPath, entry.Code) {
+		status = constants.Success
+	} else if entry.Code == constants.HTTPStatusClientClosedRequest {
+		status = constants.ClientCanceled
+	}
+	if rlm.httpRequests != nil {
+		counterWith(rlm.httpRequests, extraLabelValues(rlm.httpRequests, withOptionalLabels(rlm.httpRequestsLabels, []string{method, "", urlPath, constants.Total}, counterLabels...), entry.ExtraLabels)...).Inc()
+		counterWith(rlm.httpRequests, extraLabelValues(rlm.httpRequests, withOptionalLabels(rlm.httpRequestsLabels, []string{method, httpCode, urlPath, status}, counterLabels...), entry.ExtraLabels)...).Inc()
+	}
+	rlm.recordSLOOutcome(rlm.now(), status)
+
+	elapsed := rlm.latencyClamp.clamp(durationMillis(entry.ResponseTime))
+	latencyLabelValues := withOptionalLabels(rlm.httpRequestsLatencyLabels, []string{method, httpCode, urlPath}, latencyLabels...)
+	if rlm.httpRequestsLatencyMillis != nil {
+		observe(rlm.httpRequestsLatencyMillis, "http_request_latency_millis", elapsed, extraLabelValues(rlm.httpRequestsLatencyMillis, latencyLabelValues, entry.ExtraLabels)...)
+	}
+	if rlm.httpRequestsLatencyDigest != nil {
+		observe(rlm.httpRequestsLatencyDigest, "http_request_latency_millis", elapsed, extraLabelValues(rlm.httpRequestsLatencyDigest, latencyLabelValues, entry.ExtraLabels)...)
+	}
+	if rlm.httpRequestsLatencyByProfile != nil {
+		observe(rlm.httpRequestsLatencyByProfile, "http_request_latency_millis", elapsed, extraLabelValues(rlm.httpRequestsLatencyByProfile, latencyLabelValues, entry.ExtraLabels)...)
+	}
+
+	reqSize := float64(max(entry.RequestBodySizeBytes, 0))
+	respSize := float64(max(entry.ResponseBodySizeBytes, 0))
+	clampedReqSize := rlm.requestSizeClamp.clamp(reqSize, urlPath)
+	clampedRespSize := rlm.responseSizeClamp.clamp(respSize, urlPath)
+	if rlm.httpRequestSizeBytes != nil {
+		observe(rlm.httpRequestSizeBytes, "http_request_size_bytes", clampedReqSize, extraLabelValues(rlm.httpRequestSizeBytes, []string{method, httpCode, urlPath}, entry.ExtraLabels)...)
+	}
+	if rlm.httpResponseSizeBytes != nil {
+		observe(rlm.httpResponseSizeBytes, "http_response_size_bytes", clampedRespSize, extraLabelValues(rlm.httpResponseSizeBytes, []string{method, httpCode, urlPath}, entry.ExtraLabels)...)
+	}
+	if rlm.httpRequestBytesTotal != nil {
+		counterWith(rlm.httpRequestBytesTotal, extraLabelValues(rlm.httpRequestBytesTotal, []string{method, httpCode, urlPath}, entry.ExtraLabels)...).Add(reqSize)
+	}
+	if rlm.httpResponseBytesTotal != nil && respSize > 0 {
+		counterWith(rlm.httpResponseBytesTotal, extraLabelValues(rlm.httpResponseBytesTotal, []string{method, httpCode, urlPath}, entry.ExtraLabels)...).Add(respSize)
+	}
+}
+
+// stringSet returns the set of the given values, such as the skip paths or allowed callers of the router metrics.
+func stringSet(values []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(values))
+	for _, value := range values {
+		set[value] = struct{}{}
+	}
+	return set
+}
+
+// routeStatusSets returns the sets of the given status codes per route, such as the acceptable statuses of
+// the router metrics, or nil when there are none.
+func routeStatusSets(statuses map[string][]int) map[string]map[int]struct{} {
+	if len(statuses) == 0 {
+		return nil
+	}
+	sets := make(map[string]map[int]struct{}, len(statuses))
+	for route, codes := range statuses {
+		set := make(map[int]struct{}, len(codes))
+		for _, code := range codes {
+			set[code] = struct{}{}
+		}
+		sets[route] = set
+	}
+	return sets
+}
+
+// isSuccessCode reports whether the status code of a request to route is recorded as success: a 2XX code,
+// or one of the acceptable statuses configured for route.
+func (rlm *PromRouterMetrics) isSuccessCode(route string, code int) bool {
+	if code >= constants.HTTPStatus2XXMinValue && code <= constants.HTTPStatus2XXMaxValue {
+		return true
+	}
+	_, ok := rlm.acceptableStatuses[route][code]
+	return ok
+}
+
+// skipPath reports whether requests to path are not recorded, because path is the metrics path
+// or one of the configured skip paths. It is checked before any per-request work, so skipped
+// requests cost no allocations. Every router integration should go through it.
+func (rlm *PromRouterMetrics) skipPath(metricsPath, path string) bool {
+	if path == metricsPath {
+		return true
+	}
+	_, ok := rlm.skipPaths[path]
+	return ok
+}
+
+// routePath returns the route template a request matched, applying the NotFoundPolicy to requests matching
+// no route (an empty route), and false when the request isn't recorded.
+func (rlm *PromRouterMetrics) routePath(route, rawPath string) (string, bool) {
+	if route != "" {
+		return route, true
+	}
+	switch rlm.notFoundPolicy {
+	case constants.NotFoundPolicyDrop:
+		return "", false
+	case constants.NotFoundPolicyRaw:
+		return rawPath, true
+	default:
+		return constants.PathUnmatched, true
+	}
+}
+
+// contextValue returns the value stored under key in the gin context, falling back to the request context.
+func contextValue(gc *gin.Context, key any) any {
+	if value := gc.Value(key); value != nil {
+		return value
+	}
+	return gc.Request.Context().Value(key)
+}
+
+// requestExtraLabels returns the extra label values of the request stored under ExtraLabelsKey, or nil
+// when the key is not configured or no map[string]string is stored under it.
+func (rlm *PromRouterMetrics) requestExtraLabels(gc *gin.Context) map[string]string {
+	if rlm.extraLabelsKey == nil {
+		return nil
+	}
+	extra, _ := contextValue(gc, rlm.extraLabelsKey).(map[string]string)
+	return extra
+}
+
+// standardHTTPMethods is the set of HTTP methods recorded as-is when method normalization is enabled.
+var standardHTTPMethods = map[string]struct{}{
+	http.MethodGet:     {},
+	http.MethodHead:    {},
+	http.MethodPost:    {},
+	http.MethodPut:     {},
+	http.MethodPatch:   {},
+	http.MethodDelete:  {},
+	http.MethodConnect: {},
+	http.MethodOptions: {},
+	http.MethodTrace:   {},
+}
+
+// normalizeHTTPMethod returns method in uppercase when it is a standard HTTP method in any case (e.g. "get"
+// or "Post"), so that it doesn't split the series of its uppercase form, and constants.MethodOther otherwise.
+func normalizeHTTPMethod(method string) string {
+	method = strings.ToUpper(method)
+	if _, ok := standardHTTPMethods[method]; ok {
+		return method
+	}
+	return constants.MethodOther
+}
+
+// caller returns the caller label value of a request from its caller identity header value:
+// constants.CallerUnknown when the header is absent, the identity when it is in AllowedCallers,
+// and constants.CallerOther otherwise.
+func (rlm *PromRouterMetrics) caller(identity string) string {
+	identity = strings.TrimSpace(identity)
+	if identity == "" {
+		return constants.CallerUnknown
+	}
+	if _, ok := rlm.allowedCallers[identity]; ok {
+		return identity
+	}
+	recordCardinalityProtection("http_requests", constants.ReasonCallerFolded)
+	return constants.CallerOther
+}
+
+// knownContentTypes is the set of base media types recorded as-is by content type tracking.
+var knownContentTypes = map[string]struct{}{
+	"application/json":                  {},
+	"application/problem+json":          {},
+	"application/x-ndjson":              {},
+	"application/xml":                   {},
+	"application/javascript":            {},
+	"application/octet-stream":          {},
+	"application/pdf":                   {},
+	"application/zip":                   {},
+	"application/protobuf":              {},
+	"application/x-protobuf":            {},
+	"application/x-www-form-urlencoded": {},
+	"multipart/form-data":               {},
+	"text/css":                          {},
+	"text/csv":                          {},
+	"text/event-stream":                 {},
+	"text/html":                         {},
+	"text/plain":                        {},
+	"text/xml":                          {},
+	"image/gif":                         {},
+	"image/jpeg":                        {},
+	"image/png":                         {},
+	"image/svg+xml":                     {},
+	"image/webp":                        {},
+}
+
+// normalizeContentType returns the lower-cased base media type of a Content-Type header value,
+// an empty string when the header is absent, and constants.ContentTypeOther for unknown types.
+func normalizeContentType(contentType string) string {
+	baseType, _, _ := strings.Cut(contentType, ";")
+	baseType = strings.ToLower(strings.TrimSpace(baseType))
+	if baseType == "" {
+		return ""
+	}
+	if _, ok := knownContentTypes[baseType]; ok {
+		return baseType
+	}
+	return constants.ContentTypeOther
+}
+
+// DefaultVersionExtractor returns the first path segment that looks like an API version
+// ("v" followed by one or more digits, e.g. "v1" or "v12"), or an empty string when none is found.
+// It is used for the api_version label when RouterMetricsMeta.TrackAPIVersion is enabled.
+func DefaultVersionExtractor(path string) string {
+	for _, segment := range strings.Split(path, "/") {
+		if len(segment) >= 2 && segment[0] == 'v' && strings.Trim(segment[1:], "0123456789") == "" {
+			return segment
+		}
+	}
+	return ""
+}
+
+// DefaultRouteGroupExtractor returns the first segment of the route template (e.g. "admin" for
+// "/admin/users/:id"), or an empty string for the root path. Requests matching no route are grouped
+// under constants.PathUnmatched by default, which is returned as is.
+// It is used for the route_group label when RouterMetricsMeta.TrackRouteGroup is enabled.
+func DefaultRouteGroupExtractor(path string) string {
+	segment, _, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	return segment
 }
 
 // computeApproximateRequestSize calculates an approximate size of the HTTP request in bytes.
@@ -164,6 +815,22 @@ func computeApproximateRequestSize(r *http.Request) int {
 	return totalSize
 }
 
+// harvestAppErrors counts the distinct error codes of each error attached to the request with gc.Error
+// that is or wraps an *ae.AppError in the app metrics. Other attached errors are ignored.
+func (rlm *PromRouterMetrics) harvestAppErrors(gc *gin.Context) {
+	for _, ginErr := range gc.Errors {
+		var appErr *ae.AppError
+		if !errors.As(ginErr.Err, &appErr) || appErr == nil {
+			continue
+		}
+		errCodes := ErrorCodesFromChain(appErr)
+		if len(errCodes) == 0 {
+			errCodes = []string{constants.ErrorCodeUnknown}
+		}
+		rlm.appErrors.LogMetricsUnique(errCodes)
+	}
+}
+
 // GetHTTPRequestsMetric returns the underlying Prometheus CounterVec
 // for the HTTP requests counter. This can be used for advanced operations.
 //
@@ -180,6 +847,30 @@ func (rlm *PromRouterMetrics) GetHTTPRequestsLatencyMillisMetric() *prometheus.H
 	return rlm.httpRequestsLatencyMillis
 }
 
+// GetHTTPRequestsLatencyDigestMetric returns the underlying TDigestVec
+// for the HTTP request latency, when it is backed by a t-digest. This can be used for advanced operations.
+//
+// Returns nil if the metric was not configured with quantiles during initialization.
+func (rlm *PromRouterMetrics) GetHTTPRequestsLatencyDigestMetric() *TDigestVec {
+	return rlm.httpRequestsLatencyDigest
+}
+
+// GetHTTPRequestsLatencyByProfileMetric returns the HistogramVec of each latency bucket profile,
+// keyed by profile name, when the latency histogram is split into bucket profiles.
+//
+// Returns nil if the metric was not configured with bucket profiles during initialization.
+func (rlm *PromRouterMetrics) GetHTTPRequestsLatencyByProfileMetric() map[string]*prometheus.HistogramVec {
+	if rlm.httpRequestsLatencyByProfile == nil {
+		return nil
+	}
+	vecs := make(map[string]*prometheus.HistogramVec, len(rlm.httpRequestsLatencyByProfile.profiles)+1)
+	vecs[constants.BucketProfileDefault] = rlm.httpRequestsLatencyByProfile.defaultVec
+	for profile, vec := range rlm.httpRequestsLatencyByProfile.profiles {
+		vecs[profile] = vec
+	}
+	return vecs
+}
+
 // GetHTTPRequestSizeBytesMetric returns the underlying Prometheus HistogramVec
 // for the HTTP request size. This can be used for advanced operations.
 //
@@ -195,3 +886,152 @@ func (rlm *PromRouterMetrics) GetHTTPRequestSizeBytesMetric() *prometheus.Histog
 func (rlm *PromRouterMetrics) GetHTTPResponseSizeBytesMetric() *prometheus.HistogramVec {
 	return rlm.httpResponseSizeBytes
 }
+
+// GetHTTPResponseUncompressedSizeBytesMetric returns the underlying Prometheus HistogramVec
+// for the HTTP response size before compression. This can be used for advanced operations.
+//
+// Returns nil if the metric was not configured during initialization.
+func (rlm *PromRouterMetrics) GetHTTPResponseUncompressedSizeBytesMetric() *prometheus.HistogramVec {
+	return rlm.httpResponseUncompressedSize
+}
+
+// GetHTTPResponseCompressionRatioMetric returns the underlying Prometheus HistogramVec
+// for the HTTP response compression ratio. This can be used for advanced operations.
+//
+// Returns nil if the metric was not configured during initialization.
+func (rlm *PromRouterMetrics) GetHTTPResponseCompressionRatioMetric() *prometheus.HistogramVec {
+	return rlm.httpResponseCompressionRatio
+}
+
+// GetHTTPRequestBytesTotalMetric returns the underlying Prometheus CounterVec
+// for the cumulative HTTP request bytes. This can be used for advanced operations.
+//
+// Returns nil if the metric was not configured during initialization.
+func (rlm *PromRouterMetrics) GetHTTPRequestBytesTotalMetric() *prometheus.CounterVec {
+	return rlm.httpRequestBytesTotal
+}
+
+// GetHTTPResponseBytesTotalMetric returns the underlying Prometheus CounterVec
+// for the cumulative HTTP response bytes. This can be used for advanced operations.
+//
+// Returns nil if the metric was not configured during initialization.
+func (rlm *PromRouterMetrics) GetHTTPResponseBytesTotalMetric() *prometheus.CounterVec {
+	return rlm.httpResponseBytesTotal
+}
+
+// GetHTTPRequestsRejectedConcurrencyMetric returns the underlying Prometheus CounterVec
+// for the concurrency rejection counter. This can be used for advanced operations.
+//
+// Returns nil if the metric was not configured during initialization.
+func (rlm *PromRouterMetrics) GetHTTPRequestsRejectedConcurrencyMetric() *prometheus.CounterVec {
+	return rlm.httpRequestsRejected
+}
+
+// GetHTTPRequestsAbortedMetric returns the underlying Prometheus CounterVec
+// for the aborted requests counter. This can be used for advanced operations.
+//
+// Returns nil if the metric was not configured during initialization.
+func (rlm *PromRouterMetrics) GetHTTPRequestsAbortedMetric() *prometheus.CounterVec {
+	return rlm.httpRequestsAborted
+}
+
+// observeTTFB observes the time to first byte of a request into the TTFB histogram.
+func (rlm *PromRouterMetrics) observeTTFB(ttfb time.Duration, method, httpCode, urlPath string, latencyLabels []optionalLabel, extra map[string]string) {
+	observe(rlm.httpTTFBMillis, "http_ttfb_millis", durationMillis(ttfb), extraLabelValues(rlm.httpTTFBMillis, withOptionalLabels(rlm.httpTTFBMillisLabels, []string{method, httpCode, urlPath}, latencyLabels...), extra)...)
+}
+
+// recordSLOOutcome adds the outcome of a request completed at now to the error budget burn rate window.
+// Client-canceled requests say nothing about the service and are left out.
+func (rlm *PromRouterMetrics) recordSLOOutcome(now time.Time, status string) {
+	if rlm.errorBudgetBurnRate == nil || status == constants.ClientCanceled {
+		return
+	}
+	rlm.errorBudgetBurnRate.record(now, status == constants.Success)
+}
+
+// GetHTTPMetricsMiddlewareOverheadMicrosMetric returns the underlying Prometheus HistogramVec
+// for the middleware overhead. This can be used for advanced operations.
+//
+// Returns nil if the metric was not configured during initialization.
+func (rlm *PromRouterMetrics) GetHTTPMetricsMiddlewareOverheadMicrosMetric() *prometheus.HistogramVec {
+	return rlm.middlewareOverheadMicros
+}
+
+// GetHTTPTTFBMillisMetric returns the underlying Prometheus HistogramVec
+// for the time to first byte. This can be used for advanced operations.
+//
+// Returns nil if the metric was not configured during initialization.
+func (rlm *PromRouterMetrics) GetHTTPTTFBMillisMetric() *prometheus.HistogramVec {
+	return rlm.httpTTFBMillis
+}
+
+// GetErrorBudgetBurnRateMetric returns the collector backing the error budget burn rate gauge.
+// It can be registered against additional registries or gathered directly.
+//
+// Returns nil if the metric was not configured during initialization.
+func (rlm *PromRouterMetrics) GetErrorBudgetBurnRateMetric() prometheus.Collector {
+	if rlm.errorBudgetBurnRate == nil {
+		return nil
+	}
+	return rlm.errorBudgetBurnRate
+}
+
+// Collectors returns every collector registered by the router metrics, skipping the metrics that were
+// not configured, so they can be registered with (or unregistered from) another registry in one call.
+// Bucket profiles are returned as one HistogramVec per profile, the way they are registered.
+//
+// Example:
+//
+//	reg := prometheus.NewRegistry()
+//	reg.MustRegister(routerMetrics.(*prom.PromRouterMetrics).Collectors()...)
+func (rlm *PromRouterMetrics) Collectors() []prometheus.Collector {
+	var collectors []prometheus.Collector
+	if rlm.httpRequests != nil {
+		collectors = append(collectors, rlm.httpRequests)
+	}
+	if rlm.httpRequestsLatencyMillis != nil {
+		collectors = append(collectors, rlm.httpRequestsLatencyMillis)
+	}
+	if rlm.httpRequestsLatencyDigest != nil {
+		collectors = append(collectors, rlm.httpRequestsLatencyDigest)
+	}
+	if rlm.httpRequestsLatencyByProfile != nil {
+		for _, vec := range rlm.httpRequestsLatencyByProfile.vecs() {
+			collectors = append(collectors, vec)
+		}
+	}
+	if rlm.httpTTFBMillis != nil {
+		collectors = append(collectors, rlm.httpTTFBMillis)
+	}
+	if rlm.httpRequestSizeBytes != nil {
+		collectors = append(collectors, rlm.httpRequestSizeBytes)
+	}
+	if rlm.httpResponseSizeBytes != nil {
+		collectors = append(collectors, rlm.httpResponseSizeBytes)
+	}
+	if rlm.httpResponseUncompressedSize != nil {
+		collectors = append(collectors, rlm.httpResponseUncompressedSize)
+	}
+	if rlm.httpResponseCompressionRatio != nil {
+		collectors = append(collectors, rlm.httpResponseCompressionRatio)
+	}
+	if rlm.httpRequestBytesTotal != nil {
+		collectors = append(collectors, rlm.httpRequestBytesTotal)
+	}
+	if rlm.httpResponseBytesTotal != nil {
+		collectors = append(collectors, rlm.httpResponseBytesTotal)
+	}
+	if rlm.httpRequestsRejected != nil {
+		collectors = append(collectors, rlm.httpRequestsRejected)
+	}
+	if rlm.httpRequestsAborted != nil {
+		collectors = append(collectors, rlm.httpRequestsAborted)
+	}
+	if rlm.middlewareOverheadMicros != nil {
+		collectors = append(collectors, rlm.middlewareOverheadMicros)
+	}
+	if rlm.errorBudgetBurnRate != nil {
+		collectors = append(collectors, rlm.errorBudgetBurnRate)
+	}
+	return collectors
+}
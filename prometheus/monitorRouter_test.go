@@ -0,0 +1,565 @@
+package prometheus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/piyushkumar96/app-monitoring/constants"
+	"github.com/piyushkumar96/app-monitoring/models"
+
+	"github.com/gin-gonic/gin"
+	ae "github.com/piyushkumar96/app-error"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// newTestRouterMetrics creates router metrics against a fresh registry, recording the request counter and
+// the latency histogram with their default labels unless meta configures them.
+func newTestRouterMetrics(t testing.TB, meta *models.RouterMetricsMeta) *PromRouterMetrics {
+	t.Helper()
+	useTestRegistry(t)
+	if meta.HTTPRequests == nil {
+		meta.HTTPRequests = &models.MetricMeta{Labels: routerRequestsLabelNames}
+	}
+	if meta.HTTPRequestsLatencyMillis == nil {
+		meta.HTTPRequestsLatencyMillis = &models.MetricMeta{Labels: routerResponseLabelNames}
+	}
+	return NewPromRouterMetrics(meta).(*PromRouterMetrics)
+}
+
+// serve serves a request with method to target through engine, returning the recorded response.
+func serve(engine *gin.Engine, method, target string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	engine.ServeHTTP(recorder, httptest.NewRequest(method, target, nil))
+	return recorder
+}
+
+// requestCount returns the value of the request counter series of the label values.
+func requestCount(rlm *PromRouterMetrics, method, code, path, status string) float64 {
+	return testutil.ToFloat64(rlm.httpRequests.WithLabelValues(method, code, path, status))
+}
+
+// protectionCount returns the number of label values of metric folded for reason so far.
+func protectionCount(metric, reason string) float64 {
+	registerCardinalityProtection()
+	return testutil.ToFloat64(cardinalityProtection.WithLabelValues(metric, reason))
+}
+
+func TestLogMetricsFoldsUnknownMethods(t *testing.T) {
+	rlm := newTestRouterMetrics(t, &models.RouterMetricsMeta{})
+	engine := gin.New()
+	engine.Use(rlm.LogMetrics("/metrics"))
+	engine.Handle("BREW", "/coffee", func(c *gin.Context) { c.Status(http.StatusOK) })
+	folded := protectionCount("http_requests", constants.ReasonMethodFolded)
+
+	serve(engine, "BREW", "/coffee")
+
+	if got := requestCount(rlm, constants.MethodOther, "", "/coffee", constants.Total); got != 1 {
+		t.Errorf("total requests with method %s = %v, want 1", constants.MethodOther, got)
+	}
+	if got := requestCount(rlm, constants.MethodOther, "200", "/coffee", constants.Success); got != 1 {
+		t.Errorf("successful requests with method %s = %v, want 1", constants.MethodOther, got)
+	}
+	if got := testutil.CollectAndCount(rlm.httpRequests); got != 2 {
+		t.Errorf("request series = %d, want 2 (no series for the raw method)", got)
+	}
+	if got := protectionCount("http_requests", constants.ReasonMethodFolded) - folded; got != 1 {
+		t.Errorf("folded methods counted = %v, want 1", got)
+	}
+}
+
+func TestLogMetricsKeepsUnknownMethodsWithoutNormalization(t *testing.T) {
+	rlm := newTestRouterMetrics(t, &models.RouterMetricsMeta{DisableMethodNormalization: true})
+	engine := gin.New()
+	engine.Use(rlm.LogMetrics("/metrics"))
+	engine.Handle("BREW", "/coffee", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	serve(engine, "BREW", "/coffee")
+
+	if got := requestCount(rlm, "BREW", "200", "/coffee", constants.Success); got != 1 {
+		t.Errorf("successful requests with method BREW = %v, want 1", got)
+	}
+}
+
+func TestLogMetricsRecordsCanceledRequestsAsClientCanceled(t *testing.T) {
+	rlm := newTestRouterMetrics(t, &models.RouterMetricsMeta{})
+	engine := gin.New()
+	engine.Use(rlm.LogMetrics("/metrics"))
+	engine.GET("/reports", func(c *gin.Context) {
+		<-c.Request.Context().Done()
+		c.Status(http.StatusServiceUnavailable)
+	})
+	engine.GET("/exports", func(c *gin.Context) { c.Status(constants.HTTPStatusClientClosedRequest) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/reports", nil).WithContext(ctx))
+	serve(engine, http.MethodGet, "/exports")
+
+	if got := requestCount(rlm, http.MethodGet, "503", "/reports", constants.ClientCanceled); got != 1 {
+		t.Errorf("canceled requests = %v, want 1", got)
+	}
+	if got := requestCount(rlm, http.MethodGet, "503", "/reports", constants.Failure); got != 0 {
+		t.Errorf("canceled requests recorded as failures = %v, want 0", got)
+	}
+	if got := requestCount(rlm, http.MethodGet, "499", "/exports", constants.ClientCanceled); got != 1 {
+		t.Errorf("requests answered with 499 = %v, want 1", got)
+	}
+}
+
+func TestLogMetricsCountsAbortedRequests(t *testing.T) {
+	rlm := newTestRouterMetrics(t, &models.RouterMetricsMeta{
+		HTTPRequestsAborted: &models.MetricMeta{Labels: routerAbortedLabelNames},
+	})
+	handled := false
+	engine := gin.New()
+	engine.Use(rlm.LogMetrics("/metrics"))
+	engine.Use(func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			c.AbortWithStatus(http.StatusUnauthorized)
+		}
+	})
+	engine.GET("/orders", func(c *gin.Context) {
+		handled = true
+		c.Status(http.StatusOK)
+	})
+
+	serve(engine, http.MethodGet, "/orders")
+
+	if handled {
+		t.Fatal("handler ran for an aborted request")
+	}
+	if got := testutil.ToFloat64(rlm.httpRequestsAborted.WithLabelValues("/orders", "401")); got != 1 {
+		t.Errorf("aborted requests = %v, want 1", got)
+	}
+	if got := requestCount(rlm, http.MethodGet, "401", "/orders", constants.Failure); got != 1 {
+		t.Errorf("failed requests = %v, want 1", got)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	req.Header.Set("Authorization", "Bearer token")
+	engine.ServeHTTP(httptest.NewRecorder(), req)
+
+	if !handled {
+		t.Fatal("handler didn't run for an authorized request")
+	}
+	if got := testutil.CollectAndCount(rlm.httpRequestsAborted); got != 1 {
+		t.Errorf("aborted request series = %d, want 1 (handled requests aren't aborted)", got)
+	}
+}
+
+// brokenConnWriter is a response writer whose connection breaks after the first body write.
+type brokenConnWriter struct {
+	*httptest.ResponseRecorder
+	writes int
+}
+
+func (w *brokenConnWriter) Write(data []byte) (int, error) {
+	w.writes++
+	if w.writes > 1 {
+		return 0, errors.New("write: broken pipe")
+	}
+	return w.ResponseRecorder.Write(data)
+}
+
+func (w *brokenConnWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func TestLogMetricsRecordsPartialResponses(t *testing.T) {
+	rlm := newTestRouterMetrics(t, &models.RouterMetricsMeta{TrackPartialResponses: true})
+	engine := gin.New()
+	engine.Use(rlm.LogMetrics("/metrics"))
+	engine.GET("/stream", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+		_, _ = c.Writer.WriteString("first chunk")
+		_, _ = c.Writer.WriteString("second chunk")
+	})
+	engine.GET("/failed-stream", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+		_, _ = c.Writer.WriteString("first chunk")
+		_ = c.Error(errors.New("upstream closed"))
+	})
+
+	engine.ServeHTTP(&brokenConnWriter{ResponseRecorder: httptest.NewRecorder()}, httptest.NewRequest(http.MethodGet, "/stream", nil))
+	serve(engine, http.MethodGet, "/stream")
+	serve(engine, http.MethodGet, "/failed-stream")
+
+	if got := requestCount(rlm, http.MethodGet, "200", "/stream", constants.Partial); got != 1 {
+		t.Errorf("responses cut short by the connection = %v, want 1", got)
+	}
+	if got := requestCount(rlm, http.MethodGet, "200", "/stream", constants.Success); got != 1 {
+		t.Errorf("complete responses = %v, want 1", got)
+	}
+	if got := requestCount(rlm, http.MethodGet, "200", "/failed-stream", constants.Partial); got != 1 {
+		t.Errorf("responses failed by the handler after streaming = %v, want 1", got)
+	}
+}
+
+func TestPartialResponseWriterRecordsFirstWriteError(t *testing.T) {
+	engine := gin.New()
+	engine.GET("/stream", func(c *gin.Context) {
+		writer := &partialResponseWriter{ResponseWriter: c.Writer}
+		if _, err := writer.Write([]byte("first chunk")); err != nil || writer.writeErr != nil {
+			t.Errorf("first write: err = %v, recorded %v, want none", err, writer.writeErr)
+		}
+		_, err := writer.WriteString("second chunk")
+		_, _ = writer.Write([]byte("third chunk"))
+		if err == nil || writer.writeErr != err {
+			t.Errorf("recorded write error = %v, want the first one, %v", writer.writeErr, err)
+		}
+		if size := writer.Size(); size != len("first chunk") {
+			t.Errorf("size = %d, want the %d bytes written before the error", size, len("first chunk"))
+		}
+	})
+
+	engine.ServeHTTP(&brokenConnWriter{ResponseRecorder: httptest.NewRecorder()}, httptest.NewRequest(http.MethodGet, "/stream", nil))
+}
+
+// appErrorsRecorder records the error codes the middleware harvests.
+type appErrorsRecorder struct {
+	harvested [][]string
+}
+
+func (r *appErrorsRecorder) LogMetricsUnique(errCodes []string) {
+	r.harvested = append(r.harvested, errCodes)
+}
+
+func TestLogMetricsHarvestsAppErrors(t *testing.T) {
+	appErrors := &appErrorsRecorder{}
+	rlm := newTestRouterMetrics(t, &models.RouterMetricsMeta{AppErrors: appErrors})
+	engine := gin.New()
+	engine.Use(rlm.LogMetrics("/metrics"))
+	engine.GET("/orders", func(c *gin.Context) { c.Status(http.StatusOK) })
+	engine.GET("/failed-orders", func(c *gin.Context) {
+		_ = c.Error(fmt.Errorf("loading order: %w", testAppError("ERR_ORDER")))
+		_ = c.Error(&ae.AppError{ActualErr: errors.New("no code")})
+		_ = c.Error(errors.New("not an app error"))
+		c.Status(http.StatusInternalServerError)
+	})
+
+	serve(engine, http.MethodGet, "/orders")
+	if len(appErrors.harvested) != 0 {
+		t.Fatalf("harvested %q without attached errors, want nothing", appErrors.harvested)
+	}
+
+	serve(engine, http.MethodGet, "/failed-orders")
+	want := [][]string{{"ERR_ORDER"}, {constants.ErrorCodeUnknown}}
+	if !slices.EqualFunc(appErrors.harvested, want, slices.Equal[[]string]) {
+		t.Errorf("harvested %q, want %q (errors that aren't AppErrors ignored)", appErrors.harvested, want)
+	}
+}
+
+// BenchmarkLogMetricsIdle compares a request served through the middleware with every router metric
+// disabled against the same request served by a bare handler.
+func BenchmarkLogMetricsIdle(b *testing.B) {
+	useTestRegistry(b)
+	rlm := NewPromRouterMetrics(&models.RouterMetricsMeta{}).(*PromRouterMetrics)
+	if !rlm.idle {
+		b.Fatal("router metrics with every metric disabled aren't idle")
+	}
+	bare := gin.New()
+	bare.GET("/users/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+	idle := gin.New()
+	idle.Use(rlm.LogMetrics("/metrics"))
+	idle.GET("/users/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+	req := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+	recorder := httptest.NewRecorder()
+
+	b.Run("bare handler", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			bare.ServeHTTP(recorder, req)
+		}
+	})
+	b.Run("idle middleware", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			idle.ServeHTTP(recorder, req)
+		}
+	})
+}
+
+// fakeClock is a clock for RouterMetricsMeta.Now that only moves when advanced.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) advance(d time.Duration) { c.now = c.now.Add(d) }
+
+// histogramSample returns the number and the sum of the observations of the histogram series of the label values.
+func histogramSample(t *testing.T, vec *prometheus.HistogramVec, labelValues ...string) (uint64, float64) {
+	t.Helper()
+	var metric dto.Metric
+	if err := vec.WithLabelValues(labelValues...).(prometheus.Metric).Write(&metric); err != nil {
+		t.Fatalf("write histogram: %v", err)
+	}
+	return metric.GetHistogram().GetSampleCount(), metric.GetHistogram().GetSampleSum()
+}
+
+func TestLogMetricsRecordsTimeToFirstByte(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	rlm := newTestRouterMetrics(t, &models.RouterMetricsMeta{
+		HTTPTTFBMillis: &models.MetricMeta{Labels: routerResponseLabelNames},
+		Now:            clock.Now,
+	})
+	engine := gin.New()
+	engine.Use(rlm.LogMetrics("/metrics"))
+	engine.GET("/buffered", func(c *gin.Context) {
+		clock.advance(30 * time.Millisecond)
+		c.String(http.StatusOK, "whole body")
+	})
+	engine.GET("/streamed", func(c *gin.Context) {
+		clock.advance(10 * time.Millisecond)
+		c.Status(http.StatusOK)
+		_, _ = c.Writer.WriteString("first event")
+		c.Writer.Flush()
+		clock.advance(40 * time.Millisecond)
+		_, _ = c.Writer.WriteString("second event")
+	})
+	engine.GET("/empty", func(c *gin.Context) {
+		clock.advance(20 * time.Millisecond)
+		c.Status(http.StatusNoContent)
+	})
+
+	tests := []struct {
+		path        string
+		code        string
+		wantTTFB    float64
+		wantLatency float64
+	}{
+		{path: "/buffered", code: "200", wantTTFB: 30, wantLatency: 30},
+		{path: "/streamed", code: "200", wantTTFB: 10, wantLatency: 50},
+		{path: "/empty", code: "204", wantTTFB: 20, wantLatency: 20},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path[1:], func(t *testing.T) {
+			serve(engine, http.MethodGet, tt.path)
+
+			if _, got := histogramSample(t, rlm.httpTTFBMillis, http.MethodGet, tt.code, tt.path); got != tt.wantTTFB {
+				t.Errorf("time to first byte = %vms, want %vms", got, tt.wantTTFB)
+			}
+			if _, got := histogramSample(t, rlm.httpRequestsLatencyMillis, http.MethodGet, tt.code, tt.path); got != tt.wantLatency {
+				t.Errorf("latency = %vms, want %vms", got, tt.wantLatency)
+			}
+		})
+	}
+}
+
+func TestLogMetricsUppercasesMethods(t *testing.T) {
+	rlm := newTestRouterMetrics(t, &models.RouterMetricsMeta{})
+	engine := gin.New()
+	engine.Use(rlm.LogMetrics("/metrics"))
+	folded := protectionCount("http_requests", constants.ReasonMethodFolded)
+
+	serve(engine, "get", "/users")
+	serve(engine, "Get", "/users")
+	serve(engine, http.MethodGet, "/users")
+
+	if got := requestCount(rlm, http.MethodGet, "", constants.PathUnmatched, constants.Total); got != 3 {
+		t.Errorf("total requests with method GET = %v, want 3", got)
+	}
+	if got := testutil.CollectAndCount(rlm.httpRequests); got != 2 {
+		t.Errorf("request series = %d, want 2 (no series for get or Get)", got)
+	}
+	if got := protectionCount("http_requests", constants.ReasonMethodFolded) - folded; got != 0 {
+		t.Errorf("folded methods counted = %v, want 0 (lowercase methods aren't unknown)", got)
+	}
+}
+
+func TestLogMetricsNotFoundPolicies(t *testing.T) {
+	tests := []struct {
+		policy     string
+		wantPath   string
+		wantSeries int
+	}{
+		{policy: "", wantPath: constants.PathUnmatched, wantSeries: 4},
+		{policy: constants.NotFoundPolicyGroup, wantPath: constants.PathUnmatched, wantSeries: 4},
+		{policy: constants.NotFoundPolicyDrop, wantSeries: 2},
+		{policy: constants.NotFoundPolicyRaw, wantPath: "/wp-login.php", wantSeries: 4},
+	}
+	for _, tt := range tests {
+		name := tt.policy
+		if name == "" {
+			name = "default"
+		}
+		t.Run(name, func(t *testing.T) {
+			rlm := newTestRouterMetrics(t, &models.RouterMetricsMeta{NotFoundPolicy: tt.policy})
+			engine := gin.New()
+			engine.Use(rlm.LogMetrics("/metrics"))
+			engine.GET("/users/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			serve(engine, http.MethodGet, "/users/42")
+			serve(engine, http.MethodGet, "/wp-login.php")
+
+			if got := requestCount(rlm, http.MethodGet, "200", "/users/:id", constants.Success); got != 1 {
+				t.Errorf("matched requests = %v, want 1", got)
+			}
+			if got := testutil.CollectAndCount(rlm.httpRequests); got != tt.wantSeries {
+				t.Errorf("request series = %d, want %d", got, tt.wantSeries)
+			}
+			if tt.wantPath == "" {
+				return
+			}
+			if got := requestCount(rlm, http.MethodGet, "404", tt.wantPath, constants.Failure); got != 1 {
+				t.Errorf("unmatched requests under %s = %v, want 1", tt.wantPath, got)
+			}
+		})
+	}
+}
+
+func TestLogMetricsAcceptableStatusesPerRoute(t *testing.T) {
+	rlm := newTestRouterMetrics(t, &models.RouterMetricsMeta{
+		AcceptableStatuses: map[string][]int{"/orders": {http.StatusConflict}},
+	})
+	engine := gin.New()
+	engine.Use(rlm.LogMetrics("/metrics"))
+	engine.POST("/orders", func(c *gin.Context) { c.Status(http.StatusConflict) })
+	engine.POST("/payments", func(c *gin.Context) { c.Status(http.StatusConflict) })
+	engine.DELETE("/orders", func(c *gin.Context) { c.Status(http.StatusInternalServerError) })
+
+	serve(engine, http.MethodPost, "/orders")
+	serve(engine, http.MethodPost, "/payments")
+	serve(engine, http.MethodDelete, "/orders")
+
+	tests := []struct {
+		name   string
+		method string
+		code   string
+		path   string
+		status string
+	}{
+		{name: "acceptable status on its route", method: http.MethodPost, code: "409", path: "/orders", status: constants.Success},
+		{name: "same status on another route", method: http.MethodPost, code: "409", path: "/payments", status: constants.Failure},
+		{name: "other status on the route", method: http.MethodDelete, code: "500", path: "/orders", status: constants.Failure},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := requestCount(rlm, tt.method, tt.code, tt.path, tt.status); got != 1 {
+				t.Errorf("%s %s answered with %s recorded as %s = %v, want 1", tt.method, tt.path, tt.code, tt.status, got)
+			}
+		})
+	}
+}
+
+func TestWildcardRoutesRecordTheirTemplate(t *testing.T) {
+	handleFiles := func(c *gin.Context) { c.String(http.StatusOK, c.Param("filepath")) }
+
+	t.Run("LogMetrics", func(t *testing.T) {
+		rlm := newTestRouterMetrics(t, &models.RouterMetricsMeta{})
+		engine := gin.New()
+		engine.Use(rlm.LogMetrics("/metrics"))
+		engine.GET("/files/*filepath", handleFiles)
+
+		if body := serve(engine, http.MethodGet, "/files/a/b/c").Body.String(); body != "/a/b/c" {
+			t.Fatalf("served %q, want the wildcard /a/b/c", body)
+		}
+
+		if got := requestCount(rlm, http.MethodGet, "200", "/files/*filepath", constants.Success); got != 1 {
+			t.Errorf("requests under the route template = %v, want 1", got)
+		}
+		if got := testutil.CollectAndCount(rlm.httpRequests); got != 2 {
+			t.Errorf("request series = %d, want 2 (none for the expanded path)", got)
+		}
+	})
+	t.Run("WrapHandler", func(t *testing.T) {
+		rlm := newTestRouterMetrics(t, &models.RouterMetricsMeta{})
+		logs := useTestLogger(t, slog.LevelWarn)
+		engine := gin.New()
+		// The template is captured even when a middleware of the engine aborts the request
+		engine.Use(func(c *gin.Context) {
+			if c.Query("deny") != "" {
+				c.AbortWithStatus(http.StatusForbidden)
+			}
+		})
+		handler := WrapHandler(rlm, "/metrics", engine)
+		engine.GET("/files/*filepath", handleFiles)
+
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/files/a/b/c", nil))
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/files/a?deny=1", nil))
+
+		if got := requestCount(rlm, http.MethodGet, "200", "/files/*filepath", constants.Success); got != 1 {
+			t.Errorf("requests under the route template = %v, want 1", got)
+		}
+		if got := requestCount(rlm, http.MethodGet, "403", "/files/*filepath", constants.Failure); got != 1 {
+			t.Errorf("aborted requests under the route template = %v, want 1", got)
+		}
+		if got := testutil.CollectAndCount(rlm.httpRequests); got != 3 {
+			t.Errorf("request series = %d, want 3 (none for the expanded paths)", got)
+		}
+		if strings.Contains(logs.String(), "OnGinRoutesRegisteredBeforeWrap") {
+			t.Errorf("warned about routes registered after wrapping; logs: %s", logs.String())
+		}
+	})
+	t.Run("routes registered before WrapHandler", func(t *testing.T) {
+		rlm := newTestRouterMetrics(t, &models.RouterMetricsMeta{})
+		logs := useTestLogger(t, slog.LevelWarn)
+		engine := gin.New()
+		engine.GET("/files/*filepath", handleFiles)
+		WrapHandler(rlm, "/metrics", engine)
+
+		if !strings.Contains(logs.String(), `"code":"OnGinRoutesRegisteredBeforeWrap"`) {
+			t.Errorf("routes registered before wrapping weren't reported; logs: %s", logs.String())
+		}
+	})
+}
+
+func TestSkippedPathsCreateNoSeries(t *testing.T) {
+	ok := func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) }
+	tests := []struct {
+		name    string
+		handler func(rlm *PromRouterMetrics) http.Handler
+	}{
+		{name: "LogMetrics", handler: func(rlm *PromRouterMetrics) http.Handler {
+			engine := gin.New()
+			engine.Use(rlm.LogMetrics("/metrics"))
+			for _, path := range []string{"/metrics", "/healthz", "/users"} {
+				engine.GET(path, gin.WrapF(ok))
+			}
+			return engine
+		}},
+		{name: "WrapHandler", handler: func(rlm *PromRouterMetrics) http.Handler {
+			mux := http.NewServeMux()
+			for _, path := range []string{"/metrics", "/healthz", "/users"} {
+				mux.HandleFunc("GET "+path, ok)
+			}
+			return WrapHandler(rlm, "/metrics", mux)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rlm := newTestRouterMetrics(t, &models.RouterMetricsMeta{SkipPaths: []string{"/healthz"}})
+			handler := tt.handler(rlm)
+
+			for _, path := range []string{"/metrics", "/healthz"} {
+				recorder := httptest.NewRecorder()
+				handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+				if recorder.Code != http.StatusOK {
+					t.Fatalf("GET %s answered %d, want it served", path, recorder.Code)
+				}
+			}
+			if got := testutil.CollectAndCount(rlm.httpRequests); got != 0 {
+				t.Errorf("request series after skipped paths = %d, want 0", got)
+			}
+			if got := testutil.CollectAndCount(rlm.httpRequestsLatencyMillis); got != 0 {
+				t.Errorf("latency series after skipped paths = %d, want 0", got)
+			}
+
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users", nil))
+			if got := requestCount(rlm, http.MethodGet, "200", "/users", constants.Success); got != 1 {
+				t.Errorf("requests to a recorded path = %v, want 1", got)
+			}
+		})
+	}
+}
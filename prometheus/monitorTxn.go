@@ -0,0 +1,132 @@
+package prometheus
+
+import (
+	"time"
+
+	"github.com/piyushkumar96/app-monitoring/constants"
+	"github.com/piyushkumar96/app-monitoring/interfaces"
+	"github.com/piyushkumar96/app-monitoring/models"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// NewPromTxnMetrics creates and registers Prometheus metrics for database transaction lifecycles.
+// It initializes a counter for transactions by outcome and a histogram for transaction durations.
+//
+// The metrics track:
+//   - TransactionsTotal: Counter for total/commit/rollback transactions
+//   - TransactionDurationMillis: Histogram for transaction duration (begin to commit/rollback) in milliseconds
+//
+// Parameters:
+//   - meta: Configuration containing the namespace and metric settings.
+//     Set individual metric configs to nil to disable them.
+//     A nil meta (e.g. an omitted config section) returns the NoOp implementation.
+//
+// Returns an interfaces.TxnMetricsInterface instance that can be used to time transactions.
+//
+// Example:
+//
+//	txnMetrics := prometheus.NewPromTxnMetrics(&models.TxnMetricsMeta{
+//	    Namespace: "myapp",
+//	    TransactionsTotal: &models.MetricMeta{
+//	        Labels: []string{"source", "outcome"},
+//	    },
+//	    TransactionDurationMillis: &models.MetricMeta{
+//	        Labels:  []string{"source"},
+//	        Buckets: prometheus.GetPromExponentialBuckets(1, 2, 14),
+//	    },
+//	})
+//
+//	txn := txnMetrics.BeginTxn("OrderRepository")
+//	if err := tx.Commit(); err != nil {
+//	    txn.Rollback()
+//	} else {
+//	    txn.Commit()
+//	}
+func NewPromTxnMetrics(meta *models.TxnMetricsMeta) interfaces.TxnMetricsInterface {
+	if metricsDisabled() || meta == nil {
+		return NewNoOpPromTxnMetrics()
+	}
+	meta = withEnabledMetrics(meta, meta.EnabledMetrics, txnMetricFields)
+	recordDashboardHints(meta.Namespace, meta, txnMetricFields)
+	recordSeriesRateLimits(meta.Namespace, meta, txnMetricFields)
+
+	var transactionsTotal *prometheus.CounterVec
+	var transactionDurationMillis *prometheus.HistogramVec
+
+	if meta.TransactionsTotal != nil {
+		labels := conventionalLabelOrder("db_transactions_total", meta.TransactionsTotal.Labels, txnTotalLabelNames)
+		transactionsTotal = GetPromCounterVec(meta.Namespace, "db_transactions_total", metricHelp(meta.TransactionsTotal, "Number of database transactions for total/commit/rollback"), labels)
+	}
+	if meta.TransactionDurationMillis != nil {
+		transactionDurationMillis = newNonNegativeHistogramVec(meta.Namespace, "db_transaction_duration_millis", metricHelp(meta.TransactionDurationMillis, "Tracks the duration of database transactions from begin to commit or rollback"), meta.TransactionDurationMillis.Labels, metricBuckets(meta.TransactionDurationMillis))
+	}
+
+	return &PromTxnMetrics{
+		transactionsTotal:         transactionsTotal,
+		transactionDurationMillis: transactionDurationMillis,
+	}
+}
+
+// BeginTxn increments the total transactions counter and returns a handle timing the transaction.
+func (tm *PromTxnMetrics) BeginTxn(source string) interfaces.TxnHandle {
+	if tm.transactionsTotal != nil {
+		counterWith(tm.transactionsTotal, source, constants.Total).Inc()
+	}
+	return &promTxnHandle{metrics: tm, source: source, start: time.Now()}
+}
+
+// promTxnHandle times a single transaction started by PromTxnMetrics.BeginTxn.
+type promTxnHandle struct {
+	metrics *PromTxnMetrics
+	source  string
+	start   time.Time
+}
+
+// Commit records the transaction as committed along with its duration.
+func (th *promTxnHandle) Commit() {
+	th.end(constants.TxnCommit)
+}
+
+// Rollback records the transaction as rolled back along with its duration.
+func (th *promTxnHandle) Rollback() {
+	th.end(constants.TxnRollback)
+}
+
+// end records the outcome and duration of the transaction.
+func (th *promTxnHandle) end(outcome string) {
+	if th.metrics.transactionsTotal != nil {
+		counterWith(th.metrics.transactionsTotal, th.source, outcome).Inc()
+	}
+	if th.metrics.transactionDurationMillis != nil {
+		observe(th.metrics.transactionDurationMillis, "db_transaction_duration_millis", durationMillis(time.Since(th.start)), th.source)
+	}
+}
+
+// GetTransactionsTotalMetric returns the underlying Prometheus CounterVec
+// for the transactions counter. This can be used for advanced operations.
+//
+// Returns nil if the metric was not configured during initialization.
+func (tm *PromTxnMetrics) GetTransactionsTotalMetric() *prometheus.CounterVec {
+	return tm.transactionsTotal
+}
+
+// GetTransactionDurationMillisMetric returns the underlying Prometheus HistogramVec
+// for the transaction duration. This can be used for advanced operations.
+//
+// Returns nil if the metric was not configured during initialization.
+func (tm *PromTxnMetrics) GetTransactionDurationMillisMetric() *prometheus.HistogramVec {
+	return tm.transactionDurationMillis
+}
+
+// Collectors returns every collector registered by the transaction metrics, skipping the metrics that were not configured.
+func (tm *PromTxnMetrics) Collectors() []prometheus.Collector {
+	var collectors []prometheus.Collector
+	if tm.transactionsTotal != nil {
+		collectors = append(collectors, tm.transactionsTotal)
+	}
+	if tm.transactionDurationMillis != nil {
+		collectors = append(collectors, tm.transactionDurationMillis)
+	}
+	return collectors
+}
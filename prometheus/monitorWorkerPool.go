@@ -0,0 +1,177 @@
+package prometheus
+
+import (
+	"time"
+
+	"github.com/piyushkumar96/app-monitoring/constants"
+	"github.com/piyushkumar96/app-monitoring/interfaces"
+	"github.com/piyushkumar96/app-monitoring/models"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// NewPromWorkerPoolMetrics creates and registers Prometheus metrics for continuously-running worker pools,
+// separating the time tasks wait in the queue from the time they execute.
+//
+// The metrics track:
+//   - QueueWaitMillis: Histogram for the time tasks wait in the queue in milliseconds
+//   - ExecMillis: Histogram for the task execution duration in milliseconds
+//   - TasksTotal: Counter for completed tasks by status (success/failure)
+//   - QueueDepth: Gauge for the tasks enqueued but not started yet
+//
+// Parameters:
+//   - meta: Configuration containing the namespace and metric settings.
+//     Set individual metric configs to nil to disable them.
+//     A nil meta (e.g. an omitted config section) returns the NoOp implementation.
+//
+// Returns an interfaces.WorkerPoolMetricsInterface instance that can be used to time tasks.
+//
+// Example:
+//
+//	workerMetrics := prometheus.NewPromWorkerPoolMetrics(&models.WorkerPoolMetricsMeta{
+//	    Namespace: "myapp",
+//	    QueueWaitMillis: &models.MetricMeta{Labels: []string{"task_type"}},
+//	    ExecMillis:      &models.MetricMeta{Labels: []string{"task_type"}},
+//	    TasksTotal:      &models.MetricMeta{Labels: []string{"task_type", "status"}},
+//	    QueueDepth:      &models.MetricMeta{Labels: []string{"task_type"}},
+//	})
+//
+//	queue <- task{payload: p, handle: workerMetrics.Enqueue("send_email")}
+//
+//	// In a worker:
+//	t := <-queue
+//	t.handle.Start()
+//	t.handle.Done(sendEmail(t.payload))
+func NewPromWorkerPoolMetrics(meta *models.WorkerPoolMetricsMeta) interfaces.WorkerPoolMetricsInterface {
+	if metricsDisabled() || meta == nil {
+		return NewNoOpPromWorkerPoolMetrics()
+	}
+	meta = withEnabledMetrics(meta, meta.EnabledMetrics, workerPoolMetricFields)
+	recordDashboardHints(meta.Namespace, meta, workerPoolMetricFields)
+	recordSeriesRateLimits(meta.Namespace, meta, workerPoolMetricFields)
+
+	var queueWaitMillis, execMillis *prometheus.HistogramVec
+	var tasksTotal *prometheus.CounterVec
+	var queueDepth *prometheus.GaugeVec
+
+	if meta.QueueWaitMillis != nil {
+		labels := conventionalLabelOrder("worker_queue_wait_millis", meta.QueueWaitMillis.Labels, workerTaskLabelNames)
+		queueWaitMillis = newNonNegativeHistogramVec(meta.Namespace, "worker_queue_wait_millis", metricHelp(meta.QueueWaitMillis, "Tracks the time tasks wait in the queue before a worker starts them"), labels, metricBuckets(meta.QueueWaitMillis))
+	}
+	if meta.ExecMillis != nil {
+		labels := conventionalLabelOrder("worker_exec_millis", meta.ExecMillis.Labels, workerTaskLabelNames)
+		execMillis = newNonNegativeHistogramVec(meta.Namespace, "worker_exec_millis", metricHelp(meta.ExecMillis, "Tracks the execution duration of worker pool tasks"), labels, metricBuckets(meta.ExecMillis))
+	}
+	if meta.TasksTotal != nil {
+		labels := conventionalLabelOrder("worker_tasks_total", meta.TasksTotal.Labels, workerTotalLabelNames)
+		tasksTotal = GetPromCounterVec(meta.Namespace, "worker_tasks_total", metricHelp(meta.TasksTotal, "Number of worker pool tasks completed by status"), labels)
+	}
+	if meta.QueueDepth != nil {
+		labels := conventionalLabelOrder("worker_queue_depth", meta.QueueDepth.Labels, workerTaskLabelNames)
+		queueDepth = GetPromGaugeVec(meta.Namespace, "worker_queue_depth", metricHelp(meta.QueueDepth, "Tracks the number of tasks enqueued but not started yet"), labels)
+	}
+
+	return &PromWorkerPoolMetrics{
+		queueWaitMillis: queueWaitMillis,
+		execMillis:      execMillis,
+		tasksTotal:      tasksTotal,
+		queueDepth:      queueDepth,
+	}
+}
+
+// Enqueue increments the queue depth of the task type and returns a handle timing the task.
+func (wm *PromWorkerPoolMetrics) Enqueue(taskType string) interfaces.WorkerTaskHandle {
+	if wm.queueDepth != nil {
+		gaugeWith(wm.queueDepth, taskType).Inc()
+	}
+	return &promWorkerTaskHandle{metrics: wm, taskType: taskType, enqueued: time.Now()}
+}
+
+// promWorkerTaskHandle times a single task enqueued by PromWorkerPoolMetrics.Enqueue.
+type promWorkerTaskHandle struct {
+	metrics  *PromWorkerPoolMetrics
+	taskType string
+	enqueued time.Time
+	started  time.Time
+}
+
+// Start decrements the queue depth of the task type and records the time the task waited in the queue.
+// Calls after the first are ignored.
+func (th *promWorkerTaskHandle) Start() {
+	if !th.started.IsZero() {
+		return
+	}
+	th.started = time.Now()
+	if th.metrics.queueDepth != nil {
+		gaugeWith(th.metrics.queueDepth, th.taskType).Dec()
+	}
+	if th.metrics.queueWaitMillis != nil {
+		observe(th.metrics.queueWaitMillis, "worker_queue_wait_millis", durationMillis(th.started.Sub(th.enqueued)), th.taskType)
+	}
+}
+
+// Done records the task execution duration and counts the task with its outcome, starting it first when
+// Start was not called.
+func (th *promWorkerTaskHandle) Done(err error) {
+	th.Start()
+	if th.metrics.execMillis != nil {
+		observe(th.metrics.execMillis, "worker_exec_millis", durationMillis(time.Since(th.started)), th.taskType)
+	}
+	if th.metrics.tasksTotal != nil {
+		status := constants.Success
+		if err != nil {
+			status = constants.Failure
+		}
+		counterWith(th.metrics.tasksTotal, th.taskType, status).Inc()
+	}
+}
+
+// GetQueueWaitMillisMetric returns the underlying Prometheus HistogramVec
+// for the queue wait time. This can be used for advanced operations.
+//
+// Returns nil if the metric was not configured during initialization.
+func (wm *PromWorkerPoolMetrics) GetQueueWaitMillisMetric() *prometheus.HistogramVec {
+	return wm.queueWaitMillis
+}
+
+// GetExecMillisMetric returns the underlying Prometheus HistogramVec
+// for the task execution duration. This can be used for advanced operations.
+//
+// Returns nil if the metric was not configured during initialization.
+func (wm *PromWorkerPoolMetrics) GetExecMillisMetric() *prometheus.HistogramVec {
+	return wm.execMillis
+}
+
+// GetTasksTotalMetric returns the underlying Prometheus CounterVec
+// for the completed tasks counter. This can be used for advanced operations.
+//
+// Returns nil if the metric was not configured during initialization.
+func (wm *PromWorkerPoolMetrics) GetTasksTotalMetric() *prometheus.CounterVec {
+	return wm.tasksTotal
+}
+
+// GetQueueDepthMetric returns the underlying Prometheus GaugeVec
+// for the queue depth. This can be used for advanced operations.
+//
+// Returns nil if the metric was not configured during initialization.
+func (wm *PromWorkerPoolMetrics) GetQueueDepthMetric() *prometheus.GaugeVec {
+	return wm.queueDepth
+}
+
+// Collectors returns every collector registered by the worker pool metrics, skipping the metrics that were not configured.
+func (wm *PromWorkerPoolMetrics) Collectors() []prometheus.Collector {
+	var collectors []prometheus.Collector
+	if wm.queueWaitMillis != nil {
+		collectors = append(collectors, wm.queueWaitMillis)
+	}
+	if wm.execMillis != nil {
+		collectors = append(collectors, wm.execMillis)
+	}
+	if wm.tasksTotal != nil {
+		collectors = append(collectors, wm.tasksTotal)
+	}
+	if wm.queueDepth != nil {
+		collectors = append(collectors, wm.queueDepth)
+	}
+	return collectors
+}
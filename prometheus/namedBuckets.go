@@ -0,0 +1,42 @@
+package prometheus
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/piyushkumar96/app-monitoring/models"
+)
+
+var (
+	namedBucketsMu sync.RWMutex
+	// namedBuckets holds the bucket sets registered with RegisterBucketProfile, keyed by profile name.
+	namedBuckets = make(map[string][]float64)
+)
+
+// RegisterBucketProfile defines a named bucket set (e.g. "http_latency", "db_latency" or "payload_size") that
+// histograms reference through models.MetricMeta.BucketProfile, so bucket definitions shared by many metrics
+// are written once and stay consistent. Registering a name again replaces its buckets.
+//
+// The profile is resolved when a histogram is created, so register profiles before creating any metrics;
+// histograms already created keep their buckets. The buckets are copied.
+func RegisterBucketProfile(name string, buckets []float64) {
+	namedBucketsMu.Lock()
+	defer namedBucketsMu.Unlock()
+	namedBuckets[name] = slices.Clone(buckets)
+}
+
+// metricBuckets returns the buckets of a histogram configured by metricMeta: its Buckets when set, otherwise
+// the buckets of its BucketProfile. An unknown profile is logged and the default buckets are used.
+func metricBuckets(metricMeta *models.MetricMeta) []float64 {
+	if len(metricMeta.Buckets) > 0 || metricMeta.BucketProfile == "" {
+		return metricMeta.Buckets
+	}
+	namedBucketsMu.RLock()
+	buckets, ok := namedBuckets[metricMeta.BucketProfile]
+	namedBucketsMu.RUnlock()
+	if !ok {
+		logger().Error("unknown bucket profile, using the default buckets", "code", "OnUnknownBucketProfile", "profile", metricMeta.BucketProfile)
+		return nil
+	}
+	return buckets
+}
@@ -1,6 +1,7 @@
 package prometheus
 
 import (
+	"net/http"
 	"time"
 
 	"github.com/piyushkumar96/app-monitoring/interfaces"
@@ -27,6 +28,14 @@ func (n *NoOpPromRouterMetrics) LogMetrics(_ string) gin.HandlerFunc {
 	}
 }
 
+// RecordConcurrencyRejection does nothing.
+func (n *NoOpPromRouterMetrics) RecordConcurrencyRejection(_ string) {
+}
+
+// LogBatch does nothing.
+func (n *NoOpPromRouterMetrics) LogBatch(_ []models.HTTPMetrics) {
+}
+
 // NoOpPromDBMetrics is a no-operation implementation of DBMetricsInterface.
 // Use this for testing or when you want to disable Prometheus database metrics collection.
 type NoOpPromDBMetrics struct{}
@@ -41,10 +50,31 @@ func (n *NoOpPromDBMetrics) LogMetricsPre(_ *models.DBMetricsLabelValues) time.T
 	return time.Now()
 }
 
+// LogMetricsPreWithAcquire does nothing and returns the current time.
+func (n *NoOpPromDBMetrics) LogMetricsPreWithAcquire(_ *models.DBMetricsLabelValues, _ time.Time) time.Time {
+	return time.Now()
+}
+
 // LogMetricsPost does nothing.
 func (n *NoOpPromDBMetrics) LogMetricsPost(_ *ae.AppError, _ *models.DBMetricsLabelValues, _ time.Time) {
 }
 
+// LogMetricsPostErr does nothing.
+func (n *NoOpPromDBMetrics) LogMetricsPostErr(_ error, _ *models.DBMetricsLabelValues, _ time.Time) {
+}
+
+// ObserveLatency does nothing.
+func (n *NoOpPromDBMetrics) ObserveLatency(_ *ae.AppError, _ *models.DBMetricsLabelValues, _ time.Duration) {
+}
+
+// ObserveWithStatus does nothing.
+func (n *NoOpPromDBMetrics) ObserveWithStatus(_ *models.DBMetricsLabelValues, _ string, _ time.Duration) {
+}
+
+// RecordRowsAffected does nothing.
+func (n *NoOpPromDBMetrics) RecordRowsAffected(_ *models.DBMetricsLabelValues, _ int64) {
+}
+
 // NoOpPromDownstreamServiceMetrics is a no-operation implementation of DownstreamServiceMetricsInterface.
 // Use this for testing or when you want to disable Prometheus downstream service metrics collection.
 type NoOpPromDownstreamServiceMetrics struct{}
@@ -62,6 +92,46 @@ func (n *NoOpPromDownstreamServiceMetrics) LogMetricsPre(_ *models.DownstreamSer
 func (n *NoOpPromDownstreamServiceMetrics) LogMetricsPost(_ bool, _ *models.DownstreamServiceMetricsLabelValues, _ *models.HTTPMetrics) {
 }
 
+// LogMetricsPostResp does nothing.
+func (n *NoOpPromDownstreamServiceMetrics) LogMetricsPostResp(_ *models.DownstreamServiceMetricsLabelValues, _ *http.Response, _ time.Time, _ error) {
+}
+
+// LogMetricsPostBatch does nothing.
+func (n *NoOpPromDownstreamServiceMetrics) LogMetricsPostBatch(_ *models.DownstreamServiceMetricsLabelValues, _ *models.HTTPMetrics, _, _ int) {
+}
+
+// LogMetricsPostPaged does nothing.
+func (n *NoOpPromDownstreamServiceMetrics) LogMetricsPostPaged(_ *models.DownstreamServiceMetricsLabelValues, _ *models.HTTPMetrics, _ int) {
+}
+
+// LogMetricsRetryOutcome does nothing.
+func (n *NoOpPromDownstreamServiceMetrics) LogMetricsRetryOutcome(_ *models.DownstreamServiceMetricsLabelValues, _ string, _ int) {
+}
+
+// ObserveLatency does nothing.
+func (n *NoOpPromDownstreamServiceMetrics) ObserveLatency(_ bool, _ *models.DownstreamServiceMetricsLabelValues, _ time.Duration) {
+}
+
+// ObserveWithStatus does nothing.
+func (n *NoOpPromDownstreamServiceMetrics) ObserveWithStatus(_ *models.DownstreamServiceMetricsLabelValues, _ string, _ time.Duration) {
+}
+
+// LogMetricsShortCircuited does nothing.
+func (n *NoOpPromDownstreamServiceMetrics) LogMetricsShortCircuited(_ *models.DownstreamServiceMetricsLabelValues) {
+}
+
+// RecordParseTime does nothing.
+func (n *NoOpPromDownstreamServiceMetrics) RecordParseTime(_ *models.DownstreamServiceMetricsLabelValues, _ time.Duration) {
+}
+
+// RecordUpstreamLatency does nothing.
+func (n *NoOpPromDownstreamServiceMetrics) RecordUpstreamLatency(_ *models.DownstreamServiceMetricsLabelValues, _ time.Duration) {
+}
+
+// RecordConnectionSetup does nothing.
+func (n *NoOpPromDownstreamServiceMetrics) RecordConnectionSetup(_ *models.DownstreamServiceMetricsLabelValues, _ models.ConnectionSetupTimes) {
+}
+
 // NoOpPromCronJobMetrics is a no-operation implementation of CronJobMetricsInterface.
 // Use this for testing or when you want to disable Prometheus cron job metrics collection.
 type NoOpPromCronJobMetrics struct{}
@@ -80,6 +150,26 @@ func (n *NoOpPromCronJobMetrics) LogMetricsPre(_ *models.CronJobMetricsLabelValu
 func (n *NoOpPromCronJobMetrics) LogMetricsPost(_ *ae.AppError, _ *models.CronJobMetricsLabelValues, _ time.Time) {
 }
 
+// LogMetricsPostErr does nothing.
+func (n *NoOpPromCronJobMetrics) LogMetricsPostErr(_ error, _ *models.CronJobMetricsLabelValues, _ time.Time) {
+}
+
+// ObserveLatency does nothing.
+func (n *NoOpPromCronJobMetrics) ObserveLatency(_ *ae.AppError, _ *models.CronJobMetricsLabelValues, _ time.Duration) {
+}
+
+// ObserveWithStatus does nothing.
+func (n *NoOpPromCronJobMetrics) ObserveWithStatus(_ *models.CronJobMetricsLabelValues, _ string, _ time.Duration) {
+}
+
+// RecordScheduleDrift does nothing.
+func (n *NoOpPromCronJobMetrics) RecordScheduleDrift(_ string, _, _ time.Time) {
+}
+
+// RecordBudget does nothing.
+func (n *NoOpPromCronJobMetrics) RecordBudget(_ string, _ time.Duration) {
+}
+
 // NoOpPromPSMetrics is a no-operation implementation of PSMetricsInterface.
 // Use this for testing or when you want to disable Prometheus pub/sub metrics collection.
 type NoOpPromPSMetrics struct{}
@@ -98,6 +188,47 @@ func (n *NoOpPromPSMetrics) LogMetricsPre(_ *models.PSMetricsLabelValues) time.T
 func (n *NoOpPromPSMetrics) LogMetricsPost(_ *models.PSMetricsLabelValues, _ *pubsub.EventTxnData) {
 }
 
+// LogMetricsPostWithWireSize does nothing.
+func (n *NoOpPromPSMetrics) LogMetricsPostWithWireSize(_ *models.PSMetricsLabelValues, _ *pubsub.EventTxnData, _ int) {
+}
+
+// LogMetricsPostOutcome does nothing.
+func (n *NoOpPromPSMetrics) LogMetricsPostOutcome(_ *models.PSMetricsLabelValues, _ string, _ time.Time) {
+}
+
+// LogMetricsPreWithTimestamps returns the given timestamps.
+func (n *NoOpPromPSMetrics) LogMetricsPreWithTimestamps(_ *models.PSMetricsLabelValues, publishTime, receiveTime time.Time) models.PSMessageTimestamps {
+	return models.PSMessageTimestamps{PublishTime: publishTime, ReceiveTime: receiveTime}
+}
+
+// LogMetricsPostWithTimestamps does nothing.
+func (n *NoOpPromPSMetrics) LogMetricsPostWithTimestamps(_ *models.PSMetricsLabelValues, _ models.PSMessageTimestamps) {
+}
+
+// LogMetricsBatch does nothing.
+func (n *NoOpPromPSMetrics) LogMetricsBatch(_ []models.PSBatchEntry) {
+}
+
+// ObserveLatency does nothing.
+func (n *NoOpPromPSMetrics) ObserveLatency(_ bool, _ *models.PSMetricsLabelValues, _ time.Duration) {
+}
+
+// ObserveWithStatus does nothing.
+func (n *NoOpPromPSMetrics) ObserveWithStatus(_ *models.PSMetricsLabelValues, _ string, _ time.Duration) {
+}
+
+// RecordPublishConfirmLatency does nothing.
+func (n *NoOpPromPSMetrics) RecordPublishConfirmLatency(_ *models.PSMetricsLabelValues, _ time.Duration) {
+}
+
+// SetPublisherQueueDepth does nothing.
+func (n *NoOpPromPSMetrics) SetPublisherQueueDepth(_ string, _ int) {
+}
+
+// SetSubscriptionState does nothing.
+func (n *NoOpPromPSMetrics) SetSubscriptionState(_ string, _ int) {
+}
+
 // NoOpPromAppMetrics is a no-operation implementation of AppMetricsInterface.
 // Use this for testing or when you want to disable Prometheus application error metrics collection.
 type NoOpPromAppMetrics struct{}
@@ -111,10 +242,123 @@ func NewNoOpPromAppMetrics() interfaces.AppMetricsInterface {
 func (n *NoOpPromAppMetrics) LogMetrics(_ []string) {
 }
 
+// LogMetricsUnique does nothing.
+func (n *NoOpPromAppMetrics) LogMetricsUnique(_ []string) {
+}
+
 // DecrementAppErrorCount does nothing.
 func (n *NoOpPromAppMetrics) DecrementAppErrorCount(_ string) {
 }
 
+// NoOpPromReadinessMetrics is a no-operation implementation of ReadinessMetricsInterface.
+// Use this for testing or when you want to disable Prometheus readiness metrics collection.
+type NoOpPromReadinessMetrics struct{}
+
+// NewNoOpPromReadinessMetrics creates a new no-op Prometheus readiness metrics instance.
+func NewNoOpPromReadinessMetrics() interfaces.ReadinessMetricsInterface {
+	return &NoOpPromReadinessMetrics{}
+}
+
+// SetReady does nothing.
+func (n *NoOpPromReadinessMetrics) SetReady(_ string, _ bool) {
+}
+
+// NoOpPromTxnMetrics is a no-operation implementation of TxnMetricsInterface.
+// Use this for testing or when you want to disable Prometheus transaction metrics collection.
+type NoOpPromTxnMetrics struct{}
+
+// NewNoOpPromTxnMetrics creates a new no-op Prometheus transaction metrics instance.
+func NewNoOpPromTxnMetrics() interfaces.TxnMetricsInterface {
+	return &NoOpPromTxnMetrics{}
+}
+
+// BeginTxn returns a handle that does nothing.
+func (n *NoOpPromTxnMetrics) BeginTxn(_ string) interfaces.TxnHandle {
+	return noOpTxnHandle{}
+}
+
+// noOpTxnHandle is the transaction handle returned by NoOpPromTxnMetrics.
+type noOpTxnHandle struct{}
+
+// Commit does nothing.
+func (noOpTxnHandle) Commit() {
+}
+
+// Rollback does nothing.
+func (noOpTxnHandle) Rollback() {
+}
+
+// NoOpPromOperationMetrics is a no-operation implementation of OperationMetricsInterface.
+// Use this for testing or when you want to disable Prometheus operation metrics collection.
+type NoOpPromOperationMetrics struct{}
+
+// NewNoOpPromOperationMetrics creates a new no-op Prometheus operation metrics instance.
+func NewNoOpPromOperationMetrics() interfaces.OperationMetricsInterface {
+	return &NoOpPromOperationMetrics{}
+}
+
+// Start returns a handle that does nothing.
+func (n *NoOpPromOperationMetrics) Start(_ string) interfaces.OperationHandle {
+	return noOpOperationHandle{}
+}
+
+// noOpOperationHandle is the operation handle returned by NoOpPromOperationMetrics.
+type noOpOperationHandle struct{}
+
+// Dependency returns a handle that does nothing.
+func (noOpOperationHandle) Dependency(_, _ string, _ *models.DependencyLabelValues) interfaces.DependencyHandle {
+	return noOpDependencyHandle{}
+}
+
+// End does nothing.
+func (noOpOperationHandle) End(_ *ae.AppError) {
+}
+
+// noOpDependencyHandle is the dependency handle returned by noOpOperationHandle.
+type noOpDependencyHandle struct{}
+
+// End does nothing.
+func (noOpDependencyHandle) End(_ *ae.AppError) {
+}
+
+// NoOpPromWorkerPoolMetrics is a no-operation implementation of WorkerPoolMetricsInterface.
+// Use this for testing or when you want to disable Prometheus worker pool metrics collection.
+type NoOpPromWorkerPoolMetrics struct{}
+
+// NewNoOpPromWorkerPoolMetrics creates a new no-op Prometheus worker pool metrics instance.
+func NewNoOpPromWorkerPoolMetrics() interfaces.WorkerPoolMetricsInterface {
+	return &NoOpPromWorkerPoolMetrics{}
+}
+
+// Enqueue returns a handle that does nothing.
+func (n *NoOpPromWorkerPoolMetrics) Enqueue(_ string) interfaces.WorkerTaskHandle {
+	return noOpWorkerTaskHandle{}
+}
+
+// noOpWorkerTaskHandle is the task handle returned by NoOpPromWorkerPoolMetrics.
+type noOpWorkerTaskHandle struct{}
+
+// Start does nothing.
+func (noOpWorkerTaskHandle) Start() {
+}
+
+// Done does nothing.
+func (noOpWorkerTaskHandle) Done(_ error) {
+}
+
+// NoOpPromREDMetrics is a no-operation implementation of REDMetricsInterface.
+// Use this for testing or when you want to disable Prometheus RED metrics collection.
+type NoOpPromREDMetrics struct{}
+
+// NewNoOpPromREDMetrics creates a new no-op Prometheus RED metrics instance.
+func NewNoOpPromREDMetrics() interfaces.REDMetricsInterface {
+	return &NoOpPromREDMetrics{}
+}
+
+// Observe does nothing.
+func (n *NoOpPromREDMetrics) Observe(_ string, _ time.Duration, _ error) {
+}
+
 // Compile-time interface implementation checks for NoOp types
 var (
 	_ interfaces.RouterMetricsInterface            = (*NoOpPromRouterMetrics)(nil)
@@ -123,4 +367,9 @@ var (
 	_ interfaces.CronJobMetricsInterface           = (*NoOpPromCronJobMetrics)(nil)
 	_ interfaces.PSMetricsInterface                = (*NoOpPromPSMetrics)(nil)
 	_ interfaces.AppMetricsInterface               = (*NoOpPromAppMetrics)(nil)
+	_ interfaces.ReadinessMetricsInterface         = (*NoOpPromReadinessMetrics)(nil)
+	_ interfaces.TxnMetricsInterface               = (*NoOpPromTxnMetrics)(nil)
+	_ interfaces.OperationMetricsInterface         = (*NoOpPromOperationMetrics)(nil)
+	_ interfaces.WorkerPoolMetricsInterface        = (*NoOpPromWorkerPoolMetrics)(nil)
+	_ interfaces.REDMetricsInterface               = (*NoOpPromREDMetrics)(nil)
 )
@@ -0,0 +1,41 @@
+package prometheus
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestConstructorsReturnNoOpForNilMeta(t *testing.T) {
+	registry := useTestRegistry(t)
+	tests := []struct {
+		name string
+		new  func() any
+		want any
+	}{
+		{name: "router", new: func() any { return NewPromRouterMetrics(nil) }, want: &NoOpPromRouterMetrics{}},
+		{name: "database", new: func() any { return NewPromDatabaseMetrics(nil) }, want: &NoOpPromDBMetrics{}},
+		{name: "downstream service", new: func() any { return NewPromDownstreamServiceMetrics(nil) }, want: &NoOpPromDownstreamServiceMetrics{}},
+		{name: "cron job", new: func() any { return NewPromCronJobMetrics(nil) }, want: &NoOpPromCronJobMetrics{}},
+		{name: "pub/sub", new: func() any { return NewPromPubSubMetrics(nil) }, want: &NoOpPromPSMetrics{}},
+		{name: "app", new: func() any { return NewPromAppMetrics(nil) }, want: &NoOpPromAppMetrics{}},
+		{name: "readiness", new: func() any { return NewPromReadinessMetrics(nil) }, want: &NoOpPromReadinessMetrics{}},
+		{name: "transaction", new: func() any { return NewPromTxnMetrics(nil) }, want: &NoOpPromTxnMetrics{}},
+		{name: "operation", new: func() any { return NewPromOperationMetrics(nil, nil) }, want: &NoOpPromOperationMetrics{}},
+		{name: "worker pool", new: func() any { return NewPromWorkerPoolMetrics(nil) }, want: &NoOpPromWorkerPoolMetrics{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.new(); reflect.TypeOf(got) != reflect.TypeOf(tt.want) {
+				t.Errorf("constructor with nil meta returned %T, want %T", got, tt.want)
+			}
+		})
+	}
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("gather: %v", err)
+	}
+	if len(families) != 0 {
+		t.Errorf("registered %d metric families, want none", len(families))
+	}
+}
@@ -0,0 +1,103 @@
+package prometheus
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Observation is a single histogram observation passed through the observation middlewares.
+type Observation struct {
+	// Metric is the metric name without namespace, e.g. "http_request_latency_millis".
+	Metric string
+	// LabelValues are the label values of the observed series, in the metric's label order.
+	LabelValues []string
+	// Value is the observed value, after built-in processing such as latency clamping.
+	Value float64
+	// Exemplar holds the exemplar labels attached to the observation, or nil for none.
+	// Exemplars are recorded by histograms only, and are dropped by other observers.
+	Exemplar prometheus.Labels
+
+	target labelObserver
+}
+
+// ObserveFunc records an observation.
+type ObserveFunc func(obs Observation)
+
+// labelObserver is implemented by the histogram and t-digest vecs observations are recorded into.
+type labelObserver interface {
+	WithLabelValues(labelValues ...string) prometheus.Observer
+}
+
+var (
+	observationMu    sync.RWMutex
+	middlewares      []func(next ObserveFunc) ObserveFunc
+	observationChain ObserveFunc
+)
+
+// AddObservationMiddleware appends a middleware to the chain every histogram (and t-digest)
+// observation made by this package passes through. A middleware receives the next ObserveFunc and
+// returns one wrapping it; it may change the observation (e.g. its value or exemplar), add side
+// effects such as logging, or drop it by not calling next.
+//
+// Middlewares run in the order they were added: the first one added sees the observation first,
+// and the last one calls the func recording it. They run synchronously on every observation, so
+// they must be cheap and safe for concurrent use. Built-in processing configured through the metas
+// (such as MaxLatencyMillis clamping) is applied before the chain.
+//
+// Call it during startup, before metrics are observed.
+//
+// Example:
+//
+//	prometheus.AddObservationMiddleware(func(next prometheus.ObserveFunc) prometheus.ObserveFunc {
+//		return func(obs prometheus.Observation) {
+//			if obs.Metric == "http_request_latency_millis" && obs.Value > 10000 {
+//				log.Printf("slow request: %v", obs.LabelValues)
+//			}
+//			next(obs)
+//		}
+//	})
+func AddObservationMiddleware(fn func(next ObserveFunc) ObserveFunc) {
+	observationMu.Lock()
+	defer observationMu.Unlock()
+	middlewares = append(middlewares, fn)
+
+	chain := ObserveFunc(recordObservation)
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		chain = middlewares[i](chain)
+	}
+	observationChain = chain
+}
+
+// recordObservation records the observation into its vec; it terminates the middleware chain.
+func recordObservation(obs Observation) {
+	record(observerWith(obs.target, obs.LabelValues), obs.Value, obs.Exemplar)
+}
+
+// record observes value, with the exemplar when there is one and the observer supports exemplars.
+func record(observer prometheus.Observer, value float64, exemplar prometheus.Labels) {
+	if len(exemplar) > 0 {
+		if exemplarObserver, ok := observer.(prometheus.ExemplarObserver); ok {
+			exemplarObserver.ObserveWithExemplar(value, exemplar)
+			return
+		}
+	}
+	observer.Observe(value)
+}
+
+// observe records value into the series of target, passing it through the observation middlewares.
+func observe(target labelObserver, metric string, value float64, labelValues ...string) {
+	observeWithExemplar(target, metric, value, nil, labelValues...)
+}
+
+// observeWithExemplar is like observe, attaching the exemplar labels to the observation.
+func observeWithExemplar(target labelObserver, metric string, value float64, exemplar prometheus.Labels, labelValues ...string) {
+	observationMu.RLock()
+	chain := observationChain
+	observationMu.RUnlock()
+	if chain == nil {
+		record(observerWith(target, labelValues), value, exemplar)
+		return
+	}
+	chain(Observation{Metric: metric, LabelValues: labelValues, Value: value, Exemplar: exemplar, target: target})
+}
@@ -0,0 +1,44 @@
+package prometheus
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// partialResponseWriter wraps a gin.ResponseWriter and records the first error returned while writing
+// the response body, so a response cut short by a broken connection can be told apart from a complete one.
+// The bytes written before the error are still counted by the wrapped writer's Size.
+type partialResponseWriter struct {
+	gin.ResponseWriter
+	writeErr error
+}
+
+// Write writes to the wrapped writer and records the first write error.
+func (w *partialResponseWriter) Write(data []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(data)
+	if err != nil && w.writeErr == nil {
+		w.writeErr = err
+	}
+	return n, err
+}
+
+// WriteString writes to the wrapped writer and records the first write error.
+func (w *partialResponseWriter) WriteString(s string) (int, error) {
+	n, err := w.ResponseWriter.WriteString(s)
+	if err != nil && w.writeErr == nil {
+		w.writeErr = err
+	}
+	return n, err
+}
+
+// isPartialResponse reports whether a response that was started with body bytes failed before completing:
+// writing to the client failed, the client went away, or the handler reported an error with gc.Error
+// after it had already started streaming.
+func isPartialResponse(gc *gin.Context, writer *partialResponseWriter) bool {
+	if gc.Writer.Size() <= 0 {
+		return false
+	}
+	return writer.writeErr != nil || len(gc.Errors) > 0 || errors.Is(gc.Request.Context().Err(), context.Canceled)
+}
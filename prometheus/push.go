@@ -0,0 +1,71 @@
+package prometheus
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// pushTimeout bounds a push to the Pushgateway, so an unreachable gateway can't hold up the pushing code.
+const pushTimeout = 10 * time.Second
+
+// Push sends the metrics of gatherer to the Pushgateway at url, replacing the metrics of the group identified
+// by job and the grouping labels. Use it for jobs that exit before the next scrape, so their metrics survive.
+// A nil gatherer pushes the registry the metrics are registered against (see SetRegisterer).
+//
+// Example:
+//
+//	if err := prometheus.Push("http://pushgateway:9091", "nightly_backup", map[string]string{"instance": host}, nil); err != nil {
+//	    log.Printf("pushing metrics: %v", err)
+//	}
+func Push(url, job string, grouping map[string]string, gatherer prometheus.Gatherer) error {
+	if gatherer == nil {
+		gatherer = getGatherer()
+	}
+	pusher := push.New(url, job).Gatherer(gatherer).Client(&http.Client{Timeout: pushTimeout})
+	for name, value := range grouping {
+		pusher = pusher.Grouping(name, value)
+	}
+	return pusher.Push()
+}
+
+// labelGatherer is a prometheus.Gatherer keeping the series of the wrapped gatherer whose label name is
+// set to value, along with the series that don't have the label.
+type labelGatherer struct {
+	prometheus.Gatherer
+	name  string
+	value string
+}
+
+// Gather gathers the wrapped gatherer and drops the series whose label name is set to another value,
+// and the families left without series.
+func (g labelGatherer) Gather() ([]*dto.MetricFamily, error) {
+	families, err := g.Gatherer.Gather()
+	kept := families[:0]
+	for _, family := range families {
+		metrics := family.Metric[:0]
+		for _, metric := range family.GetMetric() {
+			if g.keep(metric) {
+				metrics = append(metrics, metric)
+			}
+		}
+		family.Metric = metrics
+		if len(metrics) > 0 {
+			kept = append(kept, family)
+		}
+	}
+	return kept, err
+}
+
+// keep reports whether metric has no label name or has it set to value.
+func (g labelGatherer) keep(metric *dto.Metric) bool {
+	for _, label := range metric.GetLabel() {
+		if label.GetName() == g.name {
+			return label.GetValue() == g.value
+		}
+	}
+	return true
+}
@@ -0,0 +1,212 @@
+package prometheus
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// defaultReaperTTL is the TTL of a TTLReaper created with a non-positive one.
+const defaultReaperTTL = time.Hour
+
+var (
+	// reapedVecs holds the *reapedVec tracking the series of every metric vec registered with a TTLReaper, keyed by vec.
+	reapedVecs sync.Map
+
+	// reapingEnabled is set once a metric vec is registered with a TTLReaper, so the observations of
+	// applications that don't use one skip the lookup.
+	reapingEnabled atomic.Bool
+)
+
+// ReapableVec is a metric vec whose series a TTLReaper can delete, e.g. the *prometheus.CounterVec,
+// *prometheus.GaugeVec, *prometheus.HistogramVec or *prometheus.SummaryVec returned by a Get*Metric method.
+type ReapableVec interface {
+	DeleteLabelValues(labelValues ...string) bool
+}
+
+// TTLReaper deletes the series of the metric vecs registered with it that weren't observed for longer
+// than a TTL, so that series of transient label values (e.g. a one-off cron job name or a decommissioned
+// API) expire instead of being exposed forever. The series of a registered vec are tracked as they are
+// observed by this package: each observation stores the time it was made, and a sweep every sweep interval
+// deletes the series idle beyond the TTL. A deleted series is created again when observed again, starting
+// from zero.
+//
+// Tracking costs a clock read and a map lookup per observation into a registered vec, plus the label values
+// of every tracked series kept in memory; the observations of vecs that aren't registered are unaffected.
+// Series recorded before their vec is registered aren't tracked until they are observed again.
+//
+// Example:
+//
+//	reaper := prometheus.NewTTLReaper(24*time.Hour, time.Hour)
+//	reaper.Register(cronJobMetrics.GetJobExecutionTotalMetric())
+//	reaper.Start()
+//	defer reaper.Stop()
+type TTLReaper struct {
+	ttl           time.Duration
+	sweepInterval time.Duration
+
+	mu   sync.Mutex
+	vecs []*reapedVec
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewTTLReaper creates a TTLReaper deleting the series idle for longer than ttl, checked every sweepInterval.
+// A non-positive ttl defaults to an hour, and a non-positive sweepInterval to ttl.
+func NewTTLReaper(ttl, sweepInterval time.Duration) *TTLReaper {
+	if ttl <= 0 {
+		ttl = defaultReaperTTL
+	}
+	if sweepInterval <= 0 {
+		sweepInterval = ttl
+	}
+	return &TTLReaper{ttl: ttl, sweepInterval: sweepInterval}
+}
+
+// Register starts tracking the series of vecs, which are swept from then on. Nil vecs (e.g. returned by the
+// Get*Metric method of a metric that isn't configured) are skipped, and a vec registered with several reapers
+// is swept by each of them.
+func (r *TTLReaper) Register(vecs ...ReapableVec) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, vec := range vecs {
+		if vec == nil || reflect.ValueOf(vec).Kind() == reflect.Pointer && reflect.ValueOf(vec).IsNil() {
+			continue
+		}
+		tracked, _ := reapedVecs.LoadOrStore(vec, &reapedVec{vec: vec, series: make(map[string]*reapedSeries)})
+		r.vecs = append(r.vecs, tracked.(*reapedVec))
+		reapingEnabled.Store(true)
+	}
+}
+
+// Start sweeps the registered vecs every sweep interval on a background goroutine, until Stop is called.
+// Calls while the reaper is running are no-ops.
+func (r *TTLReaper) Start() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.stop != nil {
+		return
+	}
+	r.stop = make(chan struct{})
+	r.done = make(chan struct{})
+	runningGoroutines.Add(1)
+	go r.run(r.stop, r.done)
+}
+
+// Stop stops the background sweeps and waits for the goroutine started by Start to return. The series
+// stay tracked, so the reaper can be started again. Calls while the reaper isn't running are no-ops.
+func (r *TTLReaper) Stop() {
+	r.mu.Lock()
+	stop, done := r.stop, r.done
+	r.stop, r.done = nil, nil
+	r.mu.Unlock()
+	if stop == nil {
+		return
+	}
+	close(stop)
+	<-done
+}
+
+// Sweep deletes the series of the registered vecs that weren't observed for longer than the TTL, and
+// returns the number of series deleted. Start calls it every sweep interval.
+func (r *TTLReaper) Sweep() int {
+	r.mu.Lock()
+	vecs := r.vecs
+	r.mu.Unlock()
+
+	deadline := time.Now().Add(-r.ttl).UnixNano()
+	deleted := 0
+	for _, vec := range vecs {
+		deleted += vec.sweep(deadline)
+	}
+	return deleted
+}
+
+// run sweeps every sweep interval until stop is closed. It is counted by RunningGoroutines until done is
+// closed, so that none is reported running once Stop returns.
+func (r *TTLReaper) run(stop, done chan struct{}) {
+	defer close(done)
+	defer runningGoroutines.Add(-1)
+	ticker := time.NewTicker(r.sweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			r.Sweep()
+		}
+	}
+}
+
+// reapedVec tracks the time each series of a metric vec registered with a TTLReaper was last observed.
+type reapedVec struct {
+	vec ReapableVec
+
+	mu     sync.RWMutex
+	series map[string]*reapedSeries
+}
+
+// reapedSeries is a tracked series of a reapedVec.
+type reapedSeries struct {
+	labelValues []string
+	lastTouched atomic.Int64
+}
+
+// touchSeries stores the time of an observation of the series of vec with the label values, when vec is
+// registered with a TTLReaper.
+func touchSeries(vec any, labelValues []string) {
+	if !reapingEnabled.Load() {
+		return
+	}
+	tracked, ok := reapedVecs.Load(vec)
+	if !ok {
+		return
+	}
+	tracked.(*reapedVec).touch(labelValues, time.Now().UnixNano())
+}
+
+// touch stores now as the time the series with the label values was last observed. The key is built on the
+// stack, so touching a tracked series doesn't allocate.
+func (rv *reapedVec) touch(labelValues []string, now int64) {
+	var buf [seriesCacheKeySize]byte
+	key := appendSeriesKey(buf[:0], labelValues)
+	rv.mu.RLock()
+	series, ok := rv.series[string(key)]
+	rv.mu.RUnlock()
+	if ok {
+		series.lastTouched.Store(now)
+		return
+	}
+
+	rv.mu.Lock()
+	defer rv.mu.Unlock()
+	series, ok = rv.series[string(key)]
+	if !ok {
+		series = &reapedSeries{labelValues: append([]string(nil), labelValues...)}
+		rv.series[string(key)] = series
+	}
+	series.lastTouched.Store(now)
+}
+
+// sweep deletes the tracked series last observed before deadline, in Unix nanoseconds, and returns their number.
+func (rv *reapedVec) sweep(deadline int64) int {
+	rv.mu.Lock()
+	defer rv.mu.Unlock()
+	deleted := 0
+	for key, series := range rv.series {
+		if series.lastTouched.Load() >= deadline {
+			continue
+		}
+		delete(rv.series, key)
+		forgetAdmittedSeries(rv.vec, key)
+		if rv.vec.DeleteLabelValues(series.labelValues...) {
+			deleted++
+		}
+	}
+	if deleted > 0 {
+		forgetCachedSeries(rv.vec)
+	}
+	return deleted
+}
@@ -0,0 +1,123 @@
+package prometheus
+
+import (
+	"time"
+
+	"github.com/piyushkumar96/app-monitoring/constants"
+	"github.com/piyushkumar96/app-monitoring/interfaces"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// redDurationBuckets are the buckets of the RED duration histogram, from 1 ms to about 16 s.
+var redDurationBuckets = GetPromExponentialBuckets(1, 2, 15)
+
+// NewPromREDMetrics creates and registers the metrics of the RED method (Rate, Errors, Duration) for any
+// operation type (HTTP, database, RPC, ...), a lightweight alternative to the specialized metrics for small
+// services. They are registered against the configured registerer (see SetRegisterer).
+//
+// The metrics track:
+//   - requests_total: Counter for the operations by operation and status (success/failure)
+//   - request_duration_millis: Histogram for the operation durations in milliseconds by operation
+//
+// Parameters:
+//   - namespace: The metric namespace (typically the application name).
+//   - subsystem: The metric subsystem, placed between the namespace and the metric name; it may be empty.
+//   - constLabels: Labels with a fixed value added to both metrics (e.g. {"team": "payments"}); it may be nil.
+//
+// Returns an interfaces.REDMetricsInterface instance, or the NoOp implementation when metrics are disabled.
+//
+// Example:
+//
+//	red := prometheus.NewPromREDMetrics("myapp", "worker", nil)
+//
+//	start := time.Now()
+//	err := sendInvoice(ctx, invoice)
+//	red.Observe("send_invoice", time.Since(start), err)
+func NewPromREDMetrics(namespace, subsystem string, constLabels prometheus.Labels) interfaces.REDMetricsInterface {
+	if metricsDisabled() {
+		return NewNoOpPromREDMetrics()
+	}
+
+	requestsName := prometheus.BuildFQName("", subsystem, "requests_total")
+	durationName := prometheus.BuildFQName("", subsystem, "request_duration_millis")
+	return &PromREDMetrics{
+		requestsTotal: newConstLabelsCounterVec(namespace, requestsName, "Tracks the number of operations by outcome",
+			[]string{constants.LabelOperation, constants.LabelStatus}, constLabels),
+		requestDurationMillis: newConstLabelsHistogramVec(namespace, durationName, "Tracks the durations of operations",
+			[]string{constants.LabelOperation}, constLabels, redDurationBuckets),
+		durationMetric: durationName,
+	}
+}
+
+// newConstLabelsCounterVec creates and registers a CounterVec carrying constLabels, as GetPromCounterVec does.
+func newConstLabelsCounterVec(namespace, name, help string, labelNames []string, constLabels prometheus.Labels) *prometheus.CounterVec {
+	namespace, name = validateMetricName(namespace, name)
+	counter := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace:   namespace,
+			Name:        name,
+			Help:        help,
+			ConstLabels: constLabels,
+		}, labelNames,
+	)
+	counter, err := registerCollector(counter)
+	if err != nil {
+		logger().Error("failed to register counter vec metric", "code", "OnCounterVecMetricRegisterFailure", "err", err.Error())
+	} else {
+		recordInventory(namespace, name, MetricTypeCounter, help, labelNames, constLabels, nil)
+	}
+	storeMetricInfo(counter, namespace, name, labelNames)
+	return counter
+}
+
+// newConstLabelsHistogramVec creates and registers a HistogramVec carrying constLabels, as GetPromHistogramVec does.
+func newConstLabelsHistogramVec(namespace, name, help string, labelNames []string, constLabels prometheus.Labels, buckets []float64) *prometheus.HistogramVec {
+	namespace, name = validateMetricName(namespace, name)
+	buckets = validateBuckets(namespace, name, nonNegativeBuckets(namespace, name, buckets))
+	histogram := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace:   namespace,
+			Name:        name,
+			Help:        help,
+			Buckets:     buckets,
+			ConstLabels: constLabels,
+		}, labelNames,
+	)
+	histogram, err := registerCollector(histogram)
+	if err != nil {
+		logger().Error("failed to register histogram vec metric", "code", "OnHistogramMetricRegisterFailure", "err", err.Error())
+	} else {
+		recordInventory(namespace, name, MetricTypeHistogram, help, labelNames, constLabels, histogramBuckets(buckets))
+	}
+	storeMetricInfo(histogram, namespace, name, labelNames)
+	return histogram
+}
+
+// Observe records one operation that took d: it increments requests_total with status failure when err
+// is non-nil and success otherwise, and records d into request_duration_millis.
+func (rm *PromREDMetrics) Observe(operation string, d time.Duration, err error) {
+	status := constants.Success
+	if err != nil {
+		status = constants.Failure
+	}
+	counterWith(rm.requestsTotal, operation, status).Inc()
+	observe(rm.requestDurationMillis, rm.durationMetric, durationMillis(d), operation)
+}
+
+// GetRequestsTotalMetric returns the underlying Prometheus CounterVec
+// for the operation counter. This can be used for advanced operations.
+func (rm *PromREDMetrics) GetRequestsTotalMetric() *prometheus.CounterVec {
+	return rm.requestsTotal
+}
+
+// GetRequestDurationMillisMetric returns the underlying Prometheus HistogramVec
+// for the operation durations. This can be used for advanced operations.
+func (rm *PromREDMetrics) GetRequestDurationMillisMetric() *prometheus.HistogramVec {
+	return rm.requestDurationMillis
+}
+
+// Collectors returns every collector registered by the RED metrics.
+func (rm *PromREDMetrics) Collectors() []prometheus.Collector {
+	return []prometheus.Collector{rm.requestsTotal, rm.requestDurationMillis}
+}
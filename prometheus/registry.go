@@ -0,0 +1,146 @@
+package prometheus
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	registererMu sync.RWMutex
+	registerer   prometheus.Registerer = prometheus.DefaultRegisterer
+)
+
+// SetRegisterer sets the Prometheus registerer that metrics created afterwards are registered against.
+// By default metrics are registered against prometheus.DefaultRegisterer.
+//
+// It is read at construction time, so call it before creating any metrics. To register the same
+// metrics against several registries (e.g. during a registry migration), pass a MultiRegisterer.
+func SetRegisterer(r prometheus.Registerer) {
+	registererMu.Lock()
+	defer registererMu.Unlock()
+	registerer = r
+}
+
+// getRegisterer returns the registerer metrics are currently registered against.
+func getRegisterer() prometheus.Registerer {
+	registererMu.RLock()
+	defer registererMu.RUnlock()
+	return registerer
+}
+
+// getGatherer returns the gatherer exposing the metrics registered against the current registerer: the
+// registerer itself when it is a registry, or the first registry of a MultiRegisterer. It falls back to
+// prometheus.DefaultGatherer when the registerer can't be gathered.
+func getGatherer() prometheus.Gatherer {
+	r := getRegisterer()
+	if multi, ok := r.(MultiRegisterer); ok {
+		for _, r := range multi {
+			if gatherer, ok := r.(prometheus.Gatherer); ok {
+				return gatherer
+			}
+		}
+	}
+	if gatherer, ok := r.(prometheus.Gatherer); ok {
+		return gatherer
+	}
+	return prometheus.DefaultGatherer
+}
+
+// MultiRegisterer is a prometheus.Registerer that registers each collector against all of its registerers.
+// Because the same collector is registered everywhere, observations made through WithLabelValues are
+// visible in every registry without instrumenting the code twice.
+//
+// The overhead is paid at registration and scrape time, not per observation: each registry validates
+// and stores the collector separately, and every registry that is scraped collects it again.
+type MultiRegisterer []prometheus.Registerer
+
+// NewMultiRegisterer creates a MultiRegisterer fanning out to the given registerers.
+//
+// Example:
+//
+//	prometheus.SetRegisterer(prometheus.NewMultiRegisterer(client_prometheus.DefaultRegisterer, scopedRegistry))
+func NewMultiRegisterer(registerers ...prometheus.Registerer) MultiRegisterer {
+	return registerers
+}
+
+// Register registers the collector against every registerer.
+// Registration errors from individual registerers are joined together, and a single error is returned
+// as is, so that callers can type-assert a prometheus.AlreadyRegisteredError. The collector stays
+// registered with the registerers that accepted it.
+func (mr MultiRegisterer) Register(c prometheus.Collector) error {
+	var errs []error
+	for _, r := range mr {
+		if err := r.Register(c); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) == 1 {
+		return errs[0]
+	}
+	return errors.Join(errs...)
+}
+
+// MustRegister registers the collectors against every registerer and panics on the first error.
+func (mr MultiRegisterer) MustRegister(cs ...prometheus.Collector) {
+	for _, r := range mr {
+		r.MustRegister(cs...)
+	}
+}
+
+// Unregister unregisters the collector from every registerer.
+// It reports whether the collector was unregistered from at least one of them.
+func (mr MultiRegisterer) Unregister(c prometheus.Collector) bool {
+	unregistered := false
+	for _, r := range mr {
+		if r.Unregister(c) {
+			unregistered = true
+		}
+	}
+	return unregistered
+}
+
+// Compile-time interface implementation check
+var _ prometheus.Registerer = MultiRegisterer(nil)
+
+// registerMu serializes the registrations of the constructors, so that concurrent constructions of the same
+// metric (e.g. two goroutines creating the database metrics at startup) register it exactly once, also
+// against every registry of a MultiRegisterer.
+var registerMu sync.Mutex
+
+// registerCollector registers collector against the current registerer and returns the collector to use.
+// When an identical collector is already registered, by an earlier or concurrent construction of the same
+// metric, that registered collector is returned instead, so that observations are never made into a
+// collector that isn't exposed. Under a MultiRegisterer, the registered collector is also registered
+// against the registries that didn't have it yet. Any other registration error is returned along with
+// collector.
+func registerCollector[T prometheus.Collector](collector T) (T, error) {
+	registerMu.Lock()
+	defer registerMu.Unlock()
+
+	base := getRegisterer()
+	r := withDeploymentTrack(base)
+	err := r.Register(collector)
+	if err == nil {
+		return collector, nil
+	}
+	var alreadyRegistered prometheus.AlreadyRegisteredError
+	if !errors.As(err, &alreadyRegistered) {
+		return collector, err
+	}
+	existing, ok := alreadyRegistered.ExistingCollector.(T)
+	if !ok {
+		return collector, err
+	}
+	if _, multi := base.(MultiRegisterer); !multi {
+		return existing, nil
+	}
+	// The registries of a MultiRegisterer that didn't have the metric yet accepted collector, which would
+	// expose a collector nothing observes into: register existing in its place everywhere instead.
+	r.Unregister(collector)
+	if err := r.Register(existing); err != nil {
+		return existing, err
+	}
+	return existing, nil
+}
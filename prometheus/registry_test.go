@@ -0,0 +1,70 @@
+package prometheus
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/piyushkumar96/app-monitoring/constants"
+	"github.com/piyushkumar96/app-monitoring/models"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestRegisterCollectorReusesExistingCollectorInEveryRegistry(t *testing.T) {
+	migrated := useTestRegistry(t)
+	meta := &models.CronJobMetricsMeta{
+		Namespace:         "test",
+		JobExecutionTotal: &models.MetricMeta{Labels: cronTotalLabelNames},
+	}
+	first := NewPromCronJobMetrics(meta).(*PromCronJobMetrics)
+	scoped := prometheus.NewRegistry()
+	SetRegisterer(NewMultiRegisterer(migrated, scoped))
+
+	second := NewPromCronJobMetrics(meta).(*PromCronJobMetrics)
+	second.LogMetricsPre(&models.CronJobMetricsLabelValues{JobName: "sync_orders"})
+
+	if second.jobExecutionTotal != first.jobExecutionTotal {
+		t.Fatal("the second construction didn't reuse the registered counter")
+	}
+	for name, registry := range map[string]*prometheus.Registry{"migrated": migrated, "scoped": scoped} {
+		want := `
+# HELP test_cron_job_execution_count Number of times cron jobs executed for total/success/failure
+# TYPE test_cron_job_execution_count counter
+test_cron_job_execution_count{job_name="sync_orders",status="total"} 1
+`
+		if err := testutil.GatherAndCompare(registry, strings.NewReader(want), "test_cron_job_execution_count"); err != nil {
+			t.Errorf("%s registry: %v", name, err)
+		}
+	}
+}
+
+func TestConcurrentConstructionRegistersOneCollector(t *testing.T) {
+	registry := useTestRegistry(t)
+	meta := &models.DBMetricsMeta{
+		Namespace:               "test",
+		OperationsTotal:         &models.MetricMeta{Labels: dbTotalLabelNames},
+		OperationsLatencyMillis: &models.MetricMeta{Labels: dbLatencyLabelNames},
+	}
+	labelValues := &models.DBMetricsLabelValues{OpType: "select", Source: "postgres", AdEntity: "users", IsTxn: "false"}
+	constructed := make([]*PromDBMetrics, concurrentGoroutines)
+
+	hammer(t, registry, func(goroutine, iteration int) {
+		if iteration == 0 {
+			constructed[goroutine] = NewPromDatabaseMetrics(meta).(*PromDBMetrics)
+		}
+		dm := constructed[goroutine]
+		dm.LogMetricsPost(nil, labelValues, dm.LogMetricsPre(labelValues).Add(-time.Millisecond))
+	})
+
+	for _, dm := range constructed[1:] {
+		if dm.operationsTotal != constructed[0].operationsTotal || dm.operationsLatencyMillis != constructed[0].operationsLatencyMillis {
+			t.Fatal("concurrent constructions returned different collectors")
+		}
+	}
+	operations := float64(concurrentGoroutines * concurrentIterations)
+	if got := testutil.ToFloat64(constructed[0].operationsTotal.WithLabelValues("select", "postgres", "users", "false", constants.Total)); got != operations {
+		t.Errorf("total operations = %v, want %v", got, operations)
+	}
+}
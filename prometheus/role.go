@@ -0,0 +1,44 @@
+package prometheus
+
+import "sync"
+
+var (
+	roleMu sync.RWMutex
+	role   string
+)
+
+// SetRole sets the service role (e.g. "api", "worker", "cron") that prefixes the names of metrics created
+// afterwards as their Prometheus subsystem, between the namespace and the metric name. With namespace
+// "myapp", the database operations counter is registered as "myapp_worker_db_operations" by a
+// binary that called SetRole("worker") and "myapp_api_db_operations" by one that called
+// SetRole("api"), so binaries sharing the same package stay distinguishable in a shared Prometheus.
+//
+// Metas have no subsystem of their own, so the role applies to every metric created through this
+// package, including those of a Bundle. An empty role (the default) registers metrics as
+// "<namespace>_<name>". Like SetRegisterer, it is read at construction time, so call it before
+// creating any metrics; instances already created keep their names.
+func SetRole(r string) {
+	roleMu.Lock()
+	defer roleMu.Unlock()
+	role = r
+}
+
+// getRole returns the role metrics are currently prefixed with.
+func getRole() string {
+	roleMu.RLock()
+	defer roleMu.RUnlock()
+	return role
+}
+
+// withRole appends the current role to namespace, so that it becomes the subsystem of the metric name.
+func withRole(namespace string) string {
+	r := getRole()
+	switch {
+	case r == "":
+		return namespace
+	case namespace == "":
+		return r
+	default:
+		return namespace + "_" + r
+	}
+}
@@ -0,0 +1,509 @@
+package prometheus
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/piyushkumar96/app-monitoring/constants"
+	"github.com/piyushkumar96/app-monitoring/models"
+
+	"github.com/gin-gonic/gin"
+	ae "github.com/piyushkumar96/app-error"
+	pubsub "github.com/piyushkumar96/generic-pubsub"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// selfTestLabelValue is the placeholder label value used by SelfTest observations.
+// Series carrying it are deleted once the self-test completes.
+const selfTestLabelValue = "__selftest__"
+
+// deletableVec is a metric vector whose series can be listed and deleted.
+type deletableVec interface {
+	prometheus.Collector
+	Delete(labels prometheus.Labels) bool
+}
+
+// selfTestPath runs one observation path, turning a panic (e.g. a label count mismatch) into an error.
+func selfTestPath(name string, fn func()) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%s self-test failed: %v", name, r)
+		}
+	}()
+	fn()
+	return nil
+}
+
+// deleteSelfTestSeries deletes every series with a label value containing the self-test placeholder.
+// Series are collected before deleting, as a vector can't be modified while it is being collected.
+func deleteSelfTestSeries(vecs ...deletableVec) {
+	for _, vec := range vecs {
+		var placeholders []prometheus.Labels
+		for metric := range collectAsync(vec) {
+			var m dto.Metric
+			if err := metric.Write(&m); err != nil {
+				continue
+			}
+			labels := make(prometheus.Labels, len(m.GetLabel()))
+			isPlaceholder := false
+			for _, pair := range m.GetLabel() {
+				labels[pair.GetName()] = pair.GetValue()
+				if strings.Contains(pair.GetValue(), selfTestLabelValue) {
+					isPlaceholder = true
+				}
+			}
+			if isPlaceholder {
+				placeholders = append(placeholders, labels)
+			}
+		}
+		for _, labels := range placeholders {
+			vec.Delete(labels)
+		}
+		if len(placeholders) > 0 {
+			forgetCachedSeries(vec)
+		}
+	}
+}
+
+// SelfTest exercises the middleware once with a placeholder request and returns an error
+// if recording panics due to misconfiguration (e.g. a label count mismatch).
+// Call it in a startup health check to fail fast instead of panicking on production traffic.
+// The placeholder series are deleted afterwards so they don't pollute real data.
+func (rlm *PromRouterMetrics) SelfTest() error {
+	defer func() {
+		var vecs []deletableVec
+		if rlm.httpRequests != nil {
+			vecs = append(vecs, rlm.httpRequests)
+		}
+		if rlm.httpRequestsLatencyMillis != nil {
+			vecs = append(vecs, rlm.httpRequestsLatencyMillis)
+		}
+		if rlm.httpRequestsLatencyDigest != nil {
+			vecs = append(vecs, rlm.httpRequestsLatencyDigest)
+		}
+		if rlm.httpRequestsLatencyByProfile != nil {
+			vecs = append(vecs, rlm.httpRequestsLatencyByProfile)
+		}
+		if rlm.httpTTFBMillis != nil {
+			vecs = append(vecs, rlm.httpTTFBMillis)
+		}
+		if rlm.httpRequestSizeBytes != nil {
+			vecs = append(vecs, rlm.httpRequestSizeBytes)
+		}
+		if rlm.httpResponseSizeBytes != nil {
+			vecs = append(vecs, rlm.httpResponseSizeBytes)
+		}
+		if rlm.httpResponseUncompressedSize != nil {
+			vecs = append(vecs, rlm.httpResponseUncompressedSize)
+		}
+		if rlm.httpResponseCompressionRatio != nil {
+			vecs = append(vecs, rlm.httpResponseCompressionRatio)
+		}
+		if rlm.httpRequestBytesTotal != nil {
+			vecs = append(vecs, rlm.httpRequestBytesTotal)
+		}
+		if rlm.httpResponseBytesTotal != nil {
+			vecs = append(vecs, rlm.httpResponseBytesTotal)
+		}
+		if rlm.httpRequestsRejected != nil {
+			vecs = append(vecs, rlm.httpRequestsRejected)
+		}
+		if rlm.httpRequestsAborted != nil {
+			vecs = append(vecs, rlm.httpRequestsAborted)
+		}
+		deleteSelfTestSeries(vecs...)
+	}()
+
+	// The middleware overhead histogram has no labels to mark placeholder series with, and the error budget
+	// burn rate window can't forget outcomes, so the placeholder requests are served by a copy that records neither.
+	selfTestRouter := *rlm
+	selfTestRouter.middlewareOverheadMicros = nil
+	selfTestRouter.errorBudgetBurnRate = nil
+
+	return selfTestPath("router metrics", func() {
+		path := "/" + selfTestLabelValue
+		engine := gin.New()
+		engine.Use(selfTestRouter.LogMetrics(""), MeasureUncompressedSize())
+		engine.GET(path, func(gc *gin.Context) {
+			gc.String(http.StatusOK, selfTestLabelValue)
+		})
+		engine.GET(path+"/aborted", func(gc *gin.Context) {
+			gc.AbortWithStatus(http.StatusUnauthorized)
+		})
+		engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, http.NoBody))
+		engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path+"/aborted", http.NoBody))
+		selfTestRouter.RecordConcurrencyRejection(path)
+		selfTestRouter.LogBatch([]models.HTTPMetrics{{Method: http.MethodGet, URL: path, Code: http.StatusOK}})
+	})
+}
+
+// SelfTest exercises each database observation path once with placeholder label values and returns
+// an error if recording panics due to misconfiguration. The placeholder series are deleted afterwards.
+func (dm *PromDBMetrics) SelfTest() error {
+	defer func() {
+		var vecs []deletableVec
+		if dm.operationsTotal != nil {
+			vecs = append(vecs, dm.operationsTotal)
+		}
+		if dm.operationsLatencyMillis != nil {
+			vecs = append(vecs, dm.operationsLatencyMillis)
+		}
+		if dm.operationsLatencyDigest != nil {
+			vecs = append(vecs, dm.operationsLatencyDigest)
+		}
+		if dm.connWaitMillis != nil {
+			vecs = append(vecs, dm.connWaitMillis)
+		}
+		if dm.rowsAffected != nil {
+			vecs = append(vecs, dm.rowsAffected)
+		}
+		deleteSelfTestSeries(vecs...)
+	}()
+
+	labelValues := &models.DBMetricsLabelValues{
+		OpType:    selfTestLabelValue,
+		Source:    selfTestLabelValue,
+		AdEntity:  selfTestLabelValue,
+		IsTxn:     selfTestLabelValue,
+		Statement: selfTestLabelValue,
+	}
+	return selfTestPath("database metrics", func() {
+		start := dm.LogMetricsPreWithAcquire(labelValues, time.Now())
+		dm.LogMetricsPost(nil, labelValues, start)
+		dm.LogMetricsPost(&ae.AppError{}, labelValues, start)
+		dm.RecordRowsAffected(labelValues, 1)
+	})
+}
+
+// SelfTest exercises each downstream service observation path once with placeholder label values and
+// returns an error if recording panics due to misconfiguration. The placeholder series are deleted afterwards.
+func (dsm *PromDownstreamServiceMetrics) SelfTest() error {
+	defer func() {
+		var vecs []deletableVec
+		if dsm.httpRequests != nil {
+			vecs = append(vecs, dsm.httpRequests)
+		}
+		if dsm.httpRequestsLatencyMillis != nil {
+			vecs = append(vecs, dsm.httpRequestsLatencyMillis)
+		}
+		if dsm.httpRequestsLatencyDigest != nil {
+			vecs = append(vecs, dsm.httpRequestsLatencyDigest)
+		}
+		if dsm.httpRequestSizeBytes != nil {
+			vecs = append(vecs, dsm.httpRequestSizeBytes)
+		}
+		if dsm.httpResponseSizeBytes != nil {
+			vecs = append(vecs, dsm.httpResponseSizeBytes)
+		}
+		if dsm.lastCallTimestampSeconds != nil {
+			vecs = append(vecs, dsm.lastCallTimestampSeconds)
+		}
+		if dsm.lastSuccessTimestampSeconds != nil {
+			vecs = append(vecs, dsm.lastSuccessTimestampSeconds)
+		}
+		if dsm.responseParseMillis != nil {
+			vecs = append(vecs, dsm.responseParseMillis)
+		}
+		if dsm.upstreamLatencyMillis != nil {
+			vecs = append(vecs, dsm.upstreamLatencyMillis)
+		}
+		if dsm.inFlight != nil {
+			vecs = append(vecs, dsm.inFlight)
+		}
+		if dsm.batchItemsTotal != nil {
+			vecs = append(vecs, dsm.batchItemsTotal)
+		}
+		if dsm.retryOutcomeTotal != nil {
+			vecs = append(vecs, dsm.retryOutcomeTotal)
+		}
+		if dsm.retryAttempts != nil {
+			vecs = append(vecs, dsm.retryAttempts)
+		}
+		if dsm.pagesFetched != nil {
+			vecs = append(vecs, dsm.pagesFetched)
+		}
+		if dsm.dnsMillis != nil {
+			vecs = append(vecs, dsm.dnsMillis)
+		}
+		if dsm.connectMillis != nil {
+			vecs = append(vecs, dsm.connectMillis)
+		}
+		if dsm.tlsMillis != nil {
+			vecs = append(vecs, dsm.tlsMillis)
+		}
+		deleteSelfTestSeries(vecs...)
+	}()
+
+	labelValues := &models.DownstreamServiceMetricsLabelValues{
+		Name:          selfTestLabelValue,
+		HTTPMethod:    selfTestLabelValue,
+		APIIdentifier: selfTestLabelValue,
+	}
+	httpMetrics := &models.HTTPMetrics{Method: selfTestLabelValue}
+
+	// The shared cardinality protection counter has no labels to mark placeholder series with, so the
+	// placeholder calls are recorded by a copy that doesn't normalize service names.
+	selfTestDownstream := *dsm
+	selfTestDownstream.serviceNameNormalizer = nil
+
+	return selfTestPath("downstream service metrics", func() {
+		selfTestDownstream.LogMetricsPre(labelValues)
+		selfTestDownstream.LogMetricsPost(true, labelValues, httpMetrics)
+		selfTestDownstream.LogMetricsPost(false, labelValues, httpMetrics)
+		selfTestDownstream.LogMetricsPostBatch(labelValues, httpMetrics, 1, 1)
+		selfTestDownstream.LogMetricsRetryOutcome(labelValues, constants.RetryOutcomeSucceededAfterRetry, 2)
+		selfTestDownstream.LogMetricsPostPaged(labelValues, httpMetrics, 2)
+		selfTestDownstream.LogMetricsShortCircuited(labelValues)
+		selfTestDownstream.RecordParseTime(labelValues, 0)
+		selfTestDownstream.RecordUpstreamLatency(labelValues, 0)
+		selfTestDownstream.RecordConnectionSetup(labelValues, models.ConnectionSetupTimes{})
+	})
+}
+
+// SelfTest exercises each cron job observation path once with placeholder label values and returns
+// an error if recording panics due to misconfiguration. The placeholder series are deleted afterwards.
+func (cjm *PromCronJobMetrics) SelfTest() error {
+	defer func() {
+		var vecs []deletableVec
+		if cjm.jobExecutionTotal != nil {
+			vecs = append(vecs, cjm.jobExecutionTotal)
+		}
+		if cjm.jobExecutionLatencyMillis != nil {
+			vecs = append(vecs, cjm.jobExecutionLatencyMillis)
+		}
+		if cjm.jobExecutionLatencyDigest != nil {
+			vecs = append(vecs, cjm.jobExecutionLatencyDigest)
+		}
+		if cjm.jobExecutionLatencyByJob != nil {
+			vecs = append(vecs, cjm.jobExecutionLatencyByJob)
+		}
+		if cjm.jobScheduleDriftMillis != nil {
+			vecs = append(vecs, cjm.jobScheduleDriftMillis)
+		}
+		if cjm.jobConsecutiveFailures != nil {
+			vecs = append(vecs, cjm.jobConsecutiveFailures)
+		}
+		if cjm.jobLastRunSuccess != nil {
+			vecs = append(vecs, cjm.jobLastRunSuccess)
+		}
+		if cjm.jobOverBudget != nil {
+			vecs = append(vecs, cjm.jobOverBudget)
+		}
+		deleteSelfTestSeries(vecs...)
+
+		cjm.mu.Lock()
+		delete(cjm.consecutiveFailures, selfTestLabelValue)
+		delete(cjm.budgets, selfTestLabelValue)
+		cjm.mu.Unlock()
+	}()
+
+	labelValues := &models.CronJobMetricsLabelValues{JobName: selfTestLabelValue}
+	return selfTestPath("cron job metrics", func() {
+		cjm.RecordBudget(labelValues.JobName, time.Nanosecond)
+		start := cjm.LogMetricsPre(labelValues)
+		cjm.LogMetricsPost(nil, labelValues, start)
+		start = cjm.LogMetricsPre(labelValues)
+		cjm.LogMetricsPost(&ae.AppError{}, labelValues, start)
+		cjm.RecordScheduleDrift(labelValues.JobName, start, start)
+	})
+}
+
+// SelfTest exercises each pub/sub observation path once with placeholder label values and returns
+// an error if recording panics due to misconfiguration. The placeholder series are deleted afterwards.
+func (psm *PromPSMetrics) SelfTest() error {
+	labelValues := &models.PSMetricsLabelValues{
+		Source:       selfTestLabelValue,
+		Entity:       selfTestLabelValue,
+		EntityOpType: selfTestLabelValue,
+	}
+	defer func() {
+		var vecs []deletableVec
+		if psm.totalMessagesConsumed != nil {
+			vecs = append(vecs, psm.totalMessagesConsumed)
+		}
+		if psm.totalMessagesPublished != nil {
+			vecs = append(vecs, psm.totalMessagesPublished)
+		}
+		if psm.messagesPublishedLatencyMillis != nil {
+			vecs = append(vecs, psm.messagesPublishedLatencyMillis)
+		}
+		if psm.messagesPublishedLatencyDigest != nil {
+			vecs = append(vecs, psm.messagesPublishedLatencyDigest)
+		}
+		if psm.messagesPublishedSizeBytes != nil {
+			vecs = append(vecs, psm.messagesPublishedSizeBytes)
+		}
+		if psm.messagesPublishedWireBytes != nil {
+			vecs = append(vecs, psm.messagesPublishedWireBytes)
+		}
+		if psm.publishConfirmLatencyMillis != nil {
+			vecs = append(vecs, psm.publishConfirmLatencyMillis)
+		}
+		if psm.messagesConsumedLatencyMillis != nil {
+			vecs = append(vecs, psm.messagesConsumedLatencyMillis)
+		}
+		if psm.messageQueueMillis != nil {
+			vecs = append(vecs, psm.messageQueueMillis)
+		}
+		if psm.messageProcessMillis != nil {
+			vecs = append(vecs, psm.messageProcessMillis)
+		}
+		if psm.publishSuccessRatio != nil {
+			vecs = append(vecs, psm.publishSuccessRatio)
+		}
+		if psm.publisherQueueDepth != nil {
+			vecs = append(vecs, psm.publisherQueueDepth)
+		}
+		if psm.subscriptionState != nil {
+			vecs = append(vecs, psm.subscriptionState)
+		}
+		if psm.publishConsumeRatio != nil {
+			vecs = append(vecs, psm.publishConsumeRatio)
+		}
+		deleteSelfTestSeries(vecs...)
+
+		psm.mu.Lock()
+		delete(psm.publishConsumeCounts, labelValues.Entity)
+		psm.mu.Unlock()
+	}()
+
+	return selfTestPath("pub/sub metrics", func() {
+		psm.LogMetricsPre(labelValues)
+		psm.LogMetricsPostWithWireSize(labelValues, &pubsub.EventTxnData{IsPublished: true, TimeTakenToPublish: time.Millisecond}, 1)
+		failed := *labelValues
+		failed.ErrorCode = selfTestLabelValue
+		psm.LogMetricsPost(&failed, &pubsub.EventTxnData{})
+		psm.RecordPublishConfirmLatency(labelValues, time.Millisecond)
+		psm.LogMetricsPostOutcome(labelValues, constants.ConsumeOutcomeSkipped, psm.LogMetricsPre(labelValues))
+		now := time.Now()
+		psm.LogMetricsPostWithTimestamps(labelValues, psm.LogMetricsPreWithTimestamps(labelValues, now, now))
+		psm.SetPublisherQueueDepth(labelValues.Entity, 0)
+		psm.SetSubscriptionState(labelValues.Source, constants.SubscriptionStateDisconnected)
+	})
+}
+
+// SelfTest exercises each application error observation path once with a placeholder error code and
+// returns an error if recording panics due to misconfiguration. The placeholder series are deleted afterwards.
+func (cm *PromAppMetrics) SelfTest() error {
+	defer func() {
+		var vecs []deletableVec
+		if cm.applicationErrorsCounter != nil {
+			vecs = append(vecs, cm.applicationErrorsCounter)
+		}
+		if cm.errorRatePerMin != nil {
+			vecs = append(vecs, cm.errorRatePerMin)
+		}
+		deleteSelfTestSeries(vecs...)
+	}()
+
+	return selfTestPath("application metrics", func() {
+		cm.LogMetrics([]string{selfTestLabelValue})
+		cm.DecrementAppErrorCount(selfTestLabelValue)
+	})
+}
+
+// SelfTest exercises each transaction observation path once with a placeholder source and returns
+// an error if recording panics due to misconfiguration. The placeholder series are deleted afterwards.
+func (tm *PromTxnMetrics) SelfTest() error {
+	defer func() {
+		var vecs []deletableVec
+		if tm.transactionsTotal != nil {
+			vecs = append(vecs, tm.transactionsTotal)
+		}
+		if tm.transactionDurationMillis != nil {
+			vecs = append(vecs, tm.transactionDurationMillis)
+		}
+		deleteSelfTestSeries(vecs...)
+	}()
+
+	return selfTestPath("transaction metrics", func() {
+		tm.BeginTxn(selfTestLabelValue).Commit()
+		tm.BeginTxn(selfTestLabelValue).Rollback()
+	})
+}
+
+// SelfTest exercises each operation observation path once with placeholder label values and returns
+// an error if recording panics due to misconfiguration. The placeholder series are deleted afterwards.
+// Dependency observations are not routed to the dependency metrics, which run their own self-tests.
+func (om *PromOperationMetrics) SelfTest() error {
+	defer func() {
+		var vecs []deletableVec
+		if om.operationDurationMillis != nil {
+			vecs = append(vecs, om.operationDurationMillis)
+		}
+		if om.dependencyDurationMillis != nil {
+			vecs = append(vecs, om.dependencyDurationMillis)
+		}
+		deleteSelfTestSeries(vecs...)
+	}()
+
+	unrouted := &PromOperationMetrics{
+		operationDurationMillis:  om.operationDurationMillis,
+		dependencyDurationMillis: om.dependencyDurationMillis,
+	}
+	return selfTestPath("operation metrics", func() {
+		op := unrouted.Start(selfTestLabelValue)
+		op.Dependency(selfTestLabelValue, selfTestLabelValue, nil).End(nil)
+		op.Dependency(selfTestLabelValue, selfTestLabelValue, nil).End(&ae.AppError{})
+		op.End(nil)
+		op.End(&ae.AppError{})
+	})
+}
+
+// SelfTest exercises each worker pool observation path once with a placeholder task type and returns
+// an error if recording panics due to misconfiguration. The placeholder series are deleted afterwards.
+func (wm *PromWorkerPoolMetrics) SelfTest() error {
+	defer func() {
+		var vecs []deletableVec
+		if wm.queueWaitMillis != nil {
+			vecs = append(vecs, wm.queueWaitMillis)
+		}
+		if wm.execMillis != nil {
+			vecs = append(vecs, wm.execMillis)
+		}
+		if wm.tasksTotal != nil {
+			vecs = append(vecs, wm.tasksTotal)
+		}
+		if wm.queueDepth != nil {
+			vecs = append(vecs, wm.queueDepth)
+		}
+		deleteSelfTestSeries(vecs...)
+	}()
+
+	return selfTestPath("worker pool metrics", func() {
+		task := wm.Enqueue(selfTestLabelValue)
+		task.Start()
+		task.Done(nil)
+		wm.Enqueue(selfTestLabelValue).Done(errors.New(selfTestLabelValue))
+	})
+}
+
+// SelfTest exercises the RED observation path once with a placeholder operation, succeeding and failing,
+// and returns an error if recording panics due to misconfiguration. The placeholder series are deleted afterwards.
+func (rm *PromREDMetrics) SelfTest() error {
+	defer deleteSelfTestSeries(rm.requestsTotal, rm.requestDurationMillis)
+
+	return selfTestPath("RED metrics", func() {
+		rm.Observe(selfTestLabelValue, 0, nil)
+		rm.Observe(selfTestLabelValue, 0, errors.New(selfTestLabelValue))
+	})
+}
+
+// SelfTest exercises the readiness observation path once with a placeholder component and returns
+// an error if recording panics due to misconfiguration. The placeholder series are deleted afterwards.
+func (rm *PromReadinessMetrics) SelfTest() error {
+	defer func() {
+		if rm.appReady != nil {
+			deleteSelfTestSeries(rm.appReady)
+		}
+	}()
+
+	return selfTestPath("readiness metrics", func() {
+		rm.SetReady(selfTestLabelValue, true)
+	})
+}
@@ -0,0 +1,131 @@
+package prometheus
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// defaultSeriesCacheSize is the number of series cached per metric when a non-positive size is given.
+const defaultSeriesCacheSize = 10000
+
+// seriesCacheKeySize is the size of the stack buffer series cache keys are built in; longer keys are
+// built on the heap.
+const seriesCacheKeySize = 256
+
+var (
+	// seriesCacheSize is the number of series cached per metric, or 0 when the series cache is disabled.
+	seriesCacheSize atomic.Int64
+
+	// seriesCaches holds the *seriesCache of every metric vec observed while the series cache is enabled, keyed by vec.
+	seriesCaches sync.Map
+)
+
+// EnableSeriesCache caches the counter, gauge and observer resolved for each label value combination of
+// the counter, gauge, histogram and summary vecs of this package, so that recording into a series seen
+// before skips the label values validation and hashing of the vec lookup, e.g. on the hot path of the
+// router middleware. At most maxSeriesPerMetric series are cached per metric, the first ones recorded;
+// the other series are looked up on every observation as before. A non-positive maxSeriesPerMetric
+// defaults to 10000. The series rate limits and allowlists still apply before the cache.
+//
+// Call it during startup. Series deleted from a vec returned by a Get*Metric method (e.g. with Delete or
+// Reset) stay cached, and recording into them is lost, until ClearSeriesCache is called. The t-digest
+// and bucket profile vecs are not cached.
+//
+// Example:
+//
+//	prometheus.EnableSeriesCache(0)
+func EnableSeriesCache(maxSeriesPerMetric int) {
+	if maxSeriesPerMetric <= 0 {
+		maxSeriesPerMetric = defaultSeriesCacheSize
+	}
+	seriesCacheSize.Store(int64(maxSeriesPerMetric))
+}
+
+// DisableSeriesCache stops caching series and drops the cached ones.
+func DisableSeriesCache() {
+	seriesCacheSize.Store(0)
+	ClearSeriesCache()
+}
+
+// ClearSeriesCache drops the cached series, e.g. after deleting series from a vec returned by a Get*Metric method.
+func ClearSeriesCache() {
+	seriesCaches.Range(func(vec, _ any) bool {
+		seriesCaches.Delete(vec)
+		return true
+	})
+}
+
+// seriesCache holds the series resolved for the label value combinations of one metric vec.
+type seriesCache struct {
+	size int
+
+	mu     sync.RWMutex
+	series map[string]any
+}
+
+// seriesCacheFor returns the series cache of vec, or nil when the series cache is disabled.
+func seriesCacheFor(vec any) *seriesCache {
+	size := seriesCacheSize.Load()
+	if size <= 0 {
+		return nil
+	}
+	if cache, ok := seriesCaches.Load(vec); ok {
+		return cache.(*seriesCache)
+	}
+	cache, _ := seriesCaches.LoadOrStore(vec, &seriesCache{size: int(size), series: make(map[string]any)})
+	return cache.(*seriesCache)
+}
+
+// observerCacheFor returns the series cache of target when it is a histogram or summary vec, whose series
+// don't depend on anything but their label values, or nil otherwise.
+func observerCacheFor(target labelObserver) *seriesCache {
+	switch target.(type) {
+	case *prometheus.HistogramVec, *prometheus.SummaryVec:
+		return seriesCacheFor(target)
+	}
+	return nil
+}
+
+// load returns the series cached for labelValues. The key is built on the stack, so a hit doesn't allocate.
+func (sc *seriesCache) load(labelValues []string) (any, bool) {
+	if sc == nil {
+		return nil, false
+	}
+	var buf [seriesCacheKeySize]byte
+	key := appendSeriesKey(buf[:0], labelValues)
+	sc.mu.RLock()
+	series, ok := sc.series[string(key)]
+	sc.mu.RUnlock()
+	return series, ok
+}
+
+// store caches series for labelValues, unless the cache is full.
+func (sc *seriesCache) store(labelValues []string, series any) {
+	if sc == nil {
+		return
+	}
+	key := string(appendSeriesKey(nil, labelValues))
+	sc.mu.Lock()
+	if len(sc.series) < sc.size {
+		sc.series[key] = series
+	}
+	sc.mu.Unlock()
+}
+
+// forgetCachedSeries drops the cached series of vec, after some of its series were deleted.
+func forgetCachedSeries(vec any) {
+	seriesCaches.Delete(vec)
+}
+
+// appendSeriesKey appends the label values joined by labelKeySeparator to dst.
+func appendSeriesKey(dst []byte, labelValues []string) []byte {
+	for i, value := range labelValues {
+		if i > 0 {
+			dst = append(dst, labelKeySeparator...)
+		}
+		dst = append(dst, value...)
+	}
+	return dst
+}
@@ -0,0 +1,81 @@
+package prometheus
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// useSeriesCache enables the series cache with maxSeriesPerMetric, disabling it when the test ends.
+func useSeriesCache(t testing.TB, maxSeriesPerMetric int) {
+	t.Helper()
+	EnableSeriesCache(maxSeriesPerMetric)
+	t.Cleanup(DisableSeriesCache)
+}
+
+func TestSeriesCacheIsBounded(t *testing.T) {
+	useTestRegistry(t)
+	useSeriesCache(t, 2)
+	vec := GetPromCounterVec("cache", "orders_total", "Counts orders", []string{"region"})
+
+	for _, region := range []string{"eu", "us", "apac", "eu", "apac"} {
+		counterWith(vec, region).Inc()
+	}
+
+	if got := len(seriesCacheFor(vec).series); got != 2 {
+		t.Errorf("cached series = %d, want 2", got)
+	}
+	for region, want := range map[string]float64{"eu": 2, "us": 1, "apac": 2} {
+		if got := testutil.ToFloat64(vec.WithLabelValues(region)); got != want {
+			t.Errorf("orders in %s = %v, want %v", region, got, want)
+		}
+	}
+}
+
+func TestClearSeriesCacheRecordsIntoDeletedSeriesAgain(t *testing.T) {
+	useTestRegistry(t)
+	useSeriesCache(t, 0)
+	vec := GetPromCounterVec("cache", "orders_total", "Counts orders", []string{"region"})
+	counterWith(vec, "eu").Inc()
+	vec.DeleteLabelValues("eu")
+
+	ClearSeriesCache()
+	counterWith(vec, "eu").Inc()
+
+	if got := testutil.ToFloat64(vec.WithLabelValues("eu")); got != 1 {
+		t.Errorf("orders recorded after the series was deleted = %v, want 1", got)
+	}
+}
+
+// BenchmarkSeriesCache compares recording into a series seen before with and without the series cache,
+// for a counter increment and a histogram observation.
+func BenchmarkSeriesCache(b *testing.B) {
+	useTestRegistry(b)
+	counter := GetPromCounterVec("cache", "bench_requests_total", "Counts requests", []string{"method", "code", "path", "status"})
+	histogram := GetPromHistogramVec("cache", "bench_request_latency_millis", "Tracks request latencies", []string{"method", "code", "path"}, nil)
+
+	for _, cached := range []bool{false, true} {
+		name := "uncached"
+		if cached {
+			name = "cached"
+		}
+		b.Run(name, func(b *testing.B) {
+			if cached {
+				useSeriesCache(b, 0)
+			}
+			b.Run("counter", func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					counterWith(counter, "GET", "200", "/users/:id", "success").Inc()
+				}
+			})
+			b.Run("histogram", func(b *testing.B) {
+				labelValues := []string{"GET", "200", "/users/:id"}
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					observerWith(histogram, labelValues).Observe(12)
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package prometheus
+
+import (
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/piyushkumar96/app-monitoring/constants"
+	"github.com/piyushkumar96/app-monitoring/models"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// seriesRateLimit is the new series rate limit configured on a models.MetricMeta.
+type seriesRateLimit struct {
+	metric    string
+	perMinute float64
+	burst     float64
+}
+
+var (
+	seriesRateLimitsMu sync.RWMutex
+	// seriesRateLimits holds the configured new series rate limits, keyed by fully-qualified metric name.
+	seriesRateLimits = make(map[string]seriesRateLimit)
+
+	// seriesLimiters holds the *seriesLimiter of every metric vec created with a new series rate limit, keyed by vec.
+	seriesLimiters sync.Map
+)
+
+// recordSeriesRateLimits records the new series rate limits of the metrics configured in meta, whose metric
+// names are mapped to their *models.MetricMeta field by metricFields, so that the vecs created afterwards get
+// their limiter. A metric configured without a limit drops the limit recorded by an earlier constructor call.
+func recordSeriesRateLimits[T any](namespace string, meta *T, metricFields map[string]string) {
+	value := reflect.ValueOf(meta).Elem()
+	namespace = withRole(namespace)
+
+	limited := false
+	seriesRateLimitsMu.Lock()
+	for name, field := range metricFields {
+		metricMeta, ok := value.FieldByName(field).Interface().(*models.MetricMeta)
+		if !ok || metricMeta == nil {
+			continue
+		}
+		key := prometheus.BuildFQName(namespace, "", name)
+		if metricMeta.NewSeriesPerMinute <= 0 {
+			delete(seriesRateLimits, key)
+			continue
+		}
+		burst := float64(metricMeta.NewSeriesBurst)
+		if burst <= 0 {
+			burst = max(metricMeta.NewSeriesPerMinute, 1)
+		}
+		seriesRateLimits[key] = seriesRateLimit{metric: name, perMinute: metricMeta.NewSeriesPerMinute, burst: burst}
+		limited = true
+	}
+	seriesRateLimitsMu.Unlock()
+
+	// The counter is created outside the lock, as creating a vec attaches its limiter.
+	if limited {
+		registerCardinalityProtection()
+	}
+}
+
+// attachSeriesLimiter gives vec a limiter when a new series rate limit is recorded for its metric,
+// and removes the limiter of an earlier construction otherwise.
+func attachSeriesLimiter(vec any, fqName string) {
+	seriesRateLimitsMu.RLock()
+	limit, ok := seriesRateLimits[fqName]
+	seriesRateLimitsMu.RUnlock()
+	if !ok {
+		seriesLimiters.Delete(vec)
+		return
+	}
+	seriesLimiters.Store(vec, &seriesLimiter{
+		limit:  limit,
+		seen:   make(map[string]struct{}),
+		tokens: limit.burst,
+		last:   time.Now(),
+	})
+}
+
+// limitNewSeries returns labelValues, or the overflow label values when they would create a new series
+// of vec beyond its new series rate limit.
+func limitNewSeries(vec any, labelValues []string) []string {
+	limiter, ok := seriesLimiters.Load(vec)
+	if !ok {
+		return labelValues
+	}
+	return limiter.(*seriesLimiter).admit(labelValues)
+}
+
+// forgetAdmittedSeries forgets the admission of the series of vec with the key built by appendSeriesKey,
+// after the series was deleted (e.g. by a TTLReaper), so that the admitted series don't accumulate and a
+// series created again counts against the rate limit.
+func forgetAdmittedSeries(vec any, key string) {
+	limiter, ok := seriesLimiters.Load(vec)
+	if !ok {
+		return
+	}
+	sl := limiter.(*seriesLimiter)
+	sl.mu.Lock()
+	delete(sl.seen, key)
+	sl.mu.Unlock()
+}
+
+// seriesLimiter is a token bucket limiting the rate at which new series of a metric vec are created.
+// The admitted series are remembered until they are deleted by a TTLReaper.
+type seriesLimiter struct {
+	limit seriesRateLimit
+
+	mu     sync.Mutex
+	seen   map[string]struct{}
+	tokens float64
+	last   time.Time
+}
+
+// admit returns labelValues when their series was admitted before or a token is available, and the
+// overflow label values otherwise, counting the fold in the cardinality protection counter. The key is built
+// on the stack, so admitting a series admitted before doesn't allocate.
+func (sl *seriesLimiter) admit(labelValues []string) []string {
+	var buf [seriesCacheKeySize]byte
+	key := appendSeriesKey(buf[:0], labelValues)
+
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+	if _, ok := sl.seen[string(key)]; ok {
+		return labelValues
+	}
+	now := time.Now()
+	sl.tokens = min(sl.limit.burst, sl.tokens+now.Sub(sl.last).Minutes()*sl.limit.perMinute)
+	sl.last = now
+	if sl.tokens < 1 {
+		recordCardinalityProtection(sl.limit.metric, constants.ReasonSeriesRateLimited)
+		overflow := make([]string, len(labelValues))
+		for i := range overflow {
+			overflow[i] = constants.SeriesOverflow
+		}
+		return overflow
+	}
+	sl.tokens--
+	sl.seen[string(key)] = struct{}{}
+	return labelValues
+}
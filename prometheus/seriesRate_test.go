@@ -0,0 +1,70 @@
+package prometheus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/piyushkumar96/app-monitoring/constants"
+	"github.com/piyushkumar96/app-monitoring/models"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// newRateLimitedCronJobMetrics creates cron job metrics whose execution counter admits burst new series,
+// and practically none after.
+func newRateLimitedCronJobMetrics(t *testing.T, burst int) (*PromCronJobMetrics, *seriesLimiter) {
+	t.Helper()
+	useTestRegistry(t)
+	cjm := NewPromCronJobMetrics(&models.CronJobMetricsMeta{
+		Namespace: "test",
+		JobExecutionTotal: &models.MetricMeta{
+			Labels:             cronTotalLabelNames,
+			NewSeriesPerMinute: 0.0001,
+			NewSeriesBurst:     burst,
+		},
+	}).(*PromCronJobMetrics)
+	limiter, ok := seriesLimiters.Load(cjm.jobExecutionTotal)
+	if !ok {
+		t.Fatal("the execution counter has no series limiter")
+	}
+	return cjm, limiter.(*seriesLimiter)
+}
+
+func TestSeriesLimiterForgetsReapedSeries(t *testing.T) {
+	cjm, limiter := newRateLimitedCronJobMetrics(t, 2)
+	reaper := NewTTLReaper(time.Nanosecond, time.Hour)
+	reaper.Register(cjm.GetJobExecutionTotalMetric())
+
+	for _, job := range []string{"sync_orders", "sync_users", "sync_invoices"} {
+		cjm.LogMetricsPre(&models.CronJobMetricsLabelValues{JobName: job})
+	}
+	if got := testutil.ToFloat64(cjm.jobExecutionTotal.WithLabelValues(constants.SeriesOverflow, constants.SeriesOverflow)); got != 1 {
+		t.Errorf("executions folded into the overflow series = %v, want 1", got)
+	}
+	time.Sleep(time.Millisecond)
+
+	if deleted := reaper.Sweep(); deleted != 3 {
+		t.Errorf("reaped series = %d, want 3", deleted)
+	}
+	limiter.mu.Lock()
+	admitted := len(limiter.seen)
+	limiter.mu.Unlock()
+	if admitted != 0 {
+		t.Errorf("admitted series remembered after reaping = %d, want 0", admitted)
+	}
+
+	cjm.LogMetricsPre(&models.CronJobMetricsLabelValues{JobName: "sync_orders"})
+	if got := testutil.ToFloat64(cjm.jobExecutionTotal.WithLabelValues(constants.SeriesOverflow, constants.SeriesOverflow)); got != 1 {
+		t.Errorf("a reaped series created again wasn't limited as a new series: overflow executions = %v, want 1", got)
+	}
+}
+
+func TestSeriesLimiterAdmitsKnownSeriesWithoutAllocating(t *testing.T) {
+	_, limiter := newRateLimitedCronJobMetrics(t, 1)
+	labelValues := []string{"sync_orders", constants.Total}
+	limiter.admit(labelValues)
+
+	if allocs := testing.AllocsPerRun(100, func() { limiter.admit(labelValues) }); allocs != 0 {
+		t.Errorf("allocations per admission of a known series = %v, want 0", allocs)
+	}
+}
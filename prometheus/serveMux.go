@@ -0,0 +1,168 @@
+package prometheus
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/piyushkumar96/app-monitoring/interfaces"
+	"github.com/piyushkumar96/app-monitoring/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Serve instruments a net/http application in one call, as LogMetrics does for gin: it registers Handler
+// at metricsPath on mux, serving the registry the metrics are registered against (see SetRegisterer), and
+// returns mux wrapped with WrapHandler. Serve the returned handler in place of mux, otherwise no request
+// is recorded; routes registered on mux afterwards are recorded as well.
+//
+// Example:
+//
+//	mux := http.NewServeMux()
+//	mux.HandleFunc("GET /users/{id}", getUser)
+//	handler := prometheus.Serve(routerMetrics, mux, "/metrics")
+//	log.Fatal(http.ListenAndServe(":8080", handler))
+func Serve(metrics interfaces.RouterMetricsInterface, mux *http.ServeMux, metricsPath string) http.Handler {
+	mux.Handle(metricsPath, Handler(getGatherer()))
+	return WrapHandler(metrics, metricsPath, mux)
+}
+
+// WrapHandler returns an http.Handler recording the requests served by next, typically an http.ServeMux,
+// with the router metrics. The path label is the pattern the request matched without its method and host
+// (e.g. "/users/{id}"), so next must set http.Request.Pattern, as http.ServeMux does; requests matching no
+// pattern are recorded according to NotFoundPolicy. When next is a *gin.Engine, the path label is the route
+// template the request matched instead, as recorded by LogMetrics (e.g. "/files/*filepath"), never the path
+// a wildcard expanded to; wrap the engine before registering its routes, as routes registered earlier are
+// recorded as matching no pattern.
+//
+// Requests are recorded as LogBatch records them, with the request size approximated as by LogMetrics, the
+// caller label resolved from CallerHeader and the extra label values read from ExtraLabelsKey in the request
+// context. The handler, content type and client bucket labels are recorded empty, and no exemplars, span events, aborted
+// requests or middleware overhead are recorded. Requests to metricsPath and the SkipPaths are not recorded.
+// next is returned unwrapped when metrics is not the Prometheus implementation, e.g. the NoOp implementation
+// returned while metrics are disabled.
+func WrapHandler(metrics interfaces.RouterMetricsInterface, metricsPath string, next http.Handler) http.Handler {
+	rlm, ok := metrics.(*PromRouterMetrics)
+	if !ok || rlm.idle {
+		return next
+	}
+	if engine, ok := next.(*gin.Engine); ok {
+		next = ginPatternHandler(engine)
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if rlm.skipPath(metricsPath, r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		start := rlm.now()
+		reqSize := int64(computeApproximateRequestSize(r))
+		recorder := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(recorder, r)
+
+		urlPath, ok := rlm.routePath(patternPath(r.Pattern), r.URL.Path)
+		if !ok {
+			return
+		}
+		caller := ""
+		if rlm.callerHeader != "" {
+			caller = rlm.caller(r.Header.Get(rlm.callerHeader))
+		}
+		var extra map[string]string
+		if rlm.extraLabelsKey != nil {
+			extra, _ = r.Context().Value(rlm.extraLabelsKey).(map[string]string)
+		}
+		rlm.logEntry(models.HTTPMetrics{
+			Method:                r.Method,
+			URL:                   urlPath,
+			Code:                  recorder.status(),
+			RequestBodySizeBytes:  reqSize,
+			ResponseBodySizeBytes: recorder.size,
+			ResponseTime:          rlm.now().Sub(start),
+			ExtraLabels:           extra,
+		}, caller)
+	})
+}
+
+// ginRouteKey is the request context key of the holder ginPatternHandler reads the matched route template from.
+type ginRouteKey struct{}
+
+// ginPatternHandler returns an http.Handler serving requests with engine, then setting http.Request.Pattern
+// to the route template the request matched, if any, so WrapHandler records it as the path label. The template
+// is captured by a middleware installed once ahead of the engine's own, so aborting middlewares don't skip it,
+// which writes c.FullPath() into a holder the handler stores in the request context: requests are served
+// through engine.ServeHTTP and its pooled contexts. gin combines the middlewares of a route when it is
+// registered, so a warning is logged when routes were registered before the engine was wrapped.
+func ginPatternHandler(engine *gin.Engine) http.Handler {
+	if routes := engine.Routes(); len(routes) > 0 {
+		logger().Warn("gin routes registered before WrapHandler are recorded as matching no pattern",
+			"code", "OnGinRoutesRegisteredBeforeWrap", "routes", len(routes))
+	}
+	captureRoute := func(c *gin.Context) {
+		if route, ok := c.Request.Context().Value(ginRouteKey{}).(*string); ok {
+			*route = c.FullPath()
+		}
+	}
+	engine.Handlers = append(gin.HandlersChain{captureRoute}, engine.Handlers...)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var route string
+		engine.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), ginRouteKey{}, &route)))
+		if route != "" {
+			r.Pattern = route
+		}
+	})
+}
+
+// patternPath returns the path of an http.ServeMux pattern ("[METHOD ][HOST]/[PATH]"), e.g. "/users/{id}"
+// for "GET /users/{id}", or an empty string for an empty pattern.
+func patternPath(pattern string) string {
+	if i := strings.IndexByte(pattern, '/'); i >= 0 {
+		return pattern[i:]
+	}
+	return ""
+}
+
+// statusRecorder is an http.ResponseWriter recording the status code and body size of a response.
+type statusRecorder struct {
+	http.ResponseWriter
+	code int
+	size int64
+}
+
+// WriteHeader records the first final (non-1XX) status code and writes it to the wrapped writer.
+func (sr *statusRecorder) WriteHeader(code int) {
+	if sr.code == 0 && code >= http.StatusOK {
+		sr.code = code
+	}
+	sr.ResponseWriter.WriteHeader(code)
+}
+
+// Write writes to the wrapped writer and adds the number of bytes written to the body size.
+func (sr *statusRecorder) Write(p []byte) (int, error) {
+	if sr.code == 0 {
+		sr.code = http.StatusOK
+	}
+	n, err := sr.ResponseWriter.Write(p)
+	sr.size += int64(n)
+	return n, err
+}
+
+// Flush flushes the wrapped writer when it is an http.Flusher, so streaming handlers keep working.
+func (sr *statusRecorder) Flush() {
+	if flusher, ok := sr.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap returns the wrapped writer, for http.ResponseController.
+func (sr *statusRecorder) Unwrap() http.ResponseWriter {
+	return sr.ResponseWriter
+}
+
+// status returns the recorded status code, or 200 when the handler wrote nothing, as net/http then responds.
+func (sr *statusRecorder) status() int {
+	if sr.code == 0 {
+		return http.StatusOK
+	}
+	return sr.code
+}
@@ -0,0 +1,115 @@
+package prometheus
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// successRatio is the sliding window of outcomes of one label combination.
+type successRatio struct {
+	labelValues []string
+	window      *slidingWindow
+}
+
+// successRatioVec is a Prometheus collector exposing, per label combination, the share of successful
+// outcomes recorded over a trailing sliding window. The ratio is evaluated at scrape time, so outcomes
+// age out of the window even when nothing new is recorded, and a series whose window holds no outcome
+// anymore is dropped instead of keeping its last value.
+type successRatioVec struct {
+	desc       *prometheus.Desc
+	labelNames []string
+	window     time.Duration
+
+	// ratios holds the window of each label combination, keyed by the joined label values and guarded by mu.
+	mu     sync.Mutex
+	ratios map[string]*successRatio
+}
+
+// newSuccessRatioVec creates and registers a new successRatioVec over the given window.
+// If the same metric is already registered, the registered vec is returned. If registration fails otherwise
+// (e.g., same name with different labels), an error is logged but the vec is still returned.
+func newSuccessRatioVec(namespace, name, help string, labelNames []string, window time.Duration) *successRatioVec {
+	namespace, name = validateMetricName(namespace, name)
+	vec := &successRatioVec{
+		desc:       prometheus.NewDesc(prometheus.BuildFQName(namespace, "", name), help, labelNames, nil),
+		labelNames: labelNames,
+		window:     window,
+		ratios:     make(map[string]*successRatio),
+	}
+	vec, err := registerCollector(vec)
+	if err != nil {
+		logger().Error("failed to register success ratio vec metric", "code", "OnSuccessRatioVecMetricRegisterFailure", "err", err.Error())
+	} else {
+		recordInventory(namespace, name, MetricTypeGauge, help, labelNames, nil, nil)
+	}
+	return vec
+}
+
+// record adds an outcome observed at now for the given label values.
+// Like the Prometheus vecs, it panics when the number of label values doesn't match the label names.
+func (v *successRatioVec) record(now time.Time, success bool, labelValues ...string) {
+	if len(labelValues) != len(v.labelNames) {
+		panic(fmt.Errorf("inconsistent label cardinality: expected %d label values but got %d in %#v", len(v.labelNames), len(labelValues), labelValues))
+	}
+	key := strings.Join(labelValues, labelKeySeparator)
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	ratio, ok := v.ratios[key]
+	if !ok {
+		ratio = &successRatio{labelValues: append([]string(nil), labelValues...), window: newSlidingWindow(v.window)}
+		v.ratios[key] = ratio
+	}
+	ratio.window.record(now, success)
+}
+
+// Delete removes the series with the given labels and reports whether it existed.
+func (v *successRatioVec) Delete(labels prometheus.Labels) bool {
+	labelValues := make([]string, len(v.labelNames))
+	for i, name := range v.labelNames {
+		value, ok := labels[name]
+		if !ok {
+			return false
+		}
+		labelValues[i] = value
+	}
+	key := strings.Join(labelValues, labelKeySeparator)
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if _, ok := v.ratios[key]; !ok {
+		return false
+	}
+	delete(v.ratios, key)
+	return true
+}
+
+// Describe implements prometheus.Collector.
+func (v *successRatioVec) Describe(ch chan<- *prometheus.Desc) {
+	ch <- v.desc
+}
+
+// Collect implements prometheus.Collector, exposing each ratio over the window ending at the scrape time.
+// Series whose window holds no outcome are dropped.
+func (v *successRatioVec) Collect(ch chan<- prometheus.Metric) {
+	now := time.Now()
+	v.mu.Lock()
+	metrics := make([]prometheus.Metric, 0, len(v.ratios))
+	for key, ratio := range v.ratios {
+		value, ok := ratio.window.ratio(now)
+		if !ok {
+			delete(v.ratios, key)
+			continue
+		}
+		metrics = append(metrics, prometheus.MustNewConstMetric(v.desc, prometheus.GaugeValue, value, ratio.labelValues...))
+	}
+	v.mu.Unlock()
+
+	for _, metric := range metrics {
+		ch <- metric
+	}
+}
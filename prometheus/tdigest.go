@@ -0,0 +1,139 @@
+package prometheus
+
+import (
+	"math"
+	"sort"
+)
+
+// tDigestCompression bounds the number of centroids kept per digest (roughly compression/2 after merging),
+// trading memory for accuracy. 100 keeps tail quantiles such as p99 within a fraction of a percent.
+const tDigestCompression = 100
+
+// tDigestBufferSize is the number of unmerged observations buffered before they are merged into the centroids.
+const tDigestBufferSize = 5 * tDigestCompression
+
+// centroid is a cluster of observations summarised by their mean and count.
+type centroid struct {
+	mean  float64
+	count float64
+}
+
+// tDigest is a merging t-digest estimating quantiles of a stream of observations in bounded memory.
+// Centroids are small near the tails and large near the median, so extreme quantiles stay accurate.
+// It is not safe for concurrent use; callers guard it with their own mutex.
+type tDigest struct {
+	centroids []centroid // sorted by mean
+	buffer    []float64
+	count     float64
+	min       float64
+	max       float64
+}
+
+// newTDigest returns an empty t-digest.
+func newTDigest() *tDigest {
+	return &tDigest{
+		buffer: make([]float64, 0, tDigestBufferSize),
+		min:    math.Inf(1),
+		max:    math.Inf(-1),
+	}
+}
+
+// add records one observation.
+func (td *tDigest) add(value float64) {
+	if math.IsNaN(value) {
+		return
+	}
+	td.buffer = append(td.buffer, value)
+	td.count++
+	td.min = math.Min(td.min, value)
+	td.max = math.Max(td.max, value)
+	if len(td.buffer) >= tDigestBufferSize {
+		td.merge()
+	}
+}
+
+// merge folds the buffered observations into the centroids, merging adjacent centroids
+// as long as the merged centroid stays within one unit of the k1 scale function.
+func (td *tDigest) merge() {
+	if len(td.buffer) == 0 {
+		return
+	}
+	points := make([]centroid, 0, len(td.centroids)+len(td.buffer))
+	points = append(points, td.centroids...)
+	for _, value := range td.buffer {
+		points = append(points, centroid{mean: value, count: 1})
+	}
+	td.buffer = td.buffer[:0]
+	sort.Slice(points, func(i, j int) bool { return points[i].mean < points[j].mean })
+
+	merged := make([]centroid, 0, len(td.centroids)+1)
+	current := points[0]
+	weightSoFar := 0.0
+	weightLimit := td.count * tDigestKInverse(tDigestK(0)+1)
+	for _, point := range points[1:] {
+		if weightSoFar+current.count+point.count <= weightLimit {
+			current.count += point.count
+			current.mean += (point.mean - current.mean) * point.count / current.count
+			continue
+		}
+		weightSoFar += current.count
+		merged = append(merged, current)
+		weightLimit = td.count * tDigestKInverse(tDigestK(weightSoFar/td.count)+1)
+		current = point
+	}
+	td.centroids = append(merged, current)
+}
+
+// quantile returns the estimated value at quantile q (0 <= q <= 1), or NaN when nothing was observed.
+func (td *tDigest) quantile(q float64) float64 {
+	td.merge()
+	centroids := td.centroids
+	if len(centroids) == 0 {
+		return math.NaN()
+	}
+	if len(centroids) == 1 || q <= 0 {
+		if q >= 1 {
+			return td.max
+		}
+		if q <= 0 {
+			return td.min
+		}
+		return centroids[0].mean
+	}
+	if q >= 1 {
+		return td.max
+	}
+
+	index := q * td.count
+	first := centroids[0]
+	if index < first.count/2 {
+		return td.min + (first.mean-td.min)*index/(first.count/2)
+	}
+
+	weightSoFar := 0.0
+	for i := 0; i < len(centroids)-1; i++ {
+		left := weightSoFar + centroids[i].count/2
+		right := weightSoFar + centroids[i].count + centroids[i+1].count/2
+		if index <= right {
+			return centroids[i].mean + (centroids[i+1].mean-centroids[i].mean)*(index-left)/(right-left)
+		}
+		weightSoFar += centroids[i].count
+	}
+
+	last := centroids[len(centroids)-1]
+	lastCenter := td.count - last.count/2
+	return last.mean + (td.max-last.mean)*(index-lastCenter)/(last.count/2)
+}
+
+// tDigestK is the k1 scale function mapping a quantile to the k scale.
+func tDigestK(q float64) float64 {
+	return tDigestCompression / (2 * math.Pi) * math.Asin(2*q-1)
+}
+
+// tDigestKInverse maps a k scale value back to its quantile, saturating at 1.
+func tDigestKInverse(k float64) float64 {
+	if k >= tDigestCompression/4 {
+		return 1
+	}
+	return (math.Sin(k*2*math.Pi/tDigestCompression) + 1) / 2
+}
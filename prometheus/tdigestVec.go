@@ -0,0 +1,246 @@
+package prometheus
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// defaultTDigestAgeBuckets is the number of digests a series rotates through when a max age is set
+// without age buckets, as prometheus.DefAgeBuckets is for summaries.
+const defaultTDigestAgeBuckets = 5
+
+// TDigestVec is a Prometheus collector backing a latency metric with one client-side t-digest per
+// label combination. Each configured quantile is exposed as its own gauge named "<name>_p<NN>"
+// (e.g. "http_request_latency_millis_p99"), computed from the digest at scrape time.
+//
+// Quantiles are computed per instance over all observations since startup, or over a rotating window
+// of the last max age (see GetPromTDigestVecWithMaxAge); like Summary quantiles, they can't be
+// meaningfully aggregated across instances. Prefer histograms when cross-instance aggregation matters,
+// and TDigestVec when a single instance's tail latency must be precise.
+type TDigestVec struct {
+	labelNames []string
+	quantiles  []float64
+	descs      []*prometheus.Desc
+	maxAge     time.Duration
+	ageBuckets int
+	now        func() time.Time
+
+	// series holds the digest of each label combination, keyed by the joined label values and guarded by mu.
+	mu     sync.RWMutex
+	series map[string]*tDigestSeries
+}
+
+// tDigestSeries is the digest of one label combination of a TDigestVec.
+// With a max age, the series keeps a ring of age buckets digests, each fed every observation, and
+// resets the oldest one, the head, every max age / age buckets, the way Summary streams rotate.
+// Quantiles are read from the head, which covers between max age * (1 - 1/age buckets) and max age.
+type tDigestSeries struct {
+	labelValues []string
+	now         func() time.Time
+
+	mu          sync.Mutex
+	digests     []*tDigest
+	head        int
+	rotateEvery time.Duration
+	headExpires time.Time
+}
+
+// newTDigestSeries returns an empty series rotating its digests over maxAge, or keeping a single
+// digest of every observation when maxAge is zero.
+func newTDigestSeries(labelValues []string, maxAge time.Duration, ageBuckets int, now func() time.Time) *tDigestSeries {
+	s := &tDigestSeries{labelValues: labelValues, now: now, digests: []*tDigest{newTDigest()}}
+	if maxAge > 0 {
+		s.digests = make([]*tDigest, ageBuckets)
+		for i := range s.digests {
+			s.digests[i] = newTDigest()
+		}
+		s.rotateEvery = maxAge / time.Duration(ageBuckets)
+		s.headExpires = now().Add(s.rotateEvery)
+	}
+	return s
+}
+
+// Observe records one observation into the series' digests.
+func (s *tDigestSeries) Observe(value float64) {
+	s.mu.Lock()
+	s.rotate()
+	for _, digest := range s.digests {
+		digest.add(value)
+	}
+	s.mu.Unlock()
+}
+
+// rotate resets the head digest and moves the head to the next one for every rotation period
+// elapsed since the head expired. It must be called with mu held.
+func (s *tDigestSeries) rotate() {
+	if s.rotateEvery == 0 {
+		return
+	}
+	now := s.now()
+	for !now.Before(s.headExpires) {
+		s.digests[s.head] = newTDigest()
+		s.head = (s.head + 1) % len(s.digests)
+		s.headExpires = s.headExpires.Add(s.rotateEvery)
+	}
+}
+
+// GetPromTDigestVec creates and registers a new TDigestVec metric.
+// It is an alternative to GetPromHistogramVec and GetPromSummaryVec for latency metrics whose
+// per-instance quantiles must be precise (see TDigestVec for the tradeoffs).
+//
+// Parameters:
+//   - namespace: The metric namespace (typically the application name)
+//   - name: The metric base name; each quantile gauge is named "<name>_p<NN>"
+//   - help: Description of what the metric measures
+//   - labelNames: Slice of label names for the metric dimensions
+//   - quantiles: The quantiles to expose, between 0 and 1 (e.g. []float64{0.5, 0.9, 0.99})
+//
+// Returns a TDigestVec that can be used to observe values with different label combinations.
+// If the same metric is already registered, the registered vec is returned. If registration fails otherwise
+// (e.g., same name with different labels), an error is logged but the vec is still returned.
+func GetPromTDigestVec(namespace, name, help string, labelNames []string, quantiles []float64) *TDigestVec {
+	return GetPromTDigestVecWithMaxAge(namespace, name, help, labelNames, quantiles, 0, 0)
+}
+
+// GetPromTDigestVecWithMaxAge creates and registers a new TDigestVec metric whose quantiles are computed
+// over the observations of the last maxAge only, like the MaxAge of a Prometheus Summary, so that they
+// reflect the current latency rather than everything since startup.
+//
+// Each series rotates through ageBuckets digests (5 when zero or negative), one of which is reset every
+// maxAge/ageBuckets; every observation is added to all of them, so more buckets smooth the rotation at the
+// cost of proportionally more work per observation. A zero or negative maxAge keeps all observations,
+// as GetPromTDigestVec does. The other parameters and the registration are those of GetPromTDigestVec.
+func GetPromTDigestVecWithMaxAge(namespace, name, help string, labelNames []string, quantiles []float64, maxAge time.Duration, ageBuckets int) *TDigestVec {
+	if maxAge < 0 {
+		maxAge = 0
+	}
+	if ageBuckets <= 0 {
+		ageBuckets = defaultTDigestAgeBuckets
+	}
+	namespace, name = validateMetricName(namespace, name)
+	labelNames = withExtraLabelNames(namespace, name, labelNames)
+	descs := make([]*prometheus.Desc, len(quantiles))
+	for i, q := range quantiles {
+		descs[i] = prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", name+"_"+quantileSuffix(q)),
+			quantileHelp(help, q),
+			labelNames, nil,
+		)
+	}
+	vec := &TDigestVec{
+		labelNames: labelNames,
+		quantiles:  quantiles,
+		descs:      descs,
+		maxAge:     maxAge,
+		ageBuckets: ageBuckets,
+		now:        time.Now,
+		series:     make(map[string]*tDigestSeries),
+	}
+	vec, err := registerCollector(vec)
+	if err != nil {
+		logger().Error("failed to register t-digest vec metric", "code", "OnTDigestVecMetricRegisterFailure", "err", err.Error())
+	} else {
+		for _, q := range quantiles {
+			recordInventory(namespace, name+"_"+quantileSuffix(q), MetricTypeGauge, quantileHelp(help, q), labelNames, nil, nil)
+		}
+	}
+	storeMetricInfo(vec, namespace, name, labelNames)
+	return vec
+}
+
+// quantileSuffix returns the metric name suffix for a quantile (0.5 -> "p50", 0.999 -> "p999").
+func quantileSuffix(q float64) string {
+	return "p" + strings.ReplaceAll(strconv.FormatFloat(q*100, 'f', -1, 64), ".", "")
+}
+
+// quantileHelp returns the help text of the gauge of a quantile, e.g. "Request latency (p99)".
+func quantileHelp(help string, q float64) string {
+	return fmt.Sprintf("%s (p%s)", help, strconv.FormatFloat(q*100, 'f', -1, 64))
+}
+
+// WithLabelValues returns the series for the given label values, creating it on first use.
+// Like the Prometheus vecs, it panics when the number of label values doesn't match the label names.
+func (v *TDigestVec) WithLabelValues(labelValues ...string) prometheus.Observer {
+	if len(labelValues) != len(v.labelNames) {
+		panic(fmt.Errorf("inconsistent label cardinality: expected %d label values but got %d in %#v", len(v.labelNames), len(labelValues), labelValues))
+	}
+	key := strings.Join(labelValues, labelKeySeparator)
+
+	v.mu.RLock()
+	series, ok := v.series[key]
+	v.mu.RUnlock()
+	if ok {
+		return series
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if series, ok = v.series[key]; !ok {
+		series = newTDigestSeries(append([]string(nil), labelValues...), v.maxAge, v.ageBuckets, v.now)
+		v.series[key] = series
+	}
+	return series
+}
+
+// Delete removes the series with the given labels and reports whether it existed.
+func (v *TDigestVec) Delete(labels prometheus.Labels) bool {
+	labelValues := make([]string, len(v.labelNames))
+	for i, name := range v.labelNames {
+		value, ok := labels[name]
+		if !ok {
+			return false
+		}
+		labelValues[i] = value
+	}
+	key := strings.Join(labelValues, labelKeySeparator)
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if _, ok := v.series[key]; !ok {
+		return false
+	}
+	delete(v.series, key)
+	return true
+}
+
+// Describe implements prometheus.Collector.
+func (v *TDigestVec) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range v.descs {
+		ch <- desc
+	}
+}
+
+// Collect implements prometheus.Collector, computing each quantile gauge from the series' digests.
+// Series without observations, or without observations within the max age, are skipped.
+func (v *TDigestVec) Collect(ch chan<- prometheus.Metric) {
+	v.mu.RLock()
+	series := make([]*tDigestSeries, 0, len(v.series))
+	for _, s := range v.series {
+		series = append(series, s)
+	}
+	v.mu.RUnlock()
+
+	for _, s := range series {
+		s.mu.Lock()
+		s.rotate()
+		digest := s.digests[s.head]
+		if digest.count == 0 {
+			s.mu.Unlock()
+			continue
+		}
+		values := make([]float64, len(v.quantiles))
+		for i, q := range v.quantiles {
+			values[i] = digest.quantile(q)
+		}
+		s.mu.Unlock()
+
+		for i, desc := range v.descs {
+			ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, values[i], s.labelValues...)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package prometheus
+
+import (
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestTDigestQuantilesOfKnownDistribution(t *testing.T) {
+	const n = 100000
+	td := newTDigest()
+	random := rand.New(rand.NewSource(1))
+	for _, i := range random.Perm(n) {
+		td.add(float64(i + 1))
+	}
+
+	// The error bounds are in rank: the t-digest is most accurate at the tails
+	tests := []struct {
+		q         float64
+		rankError float64
+	}{
+		{q: 0.5, rankError: 0.01},
+		{q: 0.9, rankError: 0.005},
+		{q: 0.99, rankError: 0.001},
+		{q: 0.999, rankError: 0.0005},
+	}
+	for _, tt := range tests {
+		got := td.quantile(tt.q)
+		if rank := got / n; math.Abs(rank-tt.q) > tt.rankError {
+			t.Errorf("quantile(%v) = %v (rank %v), want within %v of rank %v", tt.q, got, rank, tt.rankError, tt.q)
+		}
+	}
+	if got := td.quantile(0); got != 1 {
+		t.Errorf("quantile(0) = %v, want the minimum 1", got)
+	}
+	if got := td.quantile(1); got != n {
+		t.Errorf("quantile(1) = %v, want the maximum %v", got, n)
+	}
+}
+
+func TestTDigestEmptyAndSingleValue(t *testing.T) {
+	empty := newTDigest()
+	if got := empty.quantile(0.5); !math.IsNaN(got) {
+		t.Errorf("quantile(0.5) of an empty digest = %v, want NaN", got)
+	}
+	empty.add(math.NaN())
+	if empty.count != 0 {
+		t.Errorf("count after adding NaN = %v, want 0", empty.count)
+	}
+
+	single := newTDigest()
+	single.add(42)
+	for _, q := range []float64{0, 0.5, 0.99, 1} {
+		if got := single.quantile(q); got != 42 {
+			t.Errorf("quantile(%v) of a single value = %v, want 42", q, got)
+		}
+	}
+}
+
+func TestTDigestMergeCompressesCentroids(t *testing.T) {
+	td := newTDigest()
+	for i := 0; i < 10*tDigestBufferSize; i++ {
+		td.add(float64(i % 997))
+	}
+	if len(td.buffer) != 0 {
+		t.Errorf("buffered observations after a multiple of the buffer size = %d, want 0", len(td.buffer))
+	}
+	if got := len(td.centroids); got == 0 || got > tDigestCompression {
+		t.Errorf("centroids = %d, want between 1 and %d", got, tDigestCompression)
+	}
+
+	td.add(-1)
+	td.quantile(0.5)
+	var count float64
+	for i, c := range td.centroids {
+		count += c.count
+		if i > 0 && c.mean < td.centroids[i-1].mean {
+			t.Fatalf("centroid %d mean %v is below the previous %v", i, c.mean, td.centroids[i-1].mean)
+		}
+	}
+	if count != td.count {
+		t.Errorf("centroid counts sum to %v, want the %v observations", count, td.count)
+	}
+	if td.min != -1 || td.max != 996 {
+		t.Errorf("min, max = %v, %v, want -1, 996", td.min, td.max)
+	}
+}
+
+func TestTDigestVecMaxAgeRotatesOldObservationsOut(t *testing.T) {
+	useTestRegistry(t)
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	vec := GetPromTDigestVecWithMaxAge("", "job_latency_millis", "Job latency", []string{"job"}, []float64{0.5}, time.Minute, 2)
+	vec.now = clock.Now
+
+	for i := 0; i < 100; i++ {
+		vec.WithLabelValues("sync").Observe(100)
+	}
+	clock.advance(30 * time.Second)
+	vec.WithLabelValues("sync").Observe(5)
+	want := `
+# HELP job_latency_millis_p50 Job latency (p50)
+# TYPE job_latency_millis_p50 gauge
+job_latency_millis_p50{job="sync"} 100
+`
+	if err := testutil.CollectAndCompare(vec, strings.NewReader(want)); err != nil {
+		t.Errorf("within the max age: %v", err)
+	}
+
+	// Once the max age has passed, only the observations of the last age bucket remain
+	clock.advance(30 * time.Second)
+	want = strings.Replace(want, "} 100", "} 5", 1)
+	if err := testutil.CollectAndCompare(vec, strings.NewReader(want)); err != nil {
+		t.Errorf("after the max age: %v", err)
+	}
+
+	clock.advance(time.Minute)
+	if got := testutil.CollectAndCount(vec); got != 0 {
+		t.Errorf("series without observations within the max age = %d, want 0", got)
+	}
+}
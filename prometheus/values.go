@@ -0,0 +1,75 @@
+package prometheus
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// CurrentValues gathers the metrics of gatherer and returns their current values keyed by metric name,
+// then by label set, for debug endpoints and test assertions that shouldn't parse the text format.
+//
+// Counters, gauges and untyped metrics are flattened to their value. Histograms and summaries are
+// reported as two entries, "<name>_count" and "<name>_sum"; their buckets and quantiles are omitted.
+// The label set key renders the labels sorted by name, e.g. `{code="200",method="GET"}`, and is an
+// empty string for metrics without labels.
+//
+// Like Gather, it returns the values it could gather together with the gathering error, if any.
+//
+// Example:
+//
+//	values, err := prometheus.CurrentValues(client_prometheus.DefaultGatherer)
+//	total := values["myapp_http_requests"][`{code="200",method="GET",path="/users",status="success"}`]
+func CurrentValues(gatherer prometheus.Gatherer) (map[string]map[string]float64, error) {
+	families, err := gatherer.Gather()
+	values := make(map[string]map[string]float64, len(families))
+	set := func(name, labelSet string, value float64) {
+		if values[name] == nil {
+			values[name] = make(map[string]float64)
+		}
+		values[name][labelSet] = value
+	}
+
+	for _, family := range families {
+		name := family.GetName()
+		for _, metric := range family.GetMetric() {
+			labelSet := formatLabelSet(metric.GetLabel())
+			switch family.GetType() {
+			case dto.MetricType_COUNTER:
+				set(name, labelSet, metric.GetCounter().GetValue())
+			case dto.MetricType_GAUGE:
+				set(name, labelSet, metric.GetGauge().GetValue())
+			case dto.MetricType_UNTYPED:
+				set(name, labelSet, metric.GetUntyped().GetValue())
+			case dto.MetricType_HISTOGRAM, dto.MetricType_GAUGE_HISTOGRAM:
+				set(name+"_count", labelSet, float64(metric.GetHistogram().GetSampleCount()))
+				set(name+"_sum", labelSet, metric.GetHistogram().GetSampleSum())
+			case dto.MetricType_SUMMARY:
+				set(name+"_count", labelSet, float64(metric.GetSummary().GetSampleCount()))
+				set(name+"_sum", labelSet, metric.GetSummary().GetSampleSum())
+			}
+		}
+	}
+	return values, err
+}
+
+// formatLabelSet renders label pairs as `{name="value",...}`. Gathered label pairs are already sorted by name.
+func formatLabelSet(pairs []*dto.LabelPair) string {
+	if len(pairs) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteByte('{')
+	for i, pair := range pairs {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(pair.GetName())
+		sb.WriteByte('=')
+		sb.WriteString(strconv.Quote(pair.GetValue()))
+	}
+	sb.WriteByte('}')
+	return sb.String()
+}
@@ -0,0 +1,60 @@
+package prometheus
+
+import "time"
+
+// slidingWindowBuckets is the number of buckets a sliding window is divided into.
+// Outcomes are aggregated per bucket, so memory stays constant regardless of throughput.
+const slidingWindowBuckets = 10
+
+// windowBucket aggregates the outcomes recorded during one slice of a sliding window.
+type windowBucket struct {
+	start   time.Time
+	success float64
+	total   float64
+}
+
+// slidingWindow tracks success/total outcome counts over a trailing time window.
+// It is not safe for concurrent use; callers guard it with their own mutex.
+type slidingWindow struct {
+	bucketWidth time.Duration
+	buckets     [slidingWindowBuckets]windowBucket
+}
+
+// newSlidingWindow creates a sliding window covering the given length.
+func newSlidingWindow(length time.Duration) *slidingWindow {
+	bucketWidth := length / slidingWindowBuckets
+	if bucketWidth <= 0 {
+		bucketWidth = 1
+	}
+	return &slidingWindow{bucketWidth: bucketWidth}
+}
+
+// record adds an outcome observed at now to the window.
+func (w *slidingWindow) record(now time.Time, success bool) {
+	start := now.Truncate(w.bucketWidth)
+	bucket := &w.buckets[(start.UnixNano()/int64(w.bucketWidth))%slidingWindowBuckets]
+	if !bucket.start.Equal(start) {
+		*bucket = windowBucket{start: start}
+	}
+	bucket.total++
+	if success {
+		bucket.success++
+	}
+}
+
+// ratio returns the success ratio of the outcomes recorded within the window ending at now,
+// and false when no outcome was recorded within the window.
+func (w *slidingWindow) ratio(now time.Time) (float64, bool) {
+	oldest := now.Truncate(w.bucketWidth).Add(-w.bucketWidth * (slidingWindowBuckets - 1))
+	var success, total float64
+	for _, bucket := range w.buckets {
+		if bucket.total > 0 && !bucket.start.Before(oldest) {
+			success += bucket.success
+			total += bucket.total
+		}
+	}
+	if total == 0 {
+		return 0, false
+	}
+	return success / total, true
+}